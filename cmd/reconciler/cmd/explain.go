@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+
+	"golang-reconciliation-service/cmd/reconciler/config"
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/parsers"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Flags for the explain command. The input and scoring flags are shared
+// with the reconcile command.
+var (
+	explainTrxID  string
+	explainStmtID string
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Explain how a transaction and a bank statement score against each other",
+	Long: `Explain loads the same files as reconcile and scores one transaction
+against one bank statement, printing every component of the confidence score:
+the raw amount, date and type scores, their weights and contributions, the
+tolerances applied and whether the pair could be matched.
+
+The pair is scored on its own. Whether another pair would claim either side
+first is only known after a full reconciliation, whose JSON report lists the
+rejected candidates of every match.
+
+Examples:
+  # Why did TX001 not match BS001?
+  reconciler explain --trx TX001 --stmt BS001 --system-file tx.csv --bank-files stmt.csv
+
+  # Score with the settings of a reconcile run
+  reconciler explain --trx TX001 --stmt BS001 --system-file tx.csv --bank-files bca.csv:bca \
+    --date-tolerance 2 --amount-tolerance 0.5 --identifier-weight 0.3 --rules rules.csv`,
+
+	PreRunE: validateExplainFlags,
+	RunE:    runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringVar(&explainTrxID, "trx", "", "ID of the system transaction (required)")
+	explainCmd.Flags().StringVar(&explainStmtID, "stmt", "", "unique identifier of the bank statement (required)")
+	explainCmd.Flags().StringVarP(&systemFile, "system-file", "s", "", "path to system transaction CSV file (required)")
+	explainCmd.Flags().StringSliceVarP(&bankFiles, "bank-files", "b", []string{}, "comma-separated bank statement CSV files, optionally as path:profile (required)")
+	explainCmd.Flags().StringVar(&profilesDir, "profiles-dir", "", "directory of bank profile files (.yaml, .yml, .toml, .json) to load")
+	explainCmd.Flags().IntVarP(&dateTolerance, "date-tolerance", "d", 1, "date matching tolerance in days")
+	explainCmd.Flags().Float64VarP(&amountTolerance, "amount-tolerance", "a", 0.0, "amount tolerance percentage (0.0-100.0)")
+	explainCmd.Flags().Float64Var(&idWeight, "identifier-weight", 0.0, "weight of transaction ID similarity in the match score (0.0-1.0)")
+	explainCmd.Flags().Float64Var(&descWeight, "description-weight", 0.0, "weight of description similarity in the match score (0.0-1.0)")
+	explainCmd.Flags().StringVar(&baseCurrency, "base-currency", "", "currency amounts are converted to before matching (e.g. USD)")
+	explainCmd.Flags().StringVar(&fxRatesFile, "fx-rates", "", "path to FX rate CSV file (date,from_currency,to_currency,rate)")
+	explainCmd.Flags().StringVar(&rulesFile, "rules", "", "path to a match rules file (.csv, .yaml, .yml, .json)")
+	explainCmd.Flags().StringSliceVar(&dateWindows, "date-window", []string{}, "date windows as LAG:LEAD[:BANK][:TYPE], replacing the date tolerance")
+	explainCmd.Flags().StringSliceVar(&holidayFiles, "holidays", []string{}, "holiday calendars (.ics, .csv), as path for every bank or path:bank for one bank")
+
+	viper.BindPFlag("explain.trx", explainCmd.Flags().Lookup("trx"))
+	viper.BindPFlag("explain.stmt", explainCmd.Flags().Lookup("stmt"))
+	viper.BindPFlag("explain.system-file", explainCmd.Flags().Lookup("system-file"))
+	viper.BindPFlag("explain.bank-files", explainCmd.Flags().Lookup("bank-files"))
+	viper.BindPFlag("explain.profiles-dir", explainCmd.Flags().Lookup("profiles-dir"))
+	viper.BindPFlag("explain.date-tolerance", explainCmd.Flags().Lookup("date-tolerance"))
+	viper.BindPFlag("explain.amount-tolerance", explainCmd.Flags().Lookup("amount-tolerance"))
+	viper.BindPFlag("explain.identifier-weight", explainCmd.Flags().Lookup("identifier-weight"))
+	viper.BindPFlag("explain.description-weight", explainCmd.Flags().Lookup("description-weight"))
+	viper.BindPFlag("explain.base-currency", explainCmd.Flags().Lookup("base-currency"))
+	viper.BindPFlag("explain.fx-rates", explainCmd.Flags().Lookup("fx-rates"))
+	viper.BindPFlag("explain.rules", explainCmd.Flags().Lookup("rules"))
+	viper.BindPFlag("explain.holidays", explainCmd.Flags().Lookup("holidays"))
+	viper.BindPFlag("explain.date-window", explainCmd.Flags().Lookup("date-window"))
+}
+
+func validateExplainFlags(cmd *cobra.Command, args []string) error {
+	explainTrxID = viper.GetString("explain.trx")
+	explainStmtID = viper.GetString("explain.stmt")
+	systemFile = viper.GetString("explain.system-file")
+	bankFiles = viper.GetStringSlice("explain.bank-files")
+	profilesDir = viper.GetString("explain.profiles-dir")
+	dateTolerance = viper.GetInt("explain.date-tolerance")
+	amountTolerance = viper.GetFloat64("explain.amount-tolerance")
+	idWeight = viper.GetFloat64("explain.identifier-weight")
+	descWeight = viper.GetFloat64("explain.description-weight")
+	baseCurrency = viper.GetString("explain.base-currency")
+	fxRatesFile = viper.GetString("explain.fx-rates")
+	rulesFile = viper.GetString("explain.rules")
+	holidayFiles = viper.GetStringSlice("explain.holidays")
+	dateWindows = viper.GetStringSlice("explain.date-window")
+
+	if explainTrxID == "" || explainStmtID == "" {
+		return fmt.Errorf("both --trx and --stmt are required")
+	}
+	if systemFile == "" {
+		return fmt.Errorf("system-file is required")
+	}
+	if len(bankFiles) == 0 {
+		return fmt.Errorf("at least one bank-file is required")
+	}
+	if err := validateFileExists(systemFile, "system transaction file"); err != nil {
+		return err
+	}
+	if err := validateBankFiles(bankFiles); err != nil {
+		return err
+	}
+	return validateScoringFlags()
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	transactionConfig, err := config.CreateSystemParserConfig(viper.GetStringMap("system"))
+	if err != nil {
+		return fmt.Errorf("failed to create transaction parser config: %w", err)
+	}
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return fmt.Errorf("failed to load bank profiles: %w", err)
+	}
+	bankConfigs, err := config.ResolveBankConfigs(bankFiles, bankProfiles)
+	if err != nil {
+		return fmt.Errorf("failed to create bank configs: %w", err)
+	}
+	matchingConfig, err := createScoringConfig()
+	if err != nil {
+		return err
+	}
+
+	transactionParser, err := parsers.NewTransactionParser(transactionConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction parser: %w", err)
+	}
+	transactions, _, err := transactionParser.ParseTransactions(systemFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse transactions: %w", err)
+	}
+	var statements []*models.BankStatement
+	for _, path := range config.BankFilePaths(bankFiles) {
+		parser, err := parsers.NewBankStatementParser(bankConfigs[path])
+		if err != nil {
+			return fmt.Errorf("failed to create parser for %s: %w", path, err)
+		}
+		parsed, _, err := parser.ParseBankStatements(path)
+		if err != nil {
+			return fmt.Errorf("failed to parse bank statements from %s: %w", path, err)
+		}
+		statements = append(statements, parsed...)
+	}
+
+	tx, stmt, err := findExplainPair(transactions, statements)
+	if err != nil {
+		return err
+	}
+
+	engine := matcher.NewMatchingEngine(matchingConfig)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		return fmt.Errorf("failed to load transactions: %w", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		return fmt.Errorf("failed to load bank statements: %w", err)
+	}
+	explanation, err := engine.ExplainPair(tx, stmt)
+	if err != nil {
+		return fmt.Errorf("failed to score the pair: %w", err)
+	}
+
+	return printExplanation(cmd.OutOrStdout(), explanation)
+}
+
+// findExplainPair looks up the transaction and statement to explain. A
+// statement ID found in more than one bank file is ambiguous.
+func findExplainPair(transactions []*models.Transaction, statements []*models.BankStatement) (*models.Transaction, *models.BankStatement, error) {
+	var tx *models.Transaction
+	for _, candidate := range transactions {
+		if candidate.TrxID == explainTrxID {
+			tx = candidate
+			break
+		}
+	}
+	if tx == nil {
+		return nil, nil, fmt.Errorf("transaction %s not found in %s", explainTrxID, systemFile)
+	}
+
+	var found []*models.BankStatement
+	for _, candidate := range statements {
+		if candidate.UniqueIdentifier == explainStmtID {
+			found = append(found, candidate)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil, fmt.Errorf("bank statement %s not found in %s", explainStmtID, strings.Join(config.BankFilePaths(bankFiles), ", "))
+	case 1:
+		return tx, found[0], nil
+	default:
+		return nil, nil, fmt.Errorf("bank statement %s appears %d times; pass only the bank file it belongs to", explainStmtID, len(found))
+	}
+}
+
+// printExplanation writes the score breakdown of a pair and whether it
+// could be matched
+func printExplanation(out io.Writer, explanation *matcher.PairExplanation) error {
+	match := explanation.Match
+	tx, stmt := match.Transaction, match.BankStatement
+	breakdown := match.Breakdown
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Transaction:\t%s\t%s %s\t%s\n", tx.TrxID, tx.Amount.String(), tx.Type, tx.TransactionTime.Format("2006-01-02 15:04"))
+	fmt.Fprintf(w, "Bank statement:\t%s\t%s\t%s\n", stmt.UniqueIdentifier, stmt.Amount.String(), stmt.Date.Format("2006-01-02"))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "COMPONENT\tSCORE\tWEIGHT\tCONTRIBUTION\tNOTE")
+	for _, component := range breakdown.Components {
+		fmt.Fprintf(w, "%s\t%.3f\t%.2f\t%.3f\t%s\n", component.Name, component.Score, component.Weight, component.Contribution, component.Note)
+	}
+	if breakdown.Adjustment > 0.0005 || breakdown.Adjustment < -0.0005 {
+		fmt.Fprintf(w, "adjustment\t\t\t%+.3f\t%s\n", breakdown.Adjustment, "rescaled for weights that did not apply")
+	}
+	fmt.Fprintf(w, "confidence\t\t\t%.3f\t\n", match.ConfidenceScore)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Amount difference:\t%s (tolerance %s)\n", match.AmountDifference.String(), breakdown.AmountTolerance.String())
+	dateDifference := math.Round(match.DateDifference.Hours()/24*100) / 100
+	if window := breakdown.DateWindow; window != nil {
+		fmt.Fprintf(w, "Date difference:\t%g days (window %d days late, %d days early)\n", dateDifference, window.LagDays, window.LeadDays)
+	} else {
+		fmt.Fprintf(w, "Date difference:\t%g days (tolerance %d days)\n", dateDifference, breakdown.DateToleranceDays)
+	}
+	fmt.Fprintf(w, "Minimum confidence:\t%.2f\n", breakdown.MinConfidenceScore)
+	fmt.Fprintf(w, "Match type:\t%s\n", match.MatchType.String())
+	if len(match.Reasons) > 0 {
+		fmt.Fprintf(w, "Reasons:\t%s\n", strings.Join(match.Reasons, "; "))
+	}
+	fmt.Fprintf(w, "Verdict:\t%s\n", explainVerdict(explanation))
+	return w.Flush()
+}
+
+func explainVerdict(explanation *matcher.PairExplanation) string {
+	match := explanation.Match
+	switch {
+	case explanation.Rejection == "" && explanation.Rule != "":
+		return fmt.Sprintf("matched by rule %q", explanation.Rule)
+	case explanation.Rejection == matcher.RejectionExcludedByRule:
+		return fmt.Sprintf("not matched: the pair is excluded by rule %q", explanation.Rule)
+	case explanation.Rejection == matcher.RejectionIgnoredByRule:
+		return fmt.Sprintf("not matched: the statement is ignored by rule %q", explanation.Rule)
+	case !explanation.Candidate:
+		return "not matched: the statement is not a candidate for the transaction (outside the amount or date window, or beyond the candidate limit)"
+	case explanation.Rejection == matcher.RejectionBelowMinConfidence:
+		return fmt.Sprintf("not matched: confidence %.3f is below the minimum %.2f", match.ConfidenceScore, match.Breakdown.MinConfidenceScore)
+	default:
+		return "can be matched, unless a better scoring pair claims either side first"
+	}
+}
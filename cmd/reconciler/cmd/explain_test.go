@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestExplainCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemFile := filepath.Join(tmpDir, "transactions.csv")
+	bankFile := filepath.Join(tmpDir, "statements.csv")
+	rulesFile := filepath.Join(tmpDir, "rules.csv")
+	files := map[string]string{
+		systemFile: "trxID,amount,type,transactionTime\nTX001,100.00,CREDIT,2024-01-15T10:30:00Z\n",
+		bankFile:   "unique_identifier,amount,date\nBS001,100.00,2024-01-15\nBS002,99.00,2024-01-16\nBS003,100.00,2024-01-15\nBS003,100.00,2024-01-15\n",
+		rulesFile:  "action,trx_id,statement_id,id_prefix,name\nexclude,TX001,BS002,,wrong customer\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		trx          string
+		stmt         string
+		rules        string
+		expectError  string
+		expectOutput []string
+	}{
+		{
+			name:         "matching pair",
+			trx:          "TX001",
+			stmt:         "BS001",
+			expectOutput: []string{"amount", "date", "type", "confidence", "1.000", "can be matched"},
+		},
+		{
+			name:         "pair below the minimum confidence",
+			trx:          "TX001",
+			stmt:         "BS002",
+			expectOutput: []string{"not matched: confidence"},
+		},
+		{
+			name:         "excluded pair",
+			trx:          "TX001",
+			stmt:         "BS002",
+			rules:        rulesFile,
+			expectOutput: []string{`excluded by rule "wrong customer"`},
+		},
+		{
+			name:        "unknown transaction",
+			trx:         "TX999",
+			stmt:        "BS001",
+			expectError: "transaction TX999 not found",
+		},
+		{
+			name:        "ambiguous statement",
+			trx:         "TX001",
+			stmt:        "BS003",
+			expectError: "appears 2 times",
+		},
+		{
+			name:        "missing statement ID",
+			trx:         "TX001",
+			expectError: "both --trx and --stmt are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			viper.Set("explain.trx", tt.trx)
+			viper.Set("explain.stmt", tt.stmt)
+			viper.Set("explain.system-file", systemFile)
+			viper.Set("explain.bank-files", []string{bankFile})
+			viper.Set("explain.date-tolerance", 1)
+			viper.Set("explain.amount-tolerance", 5.0)
+			viper.Set("explain.rules", tt.rules)
+
+			var output bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetOut(&output)
+			err := validateExplainFlags(cmd, []string{})
+			if err == nil {
+				err = runExplain(cmd, []string{})
+			}
+
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tt.expectOutput {
+				if !strings.Contains(output.String(), expected) {
+					t.Errorf("output should contain %q, got:\n%s", expected, output.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang-reconciliation-service/cmd/reconciler/config"
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/internal/reporter"
+	"golang-reconciliation-service/internal/store"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Flags for the history commands
+var (
+	historyLimit        int
+	historySince        string
+	historyOutputFormat string
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse stored reconciliation runs",
+	Long: `History browses the reconciliation runs saved by the reconcile and serve
+commands. Each run keeps its full result, the matching configuration used and
+checksums of its input files.
+
+Runs are stored under --history-dir, which defaults to a "reconciler/history"
+directory in the user config directory.
+
+Examples:
+  reconciler history list --limit 10
+  reconciler history list --since 2024-01-01
+  reconciler history show 20240115-103000-1a2b3c
+  reconciler history show 20240115-1030 --output-format json`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored reconciliation runs, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryList,
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <run-id>",
+	Short: "Show a stored reconciliation run",
+	Long: `Show prints the inputs and configuration of a stored run followed by its
+report. The run ID may be shortened to any unique prefix.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistoryShow,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+
+	historyListCmd.Flags().IntVar(&historyLimit, "limit", 20, "maximum number of runs to list (0 for all)")
+	historyListCmd.Flags().StringVar(&historySince, "since", "", "only list runs created on or after this date (YYYY-MM-DD)")
+	historyShowCmd.Flags().StringVarP(&historyOutputFormat, "output-format", "f", "console", "output format: console, json, csv")
+}
+
+// historyStoreDir returns the configured history directory or the default
+// one in the user config directory
+func historyStoreDir() (string, error) {
+	if dir := viper.GetString("history-dir"); dir != "" {
+		return dir, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine history directory, set --history-dir: %w", err)
+	}
+	return filepath.Join(configDir, "reconciler", "history"), nil
+}
+
+func openHistoryStore() (store.ResultStore, error) {
+	dir, err := historyStoreDir()
+	if err != nil {
+		return nil, err
+	}
+	return store.NewFileStore(dir)
+}
+
+// loadPreviousRun returns the latest run of a ledger, or nil if it has none
+func loadPreviousRun(ledger string) (*store.Run, error) {
+	resultStore, err := openHistoryStore()
+	if err != nil {
+		return nil, err
+	}
+	defer resultStore.Close()
+
+	return store.PreviousRun(resultStore, ledger)
+}
+
+// saveRun stores a reconciliation result in the history and returns its run
+// ID. previous is the run whose open items were carried into the result.
+func saveRun(result *reconciler.ReconciliationResult, matchingConfig *matcher.MatchingConfig, ledger string, previous *store.Run) (string, error) {
+	resultStore, err := openHistoryStore()
+	if err != nil {
+		return "", err
+	}
+	defer resultStore.Close()
+
+	run, err := store.NewRun(result, matchingConfig)
+	if err != nil {
+		return "", err
+	}
+	run.Ledger = ledger
+	run.UpdateLedger(previous)
+	if err := resultStore.Save(run); err != nil {
+		return "", err
+	}
+	return run.ID, nil
+}
+
+func runHistoryList(cmd *cobra.Command, args []string) error {
+	options := store.ListOptions{Limit: historyLimit}
+	if historySince != "" {
+		since, err := time.ParseInLocation("2006-01-02", historySince, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid since date format, expected YYYY-MM-DD: %s", historySince)
+		}
+		options.Since = since
+	}
+
+	resultStore, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	defer resultStore.Close()
+
+	summaries, err := resultStore.List(options)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(summaries) == 0 {
+		fmt.Fprintln(out, "No reconciliation runs stored.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN ID\tCREATED\tLEDGER\tSYSTEM FILE\tBANK FILES\tMATCHED\tUNMATCHED STMTS\tDISCREPANCIES\tOPEN ITEMS")
+	for _, summary := range summaries {
+		bankNames := make([]string, 0, len(summary.BankFiles))
+		for _, path := range summary.BankFiles {
+			bankNames = append(bankNames, filepath.Base(path))
+		}
+		ledger := summary.Ledger
+		if ledger == "" {
+			ledger = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%d\t%d\t%d\n",
+			summary.ID,
+			summary.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			ledger,
+			filepath.Base(summary.SystemFile),
+			strings.Join(bankNames, ","),
+			summary.MatchedTransactions, summary.TotalTransactions,
+			summary.UnmatchedStatements,
+			summary.Discrepancies,
+			summary.OpenItems)
+	}
+	return w.Flush()
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	switch historyOutputFormat {
+	case "console", "json", "csv":
+	default:
+		return fmt.Errorf("invalid output format: %s (must be console, json, or csv)", historyOutputFormat)
+	}
+
+	resultStore, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	defer resultStore.Close()
+
+	run, err := store.FindRun(resultStore, args[0])
+	if err != nil {
+		return err
+	}
+	if run.Result == nil {
+		return fmt.Errorf("run %s has no stored result", run.ID)
+	}
+
+	out := cmd.OutOrStdout()
+	// The header would break machine-readable output
+	if historyOutputFormat == "console" {
+		printRunHeader(out, run)
+	}
+
+	reportGenerator, err := reporter.NewReportGenerator(config.CreateReportConfig(historyOutputFormat))
+	if err != nil {
+		return fmt.Errorf("failed to create report generator: %w", err)
+	}
+	if err := reportGenerator.GenerateReport(run.Result, out); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	return nil
+}
+
+// printRunHeader prints the inputs and matching configuration of a run
+func printRunHeader(out io.Writer, run *store.Run) {
+	fmt.Fprintf(out, "Run:         %s\n", run.ID)
+	fmt.Fprintf(out, "Created:     %s\n", run.CreatedAt.Local().Format("2006-01-02 15:04:05 MST"))
+	fmt.Fprintf(out, "System file: %s\n", describeRunFile(run.SystemFile))
+	for _, file := range run.BankFiles {
+		fmt.Fprintf(out, "Bank file:   %s\n", describeRunFile(file))
+	}
+
+	if mc := run.MatchingConfig; mc != nil {
+		fmt.Fprintf(out, "Matching:    date tolerance %d days, amount tolerance %.2f%%, %s assignment",
+			mc.DateToleranceDays, mc.AmountTolerancePercent, mc.AssignmentMode)
+		if mc.DateWindow != nil {
+			fmt.Fprintf(out, ", date window %d days late / %d days early", mc.DateWindow.LagDays, mc.DateWindow.LeadDays)
+		}
+		if len(mc.DateWindows) > 0 {
+			fmt.Fprintf(out, ", %d date window rules", len(mc.DateWindows))
+		}
+		if mc.BaseCurrency != "" {
+			fmt.Fprintf(out, ", base currency %s", mc.BaseCurrency)
+		}
+		fmt.Fprintln(out)
+	}
+
+	if run.Ledger != "" {
+		fmt.Fprintf(out, "Ledger:      %s\n", run.Ledger)
+	}
+	if run.CarriedFrom != "" {
+		fmt.Fprintf(out, "Previous:    %s\n", run.CarriedFrom)
+	}
+	fmt.Fprintf(out, "Open items:  %d\n", len(run.OpenItems))
+	if len(run.ResolvedItems) > 0 {
+		fmt.Fprintf(out, "Resolved:    %d open items from earlier runs\n", len(run.ResolvedItems))
+		for _, item := range run.ResolvedItems {
+			fmt.Fprintf(out, "  %s (opened in %s)\n", describeOpenItem(item), item.OpenedIn)
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+func describeOpenItem(item *store.OpenItem) string {
+	if item.Transaction != nil {
+		tx := item.Transaction
+		return fmt.Sprintf("transaction %s %s %s", tx.TrxID, tx.TransactionTime.Format("2006-01-02"), tx.Amount.StringFixed(2))
+	}
+	stmt := item.Statement
+	return fmt.Sprintf("statement %s %s %s", stmt.UniqueIdentifier, stmt.Date.Format("2006-01-02"), stmt.Amount.StringFixed(2))
+}
+
+func describeRunFile(file store.InputFile) string {
+	description := file.Path
+	if file.Profile != "" {
+		description += " (" + file.Profile + ")"
+	}
+	if len(file.SHA256) >= 12 {
+		description += fmt.Sprintf(" sha256:%s %d bytes", file.SHA256[:12], file.Size)
+	}
+	return description
+}
@@ -9,9 +9,16 @@ import (
 	"time"
 
 	"golang-reconciliation-service/cmd/reconciler/config"
+	"golang-reconciliation-service/internal/calendar"
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/parsers"
 	"golang-reconciliation-service/internal/reconciler"
 	"golang-reconciliation-service/internal/reporter"
+	"golang-reconciliation-service/internal/review"
+	"golang-reconciliation-service/internal/rules"
+	"golang-reconciliation-service/internal/store"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,7 +34,28 @@ var (
 	endDate         string
 	dateTolerance   int
 	amountTolerance float64
+	assignmentMode  string
+	workers         int
+	memoryLimit     int
+	spillDir        string
+	partialMatching bool
+	oneToMany       bool
+	nearMisses      int
+	autoAccept      float64
+	ambiguityReview float64
+	reviewFile      string
+	fxRatesFile     string
+	baseCurrency    string
+	rulesFile       string
+	holidayFiles    []string
+	dateWindows     []string
+	idWeight        float64
+	descWeight      float64
+	profilesDir     string
 	showProgress    bool
+	saveHistory     bool
+	carryForward    bool
+	ledgerName      string
 )
 
 // reconcileCmd represents the reconcile command
@@ -54,6 +82,47 @@ Examples:
     --output-format json --output-file report.json \
     --date-tolerance 2 --amount-tolerance 0.1
   
+  # Maximise total match confidence instead of matching in file order
+  reconciler reconcile --system-file tx.csv --bank-files stmt.csv --assignment optimal
+  
+  # Bank references carry our transaction IDs: score them and match on them
+  reconciler reconcile --system-file tx.csv --bank-files stmt.csv --identifier-weight 0.3
+  
+  # Both sides carry narration naming the counterparty: use it to break ties
+  reconciler reconcile --system-file tx.csv --bank-files stmt.csv --description-weight 0.2
+  
+  # Bind each bank file to a bank profile
+  reconciler reconcile --system-file tx.csv --bank-files chase.csv:Chase,bca.csv:bank1
+  
+  # Load extra bank profiles (YAML, TOML or JSON) from a directory
+  reconciler reconcile --system-file tx.csv --bank-files bca.csv:bca --profiles-dir ./profiles
+  
+  # Bank files in several currencies, compared in USD
+  reconciler reconcile --system-file tx.csv --bank-files eur.csv,usd.csv \
+    --base-currency USD --fx-rates rates.csv
+  
+  # Apply manual matches, exclusions and ignore patterns from a rules file
+  reconciler reconcile --system-file tx.csv --bank-files stmt.csv --rules rules.csv
+  
+  # Hold matches scoring below 0.9 for review, then finalise them from the
+  # reviewers' decisions
+  reconciler reconcile --system-file tx.csv --bank-files stmt.csv --auto-accept 0.9 --history
+  reconciler review <run-id> --decisions decisions.csv
+  
+  # Measure the date tolerance in business days, skipping Indonesian holidays
+  # for BCA statements
+  reconciler reconcile --system-file tx.csv --bank-files bca.csv:bca,chase.csv:Chase \
+    --date-tolerance 2 --holidays id-holidays.ics:bca
+  
+  # Let statements post up to 3 days after the transaction but never before,
+  # and Chase debits up to 1 day before
+  reconciler reconcile --system-file tx.csv --bank-files bca.csv:bca,chase.csv:Chase \
+    --date-window 3:0,3:1:Chase:DEBIT
+  
+  # Carry last period's unmatched items into this period's matching
+  reconciler reconcile --system-file feb.csv --bank-files bank-feb.csv \
+    --start-date 2024-02-01 --end-date 2024-02-29 --carry-forward --ledger operating --history
+  
   # With progress indicators
   reconciler reconcile --system-file tx.csv --bank-files stmt.csv --progress`,
 	
@@ -66,7 +135,8 @@ func init() {
 
 	// Required flags
 	reconcileCmd.Flags().StringVarP(&systemFile, "system-file", "s", "", "path to system transaction CSV file (required)")
-	reconcileCmd.Flags().StringSliceVarP(&bankFiles, "bank-files", "b", []string{}, "comma-separated paths to bank statement CSV files (required)")
+	reconcileCmd.Flags().StringSliceVarP(&bankFiles, "bank-files", "b", []string{}, "comma-separated bank statement CSV files, optionally as path:profile (required)")
+	reconcileCmd.Flags().StringVar(&profilesDir, "profiles-dir", "", "directory of bank profile files (.yaml, .yml, .toml, .json) to load")
 	
 	// Output flags
 	reconcileCmd.Flags().StringVarP(&outputFormat, "output-format", "f", "console", "output format: console, json, csv")
@@ -79,9 +149,31 @@ func init() {
 	// Matching configuration flags
 	reconcileCmd.Flags().IntVarP(&dateTolerance, "date-tolerance", "d", 1, "date matching tolerance in days")
 	reconcileCmd.Flags().Float64VarP(&amountTolerance, "amount-tolerance", "a", 0.0, "amount tolerance percentage (0.0-100.0)")
+	reconcileCmd.Flags().StringVar(&assignmentMode, "assignment", "greedy", "match assignment mode: greedy, optimal")
+	reconcileCmd.Flags().IntVar(&workers, "workers", 1, "number of workers scoring date partitions concurrently; results are the same for any number")
+	reconcileCmd.Flags().IntVar(&memoryLimit, "memory-limit", 0, "reconcile out of core, keeping the heap under this many MB by spilling sorted chunks to disk (0: in memory)")
+	reconcileCmd.Flags().StringVar(&spillDir, "spill-dir", "", "directory for out-of-core spill files (default: system temp directory)")
+	reconcileCmd.Flags().Float64Var(&idWeight, "identifier-weight", 0.0, "weight of transaction ID similarity in the match score (0.0-1.0); enables matching by reference ID")
+	reconcileCmd.Flags().Float64Var(&descWeight, "description-weight", 0.0, "weight of description similarity in the match score (0.0-1.0)")
+	reconcileCmd.Flags().BoolVar(&partialMatching, "partial-matching", false, "match leftover transactions against groups of bank statements that sum to them")
+	reconcileCmd.Flags().BoolVar(&oneToMany, "one-to-many", false, "match leftover bank statements against groups of transactions that sum to them")
+	reconcileCmd.Flags().IntVar(&nearMisses, "near-misses", 0, "number of nearest counterparts reported for each unmatched item, with what blocked the match (0: none)")
+	reconcileCmd.Flags().Float64Var(&autoAccept, "auto-accept", 0.0, "hold matches scoring below this for review instead of matching them (0.0-1.0; 0: accept every match)")
+	reconcileCmd.Flags().Float64Var(&ambiguityReview, "ambiguity-review", 0.0, "hold the matches of same-day groups at least this ambiguous for review (0.0-1.0; 0: off)")
+	reconcileCmd.Flags().StringVar(&reviewFile, "review-decisions", "", "path to a review decisions file (.csv, .yaml, .yml, .json) applied to the matches held for review")
+	reconcileCmd.Flags().StringVar(&baseCurrency, "base-currency", "", "currency amounts are converted to before matching (e.g. USD)")
+	reconcileCmd.Flags().StringVar(&fxRatesFile, "fx-rates", "", "path to FX rate CSV file (date,from_currency,to_currency,rate)")
+	reconcileCmd.Flags().StringVar(&rulesFile, "rules", "", "path to a match rules file (.csv, .yaml, .yml, .json) with manual matches, exclusions and ignore patterns")
+	reconcileCmd.Flags().StringSliceVar(&dateWindows, "date-window", []string{}, "days a statement may be dated after and before its transaction, as LAG:LEAD, optionally limited to a bank and a transaction type (LAG:LEAD:BANK:TYPE); replaces the date tolerance")
+	reconcileCmd.Flags().StringSliceVar(&holidayFiles, "holidays", []string{}, "holiday calendars (.ics, .csv) measuring the date tolerance in business days, as path for every bank or path:bank for one bank")
 	
 	// UI flags
 	reconcileCmd.Flags().BoolVar(&showProgress, "progress", false, "show progress indicators")
+	
+	// History flags
+	reconcileCmd.Flags().BoolVar(&saveHistory, "history", false, "save the run to the history store (see the history command)")
+	reconcileCmd.Flags().BoolVar(&carryForward, "carry-forward", false, "match the open items left by the ledger's previous run along with this run's input")
+	reconcileCmd.Flags().StringVar(&ledgerName, "ledger", "", "name of the open-items ledger the run belongs to, e.g. one per account")
 
 	// Mark required flags
 	reconcileCmd.MarkFlagRequired("system-file")
@@ -90,25 +182,64 @@ func init() {
 	// Bind flags to viper
 	viper.BindPFlag("system-file", reconcileCmd.Flags().Lookup("system-file"))
 	viper.BindPFlag("bank-files", reconcileCmd.Flags().Lookup("bank-files"))
+	viper.BindPFlag("profiles-dir", reconcileCmd.Flags().Lookup("profiles-dir"))
 	viper.BindPFlag("output-format", reconcileCmd.Flags().Lookup("output-format"))
 	viper.BindPFlag("output-file", reconcileCmd.Flags().Lookup("output-file"))
 	viper.BindPFlag("start-date", reconcileCmd.Flags().Lookup("start-date"))
 	viper.BindPFlag("end-date", reconcileCmd.Flags().Lookup("end-date"))
 	viper.BindPFlag("date-tolerance", reconcileCmd.Flags().Lookup("date-tolerance"))
 	viper.BindPFlag("amount-tolerance", reconcileCmd.Flags().Lookup("amount-tolerance"))
+	viper.BindPFlag("assignment", reconcileCmd.Flags().Lookup("assignment"))
+	viper.BindPFlag("workers", reconcileCmd.Flags().Lookup("workers"))
+	viper.BindPFlag("memory-limit", reconcileCmd.Flags().Lookup("memory-limit"))
+	viper.BindPFlag("spill-dir", reconcileCmd.Flags().Lookup("spill-dir"))
+	viper.BindPFlag("identifier-weight", reconcileCmd.Flags().Lookup("identifier-weight"))
+	viper.BindPFlag("description-weight", reconcileCmd.Flags().Lookup("description-weight"))
+	viper.BindPFlag("partial-matching", reconcileCmd.Flags().Lookup("partial-matching"))
+	viper.BindPFlag("one-to-many", reconcileCmd.Flags().Lookup("one-to-many"))
+	viper.BindPFlag("near-misses", reconcileCmd.Flags().Lookup("near-misses"))
+	viper.BindPFlag("auto-accept", reconcileCmd.Flags().Lookup("auto-accept"))
+	viper.BindPFlag("ambiguity-review", reconcileCmd.Flags().Lookup("ambiguity-review"))
+	viper.BindPFlag("review-decisions", reconcileCmd.Flags().Lookup("review-decisions"))
+	viper.BindPFlag("base-currency", reconcileCmd.Flags().Lookup("base-currency"))
+	viper.BindPFlag("fx-rates", reconcileCmd.Flags().Lookup("fx-rates"))
+	viper.BindPFlag("rules", reconcileCmd.Flags().Lookup("rules"))
+	viper.BindPFlag("holidays", reconcileCmd.Flags().Lookup("holidays"))
+	viper.BindPFlag("date-window", reconcileCmd.Flags().Lookup("date-window"))
 	viper.BindPFlag("progress", reconcileCmd.Flags().Lookup("progress"))
+	viper.BindPFlag("history", reconcileCmd.Flags().Lookup("history"))
+	viper.BindPFlag("carry-forward", reconcileCmd.Flags().Lookup("carry-forward"))
+	viper.BindPFlag("ledger", reconcileCmd.Flags().Lookup("ledger"))
 }
 
 func validateReconcileFlags(cmd *cobra.Command, args []string) error {
 	// Get values from viper (allows override from config file)
 	systemFile = viper.GetString("system-file")
 	bankFiles = viper.GetStringSlice("bank-files")
+	profilesDir = viper.GetString("profiles-dir")
 	outputFormat = viper.GetString("output-format")
 	outputFile = viper.GetString("output-file")
 	startDate = viper.GetString("start-date")
 	endDate = viper.GetString("end-date")
 	dateTolerance = viper.GetInt("date-tolerance")
 	amountTolerance = viper.GetFloat64("amount-tolerance")
+	assignmentMode = viper.GetString("assignment")
+	workers = viper.GetInt("workers")
+	memoryLimit = viper.GetInt("memory-limit")
+	spillDir = viper.GetString("spill-dir")
+	idWeight = viper.GetFloat64("identifier-weight")
+	descWeight = viper.GetFloat64("description-weight")
+	partialMatching = viper.GetBool("partial-matching")
+	oneToMany = viper.GetBool("one-to-many")
+	nearMisses = viper.GetInt("near-misses")
+	autoAccept = viper.GetFloat64("auto-accept")
+	ambiguityReview = viper.GetFloat64("ambiguity-review")
+	reviewFile = viper.GetString("review-decisions")
+	baseCurrency = viper.GetString("base-currency")
+	fxRatesFile = viper.GetString("fx-rates")
+	rulesFile = viper.GetString("rules")
+	holidayFiles = viper.GetStringSlice("holidays")
+	dateWindows = viper.GetStringSlice("date-window")
 	showProgress = viper.GetBool("progress")
 
 	// Validate required flags
@@ -124,10 +255,8 @@ func validateReconcileFlags(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	for i, bankFile := range bankFiles {
-		if err := validateFileExists(bankFile, fmt.Sprintf("bank file %d", i+1)); err != nil {
-			return err
-		}
+	if err := validateBankFiles(bankFiles); err != nil {
+		return err
 	}
 
 	// Validate output format
@@ -157,14 +286,41 @@ func validateReconcileFlags(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Validate tolerances
-	if dateTolerance < 0 {
-		return fmt.Errorf("date tolerance cannot be negative")
+	if err := validateScoringFlags(); err != nil {
+		return err
 	}
-	if amountTolerance < 0.0 || amountTolerance > 100.0 {
-		return fmt.Errorf("amount tolerance must be between 0.0 and 100.0")
+	if _, err := matcher.ParseAssignmentMode(assignmentMode); err != nil {
+		return err
+	}
+	if workers < 0 {
+		return fmt.Errorf("workers cannot be negative: %d", workers)
+	}
+	if nearMisses < 0 {
+		return fmt.Errorf("near misses cannot be negative: %d", nearMisses)
+	}
+	if memoryLimit < 0 {
+		return fmt.Errorf("memory limit cannot be negative: %d", memoryLimit)
+	}
+	if autoAccept < 0.0 || autoAccept > 1.0 {
+		return fmt.Errorf("auto-accept score must be between 0.0 and 1.0: %f", autoAccept)
+	}
+	if ambiguityReview < 0.0 || ambiguityReview > 1.0 {
+		return fmt.Errorf("ambiguity review score must be between 0.0 and 1.0: %f", ambiguityReview)
+	}
+	// Out-of-core batches may split a day, so its ambiguity cannot be scored
+	if ambiguityReview > 0 && memoryLimit > 0 {
+		return fmt.Errorf("ambiguity-review cannot be combined with memory-limit")
+	}
+	if reviewFile != "" {
+		if err := validateFileExists(reviewFile, "review decisions file"); err != nil {
+			return err
+		}
+	}
+	if spillDir != "" {
+		if info, err := os.Stat(spillDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("spill directory does not exist: %s", spillDir)
+		}
 	}
-
 	// Validate output file directory exists if specified
 	if outputFile != "" {
 		dir := filepath.Dir(outputFile)
@@ -178,6 +334,131 @@ func validateReconcileFlags(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// validateBankFiles loads the user-defined profiles and checks every bank
+// file exists and names a known profile
+func validateBankFiles(bankFiles []string) error {
+	// User-defined profiles must be registered before bank files are bound to them
+	if profilesDir != "" {
+		if _, err := parsers.DefaultProfileRegistry.LoadDir(profilesDir); err != nil {
+			return err
+		}
+	}
+
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return err
+	}
+	for i, bankFile := range bankFiles {
+		spec := config.ParseBankFileSpec(bankFile)
+		if err := validateFileExists(spec.Path, fmt.Sprintf("bank file %d", i+1)); err != nil {
+			return err
+		}
+		if spec.Profile != "" {
+			if _, err := config.LookupBankProfile(spec.Profile, bankProfiles); err != nil {
+				return fmt.Errorf("bank file %d: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateScoringFlags checks the flags that decide how pairs are scored,
+// which the reconcile and explain commands share
+func validateScoringFlags() error {
+	// Validate tolerances
+	if dateTolerance < 0 {
+		return fmt.Errorf("date tolerance cannot be negative")
+	}
+	if amountTolerance < 0.0 || amountTolerance > 100.0 {
+		return fmt.Errorf("amount tolerance must be between 0.0 and 100.0")
+	}
+	if idWeight < 0.0 || idWeight >= 1.0 {
+		return fmt.Errorf("identifier weight must be at least 0.0 and below 1.0: %f", idWeight)
+	}
+	if descWeight < 0.0 || descWeight >= 1.0 {
+		return fmt.Errorf("description weight must be at least 0.0 and below 1.0: %f", descWeight)
+	}
+	if idWeight+descWeight >= 1.0 {
+		return fmt.Errorf("identifier and description weights must add up to less than 1.0: %f", idWeight+descWeight)
+	}
+
+	// Validate currency conversion settings
+	if _, err := models.ParseCurrencyCode(baseCurrency); err != nil {
+		return fmt.Errorf("invalid base currency: %w", err)
+	}
+	if fxRatesFile != "" {
+		if baseCurrency == "" {
+			return fmt.Errorf("fx-rates requires base-currency to be set")
+		}
+		if err := validateFileExists(fxRatesFile, "FX rate file"); err != nil {
+			return err
+		}
+	}
+
+	if rulesFile != "" {
+		if err := validateFileExists(rulesFile, "rules file"); err != nil {
+			return err
+		}
+	}
+	
+	if err := validateDateWindows(dateWindows); err != nil {
+		return err
+	}
+	return validateHolidayFiles(holidayFiles)
+}
+
+// validateDateWindows checks every date window parses, with at most one
+// window for each bank and transaction type
+func validateDateWindows(dateWindows []string) error {
+	seen := make(map[string]bool)
+	for i, dateWindow := range dateWindows {
+		rule, err := config.ParseDateWindowSpec(dateWindow)
+		if err != nil {
+			return fmt.Errorf("invalid date window %d: %w", i+1, err)
+		}
+		scope := strings.ToLower(rule.Bank) + ":" + string(rule.Type)
+		if seen[scope] {
+			return fmt.Errorf("date window %d: %s already has a window", i+1, describeDateWindowScope(rule))
+		}
+		seen[scope] = true
+	}
+	return nil
+}
+
+func describeDateWindowScope(rule matcher.DateWindowRule) string {
+	switch {
+	case rule.Bank != "" && rule.Type != "":
+		return fmt.Sprintf("%s %s", rule.Bank, rule.Type)
+	case rule.Bank != "":
+		return rule.Bank
+	case rule.Type != "":
+		return string(rule.Type)
+	default:
+		return "every bank"
+	}
+}
+
+// validateHolidayFiles checks every holiday calendar exists, with at most one
+// calendar for every bank and one for each bank
+func validateHolidayFiles(holidayFiles []string) error {
+	seen := make(map[string]bool)
+	for i, holidayFile := range holidayFiles {
+		spec := config.ParseBankFileSpec(holidayFile)
+		if err := validateFileExists(spec.Path, fmt.Sprintf("holiday calendar %d", i+1)); err != nil {
+			return err
+		}
+		bank := strings.ToLower(spec.Profile)
+		if seen[bank] {
+			if bank == "" {
+				return fmt.Errorf("holiday calendar %d: only one calendar can apply to every bank", i+1)
+			}
+			return fmt.Errorf("holiday calendar %d: bank %s already has a calendar", i+1, spec.Profile)
+		}
+		seen[bank] = true
+	}
+	return nil
+}
+
 func validateFileExists(filePath, description string) error {
 	if filePath == "" {
 		return fmt.Errorf("%s path cannot be empty", description)
@@ -219,31 +500,53 @@ func runReconcile(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create configurations
-	transactionConfig, err := config.CreateTransactionParserConfig()
+	transactionConfig, err := config.CreateSystemParserConfig(viper.GetStringMap("system"))
 	if err != nil {
 		return fmt.Errorf("failed to create transaction parser config: %w", err)
 	}
 
-	bankConfigs, err := config.CreateBankConfigs(bankFiles)
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return fmt.Errorf("failed to load bank profiles: %w", err)
+	}
+
+	bankConfigs, err := config.ResolveBankConfigs(bankFiles, bankProfiles)
 	if err != nil {
 		return fmt.Errorf("failed to create bank configs: %w", err)
 	}
+	bankPaths := config.BankFilePaths(bankFiles)
+	if viper.GetBool("verbose") {
+		for _, path := range bankPaths {
+			fmt.Fprintf(os.Stderr, "Bank file %s uses profile %s\n", path, bankConfigs[path].Name)
+		}
+	}
 
-	matchingConfig := config.CreateMatchingConfig(dateTolerance, amountTolerance)
+	matchingConfig, err := createScoringConfig()
+	if err != nil {
+		return err
+	}
+	matchingConfig.AssignmentMode, _ = matcher.ParseAssignmentMode(assignmentMode)
+	matchingConfig.Parallelism = workers
+	matchingConfig.EnablePartialMatching = partialMatching
+	matchingConfig.EnableOneToManyMatching = oneToMany
+	matchingConfig.NearMissLimit = nearMisses
+	matchingConfig.AutoAcceptScore = autoAccept
+	matchingConfig.AmbiguityReviewScore = ambiguityReview
+	if err := matchingConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid matching configuration: %w", err)
+	}
 	reconcilerConfig := config.CreateReconcilerConfig(showProgress)
-
-	// Create reconciliation service
-	// Note: We need to create a service for each bank file since the current API expects one bank config
-	// For now, we'll use the first bank file's config as the default
-	var firstBankConfig *parsers.BankConfig
-	for _, bankConfig := range bankConfigs {
-		firstBankConfig = bankConfig
-		break
+	if memoryLimit > 0 {
+		reconcilerConfig.OutOfCore = true
+		reconcilerConfig.MemoryLimitMB = memoryLimit
+		reconcilerConfig.SpillDir = spillDir
 	}
 
+	// Create reconciliation service. Each bank file is parsed with its own
+	// config from the request; the service default is the first file's config.
 	service, err := reconciler.NewReconciliationService(
 		transactionConfig,
-		firstBankConfig,
+		bankConfigs[bankPaths[0]],
 		matchingConfig,
 		reconcilerConfig,
 	)
@@ -267,13 +570,31 @@ func runReconcile(cmd *cobra.Command, args []string) error {
 	// Create reconciliation request
 	request := &reconciler.ReconciliationRequest{
 		SystemFile:        systemFile,
-		BankFiles:         bankFiles,
+		BankFiles:         bankPaths,
 		StartDate:         startTime,
 		EndDate:           endTime,
 		TransactionConfig: transactionConfig,
 		BankConfigs:       bankConfigs,
 	}
 
+	// Carry forward the items the ledger's previous run left open
+	var previousRun *store.Run
+	if viper.GetBool("carry-forward") {
+		previousRun, err = loadPreviousRun(viper.GetString("ledger"))
+		if err != nil {
+			return fmt.Errorf("failed to load open items: %w", err)
+		}
+		if previousRun != nil {
+			request.CarriedTransactions = previousRun.OpenTransactions()
+			request.CarriedStatements = previousRun.OpenStatements()
+			if viper.GetBool("verbose") {
+				fmt.Fprintf(os.Stderr, "Carrying forward %d open items from run %s\n", len(previousRun.OpenItems), previousRun.ID)
+			}
+		} else if viper.GetBool("verbose") {
+			fmt.Fprintf(os.Stderr, "No previous run to carry open items forward from\n")
+		}
+	}
+
 	// Show progress if requested
 	if showProgress {
 		fmt.Fprintf(os.Stderr, "Processing reconciliation...\n")
@@ -285,6 +606,21 @@ func runReconcile(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("reconciliation failed: %w", err)
 	}
 
+	// Finalise the matches held for review that reviewers have decided
+	if reviewFile != "" {
+		decisions, err := review.Load(reviewFile)
+		if err != nil {
+			return err
+		}
+		outcome, err := service.ApplyReviewDecisions(result, decisions)
+		if err != nil {
+			return fmt.Errorf("failed to apply review decisions: %w", err)
+		}
+		for _, decision := range outcome.Unknown {
+			fmt.Fprintf(os.Stderr, "Warning: no match of %s with %s is pending review\n", decision.TrxID, decision.StatementID)
+		}
+	}
+
 	// Generate report
 	reportConfig := config.CreateReportConfig(outputFormat)
 	reportGenerator, err := reporter.NewReportGenerator(reportConfig)
@@ -309,6 +645,16 @@ func runReconcile(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to generate report: %w", err)
 	}
 
+	// Save the run so it can be browsed later. The report has already been
+	// written, so a failure here is only a warning.
+	var runID string
+	if viper.GetBool("history") {
+		runID, err = saveRun(result, matchingConfig, viper.GetString("ledger"), previousRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save run history: %v\n", err)
+		}
+	}
+
 	// Show completion message
 	if viper.GetBool("verbose") {
 		fmt.Fprintf(os.Stderr, "\nReconciliation completed successfully.\n")
@@ -319,8 +665,124 @@ func runReconcile(cmd *cobra.Command, args []string) error {
 		if len(result.Discrepancies) > 0 {
 			fmt.Fprintf(os.Stderr, "Detected %d discrepancies.\n", len(result.Discrepancies))
 		}
+		if result.Summary.PendingReview > 0 {
+			fmt.Fprintf(os.Stderr, "Held %d matches for review.\n", result.Summary.PendingReview)
+		}
 		fmt.Fprintf(os.Stderr, "Processing time: %v\n", result.Summary.ProcessingDuration)
+		if result.Summary.CarriedForward > 0 {
+			fmt.Fprintf(os.Stderr, "Cleared %d of %d carried-forward items.\n",
+				result.Summary.CarriedCleared, result.Summary.CarriedForward)
+		}
+		if runID != "" {
+			fmt.Fprintf(os.Stderr, "Saved as run %s\n", runID)
+		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// createScoringConfig builds the matching configuration from the flags that
+// decide how pairs are scored: tolerances, weights, currency conversion,
+// rules, holiday calendars and date windows
+func createScoringConfig() (*matcher.MatchingConfig, error) {
+	matchingConfig := config.CreateMatchingConfig(dateTolerance, amountTolerance)
+	if idWeight > 0 {
+		matchingConfig.Weights = matchingConfig.Weights.WithIdentifierWeight(idWeight)
+	}
+	if descWeight > 0 {
+		matchingConfig.Weights = matchingConfig.Weights.WithDescriptionWeight(descWeight)
+	}
+	matchingConfig.BaseCurrency = baseCurrency
+	if fxRatesFile != "" {
+		rates, err := fx.LoadRateTable(fxRatesFile)
+		if err != nil {
+			return nil, err
+		}
+		matchingConfig.FXRates = rates
+	}
+	if rulesFile != "" {
+		ruleSet, err := rules.Load(rulesFile)
+		if err != nil {
+			return nil, err
+		}
+		matchingConfig.Rules = ruleSet
+	}
+	if err := loadHolidayCalendars(matchingConfig); err != nil {
+		return nil, err
+	}
+	if err := loadDateWindows(matchingConfig); err != nil {
+		return nil, err
+	}
+	return matchingConfig, nil
+}
+
+// loadDateWindows sets the --date-window windows in the matching
+// configuration. A window without a bank or transaction type replaces the
+// date tolerance; the others become rules, with banks resolved like those of
+// holiday calendars.
+func loadDateWindows(matchingConfig *matcher.MatchingConfig) error {
+	if len(dateWindows) == 0 {
+		return nil
+	}
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return err
+	}
+	
+	for _, dateWindow := range dateWindows {
+		rule, err := config.ParseDateWindowSpec(dateWindow)
+		if err != nil {
+			return err
+		}
+		if rule.Bank == "" && rule.Type == "" {
+			window := rule.DateWindow
+			matchingConfig.DateWindow = &window
+			continue
+		}
+		if rule.Bank != "" {
+			if bankConfig, err := config.LookupBankProfile(rule.Bank, bankProfiles); err == nil {
+				rule.Bank = bankConfig.Name
+			}
+		}
+		matchingConfig.DateWindows = append(matchingConfig.DateWindows, rule)
+	}
+	return nil
+}
+
+// loadHolidayCalendars loads the --holidays calendars into the matching
+// configuration. A calendar bound to a bank profile applies to the statements
+// parsed with that profile; any other calendar applies to every bank without
+// one of its own.
+func loadHolidayCalendars(matchingConfig *matcher.MatchingConfig) error {
+	if len(holidayFiles) == 0 {
+		return nil
+	}
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return err
+	}
+	
+	for _, holidayFile := range holidayFiles {
+		spec := config.ParseBankFileSpec(holidayFile)
+		cal, err := calendar.Load(spec.Path)
+		if err != nil {
+			return err
+		}
+		if spec.Profile == "" {
+			matchingConfig.Calendar = cal
+			continue
+		}
+		
+		// Statements carry the name of the profile's bank; a name that is not
+		// a profile is taken as the bank name itself
+		bank := spec.Profile
+		if bankConfig, err := config.LookupBankProfile(spec.Profile, bankProfiles); err == nil {
+			bank = bankConfig.Name
+		}
+		if matchingConfig.BankCalendars == nil {
+			matchingConfig.BankCalendars = make(map[string]*calendar.Calendar)
+		}
+		matchingConfig.BankCalendars[bank] = cal
+	}
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"golang-reconciliation-service/internal/matcher"
+
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -79,6 +81,23 @@ func TestValidateReconcileFlags(t *testing.T) {
 	if err := os.WriteFile(bankFile, []byte("unique_identifier,amount,date\nBS001,100.50,2024-01-15"), 0644); err != nil {
 		t.Fatalf("failed to create bank file: %v", err)
 	}
+	holidayFile := filepath.Join(tmpDir, "holidays.csv")
+	if err := os.WriteFile(holidayFile, []byte("date,name\n2024-04-10,Idul Fitri"), 0644); err != nil {
+		t.Fatalf("failed to create holiday file: %v", err)
+	}
+	profilesDir := filepath.Join(tmpDir, "profiles")
+	badProfilesDir := filepath.Join(tmpDir, "bad_profiles")
+	for dir, content := range map[string]string{
+		profilesDir:    "name: Acme\nidentifier_column: unique_identifier\namount_column: amount\ndate_column: date\n",
+		badProfilesDir: "name: Broken\namount_column: amount\n",
+	} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("failed to create profiles dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "profile.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create profile file: %v", err)
+		}
+	}
 
 	tests := []struct {
 		name        string
@@ -115,6 +134,46 @@ func TestValidateReconcileFlags(t *testing.T) {
 			expectError: true,
 			errorContains: "at least one bank-file is required",
 		},
+		{
+			name: "bank file with profile",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile + ":Standard"})
+				viper.Set("output-format", "console")
+			},
+			expectError: false,
+		},
+		{
+			name: "bank file with unknown profile",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile + ":NoSuchBank"})
+				viper.Set("output-format", "console")
+			},
+			expectError: true,
+			errorContains: "unknown bank profile",
+		},
+		{
+			name: "bank file with profile from profiles dir",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile + ":acme"})
+				viper.Set("profiles-dir", profilesDir)
+				viper.Set("output-format", "console")
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid profile in profiles dir",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("profiles-dir", badProfilesDir)
+				viper.Set("output-format", "console")
+			},
+			expectError: true,
+			errorContains: "identifier column cannot be empty",
+		},
 		{
 			name: "invalid output format",
 			setupFlags: func() {
@@ -170,6 +229,105 @@ func TestValidateReconcileFlags(t *testing.T) {
 			expectError: true,
 			errorContains: "amount tolerance must be between 0.0 and 100.0",
 		},
+		{
+			name: "negative near misses",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("near-misses", -1)
+			},
+			expectError: true,
+			errorContains: "near misses cannot be negative",
+		},
+		{
+			name: "auto-accept score above 1",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("auto-accept", 1.5)
+			},
+			expectError: true,
+			errorContains: "auto-accept score must be between 0.0 and 1.0",
+		},
+		{
+			name: "ambiguity review with memory limit",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("ambiguity-review", 0.5)
+				viper.Set("memory-limit", 64)
+			},
+			expectError: true,
+			errorContains: "ambiguity-review cannot be combined with memory-limit",
+		},
+		{
+			name: "missing review decisions file",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("review-decisions", filepath.Join(tmpDir, "missing.csv"))
+			},
+			expectError: true,
+			errorContains: "review decisions file does not exist",
+		},
+		{
+			name: "holiday calendars",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("holidays", []string{holidayFile, holidayFile + ":bca"})
+			},
+			expectError: false,
+		},
+		{
+			name: "missing holiday calendar",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("holidays", []string{filepath.Join(tmpDir, "missing.ics") + ":bca"})
+			},
+			expectError: true,
+			errorContains: "holiday calendar 1 does not exist",
+		},
+		{
+			name: "two holiday calendars for one bank",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("holidays", []string{holidayFile + ":bca", holidayFile + ":BCA"})
+			},
+			expectError: true,
+			errorContains: "bank BCA already has a calendar",
+		},
+		{
+			name: "invalid date window",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("date-window", []string{"3"})
+			},
+			expectError: true,
+			errorContains: "invalid date window 1",
+		},
+		{
+			name: "two date windows for one scope",
+			setupFlags: func() {
+				viper.Set("system-file", systemFile)
+				viper.Set("bank-files", []string{bankFile})
+				viper.Set("output-format", "console")
+				viper.Set("date-window", []string{"3:0", "2:0:bca:DEBIT", "1:1:BCA:dr"})
+			},
+			expectError: true,
+			errorContains: "date window 3: BCA DEBIT already has a window",
+		},
 	}
 
 	for _, tt := range tests {
@@ -215,6 +373,11 @@ func TestReconcileCommandHelp(t *testing.T) {
 		t.Error("output-format flag not found")
 	}
 	
+	// Runs are only saved to the history store on request
+	if historyFlag := cmd.Flags().Lookup("history"); historyFlag == nil || historyFlag.DefValue != "false" {
+		t.Error("history flag should default to false")
+	}
+	
 	// Test help output contains key information
 	var helpOutput bytes.Buffer
 	cmd.SetOut(&helpOutput)
@@ -283,6 +446,54 @@ func TestReconcileCommandExamples(t *testing.T) {
 	}
 }
 
+func TestLoadHolidayCalendars(t *testing.T) {
+	tmpDir := t.TempDir()
+	holidayFile := filepath.Join(tmpDir, "holidays.csv")
+	if err := os.WriteFile(holidayFile, []byte("date,name\n2024-04-10,Idul Fitri"), 0644); err != nil {
+		t.Fatalf("failed to create holiday file: %v", err)
+	}
+	
+	viper.Reset()
+	defer viper.Reset()
+	holidayFiles = []string{holidayFile, holidayFile + ":chase", holidayFile + ":BCA"}
+	defer func() { holidayFiles = nil }()
+	
+	matchingConfig := matcher.DefaultMatchingConfig()
+	if err := loadHolidayCalendars(matchingConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matchingConfig.Calendar == nil || matchingConfig.Calendar.Len() != 1 {
+		t.Errorf("expected a default calendar with one holiday, got %+v", matchingConfig.Calendar)
+	}
+	// Profiles resolve to the bank name their statements carry
+	for _, bank := range []string{"Chase", "BCA"} {
+		if matchingConfig.BankCalendars[bank] == nil {
+			t.Errorf("expected a calendar for %s, got %v", bank, matchingConfig.BankCalendars)
+		}
+	}
+}
+
+func TestLoadDateWindows(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	dateWindows = []string{"3:0", "1:1:chase:DEBIT"}
+	defer func() { dateWindows = nil }()
+	
+	matchingConfig := matcher.DefaultMatchingConfig()
+	if err := loadDateWindows(matchingConfig); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matchingConfig.DateWindow == nil || *matchingConfig.DateWindow != (matcher.DateWindow{LagDays: 3}) {
+		t.Errorf("expected a default window of 3 days late, got %+v", matchingConfig.DateWindow)
+	}
+	if len(matchingConfig.DateWindows) != 1 || matchingConfig.DateWindows[0].Bank != "Chase" {
+		t.Errorf("expected one rule for the Chase profile, got %+v", matchingConfig.DateWindows)
+	}
+	if err := matchingConfig.Validate(); err != nil {
+		t.Errorf("expected a valid configuration, got %v", err)
+	}
+}
+
 func TestOutputFormatValidation(t *testing.T) {
 	validFormats := []string{"console", "json", "csv"}
 	invalidFormats := []string{"xml", "yaml", "invalid", ""}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/internal/review"
+	"golang-reconciliation-service/internal/store"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for the review command
+var reviewDecisionsFile string
+
+// reviewCmd represents the review command
+var reviewCmd = &cobra.Command{
+	Use:   "review <run-id>",
+	Short: "List or decide the matches a stored run held for review",
+	Long: `Review lists the matches a stored run held for review: matches scoring below
+--auto-accept and matches on days whose same-day ambiguity reached
+--ambiguity-review. With --decisions, it applies the reviewers' decisions and
+saves the finalised run under the same ID. Accepted matches are counted as
+matched; rejected ones leave their transaction and statement unmatched.
+
+The decisions file is a CSV with action, trx_id and statement_id columns and
+optional reviewer and note columns, or a YAML or JSON file with a "decisions"
+list of the same fields. The action is accept or reject. Matches without a
+decision stay pending, so a run may be reviewed in several passes.
+
+The run ID may be shortened to any unique prefix.
+
+Examples:
+  reconciler review 20240115-103000-1a2b3c
+  reconciler review 20240115-1030 --decisions decisions.csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReview,
+}
+
+func init() {
+	rootCmd.AddCommand(reviewCmd)
+
+	reviewCmd.Flags().StringVar(&reviewDecisionsFile, "decisions", "", "path to a review decisions file (.csv, .yaml, .yml, .json) to apply to the run")
+}
+
+func runReview(cmd *cobra.Command, args []string) error {
+	resultStore, err := openHistoryStore()
+	if err != nil {
+		return err
+	}
+	defer resultStore.Close()
+
+	run, err := store.FindRun(resultStore, args[0])
+	if err != nil {
+		return err
+	}
+	if run.Result == nil {
+		return fmt.Errorf("run %s has no stored result", run.ID)
+	}
+
+	out := cmd.OutOrStdout()
+	if reviewDecisionsFile == "" {
+		return printPendingReview(out, run)
+	}
+
+	decisions, err := review.Load(reviewDecisionsFile)
+	if err != nil {
+		return err
+	}
+
+	// The ledger's next run has carried the pending items forward already;
+	// deciding them now would leave the two runs disagreeing
+	if run.Ledger != "" {
+		latest, err := store.LatestRun(resultStore, run.Ledger)
+		if err != nil {
+			return err
+		}
+		if latest.ID != run.ID {
+			return fmt.Errorf("run %s is not the latest run of ledger %q: its open items were carried into %s", run.ID, run.Ledger, latest.ID)
+		}
+	}
+	var previous *store.Run
+	if run.CarriedFrom != "" {
+		previous, err = resultStore.Get(run.CarriedFrom)
+		if err != nil {
+			return fmt.Errorf("failed to load previous run %s: %w", run.CarriedFrom, err)
+		}
+	}
+
+	service, err := newReviewService(run)
+	if err != nil {
+		return err
+	}
+	outcome, err := service.ApplyReviewDecisions(run.Result, decisions)
+	if err != nil {
+		return fmt.Errorf("failed to apply review decisions: %w", err)
+	}
+
+	run.UpdateLedger(previous)
+	if err := resultStore.Save(run); err != nil {
+		return fmt.Errorf("failed to save run %s: %w", run.ID, err)
+	}
+
+	fmt.Fprintf(out, "Run %s: accepted %d, rejected %d, %d still pending review\n",
+		run.ID, len(outcome.Accepted), len(outcome.Rejected), outcome.Remaining)
+	for _, decision := range outcome.Unknown {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: no match of %s with %s is pending review\n", decision.TrxID, decision.StatementID)
+	}
+	return nil
+}
+
+// newReviewService builds a service to apply decisions with. Only the
+// matching configuration matters; nothing is parsed.
+func newReviewService(run *store.Run) (*reconciler.ReconciliationService, error) {
+	matchingConfig := run.MatchingConfig
+	if matchingConfig == nil {
+		matchingConfig = matcher.DefaultMatchingConfig()
+	}
+	service, err := reconciler.NewReconciliationService(
+		parsers.DefaultTransactionParserConfig(),
+		parsers.StandardBankConfig,
+		matchingConfig,
+		reconciler.DefaultConfig(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation service: %w", err)
+	}
+	return service, nil
+}
+
+// printPendingReview lists the matches of a run held for review
+func printPendingReview(out io.Writer, run *store.Run) error {
+	if len(run.Result.PendingReview) == 0 {
+		fmt.Fprintf(out, "Run %s has no matches pending review.\n", run.ID)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TRX ID\tSTATEMENT ID\tTYPE\tCONFIDENCE\tAMOUNTS\tDATES\tREASON")
+	for _, pending := range run.Result.PendingReview {
+		match := pending.Match
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%s / %s\t%s / %s\t%s\n",
+			match.Transaction.TrxID,
+			match.BankStatement.UniqueIdentifier,
+			match.MatchType,
+			match.ConfidenceScore,
+			match.Transaction.Amount.StringFixed(2),
+			match.BankStatement.Amount.StringFixed(2),
+			match.Transaction.TransactionTime.Format("2006-01-02"),
+			match.BankStatement.Date.Format("2006-01-02"),
+			pending.DescribeReason())
+	}
+	return w.Flush()
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/reconciler"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestReviewCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemFile := filepath.Join(tmpDir, "transactions.csv")
+	bankFile := filepath.Join(tmpDir, "statements.csv")
+	decisionsFile := filepath.Join(tmpDir, "decisions.csv")
+	// TX002 settles a day late and scores below the auto-accept score
+	files := map[string]string{
+		systemFile:    "trxID,amount,type,transactionTime\nTX001,100.00,CREDIT,2024-01-15T10:30:00Z\nTX002,40.00,CREDIT,2024-01-16T10:30:00Z\n",
+		bankFile:      "unique_identifier,amount,date\nBS001,100.00,2024-01-15\nBS002,40.00,2024-01-17\n",
+		decisionsFile: "action,trx_id,statement_id,reviewer,note\naccept,TX002,BS002,alice,\nreject,TX009,BS009,alice,\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	viper.Reset()
+	viper.Set("history-dir", filepath.Join(tmpDir, "history"))
+	defer viper.Reset()
+
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.DateToleranceDays = 3
+	matchingConfig.AutoAcceptScore = 0.95
+	txConfig := parsers.DefaultTransactionParserConfig()
+	service, err := reconciler.NewReconciliationService(txConfig, parsers.StandardBankConfig, matchingConfig, reconciler.DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to create reconciliation service: %v", err)
+	}
+	result, err := service.ProcessReconciliation(context.Background(), &reconciler.ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+	})
+	if err != nil {
+		t.Fatalf("reconciliation failed: %v", err)
+	}
+	runID, err := saveRun(result, matchingConfig, "operating", nil)
+	if err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	review := func(decisions string) (string, string, error) {
+		reviewDecisionsFile = decisions
+		defer func() { reviewDecisionsFile = "" }()
+
+		var output, errOutput bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&output)
+		cmd.SetErr(&errOutput)
+		err := runReview(cmd, []string{runID})
+		return output.String(), errOutput.String(), err
+	}
+
+	output, _, err := review("")
+	if err != nil {
+		t.Fatalf("unexpected error listing pending matches: %v", err)
+	}
+	for _, expected := range []string{"TX002", "BS002", "low_confidence", "40.00 / 40.00"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("listing should contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "TX001") {
+		t.Errorf("listing should not contain the accepted TX001, got:\n%s", output)
+	}
+
+	output, errOutput, err := review(decisionsFile)
+	if err != nil {
+		t.Fatalf("unexpected error applying decisions: %v", err)
+	}
+	if !strings.Contains(output, "accepted 1, rejected 0, 0 still pending review") {
+		t.Errorf("unexpected outcome:\n%s", output)
+	}
+	if !strings.Contains(errOutput, "no match of TX009 with BS009 is pending review") {
+		t.Errorf("expected a warning for TX009, got:\n%s", errOutput)
+	}
+
+	resultStore, err := openHistoryStore()
+	if err != nil {
+		t.Fatalf("failed to open history: %v", err)
+	}
+	defer resultStore.Close()
+	run, err := resultStore.Get(runID)
+	if err != nil {
+		t.Fatalf("failed to load run: %v", err)
+	}
+	if run.Result.Summary.MatchedTransactions != 2 || run.Result.Summary.PendingReview != 0 || len(run.OpenItems) != 0 {
+		t.Errorf("expected the saved run to have 2 matches and nothing open, got %d matched, %d pending, %d open",
+			run.Result.Summary.MatchedTransactions, run.Result.Summary.PendingReview, len(run.OpenItems))
+	}
+
+	// A later run of the ledger has carried the items forward already
+	result.ProcessedAt = result.ProcessedAt.Add(time.Hour)
+	if _, err := saveRun(result, matchingConfig, "operating", run); err != nil {
+		t.Fatalf("failed to save later run: %v", err)
+	}
+	if _, _, err := review(decisionsFile); err == nil || !strings.Contains(err.Error(), "not the latest run") {
+		t.Errorf("expected an error reviewing a superseded run, got %v", err)
+	}
+	if _, _, err := review(""); err != nil {
+		t.Errorf("listing a superseded run should still work, got %v", err)
+	}
+}
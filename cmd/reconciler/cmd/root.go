@@ -9,8 +9,9 @@ import (
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile    string
+	verbose    bool
+	historyDir string
 	version = "dev"
 	commit  = "unknown"
 	date    = "unknown"
@@ -43,9 +44,11 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (optional)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&historyDir, "history-dir", "", "directory reconciliation runs are stored in (default: user config dir)")
 
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("history-dir", rootCmd.PersistentFlags().Lookup("history-dir"))
 }
 
 // initConfig reads in config file and ENV variables.
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang-reconciliation-service/cmd/reconciler/config"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/internal/reporter"
+	"golang-reconciliation-service/internal/server"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Flags for the serve command
+var (
+	serveAddr      string
+	serveDataDir   string
+	serveUploadDir string
+	serveMaxJobs   int
+	serveRetain    int
+	serveMaxUpload int64
+	serveHistory   bool
+
+	serveIncrementalState string
+	serveInbox            string
+	serveInboxPoll        time.Duration
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the reconciliation HTTP API server",
+	Long: `Serve exposes reconciliation as an HTTP/JSON API so other tools can submit
+jobs without shelling out to the CLI.
+
+Jobs are submitted to POST /api/v1/jobs, either as JSON referencing files
+under --data-dir or as a multipart upload (system_file, bank_files, fx_rates
+and an optional "request" JSON field). Poll GET /api/v1/jobs/{id} for progress
+and fetch GET /api/v1/jobs/{id}/result?format=json|csv|console once complete.
+
+With --incremental-state or --inbox the server also runs an incremental
+reconciler that matches items as they arrive. Post them to
+/api/v1/incremental/transactions and /api/v1/incremental/statements, or drop
+system files into <inbox>/transactions and bank files into <inbox>/statements.
+Items that find no partner stay open, and the open items are saved to the
+state file after every change so they survive a restart.
+
+Examples:
+  # Accept uploads only
+  reconciler serve --addr :8080
+
+  # Also allow jobs to reference files under /srv/reconciliation
+  reconciler serve --addr :8080 --data-dir /srv/reconciliation --profiles-dir ./profiles
+
+  # Match items as they arrive, keeping open items across restarts
+  reconciler serve --incremental-state state.json --inbox /srv/inbox
+
+  # Submit a job for server-side files
+  curl -X POST localhost:8080/api/v1/jobs \
+    -d '{"system_file": "tx.csv", "bank_files": ["bca.csv"], "matching": {"date_tolerance_days": 2}}'`,
+
+	PreRunE: validateServeFlags,
+	RunE:    runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().StringVar(&serveDataDir, "data-dir", "", "directory jobs may reference files in (default: uploads only)")
+	serveCmd.Flags().StringVar(&serveUploadDir, "upload-dir", "", "directory for uploaded files (default: system temp dir)")
+	serveCmd.Flags().IntVar(&serveMaxJobs, "max-jobs", 2, "maximum number of jobs running at the same time")
+	serveCmd.Flags().IntVar(&serveRetain, "max-retained-jobs", 100, "number of finished jobs kept in memory for status and result requests")
+	serveCmd.Flags().Int64Var(&serveMaxUpload, "max-upload-mb", 64, "maximum size of a job submission or incremental request in megabytes")
+	serveCmd.Flags().StringVar(&profilesDir, "profiles-dir", "", "directory of bank profile files (.yaml, .yml, .toml, .json) to load")
+	serveCmd.Flags().BoolVar(&serveHistory, "history", true, "save completed jobs to the history store (see the history command)")
+	serveCmd.Flags().StringVar(&serveIncrementalState, "incremental-state", "", "file the incremental reconciler saves its open items to (enables the incremental routes)")
+	serveCmd.Flags().StringVar(&serveInbox, "inbox", "", "directory polled for system and bank files to match incrementally")
+	serveCmd.Flags().DurationVar(&serveInboxPoll, "inbox-poll", 5*time.Second, "how often the inbox is scanned")
+
+	viper.BindPFlag("serve.addr", serveCmd.Flags().Lookup("addr"))
+	viper.BindPFlag("serve.data-dir", serveCmd.Flags().Lookup("data-dir"))
+	viper.BindPFlag("serve.upload-dir", serveCmd.Flags().Lookup("upload-dir"))
+	viper.BindPFlag("serve.max-jobs", serveCmd.Flags().Lookup("max-jobs"))
+	viper.BindPFlag("serve.max-retained-jobs", serveCmd.Flags().Lookup("max-retained-jobs"))
+	viper.BindPFlag("serve.max-upload-mb", serveCmd.Flags().Lookup("max-upload-mb"))
+	viper.BindPFlag("serve.profiles-dir", serveCmd.Flags().Lookup("profiles-dir"))
+	viper.BindPFlag("serve.history", serveCmd.Flags().Lookup("history"))
+	viper.BindPFlag("serve.incremental-state", serveCmd.Flags().Lookup("incremental-state"))
+	viper.BindPFlag("serve.inbox", serveCmd.Flags().Lookup("inbox"))
+	viper.BindPFlag("serve.inbox-poll", serveCmd.Flags().Lookup("inbox-poll"))
+}
+
+func validateServeFlags(cmd *cobra.Command, args []string) error {
+	serveAddr = viper.GetString("serve.addr")
+	serveDataDir = viper.GetString("serve.data-dir")
+	serveUploadDir = viper.GetString("serve.upload-dir")
+	serveMaxJobs = viper.GetInt("serve.max-jobs")
+	serveRetain = viper.GetInt("serve.max-retained-jobs")
+	serveMaxUpload = viper.GetInt64("serve.max-upload-mb")
+	profilesDir = viper.GetString("serve.profiles-dir")
+	serveHistory = viper.GetBool("serve.history")
+	serveIncrementalState = viper.GetString("serve.incremental-state")
+	serveInbox = viper.GetString("serve.inbox")
+	serveInboxPoll = viper.GetDuration("serve.inbox-poll")
+
+	if serveAddr == "" {
+		return fmt.Errorf("addr cannot be empty")
+	}
+	if serveMaxJobs <= 0 {
+		return fmt.Errorf("max-jobs must be positive")
+	}
+	if serveRetain <= 0 {
+		return fmt.Errorf("max-retained-jobs must be positive")
+	}
+	if serveMaxUpload <= 0 {
+		return fmt.Errorf("max-upload-mb must be positive")
+	}
+	if serveInbox != "" && serveInboxPoll <= 0 {
+		return fmt.Errorf("inbox-poll must be positive")
+	}
+	if serveDataDir != "" {
+		info, err := os.Stat(serveDataDir)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("data directory does not exist: %s", serveDataDir)
+		}
+	}
+
+	if profilesDir != "" {
+		if _, err := parsers.DefaultProfileRegistry.LoadDir(profilesDir); err != nil {
+			return err
+		}
+	}
+	if _, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	serverConfig, err := createServerConfig()
+	if err != nil {
+		return err
+	}
+
+	if serveHistory {
+		resultStore, err := openHistoryStore()
+		if err != nil {
+			return fmt.Errorf("failed to open history store: %w", err)
+		}
+		defer resultStore.Close()
+		serverConfig.Store = resultStore
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// inboxDone is closed once the inbox poller has finished its current file
+	inboxDone := make(chan struct{})
+	if serveIncrementalState != "" || serveInbox != "" {
+		incremental, err := createIncrementalService(serverConfig)
+		if err != nil {
+			return fmt.Errorf("failed to start incremental reconciler: %w", err)
+		}
+		serverConfig.Incremental = incremental
+		if serveInbox != "" {
+			go func() {
+				defer close(inboxDone)
+				incremental.Run(ctx)
+			}()
+		}
+	}
+	if serveInbox == "" {
+		close(inboxDone)
+	}
+
+	srv, err := server.New(serverConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+	defer srv.Close()
+
+	httpServer := &http.Server{
+		Addr:              serveAddr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "Reconciliation API listening on %s\n", serveAddr)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	fmt.Fprintf(os.Stderr, "Shutting down...\n")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err = httpServer.Shutdown(shutdownCtx)
+	<-inboxDone
+	return err
+}
+
+// createServerConfig builds the API server configuration from the same
+// defaults and bank profiles the reconcile command uses
+func createServerConfig() (*server.Config, error) {
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank profiles: %w", err)
+	}
+
+	transactionConfig, err := config.CreateSystemParserConfig(viper.GetStringMap("system"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction parser config: %w", err)
+	}
+
+	serverConfig := server.DefaultConfig()
+	serverConfig.DataDir = serveDataDir
+	if serveUploadDir != "" {
+		serverConfig.UploadDir = serveUploadDir
+	}
+	serverConfig.MaxConcurrentJobs = serveMaxJobs
+	serverConfig.MaxRetainedJobs = serveRetain
+	serverConfig.MaxUploadBytes = serveMaxUpload << 20
+	serverConfig.TransactionConfig = transactionConfig
+	serverConfig.MatchingConfig = config.CreateMatchingConfig(1, 0.0)
+	serverConfig.ReconcilerConfig = config.CreateReconcilerConfig(false)
+	serverConfig.ReportConfig = func(format reporter.OutputFormat) *reporter.ReportConfig {
+		reportConfig := config.CreateReportConfig(string(format))
+		reportConfig.UseColors = false
+		return reportConfig
+	}
+	serverConfig.ResolveBankConfigs = func(paths []string, profiles map[string]string) (map[string]*parsers.BankConfig, error) {
+		specs := make([]string, 0, len(paths))
+		for _, path := range paths {
+			spec := path
+			if profile := profiles[path]; profile != "" {
+				spec += ":" + profile
+			}
+			specs = append(specs, spec)
+		}
+		return config.ResolveBankConfigs(specs, bankProfiles)
+	}
+
+	return serverConfig, nil
+}
+
+// createIncrementalService builds the incremental reconciler the serve
+// command runs alongside jobs, restoring its open items from the state file
+func createIncrementalService(serverConfig *server.Config) (*reconciler.IncrementalService, error) {
+	bankProfiles, err := config.LoadBankProfiles(viper.GetStringMap("bank_profiles"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bank profiles: %w", err)
+	}
+
+	incrementalConfig := reconciler.DefaultIncrementalConfig()
+	incrementalConfig.SnapshotPath = serveIncrementalState
+	incrementalConfig.InboxDir = serveInbox
+	incrementalConfig.PollInterval = serveInboxPoll
+	incrementalConfig.TransactionConfig = serverConfig.TransactionConfig
+	incrementalConfig.ResolveBankConfig = func(path string) (*parsers.BankConfig, error) {
+		configs, err := config.ResolveBankConfigs([]string{path}, bankProfiles)
+		if err != nil {
+			return nil, err
+		}
+		return configs[path], nil
+	}
+
+	return reconciler.NewIncrementalService(serverConfig.MatchingConfig, incrementalConfig)
+}
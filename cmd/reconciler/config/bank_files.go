@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang-reconciliation-service/internal/parsers"
+)
+
+// BankFileSpec is a single --bank-files entry. A file can be bound to a bank
+// profile by appending the profile name after a colon, e.g. "bca.csv:bank1".
+type BankFileSpec struct {
+	Path    string
+	Profile string
+}
+
+// ParseBankFileSpec splits a "path[:profile]" entry. A colon followed by a
+// path separator (such as a Windows drive letter) is treated as part of the path.
+func ParseBankFileSpec(spec string) BankFileSpec {
+	spec = strings.TrimSpace(spec)
+
+	idx := strings.LastIndex(spec, ":")
+	if idx <= 0 || idx == len(spec)-1 {
+		return BankFileSpec{Path: spec}
+	}
+
+	profile := spec[idx+1:]
+	if strings.ContainsAny(profile, `/\`) {
+		return BankFileSpec{Path: spec}
+	}
+
+	return BankFileSpec{Path: spec[:idx], Profile: strings.TrimSpace(profile)}
+}
+
+// BankFilePaths returns the file paths of the given entries with any profile
+// suffix removed, in the original order
+func BankFilePaths(specs []string) []string {
+	paths := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		paths = append(paths, ParseBankFileSpec(spec).Path)
+	}
+	return paths
+}
+
+// LoadBankProfiles converts the bank_profiles section of the config file into
+// bank profiles, sorted by name. Each entry uses the same keys as a profile
+// file in --profiles-dir, plus the file patterns the profile applies to:
+//
+//	[bank_profiles.bca]
+//	identifier_column = "ref"
+//	amount_column = "amount"
+//	date_column = "posted"
+//	date_format = "02/01/2006"
+//	delimiter = ";"
+//	files = ["bca_*.csv"]
+func LoadBankProfiles(section map[string]interface{}) ([]BankProfile, error) {
+	profiles := make([]BankProfile, 0, len(section))
+
+	for name, raw := range section {
+		values, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid bank profile %s: expected a table of settings", name)
+		}
+
+		bankConfig, err := parsers.BankConfigFromMap(values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bank profile %s: %w", name, err)
+		}
+		if bankConfig.Name == "" {
+			bankConfig.Name = name
+		}
+		if err := bankConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid bank profile %s: %w", name, err)
+		}
+
+		files, err := bankProfileFiles(values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bank profile %s: %w", name, err)
+		}
+
+		profiles = append(profiles, BankProfile{
+			Name:   name,
+			Config: bankConfig,
+			Files:  files,
+		})
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// bankProfileFiles returns the validated files patterns of a profile section
+func bankProfileFiles(values map[string]interface{}) ([]string, error) {
+	data, err := json.Marshal(values["files"])
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("files must be a list of patterns")
+	}
+	for _, pattern := range files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad file pattern %q: %w", pattern, err)
+		}
+	}
+	return files, nil
+}
+
+// LookupBankProfile resolves a profile name against the config file profiles
+// and then the parser's profile registry, which holds the built-in profiles
+// and any loaded from --profiles-dir. Names are matched case-insensitively.
+func LookupBankProfile(name string, profiles []BankProfile) (*parsers.BankConfig, error) {
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Name, name) {
+			return profile.Config, nil
+		}
+	}
+	if bankConfig := parsers.GetBankConfig(name); bankConfig != nil {
+		return bankConfig, nil
+	}
+
+	return nil, fmt.Errorf("unknown bank profile: %s", name)
+}
+
+// ResolveBankConfigs returns a bank configuration for every --bank-files
+// entry, keyed by file path. Each file uses, in order of preference:
+//  1. the profile named in the entry ("path:profile")
+//  2. the first config file profile whose files patterns match the path
+//  3. the default layout, when the file headers fit it
+//  4. the layout detected from the file headers by parsers.AutoDetectBankConfig
+func ResolveBankConfigs(specs []string, profiles []BankProfile) (map[string]*parsers.BankConfig, error) {
+	bankConfigs := make(map[string]*parsers.BankConfig)
+
+	for i, entry := range specs {
+		spec := ParseBankFileSpec(entry)
+		if _, exists := bankConfigs[spec.Path]; exists {
+			return nil, fmt.Errorf("bank file listed more than once: %s", spec.Path)
+		}
+
+		var bankConfig *parsers.BankConfig
+		switch {
+		case spec.Profile != "":
+			profileConfig, err := LookupBankProfile(spec.Profile, profiles)
+			if err != nil {
+				return nil, fmt.Errorf("bank file %s: %w", spec.Path, err)
+			}
+			bankConfig = copyBankConfig(profileConfig)
+		case matchBankProfile(spec.Path, profiles) != nil:
+			bankConfig = copyBankConfig(matchBankProfile(spec.Path, profiles))
+		default:
+			bankConfig = detectBankConfig(spec.Path, defaultBankConfig(spec.Path, i, len(specs)))
+		}
+
+		bankConfigs[spec.Path] = bankConfig
+	}
+
+	return bankConfigs, nil
+}
+
+// matchBankProfile returns the config of the first profile with a files
+// pattern matching the path or its base name
+func matchBankProfile(path string, profiles []BankProfile) *parsers.BankConfig {
+	for _, profile := range profiles {
+		for _, pattern := range profile.Files {
+			if matched, _ := filepath.Match(pattern, path); matched {
+				return profile.Config
+			}
+			if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+				return profile.Config
+			}
+		}
+	}
+	return nil
+}
+
+// detectBankConfig keeps the default layout when the file headers fit it and
+// otherwise falls back to header based detection. Files that cannot be read
+// keep the default so the parser can report the problem.
+func detectBankConfig(path string, defaultConfig *parsers.BankConfig) *parsers.BankConfig {
+	headers, err := readBankFileHeaders(path, defaultConfig.Delimiter)
+	if err != nil || headersFitBankConfig(headers, defaultConfig) {
+		return defaultConfig
+	}
+
+	detected := copyBankConfig(parsers.AutoDetectBankConfig(headers))
+	detected.Description = fmt.Sprintf("Detected %s format for %s", detected.Name, path)
+	return detected
+}
+
+// readBankFileHeaders reads the header row of a bank file
+func readBankFileHeaders(path string, delimiter rune) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	return reader.Read()
+}
+
+// headersFitBankConfig reports whether every column the config needs is
+// present in the headers
+func headersFitBankConfig(headers []string, bankConfig *parsers.BankConfig) bool {
+	return bankConfig.HeaderMatchScore(headers) == 1
+}
+
+// copyBankConfig returns a shallow copy so per-file changes do not leak into
+// shared profile definitions
+func copyBankConfig(bankConfig *parsers.BankConfig) *parsers.BankConfig {
+	copied := *bankConfig
+	return &copied
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 
 	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/parsers"
 	"golang-reconciliation-service/internal/reconciler"
 	"golang-reconciliation-service/internal/reporter"
@@ -39,56 +41,90 @@ func CreateTransactionParserConfig() (*parsers.TransactionParserConfig, error) {
 	}, nil
 }
 
-// CreateBankConfigs creates bank configurations for the provided file paths
-func CreateBankConfigs(bankFiles []string) (map[string]*parsers.BankConfig, error) {
-	bankConfigs := make(map[string]*parsers.BankConfig)
-	
-	for i, bankFile := range bankFiles {
-		bankName := fmt.Sprintf("Bank_%d", i+1)
-		if len(bankFiles) == 1 {
-			bankName = "Bank"
-		} else {
-			// Try to derive name from filename
-			base := filepath.Base(bankFile)
-			ext := filepath.Ext(base)
-			if ext != "" {
-				base = base[:len(base)-len(ext)]
-			}
-			bankName = fmt.Sprintf("Bank_%s", base)
+// CreateSystemParserConfig creates the transaction parser configuration for
+// the system file, applying the settings of the config file's [system]
+// section. number_locale names a predefined locale or sets a custom one:
+//
+//	[system]
+//	number_locale = "id"
+func CreateSystemParserConfig(section map[string]interface{}) (*parsers.TransactionParserConfig, error) {
+	transactionConfig, err := CreateTransactionParserConfig()
+	if err != nil {
+		return nil, err
+	}
+	
+	if value, ok := section["number_locale"]; ok && value != nil {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid system number_locale: %w", err)
 		}
-		
-		bankConfig := &parsers.BankConfig{
-			Name:             bankName,
-			IdentifierColumn: "unique_identifier",
-			AmountColumn:     "amount",
-			DateColumn:       "date",
-			DateFormat:       "2006-01-02",
-			HasHeader:        true,
-			Delimiter:        ',',
-			ColumnAliases: map[string]string{
-				// Common aliases for bank statement columns
-				"id":           "unique_identifier",
-				"identifier":   "unique_identifier",
-				"ref":          "unique_identifier",
-				"reference":    "unique_identifier",
-				"transaction_id": "unique_identifier",
-				"statement_id": "unique_identifier",
-				"amt":          "amount",
-				"value":        "amount",
-				"sum":          "amount",
-				"balance":      "amount",
-				"transaction_date": "date",
-				"statement_date": "date",
-				"posting_date": "date",
-				"value_date":   "date",
-			},
-			Description: fmt.Sprintf("Configuration for %s", bankFile),
+		var locale models.NumberLocale
+		if err := json.Unmarshal(data, &locale); err != nil {
+			return nil, fmt.Errorf("invalid system number_locale: %w", err)
 		}
-		
-		bankConfigs[bankFile] = bankConfig
+		transactionConfig.NumberLocale = &locale
 	}
 	
-	return bankConfigs, nil
+	if err := transactionConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid system file settings: %w", err)
+	}
+	return transactionConfig, nil
+}
+
+// CreateBankConfigs creates bank configurations for the provided --bank-files
+// entries, keyed by file path. See ResolveBankConfigs for how each file's
+// layout is chosen.
+func CreateBankConfigs(bankFiles []string) (map[string]*parsers.BankConfig, error) {
+	return ResolveBankConfigs(bankFiles, nil)
+}
+
+// defaultBankConfig returns the generic unique_identifier/amount/date layout
+// for the index-th of count bank files
+func defaultBankConfig(path string, index, count int) *parsers.BankConfig {
+	bankName := fmt.Sprintf("Bank_%d", index+1)
+	if count == 1 {
+		bankName = "Bank"
+	} else {
+		// Try to derive name from filename
+		base := filepath.Base(path)
+		ext := filepath.Ext(base)
+		if ext != "" {
+			base = base[:len(base)-len(ext)]
+		}
+		bankName = fmt.Sprintf("Bank_%s", base)
+	}
+	
+	bankConfig := &parsers.BankConfig{
+		Name:             bankName,
+		IdentifierColumn: "unique_identifier",
+		AmountColumn:     "amount",
+		DateColumn:       "date",
+		// Dates are read as the Standard profile reads them
+		DateFormat:       parsers.StandardBankConfig.DateFormat,
+		DateFormats:      append([]string(nil), parsers.StandardBankConfig.DateFormats...),
+		HasHeader:        true,
+		Delimiter:        ',',
+		ColumnAliases: map[string]string{
+			// Common aliases for bank statement columns
+			"id":           "unique_identifier",
+			"identifier":   "unique_identifier",
+			"ref":          "unique_identifier",
+			"reference":    "unique_identifier",
+			"transaction_id": "unique_identifier",
+			"statement_id": "unique_identifier",
+			"amt":          "amount",
+			"value":        "amount",
+			"sum":          "amount",
+			"balance":      "amount",
+			"transaction_date": "date",
+			"statement_date": "date",
+			"posting_date": "date",
+			"value_date":   "date",
+		},
+		Description: fmt.Sprintf("Configuration for %s", path),
+	}
+	
+	return bankConfig
 }
 
 // CreateMatchingConfig creates a matching configuration with the specified tolerances
@@ -157,74 +193,26 @@ func CreateReportConfig(format string) *reporter.ReportConfig {
 type BankProfile struct {
 	Name   string
 	Config *parsers.BankConfig
+	Files  []string // File name patterns the profile applies to, if any
 }
 
-// GetCommonBankProfiles returns configurations for common bank CSV formats
+// GetCommonBankProfiles returns the bank profiles of the parser's registry:
+// the built-in profiles and any loaded at runtime, in registration order.
 func GetCommonBankProfiles() []BankProfile {
-	return []BankProfile{
-		{
-			Name: "Standard",
-			Config: &parsers.BankConfig{
-				Name:             "Standard Bank Format",
-				IdentifierColumn: "unique_identifier",
-				AmountColumn:     "amount",
-				DateColumn:       "date",
-				DateFormat:       "2006-01-02",
-				HasHeader:        true,
-				Delimiter:        ',',
-				Description:      "Standard CSV format with identifier, amount, and date columns",
-			},
-		},
-		{
-			Name: "Chase",
-			Config: &parsers.BankConfig{
-				Name:             "Chase Bank",
-				IdentifierColumn: "transaction_id",
-				AmountColumn:     "amount",
-				DateColumn:       "posting_date",
-				DateFormat:       "01/02/2006",
-				HasHeader:        true,
-				Delimiter:        ',',
-				Description:      "Chase Bank statement format",
-			},
-		},
-		{
-			Name: "Wells Fargo",
-			Config: &parsers.BankConfig{
-				Name:             "Wells Fargo",
-				IdentifierColumn: "reference_number",
-				AmountColumn:     "amount",
-				DateColumn:       "date",
-				DateFormat:       "01/02/2006",
-				HasHeader:        true,
-				Delimiter:        ',',
-				Description:      "Wells Fargo statement format",
-			},
-		},
-		{
-			Name: "Bank of America",
-			Config: &parsers.BankConfig{
-				Name:             "Bank of America",
-				IdentifierColumn: "reference_id",
-				AmountColumn:     "amount",
-				DateColumn:       "posted_date",
-				DateFormat:       "01/02/2006",
-				HasHeader:        true,
-				Delimiter:        ',',
-				Description:      "Bank of America statement format",
-			},
-		},
+	configs := parsers.ListAvailableBankConfigs()
+	
+	profiles := make([]BankProfile, 0, len(configs))
+	for _, bankConfig := range configs {
+		profiles = append(profiles, BankProfile{Name: bankConfig.Name, Config: bankConfig})
 	}
+	return profiles
 }
 
-// GetBankProfile returns a bank configuration by profile name
+// GetBankProfile returns a registered bank configuration by profile name,
+// matched case-insensitively as parsers.GetBankConfig does
 func GetBankProfile(profileName string) (*parsers.BankConfig, error) {
-	profiles := GetCommonBankProfiles()
-	
-	for _, profile := range profiles {
-		if profile.Name == profileName {
-			return profile.Config, nil
-		}
+	if bankConfig := parsers.GetBankConfig(profileName); bankConfig != nil {
+		return bankConfig, nil
 	}
 	
 	return nil, fmt.Errorf("unknown bank profile: %s", profileName)
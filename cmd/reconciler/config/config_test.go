@@ -1,9 +1,13 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/parsers"
 	"golang-reconciliation-service/internal/reporter"
 )
@@ -229,6 +233,34 @@ func TestCreateReportConfig(t *testing.T) {
 	}
 }
 
+func TestDefaultBankConfig_DateLayouts(t *testing.T) {
+	layouts := defaultBankConfig("statements.csv", 0, 1).DateLayouts()
+
+	tests := []struct {
+		input     string
+		expected  time.Time
+		wantError bool
+	}{
+		{"2024-01-15", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-15 10:30:00", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), false},
+		{"2024-01-15T10:30:00", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), false},
+		{"2024-01-15T10:30:00Z", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC), false},
+		{"01/02/2024", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			parsed, err := models.ParseTimeWithLayouts(tt.input, layouts)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseTimeWithLayouts() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && !parsed.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, parsed)
+			}
+		})
+	}
+}
+
 func TestGetCommonBankProfiles(t *testing.T) {
 	profiles := GetCommonBankProfiles()
 
@@ -263,6 +295,8 @@ func TestGetBankProfile(t *testing.T) {
 	}{
 		{"valid profile", "Standard", false},
 		{"another valid profile", "Chase", false},
+		{"sample profile", "Bank1", false},
+		{"case-insensitive name", "wells fargo", false},
 		{"invalid profile", "NonExistent", true},
 		{"empty name", "", true},
 	}
@@ -296,6 +330,43 @@ func TestGetBankProfile(t *testing.T) {
 	}
 }
 
+func TestBankProfiles_UseRegistry(t *testing.T) {
+	runtime := &parsers.BankConfig{
+		Name:             "Config Test Bank",
+		IdentifierColumn: "ref",
+		AmountColumn:     "amount",
+		DateColumn:       "date",
+		DateFormat:       "2006-01-02",
+		HasHeader:        true,
+		Delimiter:        ',',
+	}
+	if err := parsers.DefaultProfileRegistry.Register(runtime); err != nil {
+		t.Fatalf("failed to register profile: %v", err)
+	}
+
+	profiles := GetCommonBankProfiles()
+	if len(profiles) != len(parsers.ListAvailableBankConfigs()) {
+		t.Errorf("expected every registered profile, got %d of %d", len(profiles), len(parsers.ListAvailableBankConfigs()))
+	}
+	found := false
+	for _, profile := range profiles {
+		if profile.Config == runtime {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the runtime profile among the common profiles")
+	}
+
+	config, err := GetBankProfile("config test bank")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config != runtime {
+		t.Error("expected the runtime profile by name")
+	}
+}
+
 func TestValidateConfig(t *testing.T) {
 	// Create valid configurations
 	transactionConfig, _ := CreateTransactionParserConfig()
@@ -369,4 +440,220 @@ func TestValidateConfig(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestParseBankFileSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		path    string
+		profile string
+	}{
+		{"statements.csv", "statements.csv", ""},
+		{"/data/bca.csv:bank1", "/data/bca.csv", "bank1"},
+		{"chase.csv:Chase", "chase.csv", "Chase"},
+		{`C:\data\bank.csv`, `C:\data\bank.csv`, ""},
+		{`C:\data\bank.csv:bank2`, `C:\data\bank.csv`, "bank2"},
+		{"trailing.csv:", "trailing.csv:", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec := ParseBankFileSpec(tt.spec)
+			if spec.Path != tt.path || spec.Profile != tt.profile {
+				t.Errorf("expected path %q profile %q, got path %q profile %q", tt.path, tt.profile, spec.Path, spec.Profile)
+			}
+		})
+	}
+}
+
+func TestParseDateWindowSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    matcher.DateWindowRule
+		wantErr bool
+	}{
+		{spec: "2:0", want: matcher.DateWindowRule{DateWindow: matcher.DateWindow{LagDays: 2}}},
+		{spec: "3:1:bca", want: matcher.DateWindowRule{Bank: "bca", DateWindow: matcher.DateWindow{LagDays: 3, LeadDays: 1}}},
+		{spec: "1:0:bca:dr", want: matcher.DateWindowRule{Bank: "bca", Type: models.TransactionTypeDebit, DateWindow: matcher.DateWindow{LagDays: 1}}},
+		{spec: "1:0:CREDIT", want: matcher.DateWindowRule{Type: models.TransactionTypeCredit, DateWindow: matcher.DateWindow{LagDays: 1}}},
+		{spec: "2", wantErr: true},
+		{spec: "-1:0", wantErr: true},
+		{spec: "2:x", wantErr: true},
+		{spec: "1:0:bca:chase", wantErr: true},
+		{spec: "1:0:DEBIT:CREDIT", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			rule, err := ParseDateWindowSpec(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", rule)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rule != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, rule)
+			}
+		})
+	}
+}
+
+func TestLoadBankProfiles_NumberLocale(t *testing.T) {
+	profiles, err := LoadBankProfiles(map[string]interface{}{
+		"bca": map[string]interface{}{
+			"identifier_column": "ref",
+			"amount_column":     "amount",
+			"date_column":       "posted",
+			"number_locale":     "id",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to load bank profiles: %v", err)
+	}
+	locale := profiles[0].Config.NumberLocale
+	if locale == nil || locale.Name != "id" || locale.DecimalSeparator != "," {
+		t.Errorf("expected the predefined id locale, got %+v", locale)
+	}
+}
+
+func TestCreateSystemParserConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		section     map[string]interface{}
+		wantLocale  string
+		expectError bool
+	}{
+		{"no section", nil, "", false},
+		{"predefined name", map[string]interface{}{"number_locale": "id"}, "id", false},
+		{"locale table", map[string]interface{}{"number_locale": map[string]interface{}{"name": "eu"}}, "eu", false},
+		{"unknown name", map[string]interface{}{"number_locale": "xx"}, "", true},
+		{"invalid separators", map[string]interface{}{"number_locale": map[string]interface{}{"decimal_separator": ";"}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := CreateSystemParserConfig(tt.section)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			switch {
+			case tt.wantLocale == "" && config.NumberLocale != nil:
+				t.Errorf("expected no locale, got %+v", config.NumberLocale)
+			case tt.wantLocale != "" && (config.NumberLocale == nil || config.NumberLocale.Name != tt.wantLocale):
+				t.Errorf("expected locale %s, got %+v", tt.wantLocale, config.NumberLocale)
+			}
+		})
+	}
+}
+
+func TestLoadBankProfiles(t *testing.T) {
+	profiles, err := LoadBankProfiles(map[string]interface{}{
+		"bca": map[string]interface{}{
+			"identifier_column": "ref",
+			"amount_column":     "amount",
+			"date_column":       "posted",
+			"date_format":       "02/01/2006",
+			"delimiter":         ";",
+			"files":             []interface{}{"bca_*.csv"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to load bank profiles: %v", err)
+	}
+	if len(profiles) != 1 {
+		t.Fatalf("expected 1 profile, got %d", len(profiles))
+	}
+
+	profile := profiles[0]
+	if profile.Config.Name != "bca" || profile.Config.Delimiter != ';' || !profile.Config.HasHeader {
+		t.Errorf("unexpected profile config: %+v", profile.Config)
+	}
+	if len(profile.Files) != 1 || profile.Files[0] != "bca_*.csv" {
+		t.Errorf("expected file pattern bca_*.csv, got %v", profile.Files)
+	}
+
+	invalid := []map[string]interface{}{
+		{"missing_date": map[string]interface{}{"identifier_column": "ref", "amount_column": "amount"}},
+		{"bad_delimiter": map[string]interface{}{"identifier_column": "ref", "amount_column": "amount", "date_column": "date", "delimiter": ";;"}},
+	}
+	for _, section := range invalid {
+		if _, err := LoadBankProfiles(section); err == nil {
+			t.Errorf("expected error for invalid profile %v", section)
+		}
+	}
+}
+
+func TestResolveBankConfigs(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	standardFile := writeFile("standard.csv", "unique_identifier,amount,date\nBS001,100.50,2024-01-15\n")
+	bank1File := writeFile("export.csv", "transaction_id,transaction_amount,posting_date\nB1,100.50,01/15/2024\n")
+	bcaFile := writeFile("bca_jan.csv", "ref;amount;posted\nBCA1;100,50;15/01/2024\n")
+	chaseFile := writeFile("chase.csv", "transaction_id,amount,posting_date\nC1,100.50,01/15/2024\n")
+
+	profiles := []BankProfile{
+		{
+			Name: "bca",
+			Config: &parsers.BankConfig{
+				Name:             "BCA",
+				IdentifierColumn: "ref",
+				AmountColumn:     "amount",
+				DateColumn:       "posted",
+				HasHeader:        true,
+				Delimiter:        ';',
+			},
+			Files: []string{"bca_*.csv"},
+		},
+	}
+
+	configs, err := ResolveBankConfigs([]string{standardFile, bank1File, bcaFile, chaseFile + ":chase"}, profiles)
+	if err != nil {
+		t.Fatalf("failed to resolve bank configs: %v", err)
+	}
+
+	expected := map[string]string{
+		standardFile: "Bank_standard", // Default layout
+		bank1File:    "Bank1",         // Detected from headers
+		bcaFile:      "BCA",           // Config file profile matched by pattern
+		chaseFile:    "Chase",         // Explicit profile
+	}
+	for path, name := range expected {
+		config, ok := configs[path]
+		if !ok {
+			t.Errorf("missing config for %s", path)
+			continue
+		}
+		if config.Name != name {
+			t.Errorf("%s: expected profile %s, got %s", filepath.Base(path), name, config.Name)
+		}
+	}
+
+	// Resolved configs are copies of the shared profiles
+	configs[bank1File].Name = "changed"
+	if parsers.SampleBank1Config.Name != "Bank1" {
+		t.Error("resolving a profile should not modify the shared definition")
+	}
+
+	if _, err := ResolveBankConfigs([]string{standardFile + ":unknown"}, profiles); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+	if _, err := ResolveBankConfigs([]string{standardFile, standardFile + ":bank1"}, profiles); err == nil {
+		t.Error("expected error for a bank file listed twice")
+	}
+}
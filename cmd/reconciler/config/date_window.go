@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+)
+
+// ParseDateWindowSpec parses a --date-window entry: the days a statement may
+// be dated after and before its transaction, as LAG:LEAD, optionally followed
+// by a bank and a transaction type the window is limited to:
+//
+//	2:0            every statement: up to 2 days late, never early
+//	3:1:bca        BCA statements: up to 3 days late or 1 day early
+//	1:0:bca:DEBIT  BCA statements of debits
+//	1:0:DEBIT      statements of debits, at any bank
+//
+// The bank is returned as written; it is resolved to a profile's bank name by
+// the caller.
+func ParseDateWindowSpec(spec string) (matcher.DateWindowRule, error) {
+	var rule matcher.DateWindowRule
+
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) < 2 || len(parts) > 4 {
+		return rule, fmt.Errorf("expected LAG:LEAD[:BANK][:TYPE], got %q", spec)
+	}
+
+	lag, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || lag < 0 {
+		return rule, fmt.Errorf("lag must be a non-negative number of days, got %q", parts[0])
+	}
+	lead, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || lead < 0 {
+		return rule, fmt.Errorf("lead must be a non-negative number of days, got %q", parts[1])
+	}
+	rule.DateWindow = matcher.DateWindow{LagDays: lag, LeadDays: lead}
+
+	for _, part := range parts[2:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return rule, fmt.Errorf("empty bank or transaction type in %q", spec)
+		}
+		if txType, err := models.ParseTransactionType(part); err == nil {
+			if rule.Type != "" {
+				return rule, fmt.Errorf("more than one transaction type in %q", spec)
+			}
+			rule.Type = txType
+			continue
+		}
+		if rule.Bank != "" {
+			return rule, fmt.Errorf("more than one bank in %q", spec)
+		}
+		rule.Bank = part
+	}
+	return rule, nil
+}
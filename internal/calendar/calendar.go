@@ -0,0 +1,307 @@
+// Package calendar provides business-day calendars used to measure date
+// tolerances in business days. Saturdays and Sundays are never business days;
+// a calendar adds the holidays of a bank or a country, such as Indonesian
+// public holidays and cuti bersama.
+//
+// Holidays are loaded from a CSV file with a date column and an optional name
+// column:
+//
+//	date,name
+//	2024-04-10,Idul Fitri
+//	2024-04-11,Idul Fitri
+//	2024-04-12,Cuti Bersama Idul Fitri
+//
+// or from an iCalendar (.ics) file, where every all-day VEVENT is a holiday
+// running from DTSTART up to, but excluding, DTEND.
+//
+// Business days between two dates are counted in constant time for weekends
+// and with a binary search over the holidays, so long date ranges cost no more
+// than short ones.
+//
+// Example usage:
+//
+//	cal, err := calendar.Load("id-holidays.ics")
+//	days := cal.BusinessDaysBetween(txDate, stmtDate)
+package calendar
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Weekends is the calendar without holidays: only Saturdays and Sundays are
+// skipped
+var Weekends = New("weekends")
+
+// Holiday is a named non-business day
+type Holiday struct {
+	Date time.Time `json:"date"`
+	Name string    `json:"name,omitempty"`
+}
+
+// Calendar holds the holidays of a business-day calendar. It is safe for
+// concurrent use once loaded.
+type Calendar struct {
+	Name string
+
+	// holidays are the day numbers of the holidays falling on weekdays,
+	// sorted and without duplicates; holidays on weekends change nothing
+	holidays []int64
+	names    map[int64]string
+
+	mu    sync.Mutex
+	spans map[int]int // MaxSpan results by number of business days
+}
+
+// New creates a calendar with the given holidays
+func New(name string, holidays ...Holiday) *Calendar {
+	c := &Calendar{Name: name, names: make(map[int64]string)}
+	for _, holiday := range holidays {
+		c.AddHoliday(holiday.Date, holiday.Name)
+	}
+	return c
+}
+
+// Load reads a calendar from a file. The format is chosen by extension: .ics
+// or .csv. The calendar is named after the file unless an iCalendar file names
+// itself.
+func Load(path string) (*Calendar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holiday calendar: %w", err)
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var c *Calendar
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".ics", ".ical":
+		c, err = ReadICS(file, name)
+	case ".csv":
+		c, err = ReadCSV(file, name)
+	default:
+		return nil, fmt.Errorf("unsupported holiday calendar %s: expected .ics or .csv", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holiday calendar %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// csvDateLayout is the only date layout ReadCSV accepts
+const csvDateLayout = "2006-01-02"
+
+// ReadCSV reads a calendar in CSV form. The header must name a date column;
+// a name column is optional. Dates must be ISO YYYY-MM-DD, as a day-first
+// date such as 04/10/2024 cannot be told apart from a month-first one.
+func ReadCSV(r io.Reader, name string) (*Calendar, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	dateColumn, ok := columns["date"]
+	if !ok {
+		return nil, fmt.Errorf("missing required column 'date'")
+	}
+	nameColumn, hasNames := columns["name"]
+
+	c := New(name)
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if dateColumn >= len(record) {
+			return nil, fmt.Errorf("line %d: missing date", line)
+		}
+
+		value := strings.TrimSpace(record[dateColumn])
+		date, err := time.Parse(csvDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date '%s': dates must be YYYY-MM-DD", line, value)
+		}
+		holidayName := ""
+		if hasNames && nameColumn < len(record) {
+			holidayName = strings.TrimSpace(record[nameColumn])
+		}
+		c.AddHoliday(date, holidayName)
+	}
+	return c, nil
+}
+
+// AddHoliday marks the calendar date of t as a holiday. A holiday on a
+// weekend is recorded by name only.
+func (c *Calendar) AddHoliday(t time.Time, name string) {
+	day := dayNumber(t)
+	if name != "" {
+		c.names[day] = name
+	}
+	if isWeekend(day) {
+		return
+	}
+
+	i := sort.Search(len(c.holidays), func(i int) bool { return c.holidays[i] >= day })
+	if i < len(c.holidays) && c.holidays[i] == day {
+		return
+	}
+	c.holidays = append(c.holidays, 0)
+	copy(c.holidays[i+1:], c.holidays[i:])
+	c.holidays[i] = day
+
+	c.mu.Lock()
+	c.spans = nil
+	c.mu.Unlock()
+}
+
+// Len returns the number of holidays falling on weekdays
+func (c *Calendar) Len() int {
+	return len(c.holidays)
+}
+
+// IsBusinessDay reports whether the calendar date of t is neither a weekend
+// day nor a holiday
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	return c.isBusinessDay(dayNumber(t))
+}
+
+// HolidayName returns the name of the holiday on the calendar date of t, if
+// it is a named holiday
+func (c *Calendar) HolidayName(t time.Time) (string, bool) {
+	name, ok := c.names[dayNumber(t)]
+	return name, ok
+}
+
+// BusinessDaysBetween returns the number of business days from the earlier
+// of two dates up to, but excluding, the later one. Dates are compared by
+// their calendar date in their own location, so a Friday and the following
+// Monday are one business day apart, as are a Wednesday and the Friday after
+// a Thursday holiday.
+func (c *Calendar) BusinessDaysBetween(date1, date2 time.Time) int {
+	from, to := dayNumber(date1), dayNumber(date2)
+	if from > to {
+		from, to = to, from
+	}
+	return int(weekdaysBefore(to) - weekdaysBefore(from) - c.holidaysIn(from, to))
+}
+
+// MaxSpan returns the largest number of calendar days two dates can be apart
+// while at most businessDays business days apart. It bounds the calendar
+// window a business-day tolerance can reach.
+func (c *Calendar) MaxSpan(businessDays int) int {
+	if businessDays < 0 {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if span, ok := c.spans[businessDays]; ok {
+		return span
+	}
+
+	// A span is longest when it starts on a day off. Away from holidays every
+	// week is alike, so the week before the first holiday stands for them all
+	// and only the starts from there to the last holiday need to be tried.
+	start, end := int64(0), int64(7)
+	if len(c.holidays) > 0 {
+		bound := businessDays + 2*(businessDays/5+1) + len(c.holidays)
+		start = c.holidays[0] - int64(bound) - 7
+		end = c.holidays[len(c.holidays)-1] + 1
+	}
+	span := 0
+	for day := start; day < end; day++ {
+		if s := c.spanFrom(day, businessDays); s > span {
+			span = s
+		}
+	}
+
+	if c.spans == nil {
+		c.spans = make(map[int]int)
+	}
+	c.spans[businessDays] = span
+	return span
+}
+
+// spanFrom returns how many days after day its business day number n+1
+// falls, counting from zero at day itself
+func (c *Calendar) spanFrom(day int64, n int) int {
+	seen := 0
+	for d := day; ; d++ {
+		if !c.isBusinessDay(d) {
+			continue
+		}
+		if seen == n {
+			return int(d - day)
+		}
+		seen++
+	}
+}
+
+func (c *Calendar) isBusinessDay(day int64) bool {
+	if isWeekend(day) {
+		return false
+	}
+	i := sort.Search(len(c.holidays), func(i int) bool { return c.holidays[i] >= day })
+	return i == len(c.holidays) || c.holidays[i] != day
+}
+
+// holidaysIn counts the holidays in [from, to)
+func (c *Calendar) holidaysIn(from, to int64) int64 {
+	first := sort.Search(len(c.holidays), func(i int) bool { return c.holidays[i] >= from })
+	last := sort.Search(len(c.holidays), func(i int) bool { return c.holidays[i] >= to })
+	return int64(last - first)
+}
+
+// dayNumber returns the calendar date of t, in its own location, as a number
+// of days since 1970-01-01
+func dayNumber(t time.Time) int64 {
+	year, month, day := t.Date()
+	return floorDiv(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix(), 86400)
+}
+
+// weekdaysBefore counts the weekdays from Sunday 1969-12-28 up to, but
+// excluding, day. It decreases for earlier days, so differences of it count
+// weekdays between any two days.
+func weekdaysBefore(day int64) int64 {
+	sinceSunday := day + 4
+	weeks, rest := floorDiv(sinceSunday, 7), floorMod(sinceSunday, 7)
+	// rest days into the week, starting on Sunday; Monday to Friday count
+	return weeks*5 + max(0, rest-1)
+}
+
+func isWeekend(day int64) bool {
+	weekday := time.Weekday(floorMod(day+4, 7))
+	return weekday == time.Saturday || weekday == time.Sunday
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b < 0 {
+		q--
+	}
+	return q
+}
+
+func floorMod(a, b int64) int64 {
+	return a - floorDiv(a, b)*b
+}
@@ -0,0 +1,198 @@
+package calendar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Idul Fitri 2024 and its cuti bersama ran from Wednesday 10 to Tuesday 16
+// April, with Saturday 13 and Sunday 14 in between
+const idulFitriCSV = `date,name
+2024-04-10,Idul Fitri
+2024-04-11,Idul Fitri
+2024-04-12,Cuti Bersama
+2024-04-13,Weekend
+2024-04-15,Cuti Bersama
+2024-04-16,Cuti Bersama
+`
+
+const idulFitriICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"X-WR-CALNAME:Indonesia\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20240410\r\n" +
+	"DTEND;VALUE=DATE:20240412\r\n" +
+	"SUMMARY:Idul Fitri\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20240412\r\n" +
+	"SUMMARY:Cuti Bersama\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20240415\r\n" +
+	"DTEND;VALUE=DATE:20240417\r\n" +
+	"SUMMARY:Cuti\r\n" +
+	"  Bersama\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestReadCalendar(t *testing.T) {
+	readers := map[string]func() (*Calendar, error){
+		"csv": func() (*Calendar, error) { return ReadCSV(strings.NewReader(idulFitriCSV), "id") },
+		"ics": func() (*Calendar, error) { return ReadICS(strings.NewReader(idulFitriICS), "id") },
+	}
+
+	for format, read := range readers {
+		t.Run(format, func(t *testing.T) {
+			c, err := read()
+			if err != nil {
+				t.Fatalf("Failed to read calendar: %v", err)
+			}
+			if c.Len() != 5 {
+				t.Errorf("Expected 5 weekday holidays, got %d", c.Len())
+			}
+			if name, ok := c.HolidayName(date("2024-04-15")); !ok || name != "Cuti Bersama" {
+				t.Errorf("Expected 2024-04-15 to be Cuti Bersama, got %q", name)
+			}
+			if c.IsBusinessDay(date("2024-04-12")) || !c.IsBusinessDay(date("2024-04-17")) {
+				t.Error("Expected 12 April off and 17 April a business day")
+			}
+		})
+	}
+
+	c, _ := ReadICS(strings.NewReader(idulFitriICS), "id")
+	if c.Name != "Indonesia" {
+		t.Errorf("Expected the calendar to be named by X-WR-CALNAME, got %q", c.Name)
+	}
+}
+
+func TestCalendar_BusinessDaysBetween(t *testing.T) {
+	holidays, err := ReadCSV(strings.NewReader(idulFitriCSV), "id")
+	if err != nil {
+		t.Fatalf("Failed to read calendar: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		calendar *Calendar
+		from, to string
+		want     int
+	}{
+		{"same day", Weekends, "2024-04-03", "2024-04-03", 0},
+		{"next day", Weekends, "2024-04-03", "2024-04-04", 1},
+		{"friday to monday", Weekends, "2024-04-05", "2024-04-08", 1},
+		{"saturday to monday", Weekends, "2024-04-06", "2024-04-08", 0},
+		{"either order", Weekends, "2024-04-08", "2024-04-05", 1},
+		{"two years", Weekends, "2022-01-03", "2024-01-01", 520},
+		{"before 1970", Weekends, "1969-12-26", "1970-01-05", 6},
+		{"over the holidays", holidays, "2024-04-09", "2024-04-17", 1},
+		{"into the holidays", holidays, "2024-04-08", "2024-04-12", 2},
+		{"holidays without holidays", Weekends, "2024-04-09", "2024-04-17", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.calendar.BusinessDaysBetween(date(tt.from), date(tt.to)); got != tt.want {
+				t.Errorf("BusinessDaysBetween(%s, %s) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendar_MaxSpan(t *testing.T) {
+	holidays, err := ReadCSV(strings.NewReader(idulFitriCSV), "id")
+	if err != nil {
+		t.Fatalf("Failed to read calendar: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		calendar     *Calendar
+		businessDays int
+		want         int
+	}{
+		{"no tolerance", Weekends, 0, 2},
+		{"one day over a weekend", Weekends, 1, 3},
+		{"a week", Weekends, 5, 9},
+		// Saturday 6 April to Wednesday 17 April: only 8 and 9 April count
+		{"two days over the holidays", holidays, 2, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.calendar.MaxSpan(tt.businessDays); got != tt.want {
+				t.Errorf("MaxSpan(%d) = %d, want %d", tt.businessDays, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCalendar_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		read    func() (*Calendar, error)
+		wantErr string
+	}{
+		{"csv without date column", func() (*Calendar, error) {
+			return ReadCSV(strings.NewReader("day,name\n2024-01-01,New Year\n"), "x")
+		}, "missing required column 'date'"},
+		{"csv invalid date", func() (*Calendar, error) {
+			return ReadCSV(strings.NewReader("date\nsoon\n"), "x")
+		}, "line 2: invalid date"},
+		{"csv day-first date", func() (*Calendar, error) {
+			return ReadCSV(strings.NewReader("date,name\n10/04/2024,Idul Fitri\n"), "x")
+		}, "line 2: invalid date '10/04/2024': dates must be YYYY-MM-DD"},
+		{"csv iso datetime", func() (*Calendar, error) {
+			return ReadCSV(strings.NewReader("date\n2024-04-10 00:00:00\n"), "x")
+		}, "line 2: invalid date"},
+		{"ics recurring event", func() (*Calendar, error) {
+			return ReadICS(strings.NewReader("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\nRRULE:FREQ=YEARLY\nEND:VEVENT\n"), "x")
+		}, "recurring events are not supported"},
+		{"ics without start", func() (*Calendar, error) {
+			return ReadICS(strings.NewReader("BEGIN:VEVENT\nSUMMARY:Holiday\nEND:VEVENT\n"), "x")
+		}, "missing DTSTART"},
+		{"ics unterminated event", func() (*Calendar, error) {
+			return ReadICS(strings.NewReader("BEGIN:VEVENT\nDTSTART;VALUE=DATE:20240101\n"), "x")
+		}, "unterminated VEVENT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.read()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"id.csv": idulFitriCSV, "id.ics": idulFitriICS, "id.txt": idulFitriCSV} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	c, err := Load(filepath.Join(dir, "id.csv"))
+	if err != nil || c.Name != "id" || c.Len() != 5 {
+		t.Errorf("Load(id.csv) = %v, %v; expected calendar id with 5 holidays", c, err)
+	}
+	if c, err := Load(filepath.Join(dir, "id.ics")); err != nil || c.Len() != 5 {
+		t.Errorf("Load(id.ics) failed: %v", err)
+	}
+	if _, err := Load(filepath.Join(dir, "id.txt")); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected unsupported file error, got %v", err)
+	}
+}
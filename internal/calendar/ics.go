@@ -0,0 +1,121 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReadICS reads a calendar in iCalendar form. Each VEVENT is a holiday from
+// its DTSTART date up to, but excluding, its DTEND date; an event without
+// DTEND lasts one day. Events with a time of day count for the whole date
+// they start on. Recurring events are rejected, since their occurrences are
+// not expanded. X-WR-CALNAME, when present, names the calendar.
+func ReadICS(r io.Reader, name string) (*Calendar, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := New(name)
+	var event map[string]string
+	for i, line := range lines {
+		property, value := splitICSLine(line)
+		switch {
+		case property == "BEGIN" && value == "VEVENT":
+			event = make(map[string]string)
+		case property == "END" && value == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", i+1)
+			}
+			if err := c.addICSEvent(event); err != nil {
+				return nil, fmt.Errorf("event ending on line %d: %w", i+1, err)
+			}
+			event = nil
+		case event != nil:
+			event[property] = value
+		case property == "X-WR-CALNAME" && value != "":
+			c.Name = value
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	return c, nil
+}
+
+func (c *Calendar) addICSEvent(event map[string]string) error {
+	if _, ok := event["RRULE"]; ok {
+		return fmt.Errorf("recurring events are not supported: list each occurrence")
+	}
+	rawStart, ok := event["DTSTART"]
+	if !ok {
+		return fmt.Errorf("missing DTSTART")
+	}
+	start, err := parseICSDate(rawStart)
+	if err != nil {
+		return fmt.Errorf("invalid DTSTART: %w", err)
+	}
+	end := start.AddDate(0, 0, 1)
+	if rawEnd, ok := event["DTEND"]; ok {
+		if end, err = parseICSDate(rawEnd); err != nil {
+			return fmt.Errorf("invalid DTEND: %w", err)
+		}
+		if !end.After(start) {
+			end = start.AddDate(0, 0, 1)
+		}
+	}
+
+	summary := unescapeICSText(event["SUMMARY"])
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		c.AddHoliday(day, summary)
+	}
+	return nil
+}
+
+// unfoldICSLines reads the content lines of an iCalendar stream, joining the
+// continuation lines that start with a space or a tab
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// splitICSLine splits a content line into its upper-cased property name,
+// without parameters, and its value
+func splitICSLine(line string) (string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(strings.TrimSpace(line)), ""
+	}
+	property := line[:colon]
+	if semicolon := strings.Index(property, ";"); semicolon >= 0 {
+		property = property[:semicolon]
+	}
+	return strings.ToUpper(strings.TrimSpace(property)), strings.TrimSpace(line[colon+1:])
+}
+
+// parseICSDate reads the date of a DATE or DATE-TIME value
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("%q is not a date", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(value)
+}
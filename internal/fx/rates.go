@@ -0,0 +1,251 @@
+// Package fx provides foreign exchange rate tables used to convert amounts
+// into a common base currency before matching.
+//
+// Rate tables are loaded from CSV files keyed by date and currency pair:
+//
+//	date,from_currency,to_currency,rate
+//	2024-01-15,EUR,USD,1.0950
+//	2024-01-15,USD,IDR,15550
+//
+// A rate converts one unit of from_currency into to_currency. Lookups use the
+// most recent rate on or before the requested date, and inverse pairs are
+// derived automatically.
+//
+// Example usage:
+//
+//	table, err := fx.LoadRateTable("rates.csv")
+//	conv, err := table.Convert(decimal.NewFromInt(100), "EUR", "USD", date)
+package fx
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultMaxRateAgeDays is how far back a lookup may reach for a rate when no
+// rate exists for the exact date (weekends, holidays)
+const DefaultMaxRateAgeDays = 7
+
+// rateDivisionPrecision is the number of decimal places kept when deriving an
+// inverse rate
+const rateDivisionPrecision = 10
+
+// Rate is a single exchange rate observation
+type Rate struct {
+	Date time.Time
+	From string
+	To   string
+	Rate decimal.Decimal
+}
+
+// Conversion records how an amount was converted into another currency
+type Conversion struct {
+	Currency        string          `json:"currency"`
+	Amount          decimal.Decimal `json:"amount"`
+	TargetCurrency  string          `json:"target_currency"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount"`
+	Rate            decimal.Decimal `json:"rate"`
+	RateDate        time.Time       `json:"rate_date"`
+}
+
+// RateTable holds exchange rates indexed by currency pair and date
+type RateTable struct {
+	// MaxRateAgeDays limits how old a rate may be relative to the lookup date
+	MaxRateAgeDays int
+
+	// rates maps "FROM/TO" to observations sorted by date
+	rates map[string][]Rate
+}
+
+// NewRateTable creates an empty rate table
+func NewRateTable() *RateTable {
+	return &RateTable{
+		MaxRateAgeDays: DefaultMaxRateAgeDays,
+		rates:          make(map[string][]Rate),
+	}
+}
+
+// LoadRateTable reads a rate table from a CSV file
+func LoadRateTable(path string) (*RateTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FX rate file: %w", err)
+	}
+	defer file.Close()
+
+	table, err := ReadRateTable(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FX rate file %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// ReadRateTable reads a rate table in CSV form. The header must name the
+// date, from_currency, to_currency and rate columns, in any order.
+func ReadRateTable(r io.Reader) (*RateTable, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "from_currency", "to_currency", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column '%s'", required)
+		}
+	}
+
+	table := NewRateTable()
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		date, err := models.ParseTimeWithFormats(record[columns["date"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date: %w", line, err)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[columns["rate"]]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate: %w", line, err)
+		}
+
+		if err := table.AddRate(date, record[columns["from_currency"]], record[columns["to_currency"]], rate); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return table, nil
+}
+
+// AddRate adds a rate converting one unit of from into to on the given date
+func (rt *RateTable) AddRate(date time.Time, from, to string, rate decimal.Decimal) error {
+	from = NormalizeCurrency(from)
+	to = NormalizeCurrency(to)
+
+	if from == "" || to == "" {
+		return fmt.Errorf("currency codes cannot be empty")
+	}
+	if from == to {
+		return fmt.Errorf("rate for %s/%s converts a currency into itself", from, to)
+	}
+	if !rate.IsPositive() {
+		return fmt.Errorf("rate for %s/%s must be positive, got %s", from, to, rate.String())
+	}
+
+	key := pairKey(from, to)
+	observations := append(rt.rates[key], Rate{
+		Date: truncateToDay(date),
+		From: from,
+		To:   to,
+		Rate: rate,
+	})
+	sort.SliceStable(observations, func(i, j int) bool {
+		return observations[i].Date.Before(observations[j].Date)
+	})
+	rt.rates[key] = observations
+
+	return nil
+}
+
+// Len returns the number of rate observations in the table
+func (rt *RateTable) Len() int {
+	count := 0
+	for _, observations := range rt.rates {
+		count += len(observations)
+	}
+	return count
+}
+
+// Lookup returns the rate converting from into to on the given date. Direct
+// rates are preferred over inverted ones.
+func (rt *RateTable) Lookup(from, to string, on time.Time) (decimal.Decimal, time.Time, error) {
+	from = NormalizeCurrency(from)
+	to = NormalizeCurrency(to)
+
+	if from == to {
+		return decimal.NewFromInt(1), truncateToDay(on), nil
+	}
+
+	if rate, ok := rt.find(pairKey(from, to), on); ok {
+		return rate.Rate, rate.Date, nil
+	}
+	if rate, ok := rt.find(pairKey(to, from), on); ok {
+		return decimal.NewFromInt(1).DivRound(rate.Rate, rateDivisionPrecision), rate.Date, nil
+	}
+
+	return decimal.Zero, time.Time{}, fmt.Errorf("no %s/%s rate on or up to %d days before %s",
+		from, to, rt.MaxRateAgeDays, on.Format("2006-01-02"))
+}
+
+// Convert converts amount from one currency into another using the rate for
+// the given date
+func (rt *RateTable) Convert(amount decimal.Decimal, from, to string, on time.Time) (*Conversion, error) {
+	rate, rateDate, err := rt.Lookup(from, to, on)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conversion{
+		Currency:        NormalizeCurrency(from),
+		Amount:          amount,
+		TargetCurrency:  NormalizeCurrency(to),
+		ConvertedAmount: amount.Mul(rate).Round(2),
+		Rate:            rate,
+		RateDate:        rateDate,
+	}, nil
+}
+
+// find returns the latest observation for key on or before the given date
+func (rt *RateTable) find(key string, on time.Time) (Rate, bool) {
+	observations := rt.rates[key]
+	day := truncateToDay(on)
+
+	// First observation strictly after the requested day
+	idx := sort.Search(len(observations), func(i int) bool {
+		return observations[i].Date.After(day)
+	})
+	if idx == 0 {
+		return Rate{}, false
+	}
+
+	rate := observations[idx-1]
+	if rt.MaxRateAgeDays >= 0 && day.Sub(rate.Date) > time.Duration(rt.MaxRateAgeDays)*24*time.Hour {
+		return Rate{}, false
+	}
+	return rate, true
+}
+
+// NormalizeCurrency returns the upper-cased, trimmed currency code
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func pairKey(from, to string) string {
+	return from + "/" + to
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,118 @@
+package fx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const sampleRates = `date,from_currency,to_currency,rate
+2024-01-15,EUR,USD,1.10
+2024-01-16,EUR,USD,1.20
+2024-01-15,usd,idr,15500
+`
+
+func TestReadRateTable(t *testing.T) {
+	table, err := ReadRateTable(strings.NewReader(sampleRates))
+	if err != nil {
+		t.Fatalf("ReadRateTable failed: %v", err)
+	}
+	if table.Len() != 3 {
+		t.Errorf("Expected 3 rates, got %d", table.Len())
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"missing column", "date,from_currency,rate\n2024-01-15,EUR,1.1\n", "to_currency"},
+		{"invalid date", "date,from_currency,to_currency,rate\nnot-a-date,EUR,USD,1.1\n", "line 2"},
+		{"invalid rate", "date,from_currency,to_currency,rate\n2024-01-15,EUR,USD,abc\n", "invalid rate"},
+		{"non-positive rate", "date,from_currency,to_currency,rate\n2024-01-15,EUR,USD,0\n", "must be positive"},
+		{"same currency", "date,from_currency,to_currency,rate\n2024-01-15,EUR,EUR,1\n", "into itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadRateTable(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRateTable_Lookup(t *testing.T) {
+	table, err := ReadRateTable(strings.NewReader(sampleRates))
+	if err != nil {
+		t.Fatalf("ReadRateTable failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		on       time.Time
+		want     string
+		wantDate string
+		wantErr  bool
+	}{
+		{"exact date", "EUR", "USD", time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC), "1.1", "2024-01-15", false},
+		{"latest rate wins", "EUR", "USD", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), "1.2", "2024-01-16", false},
+		{"weekend falls back", "EUR", "USD", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), "1.2", "2024-01-16", false},
+		{"inverse pair", "IDR", "USD", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "0.0000645161", "2024-01-15", false},
+		{"same currency", "usd", "USD", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "1", "2024-01-15", false},
+		{"before first rate", "EUR", "USD", time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), "", "", true},
+		{"stale rate", "EUR", "USD", time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC), "", "", true},
+		{"unknown pair", "GBP", "USD", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, rateDate, err := table.Lookup(tt.from, tt.to, tt.on)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got rate %s", rate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup failed: %v", err)
+			}
+			if !rate.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Expected rate %s, got %s", tt.want, rate)
+			}
+			if rateDate.Format("2006-01-02") != tt.wantDate {
+				t.Errorf("Expected rate date %s, got %s", tt.wantDate, rateDate.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestRateTable_Convert(t *testing.T) {
+	table := NewRateTable()
+	if err := table.AddRate(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "EUR", "USD", decimal.RequireFromString("1.0950")); err != nil {
+		t.Fatalf("AddRate failed: %v", err)
+	}
+
+	conversion, err := table.Convert(decimal.RequireFromString("-100.00"), "eur", "usd", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	if conversion.Currency != "EUR" || conversion.TargetCurrency != "USD" {
+		t.Errorf("Expected EUR -> USD, got %s -> %s", conversion.Currency, conversion.TargetCurrency)
+	}
+	if !conversion.ConvertedAmount.Equal(decimal.RequireFromString("-109.50")) {
+		t.Errorf("Expected converted amount -109.50, got %s", conversion.ConvertedAmount)
+	}
+	if !conversion.Amount.Equal(decimal.RequireFromString("-100")) {
+		t.Errorf("Expected original amount to be kept, got %s", conversion.Amount)
+	}
+}
@@ -0,0 +1,195 @@
+package matcher
+
+import "github.com/shopspring/decimal"
+
+// AmountTree is an AVL tree of index entries ordered by amount. Lookups,
+// insertions and removals take O(log n) in the number of distinct amounts,
+// so an index can be kept up to date one item at a time.
+type AmountTree[E any] struct {
+	root *amountNode[E]
+	size int
+}
+
+type amountNode[E any] struct {
+	amount      decimal.Decimal
+	entry       E
+	left, right *amountNode[E]
+	height      int
+}
+
+// Len returns the number of distinct amounts in the tree
+func (t *AmountTree[E]) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.size
+}
+
+// Get returns the entry for an amount
+func (t *AmountTree[E]) Get(amount decimal.Decimal) (E, bool) {
+	node := t.root
+	for node != nil {
+		switch cmp := amount.Cmp(node.amount); {
+		case cmp < 0:
+			node = node.left
+		case cmp > 0:
+			node = node.right
+		default:
+			return node.entry, true
+		}
+	}
+	var zero E
+	return zero, false
+}
+
+// Put sets the entry for an amount, replacing any entry it had
+func (t *AmountTree[E]) Put(amount decimal.Decimal, entry E) {
+	var added bool
+	t.root, added = t.root.put(amount, entry)
+	if added {
+		t.size++
+	}
+}
+
+// Delete removes the entry for an amount and reports whether there was one
+func (t *AmountTree[E]) Delete(amount decimal.Decimal) bool {
+	var deleted bool
+	t.root, deleted = t.root.delete(amount)
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// Ascend calls fn for the entries with amounts in [minAmount, maxAmount], in
+// ascending order of amount, until fn returns false
+func (t *AmountTree[E]) Ascend(minAmount, maxAmount decimal.Decimal, fn func(amount decimal.Decimal, entry E) bool) {
+	t.root.ascend(minAmount, maxAmount, fn)
+}
+
+// All calls fn for every entry in ascending order of amount, until fn
+// returns false
+func (t *AmountTree[E]) All(fn func(amount decimal.Decimal, entry E) bool) {
+	t.root.all(fn)
+}
+
+func (n *amountNode[E]) put(amount decimal.Decimal, entry E) (*amountNode[E], bool) {
+	if n == nil {
+		return &amountNode[E]{amount: amount, entry: entry, height: 1}, true
+	}
+
+	var added bool
+	switch cmp := amount.Cmp(n.amount); {
+	case cmp < 0:
+		n.left, added = n.left.put(amount, entry)
+	case cmp > 0:
+		n.right, added = n.right.put(amount, entry)
+	default:
+		n.entry = entry
+		return n, false
+	}
+	return n.rebalance(), added
+}
+
+func (n *amountNode[E]) delete(amount decimal.Decimal) (*amountNode[E], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var deleted bool
+	switch cmp := amount.Cmp(n.amount); {
+	case cmp < 0:
+		n.left, deleted = n.left.delete(amount)
+	case cmp > 0:
+		n.right, deleted = n.right.delete(amount)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+		// Replace the node with its successor
+		successor := n.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		n.amount, n.entry = successor.amount, successor.entry
+		n.right, _ = n.right.delete(successor.amount)
+		deleted = true
+	}
+	return n.rebalance(), deleted
+}
+
+func (n *amountNode[E]) ascend(minAmount, maxAmount decimal.Decimal, fn func(decimal.Decimal, E) bool) bool {
+	if n == nil {
+		return true
+	}
+	aboveMin := n.amount.GreaterThanOrEqual(minAmount)
+	belowMax := n.amount.LessThanOrEqual(maxAmount)
+	if aboveMin && !n.left.ascend(minAmount, maxAmount, fn) {
+		return false
+	}
+	if aboveMin && belowMax && !fn(n.amount, n.entry) {
+		return false
+	}
+	if belowMax {
+		return n.right.ascend(minAmount, maxAmount, fn)
+	}
+	return true
+}
+
+func (n *amountNode[E]) all(fn func(decimal.Decimal, E) bool) bool {
+	if n == nil {
+		return true
+	}
+	return n.left.all(fn) && fn(n.amount, n.entry) && n.right.all(fn)
+}
+
+func (n *amountNode[E]) nodeHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *amountNode[E]) update() {
+	n.height = max(n.left.nodeHeight(), n.right.nodeHeight()) + 1
+}
+
+// rebalance restores the AVL property at n after one of its subtrees changed
+// height by at most one
+func (n *amountNode[E]) rebalance() *amountNode[E] {
+	n.update()
+	switch balance := n.left.nodeHeight() - n.right.nodeHeight(); {
+	case balance > 1:
+		if n.left.left.nodeHeight() < n.left.right.nodeHeight() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case balance < -1:
+		if n.right.right.nodeHeight() < n.right.left.nodeHeight() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+func (n *amountNode[E]) rotateLeft() *amountNode[E] {
+	pivot := n.right
+	n.right = pivot.left
+	pivot.left = n
+	n.update()
+	pivot.update()
+	return pivot
+}
+
+func (n *amountNode[E]) rotateRight() *amountNode[E] {
+	pivot := n.left
+	n.left = pivot.right
+	pivot.right = n
+	n.update()
+	pivot.update()
+	return pivot
+}
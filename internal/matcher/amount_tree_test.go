@@ -0,0 +1,109 @@
+package matcher
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// checkAmountTree verifies ordering, AVL balance and cached heights below a
+// node and returns its height
+func checkAmountTree(t *testing.T, n *amountNode[int], low, high *decimal.Decimal) int {
+	t.Helper()
+	if n == nil {
+		return 0
+	}
+	if (low != nil && n.amount.LessThanOrEqual(*low)) || (high != nil && n.amount.GreaterThanOrEqual(*high)) {
+		t.Fatalf("Amount %s is out of order", n.amount)
+	}
+	left := checkAmountTree(t, n.left, low, &n.amount)
+	right := checkAmountTree(t, n.right, &n.amount, high)
+	if left-right > 1 || right-left > 1 {
+		t.Fatalf("Node %s is unbalanced: %d vs %d", n.amount, left, right)
+	}
+	if n.height != max(left, right)+1 {
+		t.Fatalf("Node %s caches height %d, want %d", n.amount, n.height, max(left, right)+1)
+	}
+	return n.height
+}
+
+func TestAmountTree(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	tree := &AmountTree[int]{}
+	reference := make(map[int64]int)
+
+	for i := 0; i < 5000; i++ {
+		cents := int64(random.Intn(400) - 200)
+		amount := decimal.New(cents, -2)
+		if random.Intn(3) == 0 {
+			_, exists := reference[cents]
+			if deleted := tree.Delete(amount); deleted != exists {
+				t.Fatalf("Delete(%s) = %v, want %v", amount, deleted, exists)
+			}
+			delete(reference, cents)
+		} else {
+			tree.Put(amount, i)
+			reference[cents] = i
+		}
+		if tree.Len() != len(reference) {
+			t.Fatalf("Len() = %d, want %d", tree.Len(), len(reference))
+		}
+	}
+	checkAmountTree(t, tree.root, nil, nil)
+
+	for cents, want := range reference {
+		if got, ok := tree.Get(decimal.New(cents, -2)); !ok || got != want {
+			t.Errorf("Get(%d cents) = %d, %v, want %d", cents, got, ok, want)
+		}
+	}
+
+	keys := make([]int64, 0, len(reference))
+	for cents := range reference {
+		keys = append(keys, cents)
+	}
+	slices.Sort(keys)
+
+	tests := []struct {
+		name     string
+		min, max int64
+	}{
+		{"everything", -1000, 1000},
+		{"inner range", -50, 75},
+		{"single amount", keys[len(keys)/2], keys[len(keys)/2]},
+		{"empty range", 500, 600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []int64
+			for _, cents := range keys {
+				if cents >= tt.min && cents <= tt.max {
+					want = append(want, cents)
+				}
+			}
+			var got []int64
+			tree.Ascend(decimal.New(tt.min, -2), decimal.New(tt.max, -2), func(amount decimal.Decimal, entry int) bool {
+				got = append(got, amount.Shift(2).IntPart())
+				if entry != reference[amount.Shift(2).IntPart()] {
+					t.Errorf("Entry for %s = %d, want %d", amount, entry, reference[amount.Shift(2).IntPart()])
+				}
+				return true
+			})
+			if !slices.Equal(got, want) {
+				t.Errorf("Ascend returned %v, want %v", got, want)
+			}
+		})
+	}
+
+	// Ascend and All stop when fn returns false
+	count := 0
+	tree.All(func(decimal.Decimal, int) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("All visited %d entries after being stopped at 3", count)
+	}
+}
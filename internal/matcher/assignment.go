@@ -0,0 +1,438 @@
+package matcher
+
+import (
+	"math"
+	"sort"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// selectMatches chooses one-to-one pairs with the configured assignment mode.
+// When accept is not nil, only scored pairs it accepts are considered.
+func (me *MatchingEngine) selectMatches(accept func(*MatchResult) bool) []*MatchResult {
+	switch me.Config.AssignmentMode {
+	case AssignmentOptimal:
+		return me.selectOptimalMatches(accept)
+	default:
+		return me.selectGreedyMatches(accept)
+	}
+}
+
+// selectGreedyMatches walks transactions in input order and assigns each one
+// its best scoring statement, provided that statement has not been taken yet.
+func (me *MatchingEngine) selectGreedyMatches(accept func(*MatchResult) bool) []*MatchResult {
+	var matches []*MatchResult
+	matchedTransactionIDs := make(map[string]bool)
+	matchedStatementIDs := make(map[string]bool)
+	transactionCount := len(me.TransactionIndex.AllTransactions)
+
+	// Only the best pair of each transaction is ever considered
+	scored := me.scoreTransactions(me.TransactionIndex.AllTransactions, accept, 1)
+
+	for i, tx := range me.TransactionIndex.AllTransactions {
+		if i%100 == 0 && i > 0 {
+			me.logger.WithFields(logger.Fields{
+				"processed": i,
+				"total":     transactionCount,
+				"progress":  float64(i) / float64(transactionCount) * 100,
+			}).Debug("Transaction matching progress")
+		}
+
+		if matchedTransactionIDs[tx.TrxID] {
+			continue // Already matched
+		}
+
+		scores := scored[i]
+		if len(scores) == 0 {
+			continue
+		}
+
+		// Find best match above confidence threshold
+		bestMatch := scores[0]
+		if bestMatch.ConfidenceScore < me.Config.MinConfidenceScore {
+			continue
+		}
+
+		// Check if bank statement is already matched
+		if matchedStatementIDs[bestMatch.BankStatement.UniqueIdentifier] {
+			continue
+		}
+
+		matches = append(matches, bestMatch)
+		matchedTransactionIDs[tx.TrxID] = true
+		matchedStatementIDs[bestMatch.BankStatement.UniqueIdentifier] = true
+
+		me.logger.WithFields(logger.Fields{
+			"transaction_id":   tx.TrxID,
+			"statement_id":     bestMatch.BankStatement.UniqueIdentifier,
+			"match_type":       bestMatch.MatchType,
+			"confidence_score": bestMatch.ConfidenceScore,
+		}).Debug("Found transaction match")
+	}
+
+	return matches
+}
+
+// scoreCandidatesFor looks up and scores the statement candidates for a
+// transaction, returning the pairs at or above the minimum confidence score
+// that accept accepts, best first. Pairs ruled out by the matching rules are
+// not scored. Every statement looked at is kept on the returned pairs so the
+// match chosen can later list what it was chosen over.
+func (me *MatchingEngine) scoreCandidatesFor(tx *models.Transaction, accept func(*MatchResult) bool) []*MatchResult {
+	if me.overrides != nil && me.overrides.claimedTransactions[tx] {
+		return nil
+	}
+
+	found := me.BankStatementIndex.GetCandidates(tx, me.Config)
+	if len(found) == 0 {
+		return nil
+	}
+	considered := make([]consideredStatement, 0, len(found))
+	candidates := make([]*models.BankStatement, 0, len(found))
+	for _, stmt := range found {
+		if reason := me.overrides.ruledOut(tx, stmt); reason != "" {
+			considered = append(considered, consideredStatement{statement: stmt, reason: reason})
+			continue
+		}
+		candidates = append(candidates, stmt)
+	}
+
+	var scored []*MatchResult
+	for _, result := range me.scorePairs(tx, candidates) {
+		if result.ConfidenceScore < me.Config.MinConfidenceScore {
+			considered = append(considered, consideredStatement{
+				statement: result.BankStatement,
+				score:     result.ConfidenceScore,
+				reason:    RejectionBelowMinConfidence,
+			})
+			continue
+		}
+		scored = append(scored, result)
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].ConfidenceScore > scored[j].ConfidenceScore
+	})
+
+	var results []*MatchResult
+	for _, result := range scored {
+		entry := consideredStatement{statement: result.BankStatement, score: result.ConfidenceScore}
+		if accept != nil && !accept(result) {
+			entry.reason = RejectionNotAccepted
+		} else {
+			results = append(results, result)
+		}
+		considered = append(considered, entry)
+	}
+	for _, result := range results {
+		result.considered = considered
+	}
+
+	me.logger.WithFields(logger.Fields{
+		"transaction_id":   tx.TrxID,
+		"candidates_count": len(found),
+		"matches_count":    len(results),
+	}).Debug("Scored transaction candidates")
+
+	return results
+}
+
+// maxOptimalBucketSize bounds the transactions or statements of a bucket
+// solved with the Hungarian algorithm, which is cubic in the bucket size and
+// builds a dense weight matrix. Larger buckets, as when many items share an
+// amount and a date, are assigned greedily by score instead.
+const maxOptimalBucketSize = 300
+
+// assignmentBucket is a connected group of transactions and statements that
+// share at least one candidate pair. Buckets are independent of each other, so
+// each can be solved on its own.
+type assignmentBucket struct {
+	transactions []int
+	statements   []int
+	edges        []*MatchResult
+}
+
+// selectOptimalMatches chooses the set of one-to-one pairs that maximises the
+// total confidence score. Candidate pairs are grouped into connected buckets
+// (transactions and statements that compete for each other) and every bucket
+// is solved independently with the Hungarian algorithm.
+func (me *MatchingEngine) selectOptimalMatches(accept func(*MatchResult) bool) []*MatchResult {
+	transactions := me.TransactionIndex.AllTransactions
+
+	// Statements are identified by position so that duplicate identifiers
+	// cannot be assigned twice by accident.
+	statementPos := make(map[*models.BankStatement]int)
+	var statements []*models.BankStatement
+
+	type edge struct {
+		tx, stmt int
+		result   *MatchResult
+	}
+	var edges []edge
+
+	for i, results := range me.scoreTransactions(transactions, accept, 0) {
+		for _, result := range results {
+			if result.ConfidenceScore < me.Config.MinConfidenceScore {
+				continue
+			}
+			pos, exists := statementPos[result.BankStatement]
+			if !exists {
+				pos = len(statements)
+				statementPos[result.BankStatement] = pos
+				statements = append(statements, result.BankStatement)
+			}
+			edges = append(edges, edge{tx: i, stmt: pos, result: result})
+		}
+	}
+
+	if len(edges) == 0 {
+		return nil
+	}
+
+	// Union-find over transactions (0..n-1) and statements (n..n+m-1)
+	offset := len(transactions)
+	parent := make([]int, offset+len(statements))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	for _, e := range edges {
+		a, b := find(e.tx), find(offset+e.stmt)
+		if a != b {
+			parent[b] = a
+		}
+	}
+
+	bucketsByRoot := make(map[int]*assignmentBucket)
+	var buckets []*assignmentBucket
+	seenTx := make(map[int]bool)
+	seenStmt := make(map[int]bool)
+	for _, e := range edges {
+		root := find(e.tx)
+		bucket, exists := bucketsByRoot[root]
+		if !exists {
+			bucket = &assignmentBucket{}
+			bucketsByRoot[root] = bucket
+			buckets = append(buckets, bucket)
+		}
+		if !seenTx[e.tx] {
+			seenTx[e.tx] = true
+			bucket.transactions = append(bucket.transactions, e.tx)
+		}
+		if !seenStmt[e.stmt] {
+			seenStmt[e.stmt] = true
+			bucket.statements = append(bucket.statements, e.stmt)
+		}
+		bucket.edges = append(bucket.edges, e.result)
+	}
+
+	me.logger.WithFields(logger.Fields{
+		"candidate_pairs": len(edges),
+		"buckets":         len(buckets),
+	}).Debug("Solving optimal assignment")
+
+	var matches []*MatchResult
+	txOrder := make(map[*models.Transaction]int, len(transactions))
+	for i, tx := range transactions {
+		txOrder[tx] = i
+	}
+
+	matches = append(matches, me.solveBuckets(buckets, transactions, statementPos)...)
+
+	// Report matches in input order so output stays stable
+	sort.SliceStable(matches, func(i, j int) bool {
+		return txOrder[matches[i].Transaction] < txOrder[matches[j].Transaction]
+	})
+
+	return matches
+}
+
+// solveBucket runs the Hungarian algorithm over a single bucket and returns
+// the selected pairs. Buckets above maxOptimalBucketSize are assigned
+// greedily.
+func (me *MatchingEngine) solveBucket(bucket *assignmentBucket, transactions []*models.Transaction, statementPos map[*models.BankStatement]int) []*MatchResult {
+	// Trivial bucket: one transaction competing for one statement
+	if len(bucket.edges) == 1 {
+		return bucket.edges
+	}
+	if len(bucket.transactions) > maxOptimalBucketSize || len(bucket.statements) > maxOptimalBucketSize {
+		me.logger.WithFields(logger.Fields{
+			"transactions": len(bucket.transactions),
+			"statements":   len(bucket.statements),
+			"limit":        maxOptimalBucketSize,
+		}).Warn("Assignment bucket too large for optimal assignment, assigning greedily")
+		return greedyBucketAssignment(bucket)
+	}
+
+	rowOf := make(map[int]int, len(bucket.transactions))
+	for r, txPos := range bucket.transactions {
+		rowOf[txPos] = r
+	}
+	colOf := make(map[int]int, len(bucket.statements))
+	for c, stmtPos := range bucket.statements {
+		colOf[stmtPos] = c
+	}
+
+	// Transactions are keyed by position because TrxIDs are not guaranteed unique
+	txPosOf := make(map[*models.Transaction]int, len(bucket.transactions))
+	for _, txPos := range bucket.transactions {
+		txPosOf[transactions[txPos]] = txPos
+	}
+
+	weights := make([][]float64, len(bucket.transactions))
+	pairs := make([][]*MatchResult, len(bucket.transactions))
+	for r := range weights {
+		weights[r] = make([]float64, len(bucket.statements))
+		pairs[r] = make([]*MatchResult, len(bucket.statements))
+	}
+	for _, result := range bucket.edges {
+		r := rowOf[txPosOf[result.Transaction]]
+		c := colOf[statementPos[result.BankStatement]]
+		weights[r][c] = result.ConfidenceScore
+		pairs[r][c] = result
+	}
+
+	var matches []*MatchResult
+	for r, c := range maximumWeightAssignment(weights) {
+		if c >= 0 && pairs[r][c] != nil {
+			matches = append(matches, pairs[r][c])
+		}
+	}
+
+	return matches
+}
+
+// greedyBucketAssignment takes the pairs of a bucket best score first,
+// skipping those whose transaction or statement is already taken. Pairs of
+// equal score are taken in input order.
+func greedyBucketAssignment(bucket *assignmentBucket) []*MatchResult {
+	edges := make([]*MatchResult, len(bucket.edges))
+	copy(edges, bucket.edges)
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].ConfidenceScore > edges[j].ConfidenceScore
+	})
+
+	var matches []*MatchResult
+	takenTransactions := make(map[*models.Transaction]bool)
+	takenStatements := make(map[*models.BankStatement]bool)
+	for _, result := range edges {
+		if takenTransactions[result.Transaction] || takenStatements[result.BankStatement] {
+			continue
+		}
+		takenTransactions[result.Transaction] = true
+		takenStatements[result.BankStatement] = true
+		matches = append(matches, result)
+	}
+	return matches
+}
+
+// maximumWeightAssignment solves the assignment problem for a rows x cols
+// weight matrix and returns, for every row, the column assigned to it or -1.
+// Missing pairs should be given a weight of zero; callers are expected to
+// discard assignments that do not correspond to a real pair.
+//
+// This is the O(n^3) Hungarian algorithm with potentials, run on the
+// equivalent minimum-cost problem over a square matrix.
+func maximumWeightAssignment(weights [][]float64) []int {
+	rows := len(weights)
+	if rows == 0 {
+		return nil
+	}
+	cols := len(weights[0])
+	n := rows
+	if cols > n {
+		n = cols
+	}
+
+	maxWeight := 0.0
+	for _, row := range weights {
+		for _, w := range row {
+			if w > maxWeight {
+				maxWeight = w
+			}
+		}
+	}
+
+	cost := func(i, j int) float64 {
+		if i < rows && j < cols {
+			return maxWeight - weights[i][j]
+		}
+		return maxWeight
+	}
+
+	// 1-indexed arrays as in the classic formulation
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1)
+	way := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost(i0-1, j-1) - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+
+		for {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+			if j0 == 0 {
+				break
+			}
+		}
+	}
+
+	assignment := make([]int, rows)
+	for i := range assignment {
+		assignment[i] = -1
+	}
+	for j := 1; j <= n; j++ {
+		if p[j] > 0 && p[j] <= rows && j <= cols {
+			assignment[p[j]-1] = j - 1
+		}
+	}
+
+	return assignment
+}
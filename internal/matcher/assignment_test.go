@@ -0,0 +1,262 @@
+package matcher
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// createCompetingMatchData returns data where the first transaction's best
+// statement is the only acceptable statement for the second transaction.
+func createCompetingMatchData() ([]*models.Transaction, []*models.BankStatement) {
+	transactions := []*models.Transaction{
+		{
+			TrxID:           "TX001",
+			Amount:          decimal.NewFromFloat(100.00),
+			Type:            models.TransactionTypeCredit,
+			TransactionTime: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			TrxID:           "TX002",
+			Amount:          decimal.NewFromFloat(100.00),
+			Type:            models.TransactionTypeCredit,
+			TransactionTime: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	statements := []*models.BankStatement{
+		{
+			UniqueIdentifier: "BS001",
+			Amount:           decimal.NewFromFloat(100.00),
+			Date:             time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			UniqueIdentifier: "BS002",
+			Amount:           decimal.NewFromFloat(100.00),
+			Date:             time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	return transactions, statements
+}
+
+func TestMatchingEngine_Reconcile_AssignmentModes(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            AssignmentMode
+		expectedMatches map[string]string
+	}{
+		{
+			name: "greedy lets the first transaction take the shared statement",
+			mode: AssignmentGreedy,
+			expectedMatches: map[string]string{
+				"TX001": "BS001",
+			},
+		},
+		{
+			name: "optimal maximises total confidence",
+			mode: AssignmentOptimal,
+			expectedMatches: map[string]string{
+				"TX001": "BS002",
+				"TX002": "BS001",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			config.DateToleranceDays = 2
+			config.AssignmentMode = tt.mode
+
+			transactions, statements := createCompetingMatchData()
+			engine := NewMatchingEngine(config)
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+
+			if len(result.Matches) != len(tt.expectedMatches) {
+				t.Fatalf("Expected %d matches, got %d", len(tt.expectedMatches), len(result.Matches))
+			}
+
+			for _, match := range result.Matches {
+				expected := tt.expectedMatches[match.Transaction.TrxID]
+				if match.BankStatement.UniqueIdentifier != expected {
+					t.Errorf("Expected %s to match %s, got %s",
+						match.Transaction.TrxID, expected, match.BankStatement.UniqueIdentifier)
+				}
+			}
+
+			expectedUnmatched := len(transactions) - len(tt.expectedMatches)
+			if len(result.UnmatchedTransactions) != expectedUnmatched {
+				t.Errorf("Expected %d unmatched transactions, got %d", expectedUnmatched, len(result.UnmatchedTransactions))
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_Reconcile_OptimalIsOrderIndependent(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 2
+	config.AssignmentMode = AssignmentOptimal
+
+	transactions, statements := createCompetingMatchData()
+	reversed := []*models.Transaction{transactions[1], transactions[0]}
+
+	pairsFor := func(txs []*models.Transaction) map[string]string {
+		engine := NewMatchingEngine(config)
+		if err := engine.LoadTransactions(txs); err != nil {
+			t.Fatalf("Failed to load transactions: %v", err)
+		}
+		if err := engine.LoadBankStatements(statements); err != nil {
+			t.Fatalf("Failed to load bank statements: %v", err)
+		}
+		result, err := engine.Reconcile()
+		if err != nil {
+			t.Fatalf("Reconcile failed: %v", err)
+		}
+		pairs := make(map[string]string)
+		for _, match := range result.Matches {
+			pairs[match.Transaction.TrxID] = match.BankStatement.UniqueIdentifier
+		}
+		return pairs
+	}
+
+	original := pairsFor(transactions)
+	swapped := pairsFor(reversed)
+
+	if len(original) != len(swapped) {
+		t.Fatalf("Expected the same number of matches, got %d and %d", len(original), len(swapped))
+	}
+	for trxID, stmtID := range original {
+		if swapped[trxID] != stmtID {
+			t.Errorf("Expected %s to match %s regardless of order, got %s", trxID, stmtID, swapped[trxID])
+		}
+	}
+}
+
+func TestMaximumWeightAssignment(t *testing.T) {
+	tests := []struct {
+		name     string
+		weights  [][]float64
+		expected []int
+	}{
+		{
+			name:     "square matrix",
+			weights:  [][]float64{{0.9, 0.8}, {0.85, 0.0}},
+			expected: []int{1, 0},
+		},
+		{
+			name:     "more rows than columns",
+			weights:  [][]float64{{0.9}, {0.95}, {0.1}},
+			expected: []int{-1, 0, -1},
+		},
+		{
+			name:     "more columns than rows",
+			weights:  [][]float64{{0.1, 0.9, 0.5}},
+			expected: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := maximumWeightAssignment(tt.weights)
+			if len(assignment) != len(tt.expected) {
+				t.Fatalf("Expected %d assignments, got %d", len(tt.expected), len(assignment))
+			}
+			for i := range tt.expected {
+				if assignment[i] != tt.expected[i] {
+					t.Errorf("Row %d: expected column %d, got %d", i, tt.expected[i], assignment[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_SolveBucket_LargeBucketIsGreedy(t *testing.T) {
+	// A chain of transactions each scoring higher on the next statement than
+	// on its own, larger than the Hungarian algorithm is run on
+	size := maxOptimalBucketSize + 1
+	transactions := make([]*models.Transaction, size)
+	statements := make([]*models.BankStatement, size)
+	statementPos := make(map[*models.BankStatement]int, size)
+	bucket := &assignmentBucket{}
+	for i := 0; i < size; i++ {
+		transactions[i] = &models.Transaction{TrxID: fmt.Sprintf("TX%04d", i)}
+		statements[i] = &models.BankStatement{UniqueIdentifier: fmt.Sprintf("BS%04d", i)}
+		statementPos[statements[i]] = i
+		bucket.transactions = append(bucket.transactions, i)
+		bucket.statements = append(bucket.statements, i)
+	}
+	for i := 0; i < size; i++ {
+		bucket.edges = append(bucket.edges, &MatchResult{Transaction: transactions[i], BankStatement: statements[i], ConfidenceScore: 0.8})
+		if i+1 < size {
+			bucket.edges = append(bucket.edges, &MatchResult{Transaction: transactions[i], BankStatement: statements[i+1], ConfidenceScore: 0.9})
+		}
+	}
+
+	engine := NewMatchingEngine(DefaultMatchingConfig())
+	matches := engine.solveBucket(bucket, transactions, statementPos)
+
+	// Greedy takes every 0.9 pair, which leaves the last transaction without
+	// a statement
+	if len(matches) != size-1 {
+		t.Fatalf("Expected %d greedy matches, got %d", size-1, len(matches))
+	}
+	seenTx := make(map[*models.Transaction]bool)
+	seenStmt := make(map[*models.BankStatement]bool)
+	for _, match := range matches {
+		if match.ConfidenceScore != 0.9 {
+			t.Errorf("Expected only the best pairs to be taken, got %s-%s at %f",
+				match.Transaction.TrxID, match.BankStatement.UniqueIdentifier, match.ConfidenceScore)
+		}
+		if seenTx[match.Transaction] || seenStmt[match.BankStatement] {
+			t.Errorf("Pair %s-%s reuses a matched item", match.Transaction.TrxID, match.BankStatement.UniqueIdentifier)
+		}
+		seenTx[match.Transaction] = true
+		seenStmt[match.BankStatement] = true
+	}
+}
+
+func TestParseAssignmentMode(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    AssignmentMode
+		expectError bool
+	}{
+		{"", AssignmentGreedy, false},
+		{"greedy", AssignmentGreedy, false},
+		{"optimal", AssignmentOptimal, false},
+		{"best", AssignmentGreedy, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			mode, err := ParseAssignmentMode(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if mode != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, mode)
+			}
+		})
+	}
+}
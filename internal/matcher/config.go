@@ -29,8 +29,14 @@ package matcher
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"golang-reconciliation-service/internal/calendar"
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/rules"
+	"golang-reconciliation-service/internal/models"
+
 	"github.com/shopspring/decimal"
 )
 
@@ -101,6 +107,10 @@ const (
 	// MatchNone indicates no suitable match was found for the transaction.
 	// These represent unmatched transactions requiring investigation.
 	MatchNone
+	
+	// MatchManual represents a pair forced by a match rule rather than chosen
+	// by scoring. The rule that produced it is recorded on the match.
+	MatchManual
 )
 
 // String returns the string representation of MatchType
@@ -116,11 +126,68 @@ func (mt MatchType) String() string {
 		return "Possible"
 	case MatchNone:
 		return "None"
+	case MatchManual:
+		return "Manual"
 	default:
 		return "Unknown"
 	}
 }
 
+// AssignmentMode defines how scored candidate pairs are turned into one-to-one matches.
+type AssignmentMode int
+
+const (
+	// AssignmentGreedy walks transactions in input order and takes the best
+	// available statement for each one. It is fast but results depend on the
+	// order of rows in the input files.
+	AssignmentGreedy AssignmentMode = iota
+	
+	// AssignmentOptimal selects the set of pairs that maximises the total
+	// confidence score across all candidates. Candidate pairs are split into
+	// independent buckets and each bucket is solved with the Hungarian algorithm,
+	// so the result does not depend on input order.
+	AssignmentOptimal
+)
+
+// String returns the string representation of AssignmentMode
+func (am AssignmentMode) String() string {
+	switch am {
+	case AssignmentGreedy:
+		return "greedy"
+	case AssignmentOptimal:
+		return "optimal"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseAssignmentMode converts a string into an AssignmentMode
+func ParseAssignmentMode(s string) (AssignmentMode, error) {
+	switch s {
+	case "", "greedy":
+		return AssignmentGreedy, nil
+	case "optimal":
+		return AssignmentOptimal, nil
+	default:
+		return AssignmentGreedy, fmt.Errorf("invalid assignment mode: %s (must be 'greedy' or 'optimal')", s)
+	}
+}
+
+// MarshalText encodes the assignment mode by name, so JSON configs read "greedy" or "optimal"
+func (am AssignmentMode) MarshalText() ([]byte, error) {
+	return []byte(am.String()), nil
+}
+
+// UnmarshalText decodes an assignment mode name
+func (am *AssignmentMode) UnmarshalText(text []byte) error {
+	mode, err := ParseAssignmentMode(string(text))
+	if err != nil {
+		return err
+	}
+	*am = mode
+	return nil
+}
+
 // MatchingConfig holds configuration parameters for transaction matching.
 // This configuration controls all aspects of the matching algorithm including
 // tolerances, weights, and behavioral options. Different configurations can be
@@ -141,6 +208,14 @@ type MatchingConfig struct {
 	// DateToleranceDays defines the number of days tolerance for date matching
 	DateToleranceDays int `json:"date_tolerance_days"`
 	
+	// DateWindow, when set, replaces the symmetric DateToleranceDays with a
+	// window of days a statement may be dated after its transaction and
+	// before it. DateWindows override either for the pairs of a bank, a
+	// transaction type or both (see DateWindowFor). Statements dated before
+	// their transaction score lower than ones dated as far after it.
+	DateWindow  *DateWindow      `json:"date_window,omitempty"`
+	DateWindows []DateWindowRule `json:"date_windows,omitempty"`
+	
 	// AmountPrecision defines the number of decimal places for amount comparison
 	AmountPrecision int `json:"amount_precision"`
 	
@@ -162,6 +237,15 @@ type MatchingConfig struct {
 	// MinConfidenceScore defines the minimum confidence score for a match
 	MinConfidenceScore float64 `json:"min_confidence_score"`
 	
+	// AutoAcceptScore is the confidence score a match needs to be accepted
+	// without review. Matches scoring between MinConfidenceScore and it are
+	// held in the pending review queue. Zero accepts every match.
+	AutoAcceptScore float64 `json:"auto_accept_score,omitempty"`
+	
+	// AmbiguityReviewScore holds for review the matches made on a day whose
+	// same-day ambiguity score is at or above it. Zero disables the check.
+	AmbiguityReviewScore float64 `json:"ambiguity_review_score,omitempty"`
+	
 	// EnableTypeMatching requires transaction type compatibility
 	EnableTypeMatching bool `json:"enable_type_matching"`
 	
@@ -171,9 +255,61 @@ type MatchingConfig struct {
 	// MaxPartialMatchRatio defines the maximum ratio for partial matches (0.0 to 1.0)
 	MaxPartialMatchRatio float64 `json:"max_partial_match_ratio"`
 	
+	// EnableOneToManyMatching allows a single bank statement to be matched against
+	// a group of transactions whose amounts add up to it (batched settlements)
+	EnableOneToManyMatching bool `json:"enable_one_to_many_matching"`
+	
+	// MaxGroupCandidates limits how many transactions are considered when searching
+	// for a one-to-many group for a single bank statement
+	MaxGroupCandidates int `json:"max_group_candidates"`
+	
 	// IgnoreWeekends excludes weekends from date tolerance calculations
 	IgnoreWeekends bool `json:"ignore_weekends"`
 	
+	// Calendar measures the date tolerance and date scores in business days:
+	// weekends and its holidays do not count. BankCalendars overrides it for
+	// the statements of a bank, keyed by bank name (compared
+	// case-insensitively). Without a calendar, IgnoreWeekends measures in
+	// business days of calendar.Weekends.
+	Calendar      *calendar.Calendar            `json:"-"`
+	BankCalendars map[string]*calendar.Calendar `json:"-"`
+	
+	// AssignmentMode selects how one-to-one matches are chosen from scored candidates
+	AssignmentMode AssignmentMode `json:"assignment_mode"`
+	
+	// Parallelism is the number of workers scoring candidates concurrently.
+	// Transactions are split into date partitions of PartitionDays (7 when
+	// unset) and the partitions are scored in parallel; the matches are the
+	// same as with a single worker. Zero or one scores serially.
+	Parallelism   int `json:"parallelism,omitempty"`
+	PartitionDays int `json:"partition_days,omitempty"`
+	
+	// BaseCurrency is the currency amounts are converted into before scoring.
+	// Empty disables currency conversion.
+	BaseCurrency string `json:"base_currency,omitempty"`
+	
+	// FXRates supplies the exchange rates used to convert into BaseCurrency
+	FXRates *fx.RateTable `json:"-"`
+	
+	// Rules holds manual overrides applied before scoring: forced pairs,
+	// excluded pairs and ignored bank statements
+	Rules *rules.Set `json:"-"`
+	
+	// Strategies lists the matching passes to run, in order, by their name in
+	// DefaultStrategyRegistry. Each pass only sees the items left unmatched by
+	// the passes before it. Empty runs the single scoring pass followed by the
+	// enabled group passes.
+	Strategies []string `json:"strategies,omitempty"`
+	
+	// NearMissLimit is the number of nearest counterparts reported for each
+	// unmatched item, with what kept them from matching. Counterparts are
+	// searched within NearMissAmountPercent of the item amount and up to
+	// NearMissDays beyond the date tolerance. Zero, the default, disables near
+	// misses, as searching them scores every unmatched item again.
+	NearMissLimit         int     `json:"near_miss_limit,omitempty"`
+	NearMissAmountPercent float64 `json:"near_miss_amount_percent,omitempty"`
+	NearMissDays          int     `json:"near_miss_days,omitempty"`
+	
 	// Priority weights for different matching criteria
 	Weights MatchingWeights `json:"weights"`
 }
@@ -183,6 +319,15 @@ type MatchingWeights struct {
 	AmountWeight float64 `json:"amount_weight"`
 	DateWeight   float64 `json:"date_weight"`
 	TypeWeight   float64 `json:"type_weight"`
+	
+	// IdentifierWeight scores how well the transaction ID matches the
+	// statement identifier or description. Zero, the default, disables
+	// identifier scoring and the exact-ID fast path.
+	IdentifierWeight float64 `json:"identifier_weight,omitempty"`
+	
+	// DescriptionWeight scores how similar the transaction and statement
+	// descriptions are. Zero, the default, disables description scoring.
+	DescriptionWeight float64 `json:"description_weight,omitempty"`
 }
 
 // DefaultMatchingConfig returns a configuration with sensible defaults
@@ -199,7 +344,13 @@ func DefaultMatchingConfig() *MatchingConfig {
 		EnableTypeMatching:            true,
 		EnablePartialMatching:         false,
 		MaxPartialMatchRatio:          0.1,
+		EnableOneToManyMatching:       false,
+		MaxGroupCandidates:            50,
 		IgnoreWeekends:                false,
+		AssignmentMode:                AssignmentGreedy,
+		NearMissLimit:                 0,
+		NearMissAmountPercent:         10.0,
+		NearMissDays:                  7,
 		Weights: MatchingWeights{
 			AmountWeight: 0.6,
 			DateWeight:   0.3,
@@ -222,7 +373,13 @@ func StrictMatchingConfig() *MatchingConfig {
 		EnableTypeMatching:            true,
 		EnablePartialMatching:         false,
 		MaxPartialMatchRatio:          0.0,
+		EnableOneToManyMatching:       false,
+		MaxGroupCandidates:            50,
 		IgnoreWeekends:                false,
+		AssignmentMode:                AssignmentGreedy,
+		NearMissLimit:                 0,
+		NearMissAmountPercent:         10.0,
+		NearMissDays:                  7,
 		Weights: MatchingWeights{
 			AmountWeight: 0.7,
 			DateWeight:   0.2,
@@ -245,7 +402,13 @@ func RelaxedMatchingConfig() *MatchingConfig {
 		EnableTypeMatching:            false,
 		EnablePartialMatching:         true,
 		MaxPartialMatchRatio:          0.2,
+		EnableOneToManyMatching:       false,
+		MaxGroupCandidates:            50,
 		IgnoreWeekends:                true,
+		AssignmentMode:                AssignmentGreedy,
+		NearMissLimit:                 0,
+		NearMissAmountPercent:         10.0,
+		NearMissDays:                  7,
 		Weights: MatchingWeights{
 			AmountWeight: 0.5,
 			DateWeight:   0.4,
@@ -260,6 +423,17 @@ func (mc *MatchingConfig) Validate() error {
 		return fmt.Errorf("date tolerance days cannot be negative: %d", mc.DateToleranceDays)
 	}
 	
+	if mc.DateWindow != nil {
+		if err := mc.DateWindow.validate(); err != nil {
+			return err
+		}
+	}
+	for i, rule := range mc.DateWindows {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("date window rule %d: %w", i+1, err)
+		}
+	}
+	
 	if mc.AmountPrecision < 0 || mc.AmountPrecision > 10 {
 		return fmt.Errorf("amount precision must be between 0 and 10: %d", mc.AmountPrecision)
 	}
@@ -280,6 +454,56 @@ func (mc *MatchingConfig) Validate() error {
 		return fmt.Errorf("max partial match ratio must be between 0.0 and 1.0: %f", mc.MaxPartialMatchRatio)
 	}
 	
+	if mc.MaxGroupCandidates < 0 {
+		return fmt.Errorf("max group candidates cannot be negative: %d", mc.MaxGroupCandidates)
+	}
+	
+	if mc.AssignmentMode != AssignmentGreedy && mc.AssignmentMode != AssignmentOptimal {
+		return fmt.Errorf("invalid assignment mode: %d", mc.AssignmentMode)
+	}
+	
+	if mc.Parallelism < 0 {
+		return fmt.Errorf("parallelism cannot be negative: %d", mc.Parallelism)
+	}
+	
+	if mc.PartitionDays < 0 {
+		return fmt.Errorf("partition days cannot be negative: %d", mc.PartitionDays)
+	}
+	
+	if _, err := models.ParseCurrencyCode(mc.BaseCurrency); err != nil {
+		return fmt.Errorf("invalid base currency: %w", err)
+	}
+	
+	if mc.NearMissLimit < 0 {
+		return fmt.Errorf("near miss limit cannot be negative: %d", mc.NearMissLimit)
+	}
+	
+	if mc.NearMissAmountPercent < 0.0 || mc.NearMissAmountPercent > 100.0 {
+		return fmt.Errorf("near miss amount percent must be between 0.0 and 100.0: %f", mc.NearMissAmountPercent)
+	}
+	
+	if mc.NearMissDays < 0 {
+		return fmt.Errorf("near miss days cannot be negative: %d", mc.NearMissDays)
+	}
+	
+	if mc.AutoAcceptScore < 0.0 || mc.AutoAcceptScore > 1.0 {
+		return fmt.Errorf("auto accept score must be between 0.0 and 1.0: %f", mc.AutoAcceptScore)
+	}
+	
+	if mc.AutoAcceptScore > 0 && mc.AutoAcceptScore < mc.MinConfidenceScore {
+		return fmt.Errorf("auto accept score %.2f is below the minimum confidence score %.2f", mc.AutoAcceptScore, mc.MinConfidenceScore)
+	}
+	
+	if mc.AmbiguityReviewScore < 0.0 || mc.AmbiguityReviewScore > 1.0 {
+		return fmt.Errorf("ambiguity review score must be between 0.0 and 1.0: %f", mc.AmbiguityReviewScore)
+	}
+	
+	for _, name := range mc.Strategies {
+		if DefaultStrategyRegistry.Get(name) == nil {
+			return fmt.Errorf("unknown matching strategy %q (available: %s)", name, strings.Join(DefaultStrategyRegistry.Names(), ", "))
+		}
+	}
+	
 	// Validate weights
 	if err := mc.Weights.Validate(); err != nil {
 		return fmt.Errorf("invalid weights: %w", err)
@@ -309,8 +533,16 @@ func (mw *MatchingWeights) Validate() error {
 		return fmt.Errorf("type weight must be between 0.0 and 1.0: %f", mw.TypeWeight)
 	}
 	
+	if mw.IdentifierWeight < 0.0 || mw.IdentifierWeight > 1.0 {
+		return fmt.Errorf("identifier weight must be between 0.0 and 1.0: %f", mw.IdentifierWeight)
+	}
+	
+	if mw.DescriptionWeight < 0.0 || mw.DescriptionWeight > 1.0 {
+		return fmt.Errorf("description weight must be between 0.0 and 1.0: %f", mw.DescriptionWeight)
+	}
+	
 	// Weights should sum to approximately 1.0 (allow some tolerance)
-	total := mw.AmountWeight + mw.DateWeight + mw.TypeWeight
+	total := mw.AmountWeight + mw.DateWeight + mw.TypeWeight + mw.IdentifierWeight + mw.DescriptionWeight
 	if total < 0.9 || total > 1.1 {
 		return fmt.Errorf("weights should sum to approximately 1.0, got %f", total)
 	}
@@ -318,14 +550,54 @@ func (mw *MatchingWeights) Validate() error {
 	return nil
 }
 
+// WithIdentifierWeight returns the weights with the identifier weight set and
+// the amount, date and type weights scaled down proportionally, so the total
+// is unchanged
+func (mw MatchingWeights) WithIdentifierWeight(weight float64) MatchingWeights {
+	mw.scaleCoreWeights(mw.IdentifierWeight, weight)
+	mw.IdentifierWeight = weight
+	return mw
+}
+
+// WithDescriptionWeight returns the weights with the description weight set
+// and the amount, date and type weights scaled down proportionally, so the
+// total is unchanged
+func (mw MatchingWeights) WithDescriptionWeight(weight float64) MatchingWeights {
+	mw.scaleCoreWeights(mw.DescriptionWeight, weight)
+	mw.DescriptionWeight = weight
+	return mw
+}
+
+// scaleCoreWeights scales the amount, date and type weights to make room for
+// an optional weight changing from current to weight
+func (mw *MatchingWeights) scaleCoreWeights(current, weight float64) {
+	core := mw.AmountWeight + mw.DateWeight + mw.TypeWeight
+	if core <= 0 {
+		return
+	}
+	
+	scale := (core + current - weight) / core
+	mw.AmountWeight *= scale
+	mw.DateWeight *= scale
+	mw.TypeWeight *= scale
+}
+
 // Clone creates a deep copy of the matching configuration
 func (mc *MatchingConfig) Clone() *MatchingConfig {
 	if mc == nil {
 		return nil
 	}
 	
+	var dateWindow *DateWindow
+	if mc.DateWindow != nil {
+		window := *mc.DateWindow
+		dateWindow = &window
+	}
+	
 	return &MatchingConfig{
 		DateToleranceDays:              mc.DateToleranceDays,
+		DateWindow:                     dateWindow,
+		DateWindows:                    append([]DateWindowRule(nil), mc.DateWindows...),
 		AmountPrecision:                mc.AmountPrecision,
 		AmountTolerancePercent:         mc.AmountTolerancePercent,
 		EnableFuzzyMatching:            mc.EnableFuzzyMatching,
@@ -333,14 +605,32 @@ func (mc *MatchingConfig) Clone() *MatchingConfig {
 		BusinessTimezone:              mc.BusinessTimezone,
 		MaxCandidatesPerTransaction:   mc.MaxCandidatesPerTransaction,
 		MinConfidenceScore:            mc.MinConfidenceScore,
+		AutoAcceptScore:               mc.AutoAcceptScore,
+		AmbiguityReviewScore:          mc.AmbiguityReviewScore,
 		EnableTypeMatching:            mc.EnableTypeMatching,
 		EnablePartialMatching:         mc.EnablePartialMatching,
 		MaxPartialMatchRatio:          mc.MaxPartialMatchRatio,
+		EnableOneToManyMatching:       mc.EnableOneToManyMatching,
+		MaxGroupCandidates:            mc.MaxGroupCandidates,
 		IgnoreWeekends:                mc.IgnoreWeekends,
+		Calendar:                      mc.Calendar,
+		BankCalendars:                 mc.BankCalendars,
+		AssignmentMode:                mc.AssignmentMode,
+		Parallelism:                   mc.Parallelism,
+		PartitionDays:                 mc.PartitionDays,
+		BaseCurrency:                  mc.BaseCurrency,
+		FXRates:                       mc.FXRates,
+		Rules:                         mc.Rules,
+		Strategies:                    append([]string(nil), mc.Strategies...),
+		NearMissLimit:                 mc.NearMissLimit,
+		NearMissAmountPercent:         mc.NearMissAmountPercent,
+		NearMissDays:                  mc.NearMissDays,
 		Weights: MatchingWeights{
-			AmountWeight: mc.Weights.AmountWeight,
-			DateWeight:   mc.Weights.DateWeight,
-			TypeWeight:   mc.Weights.TypeWeight,
+			AmountWeight:      mc.Weights.AmountWeight,
+			DateWeight:        mc.Weights.DateWeight,
+			TypeWeight:        mc.Weights.TypeWeight,
+			IdentifierWeight:  mc.Weights.IdentifierWeight,
+			DescriptionWeight: mc.Weights.DescriptionWeight,
 		},
 	}
 }
@@ -359,54 +649,11 @@ func (mc *MatchingConfig) GetAmountTolerance(amount decimal.Decimal) decimal.Dec
 	return tolerance.Round(precision)
 }
 
-// IsWithinDateTolerance checks if two dates are within the configured tolerance
-func (mc *MatchingConfig) IsWithinDateTolerance(date1, date2 time.Time) bool {
-	if mc.DateToleranceDays == 0 {
-		return date1.Format("2006-01-02") == date2.Format("2006-01-02")
-	}
-	
-	diff := date1.Sub(date2)
-	if diff < 0 {
-		diff = -diff
-	}
-	
-	maxDiff := time.Duration(mc.DateToleranceDays) * 24 * time.Hour
-	
-	if !mc.IgnoreWeekends {
-		return diff <= maxDiff
-	}
-	
-	// Calculate business days difference when ignoring weekends
-	return mc.isWithinBusinessDayTolerance(date1, date2)
-}
-
-// isWithinBusinessDayTolerance checks date tolerance excluding weekends
-func (mc *MatchingConfig) isWithinBusinessDayTolerance(date1, date2 time.Time) bool {
-	if date1.Equal(date2) {
-		return true
-	}
-	
-	// Ensure date1 is earlier than date2
-	if date1.After(date2) {
-		date1, date2 = date2, date1
-	}
-	
-	businessDays := 0
-	current := date1
-	
-	for businessDays <= mc.DateToleranceDays && current.Before(date2) {
-		// Skip weekends
-		if current.Weekday() != time.Saturday && current.Weekday() != time.Sunday {
-			businessDays++
-		}
-		current = current.AddDate(0, 0, 1)
-		
-		if current.Format("2006-01-02") == date2.Format("2006-01-02") {
-			return businessDays <= mc.DateToleranceDays
-		}
-	}
-	
-	return businessDays <= mc.DateToleranceDays
+// IsWithinDateTolerance checks if the dates of a transaction and a statement,
+// in that order, are within the configured tolerance or date window, measured
+// on the calendar of statements without a bank calendar
+func (mc *MatchingConfig) IsWithinDateTolerance(txDate, stmtDate time.Time) bool {
+	return mc.dateRuleFor(nil, nil).within(txDate, stmtDate)
 }
 
 // NormalizeTime normalizes time according to the timezone handling configuration
@@ -431,8 +678,87 @@ func (mc *MatchingConfig) NormalizeTime(t time.Time) time.Time {
 	}
 }
 
+// calendarToleranceDays returns the date tolerance in calendar days, widened
+// to cover the widest date window and the days off of the widest calendar in
+// use
+func (mc *MatchingConfig) calendarToleranceDays() int {
+	calendars := []*calendar.Calendar{mc.CalendarFor("")}
+	for _, cal := range mc.BankCalendars {
+		calendars = append(calendars, cal)
+	}
+	windows := []*DateWindow{mc.DateWindow}
+	for i := range mc.DateWindows {
+		windows = append(windows, &mc.DateWindows[i].DateWindow)
+	}
+	
+	days := 0
+	for _, cal := range calendars {
+		for _, window := range windows {
+			days = max(days, dateRule{days: mc.DateToleranceDays, window: window, calendar: cal}.calendarDays())
+		}
+	}
+	return days
+}
+
+// filtersByDate reports whether candidates are filtered by date: with a
+// positive date tolerance or any date window
+func (mc *MatchingConfig) filtersByDate() bool {
+	return mc.DateToleranceDays > 0 || mc.usesDateWindows()
+}
+
+// isDateCandidate checks if a pair passes the date filter of its date rule
+func (mc *MatchingConfig) isDateCandidate(tx *models.Transaction, stmt *models.BankStatement) bool {
+	rule := mc.dateRuleFor(tx, stmt)
+	return !rule.filters() || rule.within(mc.NormalizeTime(tx.TransactionTime), mc.NormalizeTime(stmt.Date))
+}
+
+// comparesLocalDates reports whether any date rule compares the local
+// calendar dates of a pair rather than its times: business-day tolerances and
+// date windows
+func (mc *MatchingConfig) comparesLocalDates() bool {
+	return mc.IgnoreWeekends || mc.Calendar != nil || len(mc.BankCalendars) > 0 || mc.usesDateWindows()
+}
+
+// MatchingWindowDays returns the widest distance, measured in DayNumber days,
+// at which a transaction and a bank statement can still be paired by any
+// pass, and false when pairing is not bounded by date. Items further apart
+// never compete for each other, so an input can be split at such gaps and
+// matched piece by piece with the same outcome.
+//
+// Pairing is unbounded without a date tolerance or a default date window,
+// since candidates are then not all filtered by date, when identifiers are weighted, since a statement
+// carrying the transaction ID is a candidate on any date, and when the rules
+// force manual pairs. Custom strategies are assumed to pair only the
+// candidates a pass offers them.
+func (mc *MatchingConfig) MatchingWindowDays() (int, bool) {
+	if (mc.DateToleranceDays <= 0 && mc.DateWindow == nil) || mc.Weights.IdentifierWeight > 0 {
+		return 0, false
+	}
+	if mc.Rules != nil && len(mc.Rules.Matches) > 0 {
+		return 0, false
+	}
+	
+	days := mc.calendarToleranceDays()
+	if mc.comparesLocalDates() {
+		// Dates are compared on the local calendar of each date
+		days++
+	}
+	return days, true
+}
+
+// DayNumber returns the UTC day of the normalised time as a number of days
+// since the Unix epoch
+func (mc *MatchingConfig) DayNumber(t time.Time) int64 {
+	seconds := mc.NormalizeTime(t).Unix()
+	day := seconds / 86400
+	if seconds%86400 < 0 {
+		day--
+	}
+	return day
+}
+
 // String returns a human-readable description of the configuration
 func (mc *MatchingConfig) String() string {
-	return fmt.Sprintf("MatchingConfig{DateTolerance: %d days, AmountPrecision: %d, AmountTolerance: %.2f%%, Timezone: %s, MinConfidence: %.2f}",
-		mc.DateToleranceDays, mc.AmountPrecision, mc.AmountTolerancePercent, mc.TimezoneHandling.String(), mc.MinConfidenceScore)
+	return fmt.Sprintf("MatchingConfig{DateTolerance: %d days, AmountPrecision: %d, AmountTolerance: %.2f%%, Timezone: %s, MinConfidence: %.2f, Assignment: %s}",
+		mc.DateToleranceDays, mc.AmountPrecision, mc.AmountTolerancePercent, mc.TimezoneHandling.String(), mc.MinConfidenceScore, mc.AssignmentMode.String())
 }
\ No newline at end of file
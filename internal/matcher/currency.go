@@ -0,0 +1,260 @@
+package matcher
+
+import (
+	"fmt"
+	"time"
+
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+// FXDetails describes the currency conversion behind a matched pair. Amounts
+// are compared in the base currency, so Difference is what is left over after
+// conversion.
+type FXDetails struct {
+	BaseCurrency string
+	Transaction  *fx.Conversion // nil when the transaction was already in the base currency
+	Statement    *fx.Conversion // nil when the statement was already in the base currency
+	Difference   decimal.Decimal
+}
+
+// currencyConverter converts transaction and statement amounts into the
+// configured base currency. Conversions are computed once when data is
+// loaded, so lookups during matching are read-only. A nil converter leaves
+// amounts unchanged, which is the behaviour when no base currency is set.
+type currencyConverter struct {
+	base            string
+	rates           *fx.RateTable
+	txConversions   map[*models.Transaction]*fx.Conversion
+	stmtConversions map[*models.BankStatement]*fx.Conversion
+	logger          logger.Logger
+}
+
+// newCurrencyConverter returns a converter for the config, or nil when
+// currency conversion is disabled
+func newCurrencyConverter(config *MatchingConfig, log logger.Logger) *currencyConverter {
+	base := fx.NormalizeCurrency(config.BaseCurrency)
+	if base == "" {
+		return nil
+	}
+
+	return &currencyConverter{
+		base:            base,
+		rates:           config.FXRates,
+		txConversions:   make(map[*models.Transaction]*fx.Conversion),
+		stmtConversions: make(map[*models.BankStatement]*fx.Conversion),
+		logger:          log,
+	}
+}
+
+// prepareTransactions converts every foreign-currency transaction
+func (cc *currencyConverter) prepareTransactions(transactions []*models.Transaction) {
+	if cc == nil {
+		return
+	}
+
+	cc.txConversions = make(map[*models.Transaction]*fx.Conversion)
+	for _, tx := range transactions {
+		cc.addTransaction(tx)
+	}
+}
+
+// addTransaction converts a transaction that arrives after the others were
+// prepared
+func (cc *currencyConverter) addTransaction(tx *models.Transaction) {
+	if cc == nil {
+		return
+	}
+
+	if conversion := cc.convert(tx.Amount, tx.Currency, tx.TransactionTime, tx.TrxID); conversion != nil {
+		cc.txConversions[tx] = conversion
+	}
+}
+
+// forgetTransaction drops the conversion of a transaction that is no longer
+// being matched
+func (cc *currencyConverter) forgetTransaction(tx *models.Transaction) {
+	if cc != nil {
+		delete(cc.txConversions, tx)
+	}
+}
+
+// prepareStatements converts every foreign-currency bank statement
+func (cc *currencyConverter) prepareStatements(statements []*models.BankStatement) {
+	if cc == nil {
+		return
+	}
+
+	cc.stmtConversions = make(map[*models.BankStatement]*fx.Conversion)
+	for _, stmt := range statements {
+		cc.addStatement(stmt)
+	}
+}
+
+// addStatement converts a bank statement that arrives after the others were
+// prepared
+func (cc *currencyConverter) addStatement(stmt *models.BankStatement) {
+	if cc == nil {
+		return
+	}
+
+	if conversion := cc.convert(stmt.Amount, stmt.Currency, stmt.Date, stmt.UniqueIdentifier); conversion != nil {
+		cc.stmtConversions[stmt] = conversion
+	}
+}
+
+// forgetStatement drops the conversion of a bank statement that is no longer
+// being matched
+func (cc *currencyConverter) forgetStatement(stmt *models.BankStatement) {
+	if cc != nil {
+		delete(cc.stmtConversions, stmt)
+	}
+}
+
+// convert returns the conversion of a foreign amount, or nil when the amount
+// is already in the base currency or no rate is available
+func (cc *currencyConverter) convert(amount decimal.Decimal, currency string, on time.Time, id string) *fx.Conversion {
+	currency = fx.NormalizeCurrency(currency)
+	if currency == "" || currency == cc.base {
+		return nil
+	}
+
+	if cc.rates == nil {
+		cc.logger.WithFields(logger.Fields{
+			"id":       id,
+			"currency": currency,
+		}).Warn("No FX rate table loaded; amount left in its original currency")
+		return nil
+	}
+
+	conversion, err := cc.rates.Convert(amount, currency, cc.base, on)
+	if err != nil {
+		cc.logger.WithError(err).WithField("id", id).Warn("Failed to convert amount to base currency")
+		return nil
+	}
+
+	return conversion
+}
+
+// BaseAmount returns an amount in the base currency, converted as matching
+// converts it. The amount is returned unchanged when no base currency is set,
+// it is already in the base currency or no rate is available.
+func (mc *MatchingConfig) BaseAmount(amount decimal.Decimal, currency string, on time.Time) decimal.Decimal {
+	base := fx.NormalizeCurrency(mc.BaseCurrency)
+	currency = fx.NormalizeCurrency(currency)
+	if base == "" || currency == "" || currency == base || mc.FXRates == nil {
+		return amount
+	}
+	conversion, err := mc.FXRates.Convert(amount, currency, base, on)
+	if err != nil {
+		return amount
+	}
+	return conversion.ConvertedAmount
+}
+
+// transactionAmount returns the transaction amount in the base currency when
+// it was converted, or the original amount otherwise
+func (cc *currencyConverter) transactionAmount(tx *models.Transaction) decimal.Decimal {
+	if cc != nil {
+		if conversion, ok := cc.txConversions[tx]; ok {
+			return conversion.ConvertedAmount
+		}
+	}
+	return tx.Amount
+}
+
+// statementAmount returns the statement amount in the base currency when it
+// was converted, or the original amount otherwise
+func (cc *currencyConverter) statementAmount(stmt *models.BankStatement) decimal.Decimal {
+	if cc != nil {
+		if conversion, ok := cc.stmtConversions[stmt]; ok {
+			return conversion.ConvertedAmount
+		}
+	}
+	return stmt.Amount
+}
+
+// transactionCurrency returns the currency the transaction amount is
+// expressed in for matching; "" stands for the base currency
+func (cc *currencyConverter) transactionCurrency(tx *models.Transaction) string {
+	if cc == nil {
+		return ""
+	}
+	if _, ok := cc.txConversions[tx]; ok {
+		return ""
+	}
+	return cc.effectiveCurrency(tx.Currency)
+}
+
+// statementCurrency returns the currency the statement amount is expressed in
+// for matching; "" stands for the base currency
+func (cc *currencyConverter) statementCurrency(stmt *models.BankStatement) string {
+	if cc == nil {
+		return ""
+	}
+	if _, ok := cc.stmtConversions[stmt]; ok {
+		return ""
+	}
+	return cc.effectiveCurrency(stmt.Currency)
+}
+
+func (cc *currencyConverter) effectiveCurrency(currency string) string {
+	currency = fx.NormalizeCurrency(currency)
+	if currency == cc.base {
+		return ""
+	}
+	return currency
+}
+
+// comparable reports whether the amounts of a transaction and a statement are
+// in the same currency once conversions are applied
+func (cc *currencyConverter) comparable(tx *models.Transaction, stmt *models.BankStatement) bool {
+	if cc == nil {
+		return fx.NormalizeCurrency(tx.Currency) == fx.NormalizeCurrency(stmt.Currency) ||
+			tx.Currency == "" || stmt.Currency == ""
+	}
+	return cc.transactionCurrency(tx) == cc.statementCurrency(stmt)
+}
+
+// groupable reports whether a transaction and a statement may be combined in
+// a grouped match. Group totals are summed from original amounts, so only
+// pairs that share a currency and were not converted take part.
+func (cc *currencyConverter) groupable(tx *models.Transaction, stmt *models.BankStatement) bool {
+	if cc == nil {
+		return cc.comparable(tx, stmt)
+	}
+	_, txConverted := cc.txConversions[tx]
+	_, stmtConverted := cc.stmtConversions[stmt]
+	return !txConverted && !stmtConverted && cc.comparable(tx, stmt)
+}
+
+// details returns the FX details for a pair, or nil when neither side was converted
+func (cc *currencyConverter) details(tx *models.Transaction, stmt *models.BankStatement, difference decimal.Decimal) *FXDetails {
+	if cc == nil {
+		return nil
+	}
+
+	txConversion := cc.txConversions[tx]
+	stmtConversion := cc.stmtConversions[stmt]
+	if txConversion == nil && stmtConversion == nil {
+		return nil
+	}
+
+	return &FXDetails{
+		BaseCurrency: cc.base,
+		Transaction:  txConversion,
+		Statement:    stmtConversion,
+		Difference:   difference,
+	}
+}
+
+// describeConversion returns a short human-readable description of a conversion
+func describeConversion(conversion *fx.Conversion) string {
+	return fmt.Sprintf("Converted %s %s to %s %s at %s",
+		conversion.Currency, conversion.Amount.StringFixed(2),
+		conversion.TargetCurrency, conversion.ConvertedAmount.StringFixed(2),
+		conversion.Rate.String())
+}
@@ -0,0 +1,158 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func createMultiCurrencyData(t *testing.T) ([]*models.Transaction, []*models.BankStatement, *fx.RateTable) {
+	t.Helper()
+
+	rates := fx.NewRateTable()
+	if err := rates.AddRate(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "EUR", "USD", decimal.NewFromFloat(1.10)); err != nil {
+		t.Fatalf("AddRate failed: %v", err)
+	}
+
+	transactions := []*models.Transaction{
+		{
+			TrxID:           "TX001",
+			Amount:          decimal.NewFromFloat(110.00),
+			Type:            models.TransactionTypeCredit,
+			TransactionTime: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+			Currency:        "USD",
+		},
+		{
+			TrxID:           "TX002",
+			Amount:          decimal.NewFromFloat(55.00),
+			Type:            models.TransactionTypeCredit,
+			TransactionTime: time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC),
+			Currency:        "USD",
+		},
+	}
+
+	statements := []*models.BankStatement{
+		{
+			UniqueIdentifier: "BS001",
+			Amount:           decimal.NewFromFloat(100.00), // 110.00 USD
+			Date:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Currency:         "EUR",
+		},
+		{
+			UniqueIdentifier: "BS002",
+			Amount:           decimal.NewFromFloat(50.05), // 55.06 USD
+			Date:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			Currency:         "EUR",
+		},
+	}
+
+	return transactions, statements, rates
+}
+
+func TestMatchingEngine_Reconcile_ConvertsToBaseCurrency(t *testing.T) {
+	transactions, statements, rates := createMultiCurrencyData(t)
+
+	config := DefaultMatchingConfig()
+	config.AmountTolerancePercent = 1.0
+	config.BaseCurrency = "USD"
+	config.FXRates = rates
+
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if len(result.Matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(result.Matches))
+	}
+
+	expected := map[string]struct {
+		statement  string
+		converted  string
+		difference string
+	}{
+		"TX001": {"BS001", "110", "0"},
+		"TX002": {"BS002", "55.06", "0.06"},
+	}
+
+	for _, match := range result.Matches {
+		want := expected[match.Transaction.TrxID]
+		if match.BankStatement.UniqueIdentifier != want.statement {
+			t.Errorf("Expected %s to match %s, got %s", match.Transaction.TrxID, want.statement, match.BankStatement.UniqueIdentifier)
+		}
+		if match.FX == nil {
+			t.Fatalf("Expected FX details for %s", match.Transaction.TrxID)
+		}
+		if match.FX.BaseCurrency != "USD" || match.FX.Transaction != nil || match.FX.Statement == nil {
+			t.Fatalf("Expected only the statement to be converted to USD, got %+v", match.FX)
+		}
+		if !match.FX.Statement.ConvertedAmount.Equal(decimal.RequireFromString(want.converted)) {
+			t.Errorf("Expected converted amount %s, got %s", want.converted, match.FX.Statement.ConvertedAmount)
+		}
+		if !match.FX.Difference.Equal(decimal.RequireFromString(want.difference)) {
+			t.Errorf("Expected FX difference %s, got %s", want.difference, match.FX.Difference)
+		}
+
+		found := false
+		for _, reason := range match.Reasons {
+			if strings.HasPrefix(reason, "Converted EUR") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected a conversion reason for %s, got %v", match.Transaction.TrxID, match.Reasons)
+		}
+	}
+}
+
+func TestMatchingEngine_Reconcile_CurrencyMismatchWithoutRates(t *testing.T) {
+	transactions, statements, _ := createMultiCurrencyData(t)
+
+	tests := []struct {
+		name         string
+		baseCurrency string
+	}{
+		{"conversion disabled", ""},
+		{"no rate table", "USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			config.AmountTolerancePercent = 100.0
+			config.BaseCurrency = tt.baseCurrency
+
+			engine := NewMatchingEngine(config)
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+
+			// Amounts in different currencies must never be compared directly
+			for _, match := range result.Matches {
+				t.Errorf("Unexpected match %s <-> %s across currencies",
+					match.Transaction.TrxID, match.BankStatement.UniqueIdentifier)
+			}
+		})
+	}
+}
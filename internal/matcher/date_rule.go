@@ -0,0 +1,298 @@
+package matcher
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"golang-reconciliation-service/internal/calendar"
+	"golang-reconciliation-service/internal/models"
+)
+
+// leadScoreFactor scales the date score of a statement dated before its
+// transaction. Banks post late far more often than early, so a lead is the
+// weaker evidence of the two at the same distance.
+const leadScoreFactor = 0.5
+
+// DateWindow bounds how many days a bank statement may be dated after its
+// transaction (a lag, as when the bank posts a payment a day or two late)
+// and before it (a lead)
+type DateWindow struct {
+	LagDays  int `json:"lag_days"`
+	LeadDays int `json:"lead_days"`
+}
+
+// DateWindowRule applies a date window to the pairs of one bank, one
+// transaction type, or both. An empty Bank or Type matches any; banks are
+// compared case-insensitively.
+type DateWindowRule struct {
+	Bank string                 `json:"bank,omitempty"`
+	Type models.TransactionType `json:"type,omitempty"`
+	DateWindow
+}
+
+// String returns the window in the form used by the --date-window flag
+func (w DateWindow) String() string {
+	return fmt.Sprintf("%d:%d", w.LagDays, w.LeadDays)
+}
+
+func (w DateWindow) validate() error {
+	if w.LagDays < 0 || w.LeadDays < 0 {
+		return fmt.Errorf("date window days cannot be negative: lag %d, lead %d", w.LagDays, w.LeadDays)
+	}
+	return nil
+}
+
+func (r DateWindowRule) validate() error {
+	if r.Bank == "" && r.Type == "" {
+		return fmt.Errorf("date window rule must name a bank or a transaction type")
+	}
+	if r.Type != "" && !r.Type.IsValid() {
+		return fmt.Errorf("invalid transaction type in date window rule: %s", r.Type)
+	}
+	return r.DateWindow.validate()
+}
+
+// matches reports whether the rule applies to the pairs of a bank and a
+// transaction type, and how specifically: a rule naming both outranks one
+// naming the bank, which outranks one naming the type
+func (r DateWindowRule) matches(bank string, txType models.TransactionType) (int, bool) {
+	specificity := 0
+	if r.Bank != "" {
+		if !strings.EqualFold(r.Bank, bank) {
+			return 0, false
+		}
+		specificity += 2
+	}
+	if r.Type != "" {
+		if r.Type != txType {
+			return 0, false
+		}
+		specificity++
+	}
+	return specificity, true
+}
+
+// IsPairWithinDateTolerance checks if the dates of a transaction and a bank
+// statement are within the configured tolerance, measured on the calendar of
+// the statement's bank and in the date window of the pair
+func (mc *MatchingConfig) IsPairWithinDateTolerance(tx *models.Transaction, stmt *models.BankStatement) bool {
+	return mc.dateRuleFor(tx, stmt).within(mc.NormalizeTime(tx.TransactionTime), mc.NormalizeTime(stmt.Date))
+}
+
+// CalendarFor returns the business-day calendar the dates of a bank's
+// statements are measured on, or nil when every day counts
+func (mc *MatchingConfig) CalendarFor(bank string) *calendar.Calendar {
+	if bank != "" {
+		for name, cal := range mc.BankCalendars {
+			if strings.EqualFold(name, bank) {
+				return cal
+			}
+		}
+	}
+	if mc.Calendar != nil {
+		return mc.Calendar
+	}
+	if mc.IgnoreWeekends {
+		return calendar.Weekends
+	}
+	return nil
+}
+
+// DateWindowFor returns the date window of the pairs of a bank's statements
+// with transactions of a type: the most specific matching rule of
+// DateWindows, the first among equals, or else DateWindow. It returns nil
+// when the symmetric DateToleranceDays applies.
+func (mc *MatchingConfig) DateWindowFor(bank string, txType models.TransactionType) *DateWindow {
+	var window *DateWindow
+	best := -1
+	for i := range mc.DateWindows {
+		if specificity, ok := mc.DateWindows[i].matches(bank, txType); ok && specificity > best {
+			window, best = &mc.DateWindows[i].DateWindow, specificity
+		}
+	}
+	if window != nil {
+		return window
+	}
+	return mc.DateWindow
+}
+
+// usesDateWindows reports whether any pair is compared in a date window
+func (mc *MatchingConfig) usesDateWindows() bool {
+	return mc.DateWindow != nil || len(mc.DateWindows) > 0
+}
+
+// dateRule is how the dates of a pair are compared: at most days apart, or
+// within window when it is set, counting only the business days of calendar
+// when it is set
+type dateRule struct {
+	days     int
+	window   *DateWindow
+	calendar *calendar.Calendar
+}
+
+// dateRuleFor returns the date rule for a pair. A nil transaction or
+// statement matches no bank or type in particular, so nil for both gives the
+// default rule.
+func (mc *MatchingConfig) dateRuleFor(tx *models.Transaction, stmt *models.BankStatement) dateRule {
+	bank := ""
+	if stmt != nil {
+		bank = stmt.BankName
+	}
+	var txType models.TransactionType
+	if tx != nil {
+		txType = tx.Type
+	}
+	return mc.dateRuleOf(bank, txType)
+}
+
+func (mc *MatchingConfig) dateRuleOf(bank string, txType models.TransactionType) dateRule {
+	return dateRule{
+		days:     mc.DateToleranceDays,
+		window:   mc.DateWindowFor(bank, txType),
+		calendar: mc.CalendarFor(bank),
+	}
+}
+
+// filters reports whether candidates are filtered by the rule. A zero
+// tolerance leaves candidates unfiltered and only costs them their date
+// score, while a window always bounds them.
+func (r dateRule) filters() bool {
+	return r.window != nil || r.days > 0
+}
+
+// within checks if the dates of a transaction and a statement, in that
+// order, are within the rule. A zero tolerance requires the same date.
+func (r dateRule) within(txDate, stmtDate time.Time) bool {
+	if r.window != nil {
+		offset := r.offset(txDate, stmtDate)
+		if offset >= 0 {
+			return offset <= r.window.LagDays
+		}
+		return -offset <= r.window.LeadDays
+	}
+	if r.days == 0 {
+		return txDate.Format("2006-01-02") == stmtDate.Format("2006-01-02")
+	}
+	if r.calendar != nil {
+		return r.calendar.BusinessDaysBetween(txDate, stmtDate) <= r.days
+	}
+
+	diff := txDate.Sub(stmtDate)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= time.Duration(r.days)*24*time.Hour
+}
+
+// score decays linearly from 1.0 for the same date to 0.0 at the edge of the
+// tolerance, in business days when the rule has a calendar. In a window each
+// side decays over its own width, and a lead scores leadScoreFactor of a lag.
+func (r dateRule) score(txDate, stmtDate time.Time) float64 {
+	if !r.within(txDate, stmtDate) {
+		return 0.0
+	}
+	if r.window != nil {
+		offset := r.offset(txDate, stmtDate)
+		switch {
+		case offset > 0:
+			return math.Max(0.0, 1.0-float64(offset)/float64(r.window.LagDays))
+		case offset < 0:
+			return leadScoreFactor * math.Max(0.0, 1.0-float64(-offset)/float64(r.window.LeadDays))
+		default:
+			return 1.0
+		}
+	}
+	if r.days == 0 {
+		return 1.0
+	}
+	if r.calendar != nil {
+		return math.Max(0.0, 1.0-float64(r.calendar.BusinessDaysBetween(txDate, stmtDate))/float64(r.days))
+	}
+
+	diff := txDate.Sub(stmtDate)
+	if diff < 0 {
+		diff = -diff
+	}
+	maxDiff := time.Duration(r.days) * 24 * time.Hour
+	return math.Max(0.0, 1.0-float64(diff)/float64(maxDiff))
+}
+
+// offset returns how many days the statement is dated after the transaction,
+// negative when it is dated before. Dates are compared by calendar date, in
+// business days when the rule has a calendar.
+func (r dateRule) offset(txDate, stmtDate time.Time) int {
+	txDay, stmtDay := dateOnly(txDate), dateOnly(stmtDate)
+	days := int(stmtDay.Sub(txDay).Hours() / 24)
+	if r.calendar == nil {
+		return days
+	}
+
+	businessDays := r.calendar.BusinessDaysBetween(txDay, stmtDay)
+	if days < 0 {
+		return -businessDays
+	}
+	return businessDays
+}
+
+// note describes the distance of two dates for score breakdowns: in business
+// days, and in which direction when the rule has a window. It is empty when
+// there is nothing to add to the date difference.
+func (r dateRule) note(txDate, stmtDate time.Time) string {
+	if r.window == nil {
+		if r.calendar == nil {
+			return ""
+		}
+		return r.describeDays(r.calendar.BusinessDaysBetween(txDate, stmtDate))
+	}
+
+	offset := r.offset(txDate, stmtDate)
+	switch {
+	case offset > 0:
+		return fmt.Sprintf("%s after the transaction", r.describeDays(offset))
+	case offset < 0:
+		return fmt.Sprintf("%s before the transaction, scored as a lead", r.describeDays(-offset))
+	case r.calendar != nil:
+		return r.describeDays(0)
+	default:
+		return ""
+	}
+}
+
+func (r dateRule) describeDays(days int) string {
+	unit := "day"
+	if r.calendar != nil {
+		unit = "business day"
+	}
+	if days != 1 {
+		unit += "s"
+	}
+	if r.calendar != nil {
+		return fmt.Sprintf("%d %s on %s", days, unit, r.calendar.Name)
+	}
+	return fmt.Sprintf("%d %s", days, unit)
+}
+
+// calendarDays returns the largest number of calendar days two dates within
+// the rule can be apart, in either direction
+func (r dateRule) calendarDays() int {
+	if r.window != nil {
+		days := max(r.window.LagDays, r.window.LeadDays)
+		if r.calendar == nil {
+			return days
+		}
+		return r.calendar.MaxSpan(days)
+	}
+	if r.calendar == nil || r.days == 0 {
+		return r.days
+	}
+	return r.calendar.MaxSpan(r.days)
+}
+
+// dateOnly returns the calendar date of t, in its own location, as midnight
+// UTC
+func dateOnly(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,297 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/calendar"
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// idulFitri2024 holds Idul Fitri 2024 and its cuti bersama: Wednesday 10 to
+// Tuesday 16 April, around the weekend of the 13th and 14th
+func idulFitri2024() *calendar.Calendar {
+	c := calendar.New("id")
+	for _, day := range []int{10, 11, 12, 15, 16} {
+		c.AddHoliday(time.Date(2024, 4, day, 0, 0, 0, 0, time.UTC), "Idul Fitri")
+	}
+	return c
+}
+
+func TestMatchingConfig_IsPairWithinDateTolerance(t *testing.T) {
+	tx := &models.Transaction{TrxID: "TX001", TransactionTime: time.Date(2024, 4, 9, 10, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name      string
+		configure func(config *MatchingConfig)
+		bank      string
+		stmtDate  time.Time
+		want      bool
+	}{
+		{"calendar days", func(config *MatchingConfig) {}, "BCA", time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC), false},
+		{"bank calendar", func(config *MatchingConfig) {
+			config.BankCalendars = map[string]*calendar.Calendar{"BCA": idulFitri2024()}
+		}, "bca", time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC), true},
+		{"other bank", func(config *MatchingConfig) {
+			config.BankCalendars = map[string]*calendar.Calendar{"BCA": idulFitri2024()}
+			config.IgnoreWeekends = true
+		}, "Mandiri", time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC), false},
+		{"default calendar", func(config *MatchingConfig) {
+			config.Calendar = idulFitri2024()
+		}, "Mandiri", time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC), true},
+		{"weekends", func(config *MatchingConfig) {
+			config.IgnoreWeekends = true
+		}, "", time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			tt.configure(config)
+
+			stmt := &models.BankStatement{UniqueIdentifier: "BS001", Date: tt.stmtDate, BankName: tt.bank}
+			if got := config.IsPairWithinDateTolerance(tx, stmt); got != tt.want {
+				t.Errorf("IsPairWithinDateTolerance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Friday and the following Monday are one business day apart
+	config := DefaultMatchingConfig()
+	config.IgnoreWeekends = true
+	friday := &models.Transaction{TrxID: "TX002", TransactionTime: time.Date(2024, 4, 19, 10, 0, 0, 0, time.UTC)}
+	monday := &models.BankStatement{UniqueIdentifier: "BS002", Date: time.Date(2024, 4, 22, 0, 0, 0, 0, time.UTC)}
+	if !config.IsPairWithinDateTolerance(friday, monday) {
+		t.Error("Expected Friday and Monday to be within one business day")
+	}
+}
+
+func TestMatchingEngine_BusinessDayDateScore(t *testing.T) {
+	friday := time.Date(2024, 4, 19, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 4, 22, 0, 0, 0, 0, time.UTC)
+
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 2
+	engine := NewMatchingEngine(config)
+	if score := engine.calculateDateScore(friday, monday); score != 0.0 {
+		t.Errorf("Expected 0.0 for three calendar days, got %f", score)
+	}
+
+	config.IgnoreWeekends = true
+	if score := engine.calculateDateScore(friday, monday); score != 0.5 {
+		t.Errorf("Expected 0.5 for one of two business days, got %f", score)
+	}
+}
+
+func TestMatchingEngine_Reconcile_BankCalendar(t *testing.T) {
+	transactions := []*models.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: time.Date(2024, 4, 9, 10, 0, 0, 0, time.UTC)},
+		{TrxID: "TX002", Amount: decimal.NewFromInt(200), Type: models.TransactionTypeCredit, TransactionTime: time.Date(2024, 4, 9, 10, 0, 0, 0, time.UTC)},
+	}
+	// Both settle after the holidays; only BCA observes them
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC), BankName: "BCA"},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromInt(200), Date: time.Date(2024, 4, 17, 0, 0, 0, 0, time.UTC), BankName: "Chase"},
+	}
+
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 2
+	config.BankCalendars = map[string]*calendar.Calendar{"BCA": idulFitri2024()}
+	engine := NewMatchingEngine(config)
+	engine.LoadTransactions(transactions)
+	engine.LoadBankStatements(statements)
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if len(result.Matches) != 1 || result.Matches[0].Transaction.TrxID != "TX001" {
+		t.Fatalf("Expected only TX001 to match, got %d matches", len(result.Matches))
+	}
+
+	match := result.Matches[0]
+	var dateComponent *ScoreComponent
+	for i := range match.Breakdown.Components {
+		if match.Breakdown.Components[i].Name == ComponentDate {
+			dateComponent = &match.Breakdown.Components[i]
+		}
+	}
+	if dateComponent == nil || dateComponent.Score != 0.5 || !strings.Contains(dateComponent.Note, "1 business day on id") {
+		t.Errorf("Expected a date score of 0.5 for one business day, got %+v", dateComponent)
+	}
+}
+
+func TestMatchingConfig_MatchingWindowDays_BankCalendar(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 2
+	config.BankCalendars = map[string]*calendar.Calendar{"BCA": idulFitri2024()}
+
+	// Saturday 6 April to Wednesday 17 April, plus a day for local calendars
+	days, bounded := config.MatchingWindowDays()
+	if days != 12 || !bounded {
+		t.Errorf("Expected (12, true), got (%d, %v)", days, bounded)
+	}
+}
+
+func TestMatchingConfig_DateWindowFor(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.DateWindow = &DateWindow{LagDays: 3}
+	config.DateWindows = []DateWindowRule{
+		{Type: models.TransactionTypeDebit, DateWindow: DateWindow{LagDays: 2, LeadDays: 1}},
+		{Bank: "Chase", Type: models.TransactionTypeDebit, DateWindow: DateWindow{LagDays: 1, LeadDays: 1}},
+		{Bank: "Chase", DateWindow: DateWindow{LagDays: 4}},
+	}
+
+	tests := []struct {
+		bank   string
+		txType models.TransactionType
+		want   DateWindow
+	}{
+		{"BCA", models.TransactionTypeCredit, DateWindow{LagDays: 3}},
+		{"BCA", models.TransactionTypeDebit, DateWindow{LagDays: 2, LeadDays: 1}},
+		{"chase", models.TransactionTypeCredit, DateWindow{LagDays: 4}},
+		{"chase", models.TransactionTypeDebit, DateWindow{LagDays: 1, LeadDays: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.bank+" "+string(tt.txType), func(t *testing.T) {
+			if got := config.DateWindowFor(tt.bank, tt.txType); got == nil || *got != tt.want {
+				t.Errorf("DateWindowFor(%s, %s) = %v, want %v", tt.bank, tt.txType, got, tt.want)
+			}
+		})
+	}
+
+	config.DateWindow = nil
+	if got := config.DateWindowFor("BCA", models.TransactionTypeCredit); got != nil {
+		t.Errorf("Expected the date tolerance to apply without a matching rule, got %v", got)
+	}
+}
+
+func TestMatchingConfig_IsPairWithinDateTolerance_DateWindow(t *testing.T) {
+	// Monday 1 April 2024
+	txTime := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		configure func(config *MatchingConfig)
+		txType    models.TransactionType
+		bank      string
+		stmtDate  time.Time
+		want      bool
+	}{
+		{"lag within window", func(config *MatchingConfig) {
+			config.DateWindow = &DateWindow{LagDays: 3}
+		}, models.TransactionTypeCredit, "BCA", time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), true},
+		{"lag beyond window", func(config *MatchingConfig) {
+			config.DateWindow = &DateWindow{LagDays: 3}
+		}, models.TransactionTypeCredit, "BCA", time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC), false},
+		{"lead without lead days", func(config *MatchingConfig) {
+			config.DateWindow = &DateWindow{LagDays: 3}
+		}, models.TransactionTypeCredit, "BCA", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), false},
+		{"lead allowed for debits", func(config *MatchingConfig) {
+			config.DateWindow = &DateWindow{LagDays: 3}
+			config.DateWindows = []DateWindowRule{{Type: models.TransactionTypeDebit, DateWindow: DateWindow{LagDays: 3, LeadDays: 1}}}
+		}, models.TransactionTypeDebit, "BCA", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), true},
+		{"bank rule without default window", func(config *MatchingConfig) {
+			config.DateToleranceDays = 1
+			config.DateWindows = []DateWindowRule{{Bank: "BCA", DateWindow: DateWindow{LagDays: 3}}}
+		}, models.TransactionTypeCredit, "Chase", time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), false},
+		// Friday 29 March is Good Friday on the calendar below
+		{"lead in business days", func(config *MatchingConfig) {
+			config.DateWindow = &DateWindow{LeadDays: 1}
+			config.Calendar = calendar.New("us", calendar.Holiday{Date: time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)})
+		}, models.TransactionTypeCredit, "BCA", time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			tt.configure(config)
+
+			tx := &models.Transaction{TrxID: "TX001", Type: tt.txType, TransactionTime: txTime}
+			stmt := &models.BankStatement{UniqueIdentifier: "BS001", Date: tt.stmtDate, BankName: tt.bank}
+			if got := config.IsPairWithinDateTolerance(tx, stmt); got != tt.want {
+				t.Errorf("IsPairWithinDateTolerance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_DateWindowScore(t *testing.T) {
+	txTime := time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)
+
+	config := DefaultMatchingConfig()
+	config.DateWindow = &DateWindow{LagDays: 2, LeadDays: 2}
+	engine := NewMatchingEngine(config)
+
+	tests := []struct {
+		name     string
+		stmtDate time.Time
+		want     float64
+	}{
+		{"same date", time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), 1.0},
+		{"one day late", time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), 0.5},
+		{"one day early", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), 0.25},
+		{"outside window", time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC), 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := engine.calculateDateScore(txTime, tt.stmtDate); got != tt.want {
+				t.Errorf("calculateDateScore() = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBankStatementIndex_GetCandidates_DateWindow(t *testing.T) {
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "EARLY", Amount: decimal.NewFromInt(100), Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{UniqueIdentifier: "LATE", Amount: decimal.NewFromInt(100), Date: time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	tx := &models.Transaction{TrxID: "TX001", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC)}
+
+	// No date tolerance: only the window bounds the candidates
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 0
+	config.DateWindow = &DateWindow{LagDays: 3}
+
+	candidates := NewBankStatementIndex(statements).GetCandidates(tx, config)
+	if len(candidates) != 1 || candidates[0].UniqueIdentifier != "LATE" {
+		t.Errorf("Expected only the late statement as a candidate, got %v", candidates)
+	}
+	if days, bounded := config.MatchingWindowDays(); days != 4 || !bounded {
+		t.Errorf("Expected (4, true), got (%d, %v)", days, bounded)
+	}
+}
+
+func TestMatchingConfig_Validate_DateWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(config *MatchingConfig)
+		wantErr   string
+	}{
+		{"negative lag", func(config *MatchingConfig) {
+			config.DateWindow = &DateWindow{LagDays: -1}
+		}, "cannot be negative"},
+		{"rule without scope", func(config *MatchingConfig) {
+			config.DateWindows = []DateWindowRule{{DateWindow: DateWindow{LagDays: 1}}}
+		}, "must name a bank or a transaction type"},
+		{"invalid type", func(config *MatchingConfig) {
+			config.DateWindows = []DateWindowRule{{Type: "TRANSFER", DateWindow: DateWindow{LagDays: 1}}}
+		}, "invalid transaction type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			tt.configure(config)
+			if err := config.Validate(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
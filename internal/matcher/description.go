@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"strings"
+	"unicode"
+
+	"golang-reconciliation-service/internal/models"
+)
+
+// Description similarity thresholds used to explain a score
+const (
+	descriptionSimilarThreshold = 0.6
+	descriptionPartlyThreshold  = 0.3
+)
+
+// trigramSet is the set of character trigrams of a text
+type trigramSet map[string]struct{}
+
+// descriptionTrigrams splits a description into words and returns the
+// trigrams of each word, padded so that word boundaries count. Words without
+// a letter, such as amounts, dates and most reference numbers, are left out:
+// they are scored by the amount, date and identifier criteria already and
+// would only add noise here.
+func descriptionTrigrams(description string) trigramSet {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	trigrams := make(trigramSet)
+	for _, word := range words {
+		if strings.IndexFunc(word, unicode.IsLetter) == -1 {
+			continue
+		}
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return trigrams
+}
+
+// jaccard returns the size of the intersection of two sets over the size of
+// their union
+func (a trigramSet) jaccard(b trigramSet) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0.0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	shared := 0
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// calculateDescriptionScore scores how similar the transaction and statement
+// descriptions are by the Jaccard similarity of their word trigrams, so that a
+// counterparty named on both sides counts even when the bank abbreviates or
+// reorders the narration. It reports false when either side has no
+// description to compare.
+func calculateDescriptionScore(tx *models.Transaction, stmt *models.BankStatement) (float64, bool) {
+	txTrigrams := descriptionTrigrams(tx.Description)
+	stmtTrigrams := descriptionTrigrams(stmt.Description)
+	if len(txTrigrams) == 0 || len(stmtTrigrams) == 0 {
+		return 0.0, false
+	}
+	return txTrigrams.jaccard(stmtTrigrams), true
+}
+
+// applyDescriptionScore adds the weighted description score to the score of
+// the amount, date and type criteria. When either side has no description the
+// pair is not penalised: the other criteria are scaled to the same total
+// weight instead.
+func (me *MatchingEngine) applyDescriptionScore(score, descriptionScore float64, compared bool) float64 {
+	weights := me.Config.Weights
+	if compared {
+		return score + descriptionScore*weights.DescriptionWeight
+	}
+
+	core := weights.AmountWeight + weights.DateWeight + weights.TypeWeight
+	if core <= 0 {
+		return score
+	}
+	return score / core * (core + weights.DescriptionWeight)
+}
+
+func describeDescriptionMatch(score float64) string {
+	switch {
+	case score >= descriptionSimilarThreshold:
+		return "Similar description"
+	case score >= descriptionPartlyThreshold:
+		return "Description partly similar"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,120 @@
+package matcher
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCalculateDescriptionScore(t *testing.T) {
+	tests := []struct {
+		name         string
+		txDesc       string
+		stmtDesc     string
+		wantCompared bool
+		wantMin      float64
+		wantMax      float64
+	}{
+		{"identical", "ACME Corp", "acme corp", true, 1.0, 1.0},
+		{"reordered with punctuation", "Invoice ACME Corp", "CORP/ACME-INVOICE", true, 1.0, 1.0},
+		{"abbreviated counterparty", "Payment to Globex Corporation", "TRF GLOBEX CORP", true, 0.3, 0.7},
+		{"unrelated", "ACME Corp", "Initech salary", true, 0.0, 0.1},
+		{"numbers are ignored", "2024-01-15 100.00", "2024-01-15 100.00", false, 0.0, 0.0},
+		{"missing on one side", "ACME Corp", "", false, 0.0, 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &models.Transaction{TrxID: "TX1", Description: tt.txDesc}
+			stmt := &models.BankStatement{UniqueIdentifier: "BS1", Description: tt.stmtDesc}
+
+			score, compared := calculateDescriptionScore(tx, stmt)
+			if compared != tt.wantCompared {
+				t.Fatalf("Expected compared=%v, got %v", tt.wantCompared, compared)
+			}
+			if score < tt.wantMin-1e-9 || score > tt.wantMax+1e-9 {
+				t.Errorf("Expected score in [%.2f, %.2f], got %.3f", tt.wantMin, tt.wantMax, score)
+			}
+		})
+	}
+}
+
+func TestMatchingWeights_WithDescriptionWeight(t *testing.T) {
+	weights := DefaultMatchingConfig().Weights.WithIdentifierWeight(0.2).WithDescriptionWeight(0.2)
+	if err := weights.Validate(); err != nil {
+		t.Fatalf("Scaled weights are invalid: %v", err)
+	}
+	if math.Abs(weights.IdentifierWeight-0.2) > 1e-9 || math.Abs(weights.DescriptionWeight-0.2) > 1e-9 {
+		t.Errorf("Expected both optional weights to be kept, got %+v", weights)
+	}
+	if total := weights.AmountWeight + weights.DateWeight + weights.TypeWeight; math.Abs(total-0.6) > 1e-9 {
+		t.Errorf("Expected amount, date and type weights to add up to 0.6, got %.3f", total)
+	}
+}
+
+func TestMatchingEngine_Reconcile_DescriptionBreaksTie(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transactions := []*models.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(250.00), Type: models.TransactionTypeCredit, TransactionTime: day, Description: "Invoice 1001 Globex Corporation"},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(250.00), Type: models.TransactionTypeCredit, TransactionTime: day, Description: "Invoice 1002 Initech"},
+	}
+	// Both statements agree on amount and date with both transactions; only
+	// the counterparty named in the narration tells them apart
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromFloat(250.00), Date: day, Description: "TRF INITECH LTD"},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromFloat(250.00), Date: day, Description: "TRF GLOBEX CORP"},
+	}
+
+	for _, mode := range []AssignmentMode{AssignmentGreedy, AssignmentOptimal} {
+		t.Run(mode.String(), func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			config.AssignmentMode = mode
+			config.Weights = config.Weights.WithDescriptionWeight(0.2)
+
+			engine := NewMatchingEngine(config)
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+
+			expected := map[string]string{"TX001": "BS002", "TX002": "BS001"}
+			if len(result.Matches) != len(expected) {
+				t.Fatalf("Expected %d matches, got %d", len(expected), len(result.Matches))
+			}
+			for _, match := range result.Matches {
+				if want := expected[match.Transaction.TrxID]; match.BankStatement.UniqueIdentifier != want {
+					t.Errorf("Expected %s to match %s, got %s", match.Transaction.TrxID, want, match.BankStatement.UniqueIdentifier)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_ScoreMatch_MissingDescription(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tx := &models.Transaction{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: models.TransactionTypeCredit, TransactionTime: day}
+	stmt := &models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromFloat(100.00), Date: day, Description: "ACME Corp"}
+
+	config := DefaultMatchingConfig()
+	config.Weights = config.Weights.WithDescriptionWeight(0.2)
+	engine := NewMatchingEngine(config)
+
+	result, err := engine.scoreMatch(tx, stmt)
+	if err != nil {
+		t.Fatalf("scoreMatch failed: %v", err)
+	}
+	if result.MatchType != MatchExact || math.Abs(result.ConfidenceScore-1.0) > 1e-9 {
+		t.Errorf("Expected a missing description not to lower an exact match, got %s at %.3f", result.MatchType, result.ConfidenceScore)
+	}
+}
@@ -393,7 +393,9 @@ func (ech *EdgeCaseHandler) generateDuplicateReason(transactions []*models.Trans
 		len(transactions), transactions[0].Amount.String(), transactions[0].Type.String())
 }
 
-// calculateAmbiguityScore calculates how ambiguous same-day matching is
+// calculateAmbiguityScore calculates how ambiguous same-day matching is: the
+// share of potential matches competing with another for their transaction or
+// statement. 0 means every match is clear, 1 that every match is contested.
 func (ech *EdgeCaseHandler) calculateAmbiguityScore(
 	transactions []*models.Transaction,
 	statements []*models.BankStatement,
@@ -404,23 +406,27 @@ func (ech *EdgeCaseHandler) calculateAmbiguityScore(
 		return 0.0 // No ambiguity
 	}
 	
-	// Higher score = more ambiguous
-	txCount := float64(len(transactions))
-	stmtCount := float64(len(statements))
-	matchCount := float64(len(matches))
-	
-	// Calculate potential combinations
-	maxCombinations := txCount * stmtCount
-	actualMatches := matchCount
-	
-	if maxCombinations == 0 {
+	if len(matches) == 0 {
 		return 0.0
 	}
 	
-	// Ambiguity increases with more potential combinations and fewer clear matches
-	ambiguityRatio := (maxCombinations - actualMatches) / maxCombinations
+	// Count the potential matches each item takes part in
+	txMatches := make(map[string]int)
+	stmtMatches := make(map[string]int)
+	for _, match := range matches {
+		txMatches[match.Transaction.TrxID]++
+		stmtMatches[match.BankStatement.UniqueIdentifier]++
+	}
+	
+	// A match is contested when either side has another candidate
+	contested := 0
+	for _, match := range matches {
+		if txMatches[match.Transaction.TrxID] > 1 || stmtMatches[match.BankStatement.UniqueIdentifier] > 1 {
+			contested++
+		}
+	}
 	
-	return ambiguityRatio
+	return float64(contested) / float64(len(matches))
 }
 
 // determineResolutionStrategy determines the best strategy for resolving same-day ambiguity
@@ -0,0 +1,289 @@
+package matcher
+
+import (
+	"fmt"
+	"sort"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Names of the score components
+const (
+	ComponentAmount      = "amount"
+	ComponentDate        = "date"
+	ComponentType        = "type"
+	ComponentDescription = "description"
+	ComponentIdentifier  = "identifier"
+)
+
+// ScoreBreakdown records how the confidence score of a pair was put together
+// and which other candidates the match was chosen over.
+type ScoreBreakdown struct {
+	Components []ScoreComponent `json:"components"`
+
+	// Adjustment is the part of the confidence score not explained by the
+	// weighted components. It comes from the weight of an uncompared
+	// description being spread over the other components, and from a
+	// reference ID match lifting the score of an otherwise weaker pair.
+	Adjustment float64 `json:"adjustment"`
+
+	AmountTolerance    decimal.Decimal `json:"amount_tolerance"` // largest amount difference allowed, in the compared currency
+	DateToleranceDays  int             `json:"date_tolerance_days"`
+	DateWindow         *DateWindow     `json:"date_window,omitempty"` // replaces the date tolerance when set
+	MinConfidenceScore float64         `json:"min_confidence_score"`
+
+	// Rejected lists the other candidates considered for the match, best
+	// first. It is filled in once the matches are final.
+	Rejected []*RejectedCandidate `json:"rejected,omitempty"`
+}
+
+// ScoreComponent is one weighted criterion of a match score
+type ScoreComponent struct {
+	Name         string  `json:"name"`
+	Score        float64 `json:"score"` // raw score between 0.0 and 1.0
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"` // Score * Weight
+	Note         string  `json:"note,omitempty"`
+}
+
+// RejectionReason explains why a candidate was not chosen
+type RejectionReason string
+
+const (
+	RejectionBelowMinConfidence RejectionReason = "below_min_confidence" // scored below MinConfidenceScore
+	RejectionNotAccepted        RejectionReason = "not_accepted"         // refused by the filter of the matching pass
+	RejectionExcludedByRule     RejectionReason = "excluded_by_rule"     // the pair is excluded by a rule
+	RejectionIgnoredByRule      RejectionReason = "ignored_by_rule"      // the statement is ignored by a rule
+	RejectionMatchedElsewhere   RejectionReason = "matched_elsewhere"    // the candidate went to another item
+	RejectionMatchedInGroup     RejectionReason = "matched_in_group"     // the candidate went to a group match
+	RejectionOutscored          RejectionReason = "outscored"            // the chosen candidate scored at least as well
+
+	// rejectionTaken marks a statement claimed before scoring; it is
+	// resolved to a public reason once the matches are final
+	rejectionTaken RejectionReason = "taken"
+)
+
+// RejectedCandidate is a candidate a match was chosen over. Candidates of a
+// transaction are statements; the incremental reconciler also reports the
+// transactions a newly arrived statement was matched over.
+type RejectedCandidate struct {
+	Statement       *models.BankStatement `json:"statement,omitempty"`
+	Transaction     *models.Transaction   `json:"transaction,omitempty"`
+	ConfidenceScore float64               `json:"confidence_score"` // zero when the pair was not scored
+	Reason          RejectionReason       `json:"reason"`
+	MatchedTo       string                `json:"matched_to,omitempty"` // the item it went to, for RejectionMatchedElsewhere
+}
+
+// consideredStatement is a statement looked at for a transaction during
+// scoring. Each scored result of the transaction keeps the list until the
+// matches are final, so the match chosen can say what it was chosen over.
+type consideredStatement struct {
+	statement *models.BankStatement
+	score     float64
+	reason    RejectionReason // set when the pair was ruled out before selection
+}
+
+// newScoreBreakdown starts the breakdown of a pair with the tolerances it is
+// scored against
+func (me *MatchingEngine) newScoreBreakdown(tx *models.Transaction) *ScoreBreakdown {
+	return &ScoreBreakdown{
+		Components:         make([]ScoreComponent, 0, 5),
+		AmountTolerance:    me.Config.GetAmountTolerance(me.currency.transactionAmount(tx).Abs()),
+		DateToleranceDays:  me.Config.DateToleranceDays,
+		MinConfidenceScore: me.Config.MinConfidenceScore,
+	}
+}
+
+func (b *ScoreBreakdown) add(name string, score, weight float64, note string) {
+	b.Components = append(b.Components, ScoreComponent{
+		Name:         name,
+		Score:        score,
+		Weight:       weight,
+		Contribution: score * weight,
+		Note:         note,
+	})
+}
+
+// settle records the part of the confidence score the components do not
+// account for
+func (b *ScoreBreakdown) settle(confidenceScore float64) {
+	sum := 0.0
+	for _, component := range b.Components {
+		sum += component.Contribution
+	}
+	b.Adjustment = confidenceScore - sum
+}
+
+// Component returns the named component, or nil when it was not scored
+func (b *ScoreBreakdown) Component(name string) *ScoreComponent {
+	if b == nil {
+		return nil
+	}
+	for i := range b.Components {
+		if b.Components[i].Name == name {
+			return &b.Components[i]
+		}
+	}
+	return nil
+}
+
+// amountNote explains an amount score of zero that is not down to the
+// difference between the amounts
+func (me *MatchingEngine) amountNote(tx *models.Transaction, stmt *models.BankStatement) string {
+	if !me.currency.comparable(tx, stmt) {
+		return "different currencies"
+	}
+	return ""
+}
+
+func (me *MatchingEngine) typeNote() string {
+	if !me.Config.EnableTypeMatching {
+		return "type matching disabled"
+	}
+	return ""
+}
+
+// ruledOut returns why a statement may not be scored against a transaction,
+// or an empty reason when it may
+func (o *ruleOverrides) ruledOut(tx *models.Transaction, stmt *models.BankStatement) RejectionReason {
+	switch {
+	case o == nil:
+		return ""
+	case o.removedStatements[stmt]:
+		return rejectionTaken
+	case o.excludedPairs[pairKey(tx.TrxID, stmt.UniqueIdentifier)]:
+		return RejectionExcludedByRule
+	default:
+		return ""
+	}
+}
+
+// explainRejections fills in the rejected candidates of every match once the
+// matches are final. A candidate ruled out or scored too low keeps the reason
+// it was given while scoring; any other candidate either went to another
+// item or lost to the statement chosen.
+func (me *MatchingEngine) explainRejections(result *ReconciliationResult) {
+	matchedTo := make(map[*models.BankStatement]*models.Transaction, len(result.Matches))
+	for _, match := range result.Matches {
+		matchedTo[match.BankStatement] = match.Transaction
+	}
+	grouped := make(map[*models.BankStatement]bool)
+	for _, group := range result.GroupMatches {
+		for _, stmt := range group.Statements {
+			grouped[stmt] = true
+		}
+	}
+	ignored := make(map[*models.BankStatement]bool, len(result.IgnoredStatements))
+	for _, ignore := range result.IgnoredStatements {
+		ignored[ignore.Statement] = true
+	}
+
+	for _, match := range result.Matches {
+		considered := match.considered
+		match.considered = nil
+		if match.Breakdown == nil {
+			continue
+		}
+
+		var rejected []*RejectedCandidate
+		for _, candidate := range considered {
+			if candidate.statement == match.BankStatement {
+				continue
+			}
+			rejection := &RejectedCandidate{
+				Statement:       candidate.statement,
+				ConfidenceScore: candidate.score,
+				Reason:          candidate.reason,
+			}
+			if rejection.Reason == "" || rejection.Reason == rejectionTaken {
+				switch other := matchedTo[candidate.statement]; {
+				case other != nil:
+					rejection.Reason = RejectionMatchedElsewhere
+					rejection.MatchedTo = other.TrxID
+				case grouped[candidate.statement]:
+					rejection.Reason = RejectionMatchedInGroup
+				case ignored[candidate.statement]:
+					rejection.Reason = RejectionIgnoredByRule
+				case rejection.Reason == rejectionTaken:
+					rejection.Reason = RejectionMatchedElsewhere
+				default:
+					rejection.Reason = RejectionOutscored
+				}
+			}
+			rejected = append(rejected, rejection)
+		}
+		sortRejected(rejected)
+		match.Breakdown.Rejected = rejected
+	}
+}
+
+func sortRejected(rejected []*RejectedCandidate) {
+	sort.SliceStable(rejected, func(i, j int) bool {
+		return rejected[i].ConfidenceScore > rejected[j].ConfidenceScore
+	})
+}
+
+// PairExplanation is the outcome of scoring a single pair on its own
+type PairExplanation struct {
+	Match *MatchResult
+
+	// Candidate reports whether the statement is among the candidates looked
+	// up for the transaction, within the date window and candidate limit
+	Candidate bool
+
+	// Rejection is why the pair could not be matched, or empty when it could
+	// be, provided no better pair claims either side
+	Rejection RejectionReason
+
+	// Rule names the rule behind a manual match, exclusion or ignore
+	Rule string
+}
+
+// ExplainPair scores a transaction against a bank statement the way Reconcile
+// would and reports whether the pair could be matched. The loaded data is
+// needed for currency conversion and rule resolution. Competition from other
+// pairs is not considered; a full reconciliation records that in the
+// rejected candidates of each match.
+func (me *MatchingEngine) ExplainPair(tx *models.Transaction, stmt *models.BankStatement) (*PairExplanation, error) {
+	if me.TransactionIndex == nil || me.BankStatementIndex == nil {
+		return nil, fmt.Errorf("transactions and bank statements must be loaded before explaining a pair")
+	}
+
+	result, err := me.scoreMatch(tx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	explanation := &PairExplanation{Match: result}
+	for _, candidate := range me.BankStatementIndex.GetCandidates(tx, me.Config) {
+		if candidate == stmt {
+			explanation.Candidate = true
+			break
+		}
+	}
+
+	if set := me.Config.Rules; set != nil {
+		if rule := set.MatchRuleFor(tx.TrxID, stmt.UniqueIdentifier); rule != nil {
+			result.MatchType = MatchManual
+			result.Rule = rule.Name
+			explanation.Rule = rule.Name
+			return explanation, nil
+		}
+		if rule := set.ExclusionFor(tx.TrxID, stmt.UniqueIdentifier); rule != nil {
+			explanation.Rejection = RejectionExcludedByRule
+			explanation.Rule = rule.Name
+			return explanation, nil
+		}
+		if rule := set.IgnoreRuleFor(stmt); rule != nil {
+			explanation.Rejection = RejectionIgnoredByRule
+			explanation.Rule = rule.Name
+			return explanation, nil
+		}
+	}
+
+	if result.ConfidenceScore < me.Config.MinConfidenceScore {
+		explanation.Rejection = RejectionBelowMinConfidence
+	}
+	return explanation, nil
+}
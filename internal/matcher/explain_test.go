@@ -0,0 +1,285 @@
+package matcher
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/rules"
+
+	"github.com/shopspring/decimal"
+)
+
+func createExplainTestData(t *testing.T) (*MatchingConfig, []*models.Transaction, []*models.BankStatement) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	set, err := rules.ReadCSV(strings.NewReader(`action,trx_id,statement_id,id_prefix,name
+exclude,TX001,BS004,,wrong customer
+`))
+	if err != nil {
+		t.Fatalf("Failed to read rules: %v", err)
+	}
+
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 3
+	config.AmountTolerancePercent = 10
+	config.Rules = set
+
+	transactions := []*models.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day.AddDate(0, 0, 1)},
+	}
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: day},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromInt(100), Date: day.AddDate(0, 0, 1)},
+		{UniqueIdentifier: "BS003", Amount: decimal.NewFromInt(95), Date: day},
+		{UniqueIdentifier: "BS004", Amount: decimal.NewFromInt(100), Date: day.AddDate(0, 0, 2)},
+		{UniqueIdentifier: "BS005", Amount: decimal.NewFromInt(100), Date: day.AddDate(0, 0, 10)},
+	}
+	return config, transactions, statements
+}
+
+func TestMatchingEngine_ScoreBreakdown(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tx := &models.Transaction{TrxID: "TX001", Amount: decimal.NewFromInt(200), Type: models.TransactionTypeDebit,
+		TransactionTime: day, Description: "Payment to ACME"}
+
+	tests := []struct {
+		name           string
+		configure      func(*MatchingConfig)
+		stmt           *models.BankStatement
+		wantComponents []string
+		wantScores     map[string]float64
+		wantNote       map[string]string
+		wantAdjustment bool
+		wantTolerance  string
+	}{
+		{
+			name:           "core components",
+			configure:      func(c *MatchingConfig) { c.AmountTolerancePercent = 1 },
+			stmt:           &models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(-199), Date: day.AddDate(0, 0, 1)},
+			wantComponents: []string{ComponentAmount, ComponentDate, ComponentType},
+			wantScores:     map[string]float64{ComponentAmount: 0.5, ComponentDate: 0.0, ComponentType: 1.0},
+			wantTolerance:  "2",
+		},
+		{
+			name:           "type matching disabled",
+			configure:      func(c *MatchingConfig) { c.EnableTypeMatching = false },
+			stmt:           &models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(200), Date: day},
+			wantComponents: []string{ComponentAmount, ComponentDate, ComponentType},
+			wantScores:     map[string]float64{ComponentAmount: 1.0, ComponentDate: 1.0, ComponentType: 1.0},
+			wantNote:       map[string]string{ComponentType: "type matching disabled"},
+			wantTolerance:  "0",
+		},
+		{
+			name: "description not compared",
+			configure: func(c *MatchingConfig) {
+				c.Weights = c.Weights.WithDescriptionWeight(0.2)
+			},
+			stmt:           &models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(-200), Date: day},
+			wantComponents: []string{ComponentAmount, ComponentDate, ComponentType, ComponentDescription},
+			wantScores:     map[string]float64{ComponentDescription: 0.0},
+			wantNote:       map[string]string{ComponentDescription: "not compared, weight spread over the other components"},
+			wantAdjustment: true,
+			wantTolerance:  "0",
+		},
+		{
+			name: "identifier",
+			configure: func(c *MatchingConfig) {
+				c.Weights = c.Weights.WithIdentifierWeight(0.3)
+			},
+			stmt: &models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(-200), Date: day,
+				Description: "TX001"},
+			wantComponents: []string{ComponentAmount, ComponentDate, ComponentType, ComponentIdentifier},
+			wantScores:     map[string]float64{ComponentIdentifier: 1.0},
+			wantNote:       map[string]string{ComponentIdentifier: string(IdentifierExact)},
+			wantTolerance:  "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			tt.configure(config)
+			engine := NewMatchingEngine(config)
+
+			result, err := engine.scoreMatch(tx, tt.stmt)
+			if err != nil {
+				t.Fatalf("Failed to score match: %v", err)
+			}
+			breakdown := result.Breakdown
+			if breakdown == nil {
+				t.Fatal("Expected a score breakdown")
+			}
+
+			var names []string
+			sum := 0.0
+			for _, component := range breakdown.Components {
+				names = append(names, component.Name)
+				sum += component.Contribution
+				if math.Abs(component.Contribution-component.Score*component.Weight) > 1e-9 {
+					t.Errorf("Component %s contributes %f, want %f", component.Name, component.Contribution, component.Score*component.Weight)
+				}
+				if want, ok := tt.wantScores[component.Name]; ok && math.Abs(component.Score-want) > 1e-9 {
+					t.Errorf("Component %s scored %f, want %f", component.Name, component.Score, want)
+				}
+				if component.Note != tt.wantNote[component.Name] {
+					t.Errorf("Component %s note = %q, want %q", component.Name, component.Note, tt.wantNote[component.Name])
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantComponents, ",") {
+				t.Errorf("Components = %v, want %v", names, tt.wantComponents)
+			}
+			if math.Abs(sum+breakdown.Adjustment-result.ConfidenceScore) > 1e-9 {
+				t.Errorf("Components and adjustment add up to %f, confidence is %f", sum+breakdown.Adjustment, result.ConfidenceScore)
+			}
+			if (math.Abs(breakdown.Adjustment) > 1e-9) != tt.wantAdjustment {
+				t.Errorf("Adjustment = %f, want an adjustment: %v", breakdown.Adjustment, tt.wantAdjustment)
+			}
+			if breakdown.AmountTolerance.String() != tt.wantTolerance {
+				t.Errorf("Amount tolerance = %s, want %s", breakdown.AmountTolerance, tt.wantTolerance)
+			}
+			if breakdown.DateToleranceDays != config.DateToleranceDays || breakdown.MinConfidenceScore != config.MinConfidenceScore {
+				t.Errorf("Breakdown tolerances %d/%f do not match the config", breakdown.DateToleranceDays, breakdown.MinConfidenceScore)
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_Reconcile_RejectedCandidates(t *testing.T) {
+	// Both modes settle TX001=BS001 and TX002=BS002
+	want := map[string]map[string]RejectionReason{
+		"TX001": {
+			"BS002": RejectionMatchedElsewhere,
+			"BS003": RejectionBelowMinConfidence,
+			"BS004": RejectionExcludedByRule,
+		},
+		"TX002": {
+			"BS001": RejectionMatchedElsewhere,
+			"BS003": RejectionBelowMinConfidence,
+			"BS004": RejectionOutscored,
+		},
+	}
+
+	for _, mode := range []AssignmentMode{AssignmentGreedy, AssignmentOptimal} {
+		t.Run(mode.String(), func(t *testing.T) {
+			config, transactions, statements := createExplainTestData(t)
+			config.AssignmentMode = mode
+			engine := NewMatchingEngine(config)
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			if len(result.Matches) != 2 {
+				t.Fatalf("Expected 2 matches, got %d", len(result.Matches))
+			}
+
+			for _, match := range result.Matches {
+				if match.considered != nil {
+					t.Error("Expected the considered statements to be released")
+				}
+				got := make(map[string]RejectionReason)
+				previous := math.Inf(1)
+				for _, rejected := range match.Breakdown.Rejected {
+					got[rejected.Statement.UniqueIdentifier] = rejected.Reason
+					if rejected.ConfidenceScore > previous {
+						t.Errorf("%s: rejected candidates are not sorted best first", match.Transaction.TrxID)
+					}
+					previous = rejected.ConfidenceScore
+					if rejected.Reason == RejectionMatchedElsewhere && rejected.MatchedTo == "" {
+						t.Errorf("%s: expected %s to name the transaction it went to", match.Transaction.TrxID, rejected.Statement.UniqueIdentifier)
+					}
+				}
+				wantRejected := want[match.Transaction.TrxID]
+				if len(got) != len(wantRejected) {
+					t.Errorf("%s: rejected %v, want %v", match.Transaction.TrxID, got, wantRejected)
+				}
+				for id, reason := range wantRejected {
+					if got[id] != reason {
+						t.Errorf("%s: %s rejected as %q, want %q", match.Transaction.TrxID, id, got[id], reason)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_ExplainPair(t *testing.T) {
+	config, transactions, statements := createExplainTestData(t)
+	engine := NewMatchingEngine(config)
+	if _, err := engine.ExplainPair(transactions[0], statements[0]); err == nil {
+		t.Error("Expected an error before data is loaded")
+	}
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	tests := []struct {
+		stmt          int
+		wantCandidate bool
+		wantRejection RejectionReason
+		wantRule      string
+	}{
+		{stmt: 0, wantCandidate: true},
+		{stmt: 2, wantCandidate: true, wantRejection: RejectionBelowMinConfidence},
+		{stmt: 3, wantCandidate: true, wantRejection: RejectionExcludedByRule, wantRule: "wrong customer"},
+		{stmt: 4, wantCandidate: false, wantRejection: RejectionBelowMinConfidence},
+	}
+
+	for _, tt := range tests {
+		stmt := statements[tt.stmt]
+		t.Run(stmt.UniqueIdentifier, func(t *testing.T) {
+			explanation, err := engine.ExplainPair(transactions[0], stmt)
+			if err != nil {
+				t.Fatalf("ExplainPair failed: %v", err)
+			}
+			if explanation.Candidate != tt.wantCandidate || explanation.Rejection != tt.wantRejection || explanation.Rule != tt.wantRule {
+				t.Errorf("Explanation = candidate %v, rejection %q, rule %q; want %v, %q, %q",
+					explanation.Candidate, explanation.Rejection, explanation.Rule, tt.wantCandidate, tt.wantRejection, tt.wantRule)
+			}
+			if explanation.Match.Breakdown == nil || len(explanation.Match.Breakdown.Components) != 3 {
+				t.Errorf("Expected a breakdown with 3 components, got %+v", explanation.Match.Breakdown)
+			}
+		})
+	}
+}
+
+func TestIncrementalReconciler_RejectedCandidates(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	config := DefaultMatchingConfig()
+	config.AmountTolerancePercent = 10
+	ir, err := NewIncrementalReconciler(config)
+	if err != nil {
+		t.Fatalf("Failed to create incremental reconciler: %v", err)
+	}
+
+	for _, amount := range []int64{99, 100, 80} {
+		tx := &models.Transaction{TrxID: "TX" + decimal.NewFromInt(amount).String(), Amount: decimal.NewFromInt(amount),
+			Type: models.TransactionTypeCredit, TransactionTime: day}
+		if _, err := ir.AddTransaction(tx); err != nil {
+			t.Fatalf("Failed to add transaction: %v", err)
+		}
+	}
+	match, err := ir.AddStatement(&models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: day})
+	if err != nil {
+		t.Fatalf("Failed to add statement: %v", err)
+	}
+	if match == nil || match.Transaction.TrxID != "TX100" {
+		t.Fatalf("Expected BS001 to match TX100, got %+v", match)
+	}
+	rejected := match.Breakdown.Rejected
+	if len(rejected) != 1 || rejected[0].Transaction.TrxID != "TX99" || rejected[0].Reason != RejectionOutscored {
+		t.Errorf("Expected TX99 to be rejected as outscored, got %+v", rejected)
+	}
+}
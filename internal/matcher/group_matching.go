@@ -0,0 +1,410 @@
+package matcher
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+// GroupMatchType identifies the shape of a grouped match
+type GroupMatchType string
+
+const (
+	// GroupManyToOne is a single transaction settled by several bank statements,
+	// for example a payment the bank split into multiple postings.
+	GroupManyToOne GroupMatchType = "many_to_one"
+	
+	// GroupOneToMany is a single bank statement that settles several transactions,
+	// for example an aggregated settlement credit covering many repayments.
+	GroupOneToMany GroupMatchType = "one_to_many"
+)
+
+const (
+	// defaultMaxGroupCandidates is used when MaxGroupCandidates is not set
+	defaultMaxGroupCandidates = 50
+	
+	// maxSubsetSearchSteps bounds the subset-sum search for a single statement
+	maxSubsetSearchSteps = 200000
+)
+
+// GroupMatch represents a match between one or more transactions and one or
+// more bank statements whose amounts add up to each other. It is produced by the
+// partial matching pass that runs after one-to-one matching.
+type GroupMatch struct {
+	GroupType        GroupMatchType
+	Transactions     []*models.Transaction
+	Statements       []*models.BankStatement
+	TransactionTotal decimal.Decimal
+	StatementTotal   decimal.Decimal
+	AmountDifference decimal.Decimal
+	ConfidenceScore  float64
+	Reasons          []string
+}
+
+// matchPartialGroups runs the many-to-one pass over the items left by the
+// one-to-one pass. Each unmatched transaction is offered combinations of
+// unmatched statements within the date tolerance, and the best combination that
+// meets the confidence threshold is accepted. Statements are used at most once.
+// When amounts are converted to a base currency, only items that kept their
+// original currency are grouped; see currencyConverter.groupable.
+func (me *MatchingEngine) matchPartialGroups(
+	transactions []*models.Transaction,
+	statements []*models.BankStatement,
+) ([]*GroupMatch, []*models.Transaction, []*models.BankStatement) {
+
+	if !me.Config.EnablePartialMatching || len(transactions) == 0 || len(statements) < 2 {
+		return nil, transactions, statements
+	}
+
+	handler := NewEdgeCaseHandler(me.Config)
+	usedStatements := make(map[*models.BankStatement]bool)
+	var groups []*GroupMatch
+	var remainingTransactions []*models.Transaction
+
+	for _, tx := range transactions {
+		candidates := me.partialCandidatesFor(tx, statements, usedStatements)
+		if len(candidates) < 2 {
+			remainingTransactions = append(remainingTransactions, tx)
+			continue
+		}
+
+		// Results are sorted by confidence, so only the first needs checking
+		partials := handler.HandlePartialMatches(tx, candidates)
+		if len(partials) == 0 || partials[0].Confidence < me.Config.MinConfidenceScore {
+			remainingTransactions = append(remainingTransactions, tx)
+			continue
+		}
+
+		accepted := partials[0]
+		for _, stmt := range accepted.PartialStatements {
+			usedStatements[stmt] = true
+		}
+
+		group := &GroupMatch{
+			GroupType:        GroupManyToOne,
+			Transactions:     []*models.Transaction{tx},
+			Statements:       accepted.PartialStatements,
+			TransactionTotal: tx.Amount.Abs(),
+			StatementTotal:   accepted.TotalAmount,
+			AmountDifference: accepted.AmountDifference,
+			ConfidenceScore:  accepted.Confidence,
+			Reasons: []string{
+				fmt.Sprintf("%d bank statements sum to the transaction amount", len(accepted.PartialStatements)),
+				"All statements within date tolerance",
+			},
+		}
+		groups = append(groups, group)
+
+		me.logger.WithFields(logger.Fields{
+			"transaction_id":   tx.TrxID,
+			"statement_count":  len(accepted.PartialStatements),
+			"confidence_score": accepted.Confidence,
+		}).Debug("Found many-to-one group match")
+	}
+
+	var remainingStatements []*models.BankStatement
+	for _, stmt := range statements {
+		if !usedStatements[stmt] {
+			remainingStatements = append(remainingStatements, stmt)
+		}
+	}
+
+	return groups, remainingTransactions, remainingStatements
+}
+
+// partialCandidatesFor returns the unused statements that could form part of a
+// split settlement for the transaction: same direction, smaller than the
+// transaction and within the date tolerance. The closest dates are preferred and
+// the list is capped at MaxCandidatesPerTransaction to bound the combination search.
+func (me *MatchingEngine) partialCandidatesFor(
+	tx *models.Transaction,
+	statements []*models.BankStatement,
+	used map[*models.BankStatement]bool,
+) []*models.BankStatement {
+
+	target := tx.Amount.Abs()
+
+	var candidates []*models.BankStatement
+	for _, stmt := range statements {
+		if used[stmt] || stmt.Amount.IsZero() {
+			continue
+		}
+		if !me.currency.groupable(tx, stmt) {
+			continue
+		}
+		if me.Config.EnableTypeMatching && stmt.GetTransactionType() != tx.Type {
+			continue
+		}
+		if !stmt.Amount.Abs().LessThan(target) {
+			continue
+		}
+		if !me.Config.IsPairWithinDateTolerance(tx, stmt) {
+			continue
+		}
+		candidates = append(candidates, stmt)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return me.calculateDateDifference(tx.TransactionTime, candidates[i].Date) <
+			me.calculateDateDifference(tx.TransactionTime, candidates[j].Date)
+	})
+
+	if me.Config.MaxCandidatesPerTransaction > 0 && len(candidates) > me.Config.MaxCandidatesPerTransaction {
+		candidates = candidates[:me.Config.MaxCandidatesPerTransaction]
+	}
+
+	return candidates
+}
+
+// matchBatchedStatements runs the one-to-many pass over the items left by the
+// earlier passes. For each unmatched statement, unmatched transactions of the
+// same direction within the date tolerance are searched for a subset whose
+// amounts add up to the statement amount. Transactions are used at most once.
+// As with the many-to-one pass, converted amounts are not grouped.
+func (me *MatchingEngine) matchBatchedStatements(
+	transactions []*models.Transaction,
+	statements []*models.BankStatement,
+) ([]*GroupMatch, []*models.Transaction, []*models.BankStatement) {
+
+	if !me.Config.EnableOneToManyMatching || len(statements) == 0 || len(transactions) < 2 {
+		return nil, transactions, statements
+	}
+
+	available := make(map[*models.Transaction]bool, len(transactions))
+	for _, tx := range transactions {
+		available[tx] = true
+	}
+
+	var groups []*GroupMatch
+	var remainingStatements []*models.BankStatement
+
+	for _, stmt := range statements {
+		candidates := me.batchCandidatesFor(stmt, available)
+		if len(candidates) < 2 {
+			remainingStatements = append(remainingStatements, stmt)
+			continue
+		}
+
+		subset, total := me.findTransactionSubset(stmt, candidates)
+		if subset == nil {
+			remainingStatements = append(remainingStatements, stmt)
+			continue
+		}
+
+		group := me.buildOneToManyGroup(stmt, subset, total)
+		if group.ConfidenceScore < me.Config.MinConfidenceScore {
+			remainingStatements = append(remainingStatements, stmt)
+			continue
+		}
+
+		for _, tx := range subset {
+			available[tx] = false
+		}
+		groups = append(groups, group)
+
+		me.logger.WithFields(logger.Fields{
+			"statement_id":      stmt.UniqueIdentifier,
+			"transaction_count": len(subset),
+			"confidence_score":  group.ConfidenceScore,
+		}).Debug("Found one-to-many group match")
+	}
+
+	var remainingTransactions []*models.Transaction
+	for _, tx := range transactions {
+		if available[tx] {
+			remainingTransactions = append(remainingTransactions, tx)
+		}
+	}
+
+	return groups, remainingTransactions, remainingStatements
+}
+
+// batchCandidatesFor returns available transactions that could be part of a
+// batched settlement for the statement, looked up through the transaction date
+// index. The closest dates are preferred and the list is capped at MaxGroupCandidates.
+func (me *MatchingEngine) batchCandidatesFor(
+	stmt *models.BankStatement,
+	available map[*models.Transaction]bool,
+) []*models.Transaction {
+
+	target := stmt.Amount.Abs()
+	stmtType := stmt.GetTransactionType()
+
+	// Widen the calendar lookup when days off do not count towards the tolerance
+	lookupDays := me.Config.dateRuleOf(stmt.BankName, stmtType).calendarDays()
+	day := time.Date(stmt.Date.Year(), stmt.Date.Month(), stmt.Date.Day(), 0, 0, 0, 0, stmt.Date.Location())
+
+	var candidates []*models.Transaction
+	for _, tx := range me.TransactionIndex.GetByDateRange(day.AddDate(0, 0, -lookupDays), day.AddDate(0, 0, lookupDays)) {
+		if !available[tx] || tx.Amount.IsZero() {
+			continue
+		}
+		if !me.currency.groupable(tx, stmt) {
+			continue
+		}
+		if tx.Type != stmtType {
+			continue
+		}
+		if !tx.Amount.Abs().LessThan(target) {
+			continue
+		}
+		if !me.Config.IsPairWithinDateTolerance(tx, stmt) {
+			continue
+		}
+		candidates = append(candidates, tx)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return me.calculateDateDifference(candidates[i].TransactionTime, stmt.Date) <
+			me.calculateDateDifference(candidates[j].TransactionTime, stmt.Date)
+	})
+
+	limit := me.Config.MaxGroupCandidates
+	if limit <= 0 {
+		limit = defaultMaxGroupCandidates
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return candidates
+}
+
+// findTransactionSubset searches for a subset of candidates whose absolute
+// amounts sum to the statement amount within the configured amount tolerance.
+// Amounts are compared in minor units at the configured precision. The search
+// is a depth-first walk over candidates sorted by amount (largest first) with
+// pruning on the remaining total, and gives up after a fixed number of steps.
+func (me *MatchingEngine) findTransactionSubset(
+	stmt *models.BankStatement,
+	candidates []*models.Transaction,
+) ([]*models.Transaction, decimal.Decimal) {
+
+	scale := decimal.New(1, int32(me.Config.AmountPrecision))
+	toUnits := func(d decimal.Decimal) int64 {
+		return d.Abs().Mul(scale).Round(0).IntPart()
+	}
+
+	target := toUnits(stmt.Amount)
+	tolerance := toUnits(me.Config.GetAmountTolerance(stmt.Amount))
+
+	sorted := make([]*models.Transaction, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Amount.Abs().GreaterThan(sorted[j].Amount.Abs())
+	})
+
+	units := make([]int64, len(sorted))
+	suffix := make([]int64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		units[i] = toUnits(sorted[i].Amount)
+		suffix[i] = suffix[i+1] + units[i]
+	}
+
+	// Fast path: the whole candidate set is the batch
+	if diff := suffix[0] - target; diff >= -tolerance && diff <= tolerance {
+		return sorted, me.sumTransactionAmounts(sorted)
+	}
+
+	var chosen []int
+	var found []int
+	steps := 0
+
+	var search func(start int, sum int64) bool
+	search = func(start int, sum int64) bool {
+		if diff := sum - target; diff >= -tolerance && diff <= tolerance && len(chosen) >= 2 {
+			found = append([]int(nil), chosen...)
+			return true
+		}
+		for i := start; i < len(sorted); i++ {
+			steps++
+			if steps > maxSubsetSearchSteps {
+				return false
+			}
+			if sum+units[i] > target+tolerance {
+				continue
+			}
+			if sum+suffix[i] < target-tolerance {
+				return false // Remaining amounts cannot reach the target
+			}
+			chosen = append(chosen, i)
+			if search(i+1, sum+units[i]) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+
+	if !search(0, 0) {
+		return nil, decimal.Zero
+	}
+
+	subset := make([]*models.Transaction, len(found))
+	for i, idx := range found {
+		subset[i] = sorted[idx]
+	}
+
+	return subset, me.sumTransactionAmounts(subset)
+}
+
+// buildOneToManyGroup scores a batched settlement. The confidence uses the
+// engine weights: the amount score of the total, the average date score of
+// the members and full type score since all members share the statement direction.
+func (me *MatchingEngine) buildOneToManyGroup(
+	stmt *models.BankStatement,
+	subset []*models.Transaction,
+	total decimal.Decimal,
+) *GroupMatch {
+
+	target := stmt.Amount.Abs()
+	difference := total.Sub(target).Abs()
+
+	amountScore := 1.0
+	if !difference.IsZero() {
+		tolerance := me.Config.GetAmountTolerance(target)
+		amountScore = 0.0
+		if tolerance.IsPositive() {
+			amountScore = math.Max(0.0, 1.0-difference.Div(tolerance).InexactFloat64())
+		}
+	}
+
+	stmtDate := me.Config.NormalizeTime(stmt.Date)
+	dateScore := 0.0
+	for _, tx := range subset {
+		dateScore += me.Config.dateRuleFor(tx, stmt).score(me.Config.NormalizeTime(tx.TransactionTime), stmtDate)
+	}
+	dateScore /= float64(len(subset))
+
+	weights := me.Config.Weights
+	confidence := amountScore*weights.AmountWeight + dateScore*weights.DateWeight + 1.0*weights.TypeWeight
+
+	return &GroupMatch{
+		GroupType:        GroupOneToMany,
+		Transactions:     subset,
+		Statements:       []*models.BankStatement{stmt},
+		TransactionTotal: total,
+		StatementTotal:   target,
+		AmountDifference: difference,
+		ConfidenceScore:  confidence,
+		Reasons: []string{
+			fmt.Sprintf("%d transactions sum to the bank statement amount", len(subset)),
+			"All transactions within date tolerance",
+		},
+	}
+}
+
+// sumTransactionAmounts returns the total of the absolute transaction amounts
+func (me *MatchingEngine) sumTransactionAmounts(transactions []*models.Transaction) decimal.Decimal {
+	total := decimal.Zero
+	for _, tx := range transactions {
+		total = total.Add(tx.Amount.Abs())
+	}
+	return total
+}
@@ -0,0 +1,263 @@
+package matcher
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func createSplitSettlementData() ([]*models.Transaction, []*models.BankStatement) {
+	transactions := []*models.Transaction{
+		{
+			TrxID:           "TX001",
+			Amount:          decimal.NewFromFloat(1000.00),
+			Type:            models.TransactionTypeCredit,
+			TransactionTime: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			TrxID:           "TX002",
+			Amount:          decimal.NewFromFloat(50.00),
+			Type:            models.TransactionTypeDebit,
+			TransactionTime: time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC),
+		},
+	}
+
+	statements := []*models.BankStatement{
+		{
+			UniqueIdentifier: "BS001",
+			Amount:           decimal.NewFromFloat(600.00),
+			Date:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			UniqueIdentifier: "BS002",
+			Amount:           decimal.NewFromFloat(400.00),
+			Date:             time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			UniqueIdentifier: "BS003",
+			Amount:           decimal.NewFromFloat(-50.00),
+			Date:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			UniqueIdentifier: "BS004",
+			Amount:           decimal.NewFromFloat(400.00),
+			Date:             time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC), // Outside date tolerance
+		},
+	}
+
+	return transactions, statements
+}
+
+func TestMatchingEngine_Reconcile_ManyToOneGroups(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.EnablePartialMatching = true
+
+	transactions, statements := createSplitSettlementData()
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if len(result.Matches) != 1 || result.Matches[0].Transaction.TrxID != "TX002" {
+		t.Fatalf("Expected TX002 to be matched one-to-one, got %d matches", len(result.Matches))
+	}
+
+	if len(result.GroupMatches) != 1 {
+		t.Fatalf("Expected 1 group match, got %d", len(result.GroupMatches))
+	}
+
+	group := result.GroupMatches[0]
+	if group.GroupType != GroupManyToOne {
+		t.Errorf("Expected group type %s, got %s", GroupManyToOne, group.GroupType)
+	}
+	if len(group.Transactions) != 1 || group.Transactions[0].TrxID != "TX001" {
+		t.Errorf("Expected group to settle TX001")
+	}
+	if len(group.Statements) != 2 {
+		t.Fatalf("Expected 2 statements in group, got %d", len(group.Statements))
+	}
+	for _, stmt := range group.Statements {
+		if stmt.UniqueIdentifier == "BS004" {
+			t.Errorf("Statement outside date tolerance should not be grouped")
+		}
+	}
+	if !group.StatementTotal.Equal(decimal.NewFromFloat(1000.00)) {
+		t.Errorf("Expected statement total 1000.00, got %s", group.StatementTotal.String())
+	}
+
+	if len(result.UnmatchedTransactions) != 0 {
+		t.Errorf("Expected no unmatched transactions, got %d", len(result.UnmatchedTransactions))
+	}
+	if len(result.UnmatchedStatements) != 1 || result.UnmatchedStatements[0].UniqueIdentifier != "BS004" {
+		t.Errorf("Expected only BS004 to remain unmatched")
+	}
+
+	summary := result.Summary
+	if summary.ManyToOneMatches != 1 {
+		t.Errorf("Expected 1 many-to-one match in summary, got %d", summary.ManyToOneMatches)
+	}
+	if summary.MatchedTransactions != 2 {
+		t.Errorf("Expected 2 matched transactions, got %d", summary.MatchedTransactions)
+	}
+	if summary.MatchedStatements != 3 {
+		t.Errorf("Expected 3 matched statements, got %d", summary.MatchedStatements)
+	}
+}
+
+func TestMatchingEngine_Reconcile_PartialMatchingDisabled(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.EnablePartialMatching = false
+
+	transactions, statements := createSplitSettlementData()
+	engine := NewMatchingEngine(config)
+	engine.LoadTransactions(transactions)
+	engine.LoadBankStatements(statements)
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if len(result.GroupMatches) != 0 {
+		t.Errorf("Expected no group matches when partial matching is disabled, got %d", len(result.GroupMatches))
+	}
+	if len(result.UnmatchedTransactions) != 1 {
+		t.Errorf("Expected 1 unmatched transaction, got %d", len(result.UnmatchedTransactions))
+	}
+}
+
+func createBatchedSettlementData() ([]*models.Transaction, []*models.BankStatement) {
+	day := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	amounts := []float64{120.00, 80.00, 45.50, 54.50, 300.00, 17.25}
+
+	var transactions []*models.Transaction
+	for i, amount := range amounts {
+		transactions = append(transactions, &models.Transaction{
+			TrxID:           fmt.Sprintf("REPAY%03d", i+1),
+			Amount:          decimal.NewFromFloat(amount),
+			Type:            models.TransactionTypeCredit,
+			TransactionTime: day.Add(time.Duration(i+8) * time.Hour),
+		})
+	}
+
+	statements := []*models.BankStatement{
+		{
+			UniqueIdentifier: "SETTLE001",
+			Amount:           decimal.NewFromFloat(300.00), // 120 + 80 + 45.50 + 54.50
+			Date:             day,
+		},
+		{
+			UniqueIdentifier: "SETTLE002",
+			Amount:           decimal.NewFromFloat(999.99), // No combination adds up
+			Date:             day,
+		},
+	}
+
+	return transactions, statements
+}
+
+func TestMatchingEngine_Reconcile_OneToManyGroups(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.EnableOneToManyMatching = true
+
+	transactions, statements := createBatchedSettlementData()
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	// REPAY005 (300.00) matches SETTLE001 one-to-one first, so the batch has to
+	// come from the remaining transactions; nothing else sums to 300.00.
+	if len(result.Matches) != 1 || result.Matches[0].Transaction.TrxID != "REPAY005" {
+		t.Fatalf("Expected REPAY005 to match one-to-one, got %d matches", len(result.Matches))
+	}
+	if len(result.GroupMatches) != 0 {
+		t.Errorf("Expected no group matches, got %d", len(result.GroupMatches))
+	}
+
+	// Without the exact single candidate the batch should be found
+	engine = NewMatchingEngine(config)
+	engine.LoadTransactions(append(transactions[:4:4], transactions[5]))
+	engine.LoadBankStatements(statements)
+
+	result, err = engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if len(result.GroupMatches) != 1 {
+		t.Fatalf("Expected 1 group match, got %d", len(result.GroupMatches))
+	}
+
+	group := result.GroupMatches[0]
+	if group.GroupType != GroupOneToMany {
+		t.Errorf("Expected group type %s, got %s", GroupOneToMany, group.GroupType)
+	}
+	if len(group.Statements) != 1 || group.Statements[0].UniqueIdentifier != "SETTLE001" {
+		t.Errorf("Expected the group to settle SETTLE001")
+	}
+	if len(group.Transactions) != 4 {
+		t.Errorf("Expected 4 transactions in the batch, got %d", len(group.Transactions))
+	}
+	if !group.TransactionTotal.Equal(decimal.NewFromFloat(300.00)) {
+		t.Errorf("Expected transaction total 300.00, got %s", group.TransactionTotal.String())
+	}
+
+	if result.Summary.OneToManyMatches != 1 {
+		t.Errorf("Expected 1 one-to-many match in summary, got %d", result.Summary.OneToManyMatches)
+	}
+	if result.Summary.ManyToOneMatches != 0 {
+		t.Errorf("Expected no many-to-one matches in summary, got %d", result.Summary.ManyToOneMatches)
+	}
+	if len(result.UnmatchedTransactions) != 1 || result.UnmatchedTransactions[0].TrxID != "REPAY006" {
+		t.Errorf("Expected only REPAY006 to remain unmatched")
+	}
+	if len(result.UnmatchedStatements) != 1 || result.UnmatchedStatements[0].UniqueIdentifier != "SETTLE002" {
+		t.Errorf("Expected only SETTLE002 to remain unmatched")
+	}
+}
+
+func TestMatchingEngine_findTransactionSubset(t *testing.T) {
+	config := DefaultMatchingConfig()
+	engine := NewMatchingEngine(config)
+
+	transactions, _ := createBatchedSettlementData()
+	stmt := &models.BankStatement{
+		UniqueIdentifier: "SETTLE",
+		Amount:           decimal.NewFromFloat(62.75), // 45.50 + 17.25
+		Date:             time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	subset, total := engine.findTransactionSubset(stmt, transactions)
+	if subset == nil {
+		t.Fatal("Expected a subset to be found")
+	}
+	if !total.Equal(stmt.Amount) {
+		t.Errorf("Expected total %s, got %s", stmt.Amount.String(), total.String())
+	}
+
+	stmt.Amount = decimal.NewFromFloat(1.00)
+	if subset, _ := engine.findTransactionSubset(stmt, transactions); subset != nil {
+		t.Errorf("Expected no subset for an unreachable amount, got %d transactions", len(subset))
+	}
+}
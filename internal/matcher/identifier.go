@@ -0,0 +1,279 @@
+package matcher
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Identifier similarity scores. Banks often copy our transaction ID into the
+// statement reference or description, sometimes with a prefix or suffix of
+// their own, sometimes with a typo.
+const (
+	identifierScoreExact     = 1.0
+	identifierScoreAffix     = 0.9 // one ID is a prefix or suffix of the other
+	identifierScoreEmbedded  = 0.8 // one ID appears inside the other
+	identifierScoreEditScale = 0.7 // multiplied by the edit-distance similarity
+
+	// minReferenceLength is the shortest normalised ID compared by anything
+	// other than equality, and the shortest description word indexed as a
+	// reference. Shorter IDs would match by coincidence.
+	minReferenceLength = 4
+
+	// minEditSimilarity is the lowest edit-distance similarity that scores
+	minEditSimilarity = 0.75
+)
+
+// IdentifierMatchKind describes how a transaction ID was found on a statement
+type IdentifierMatchKind string
+
+const (
+	IdentifierNone     IdentifierMatchKind = ""
+	IdentifierExact    IdentifierMatchKind = "exact"         // same ID after normalisation
+	IdentifierAffix    IdentifierMatchKind = "prefix/suffix" // one ID starts or ends the other
+	IdentifierEmbedded IdentifierMatchKind = "embedded"      // one ID appears inside the other
+	IdentifierSimilar  IdentifierMatchKind = "similar"       // within a small edit distance
+)
+
+// NormalizeReference normalises an ID for comparison: it applies
+// models.NormalizeIdentifier and drops everything but letters and digits, so
+// "txn-2024/001" and "2024001" compare equal
+func NormalizeReference(id string) string {
+	normalized := models.NormalizeIdentifier(id)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, normalized)
+}
+
+// statementReferences returns the normalised references a statement carries:
+// its unique identifier and every sufficiently long word of its description
+func statementReferences(stmt *models.BankStatement) []string {
+	var references []string
+	if id := NormalizeReference(stmt.UniqueIdentifier); id != "" {
+		references = append(references, id)
+	}
+	for _, word := range strings.FieldsFunc(stmt.Description, unicode.IsSpace) {
+		if reference := NormalizeReference(word); len(reference) >= minReferenceLength {
+			references = append(references, reference)
+		}
+	}
+	return references
+}
+
+// calculateIdentifierScore scores how well the transaction ID matches the
+// statement's identifier or a reference in its description. The best match
+// over all references counts.
+func calculateIdentifierScore(tx *models.Transaction, stmt *models.BankStatement) (float64, IdentifierMatchKind) {
+	txID := NormalizeReference(tx.TrxID)
+	if txID == "" {
+		return 0.0, IdentifierNone
+	}
+
+	bestScore, bestKind := 0.0, IdentifierNone
+	for _, reference := range statementReferences(stmt) {
+		score, kind := compareReferences(txID, reference)
+		if score > bestScore {
+			bestScore, bestKind = score, kind
+		}
+		if bestScore == identifierScoreExact {
+			break
+		}
+	}
+	return bestScore, bestKind
+}
+
+// compareReferences scores two normalised IDs
+func compareReferences(a, b string) (float64, IdentifierMatchKind) {
+	if a == b {
+		return identifierScoreExact, IdentifierExact
+	}
+
+	shorter, longer := a, b
+	if len(shorter) > len(longer) {
+		shorter, longer = longer, shorter
+	}
+	if len(shorter) < minReferenceLength {
+		return 0.0, IdentifierNone
+	}
+
+	if strings.HasPrefix(longer, shorter) || strings.HasSuffix(longer, shorter) {
+		return identifierScoreAffix, IdentifierAffix
+	}
+	if strings.Contains(longer, shorter) {
+		return identifierScoreEmbedded, IdentifierEmbedded
+	}
+
+	similarity := 1.0 - float64(levenshtein(a, b))/float64(len(longer))
+	if similarity >= minEditSimilarity {
+		return similarity * identifierScoreEditScale, IdentifierSimilar
+	}
+	return 0.0, IdentifierNone
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// applyIdentifierScore adds the weighted identifier score to the score of the
+// other criteria. Many statements carry no reference at all, so a missing or
+// weak one is not evidence against a pair: the identifier can only raise the
+// score above what the other criteria give on their own, scaled to the same
+// total weight.
+func (me *MatchingEngine) applyIdentifierScore(score, identifierScore float64) float64 {
+	weights := me.Config.Weights
+	others := weights.AmountWeight + weights.DateWeight + weights.TypeWeight + weights.DescriptionWeight
+	withIdentifier := score + identifierScore*weights.IdentifierWeight
+	if others <= 0 {
+		return withIdentifier
+	}
+	return math.Max(withIdentifier, score/others*(others+weights.IdentifierWeight))
+}
+
+func describeIdentifierMatch(kind IdentifierMatchKind) string {
+	switch kind {
+	case IdentifierExact:
+		return "Reference ID match"
+	case IdentifierAffix, IdentifierEmbedded:
+		return fmt.Sprintf("Reference ID match (%s)", kind)
+	default:
+		return "Similar reference ID"
+	}
+}
+
+const (
+	// referenceAmountPercent bounds how far apart, as a percentage of the
+	// transaction amount, the amounts of a pair matched by reference may be
+	referenceAmountPercent = 5.0
+
+	// referenceExtraDays bounds how many days beyond the date tolerance of the
+	// pair a statement matched by reference may be dated
+	referenceExtraDays = 7
+)
+
+// selectIdentifierMatches is the exact-ID fast path. A transaction whose
+// normalised ID is carried by exactly one eligible statement is paired with it
+// before the assignment passes run, so a shared reference wins over small
+// amount or date differences. The pair must still be in the same currency
+// and direction, within referenceAmountPercent of the amount and
+// referenceExtraDays beyond the date tolerance. It only runs when identifiers
+// are weighted.
+func (me *MatchingEngine) selectIdentifierMatches() []*MatchResult {
+	if me.Config.Weights.IdentifierWeight <= 0 {
+		return nil
+	}
+
+	matches := me.matchByReference()
+	for _, match := range matches {
+		me.overrides.claim(match.Transaction, match.BankStatement)
+	}
+
+	me.logger.WithField("reference_matches", len(matches)).Debug("Matched transactions by reference ID")
+	return matches
+}
+
+// matchByReference pairs each transaction with the one eligible statement
+// carrying its ID, if there is exactly one. Statements already claimed or
+// paired earlier in the same call are not eligible.
+func (me *MatchingEngine) matchByReference() []*MatchResult {
+	var matches []*MatchResult
+	taken := make(map[*models.BankStatement]bool)
+	for _, tx := range me.TransactionIndex.AllTransactions {
+		// Claimed statements and excluded pairs are filtered out here
+		var eligible []*models.BankStatement
+		for _, stmt := range me.overrides.candidatesFor(tx, me.BankStatementIndex.GetByReference(tx.TrxID)) {
+			if taken[stmt] || !me.currency.comparable(tx, stmt) || me.calculateTypeScore(tx, stmt) == 0.0 {
+				continue
+			}
+			if !me.nearEnoughForReference(tx, stmt) {
+				continue // Too far off for a reference alone; scoring decides
+			}
+			eligible = append(eligible, stmt)
+		}
+		if len(eligible) != 1 {
+			continue // No reference, or an ambiguous one left to scoring
+		}
+
+		result, err := me.scoreMatch(tx, eligible[0])
+		if err != nil {
+			me.logger.WithError(err).WithField("transaction_id", tx.TrxID).Warn("Failed to score reference match")
+			continue
+		}
+		// The shared reference vouches for the pair even when the amounts or
+		// dates alone would not, but such pairs still deserve a review
+		if result.MatchType == MatchNone {
+			result.MatchType = MatchPossible
+		}
+
+		matches = append(matches, result)
+		taken[eligible[0]] = true
+	}
+	return matches
+}
+
+// nearEnoughForReference reports whether a pair is close enough in amount
+// and date for a shared reference to decide it
+func (me *MatchingEngine) nearEnoughForReference(tx *models.Transaction, stmt *models.BankStatement) bool {
+	txAmount := me.currency.transactionAmount(tx).Abs()
+	stmtAmount := me.currency.statementAmount(stmt).Abs()
+	limit := txAmount.Mul(decimal.NewFromFloat(referenceAmountPercent / 100.0))
+	if stmtAmount.Sub(txAmount).Abs().GreaterThan(limit) {
+		return false
+	}
+
+	days := me.Config.DayNumber(stmt.Date) - me.Config.DayNumber(tx.TransactionTime)
+	if days < 0 {
+		days = -days
+	}
+	return days <= int64(me.Config.dateRuleFor(tx, stmt).calendarDays()+referenceExtraDays)
+}
+
+// GetByReference returns the statements whose identifier or description
+// carries exactly the given ID after normalisation
+func (bsi *BankStatementIndex) GetByReference(id string) []*models.BankStatement {
+	reference := NormalizeReference(id)
+	if reference == "" {
+		return nil
+	}
+	return bsi.ReferenceIndex[reference]
+}
+
+// buildReferenceIndex indexes statements by their normalised references
+func (bsi *BankStatementIndex) buildReferenceIndex() {
+	bsi.ReferenceIndex = make(map[string][]*models.BankStatement)
+	for _, stmt := range bsi.AllStatements {
+		seen := make(map[string]bool)
+		for _, reference := range statementReferences(stmt) {
+			if !seen[reference] {
+				seen[reference] = true
+				bsi.ReferenceIndex[reference] = append(bsi.ReferenceIndex[reference], stmt)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package matcher
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNormalizeReference(t *testing.T) {
+	tests := map[string]string{
+		"TX-2024/001":   "TX2024001",
+		" txn-2024001 ": "2024001",
+		"REF:ABC 12":    "ABC12",
+		"":              "",
+	}
+	for input, want := range tests {
+		if got := NormalizeReference(input); got != want {
+			t.Errorf("NormalizeReference(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestCalculateIdentifierScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		trxID       string
+		stmtID      string
+		description string
+		wantKind    IdentifierMatchKind
+		wantScore   float64
+	}{
+		{"exact", "TX1001", "tx-1001", "", IdentifierExact, 1.0},
+		{"bank prefix", "TX1001", "BCA-TX1001", "", IdentifierAffix, 0.9},
+		{"bank suffix", "TX1001", "TX1001-01", "", IdentifierAffix, 0.9},
+		{"embedded", "TX1001", "BCATX1001X", "", IdentifierEmbedded, 0.8},
+		{"typo", "TX10012", "TX10013", "", IdentifierSimilar, 0.7 * (1.0 - 1.0/7.0)},
+		{"too different", "TX10012", "TX10021", "", IdentifierNone, 0.0},
+		{"in description", "TX1001", "BS-77", "Payment ref TX-1001 thanks", IdentifierExact, 1.0},
+		{"unrelated", "TX1001", "BS-7731", "salary", IdentifierNone, 0.0},
+		{"short ids only compare exactly", "TX1", "TX12", "", IdentifierNone, 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &models.Transaction{TrxID: tt.trxID}
+			stmt := &models.BankStatement{UniqueIdentifier: tt.stmtID, Description: tt.description}
+
+			score, kind := calculateIdentifierScore(tx, stmt)
+			if kind != tt.wantKind || math.Abs(score-tt.wantScore) > 1e-9 {
+				t.Errorf("got %.3f (%q), want %.3f (%q)", score, kind, tt.wantScore, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestMatchingWeights_WithIdentifierWeight(t *testing.T) {
+	weights := DefaultMatchingConfig().Weights.WithIdentifierWeight(0.4)
+	if err := weights.Validate(); err != nil {
+		t.Fatalf("Scaled weights are invalid: %v", err)
+	}
+	if math.Abs(weights.AmountWeight-0.36) > 1e-9 || math.Abs(weights.IdentifierWeight-0.4) > 1e-9 {
+		t.Errorf("Unexpected scaled weights: %+v", weights)
+	}
+}
+
+func TestMatchingEngine_Reconcile_ReferenceFastPath(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transactions := []*models.Transaction{
+		{TrxID: "INV-20240115-01", Amount: decimal.NewFromFloat(500.00), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "INV-20240115-02", Amount: decimal.NewFromFloat(120.00), Type: models.TransactionTypeCredit, TransactionTime: day},
+	}
+	statements := []*models.BankStatement{
+		// Exact amount and date, but no reference
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromFloat(500.00), Date: day},
+		// Bank fee deducted and posted four days later, with our reference
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromFloat(497.50), Date: day.AddDate(0, 0, 4), Description: "TRF INV2024011501"},
+		// Two statements carry the second reference, so scoring decides
+		{UniqueIdentifier: "INV2024011502", Amount: decimal.NewFromFloat(100.00), Date: day},
+		{UniqueIdentifier: "BS004", Amount: decimal.NewFromFloat(120.00), Date: day, Description: "INV-20240115-02"},
+	}
+
+	tests := []struct {
+		name     string
+		weight   float64
+		expected map[string]string
+	}{
+		{"identifiers not weighted", 0.0, map[string]string{"INV-20240115-01": "BS001", "INV-20240115-02": "BS004"}},
+		{"identifiers weighted", 0.3, map[string]string{"INV-20240115-01": "BS002", "INV-20240115-02": "BS004"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			config.Weights = config.Weights.WithIdentifierWeight(tt.weight)
+
+			engine := NewMatchingEngine(config)
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+
+			got := make(map[string]string)
+			for _, match := range result.Matches {
+				got[match.Transaction.TrxID] = match.BankStatement.UniqueIdentifier
+			}
+			for trxID, stmtID := range tt.expected {
+				if got[trxID] != stmtID {
+					t.Errorf("Expected %s to match %s, got %q", trxID, stmtID, got[trxID])
+				}
+			}
+
+			for _, match := range result.Matches {
+				if match.BankStatement.UniqueIdentifier == "BS002" && match.MatchType == MatchNone {
+					t.Errorf("Expected a reference match to be reported for review, got %s", match.MatchType)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_Reconcile_ReferenceFastPathBounds(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		amount    float64
+		date      time.Time
+		wantMatch bool
+	}{
+		{"small fee, days later", 497.50, day.AddDate(0, 0, 4), true},
+		{"amount far off", 5.00, day, false},
+		{"months later", 500.00, day.AddDate(0, 6, 0), false},
+		{"amount and date far off", 5.00, day.AddDate(0, 6, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			config.Weights = config.Weights.WithIdentifierWeight(0.3)
+
+			engine := NewMatchingEngine(config)
+			transactions := []*models.Transaction{
+				{TrxID: "INV-20240115-01", Amount: decimal.NewFromFloat(500.00), Type: models.TransactionTypeCredit, TransactionTime: day},
+			}
+			statements := []*models.BankStatement{
+				{UniqueIdentifier: "BS001", Amount: decimal.NewFromFloat(tt.amount), Date: tt.date, Description: "TRF INV2024011501"},
+			}
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			if matches := engine.matchByReference(); (len(matches) == 1) != tt.wantMatch {
+				t.Errorf("Expected a reference match: %v, got %d matches", tt.wantMatch, len(matches))
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+			if (len(result.Matches) == 1) != tt.wantMatch {
+				t.Errorf("Expected the pair to be matched: %v, got %d matches", tt.wantMatch, len(result.Matches))
+			}
+		})
+	}
+}
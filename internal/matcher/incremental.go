@@ -0,0 +1,560 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/rules"
+	"golang-reconciliation-service/pkg/errors"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes incompatibly
+const snapshotVersion = 1
+
+// IncrementalReconciler matches transactions and bank statements one at a
+// time as they arrive. Each new item is scored against the items still open
+// on the other side and matched with the best candidate at or above
+// MinConfidenceScore; ties go to the item that arrived first. An item that
+// finds no partner stays open until one arrives.
+//
+// Only the open items are indexed, so memory follows the backlog rather than
+// the history. Matching rules apply as in a batch run: items named by a match
+// rule wait for each other and are paired as soon as both have arrived,
+// exclusions are never matched, and ignored statements are never opened. Strategies, group matching and optimal
+// assignment need the whole period at once and are not used.
+//
+// An IncrementalReconciler is safe for concurrent use.
+type IncrementalReconciler struct {
+	mu     sync.Mutex
+	saveMu sync.Mutex
+	engine *MatchingEngine
+	logger logger.Logger
+
+	// sequence numbers record arrival order for tie-breaking and listing
+	sequence     int64
+	transactions map[*models.Transaction]int64
+	statements   map[*models.BankStatement]int64
+
+	// open items by identifier, for match rules and duplicate checks.
+	// Statement identifiers are only unique within a bank.
+	transactionsByID map[string]*models.Transaction
+	statementsByID   map[string][]*models.BankStatement
+
+	// open transactions by normalised ID, so statements carrying a
+	// transaction ID find it whatever the amount and date
+	transactionsByReference map[string][]*models.Transaction
+
+	rulesByTransaction map[string][]*rules.PairRule
+	rulesByStatement   map[string][]*rules.PairRule
+
+	stats IncrementalStats
+}
+
+// IncrementalStats counts what an IncrementalReconciler has seen since it was
+// first started
+type IncrementalStats struct {
+	TransactionsReceived int64 `json:"transactions_received"`
+	StatementsReceived   int64 `json:"statements_received"`
+	Matches              int64 `json:"matches"`
+	ManualMatches        int64 `json:"manual_matches"`
+	IgnoredStatements    int64 `json:"ignored_statements"`
+	OpenTransactions     int   `json:"open_transactions"`
+	OpenStatements       int   `json:"open_statements"`
+}
+
+// incrementalSnapshot is the on-disk state of an IncrementalReconciler. Open
+// items are listed in arrival order.
+type incrementalSnapshot struct {
+	Version      int                     `json:"version"`
+	SavedAt      time.Time               `json:"saved_at"`
+	Stats        IncrementalStats        `json:"stats"`
+	Transactions []*models.Transaction   `json:"open_transactions"`
+	Statements   []*models.BankStatement `json:"open_statements"`
+}
+
+// NewIncrementalReconciler creates an incremental reconciler with no open items
+func NewIncrementalReconciler(config *MatchingConfig) (*IncrementalReconciler, error) {
+	if config == nil {
+		config = DefaultMatchingConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, errors.ConfigurationError(
+			errors.CodeInvalidConfig,
+			"matching_config",
+			nil,
+			err,
+		).WithSuggestion("Check the matching configuration")
+	}
+
+	engine := NewMatchingEngine(config)
+	engine.TransactionIndex = newTransactionIndex(nil, engine.currency)
+	engine.BankStatementIndex = newBankStatementIndex(nil, engine.currency)
+	engine.overrides = &ruleOverrides{
+		claimedTransactions: make(map[*models.Transaction]bool),
+		removedStatements:   make(map[*models.BankStatement]bool),
+		excludedPairs:       make(map[string]bool),
+	}
+
+	ir := &IncrementalReconciler{
+		engine:                  engine,
+		logger:                  logger.GetGlobalLogger().WithComponent("incremental_reconciler"),
+		transactions:            make(map[*models.Transaction]int64),
+		statements:              make(map[*models.BankStatement]int64),
+		transactionsByID:        make(map[string]*models.Transaction),
+		statementsByID:          make(map[string][]*models.BankStatement),
+		transactionsByReference: make(map[string][]*models.Transaction),
+		rulesByTransaction:      make(map[string][]*rules.PairRule),
+		rulesByStatement:        make(map[string][]*rules.PairRule),
+	}
+
+	if set := config.Rules; set != nil {
+		for _, rule := range set.Exclusions {
+			engine.overrides.excludedPairs[pairKey(rule.TrxID, rule.StatementID)] = true
+		}
+		for _, rule := range set.Matches {
+			ir.rulesByTransaction[rule.TrxID] = append(ir.rulesByTransaction[rule.TrxID], rule)
+			ir.rulesByStatement[rule.StatementID] = append(ir.rulesByStatement[rule.StatementID], rule)
+		}
+	}
+
+	return ir, nil
+}
+
+// LoadIncrementalReconciler restores an incremental reconciler from a
+// snapshot written by SaveSnapshot. A missing file gives a reconciler with no
+// open items. Open items are restored as they were, without matching them
+// against each other again.
+func LoadIncrementalReconciler(path string, config *MatchingConfig) (*IncrementalReconciler, error) {
+	ir, err := NewIncrementalReconciler(config)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ir, nil
+	}
+	if err != nil {
+		return nil, errors.FileError(errors.CodeFileNotFound, path, err)
+	}
+
+	var snapshot incrementalSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.FileError(errors.CodeFileCorrupted, path, err).
+			WithSuggestion("Restore the snapshot from a backup or remove it to start with no open items")
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, errors.FileError(errors.CodeFileCorrupted, path,
+			fmt.Errorf("unsupported snapshot version %d", snapshot.Version))
+	}
+
+	for _, tx := range snapshot.Transactions {
+		ir.engine.currency.addTransaction(tx)
+		ir.open(tx)
+	}
+	for _, stmt := range snapshot.Statements {
+		ir.engine.currency.addStatement(stmt)
+		ir.openStatement(stmt)
+	}
+	ir.stats = snapshot.Stats
+	ir.stats.OpenTransactions = len(ir.transactions)
+	ir.stats.OpenStatements = len(ir.statements)
+
+	ir.logger.WithFields(logger.Fields{
+		"path":              path,
+		"open_transactions": len(ir.transactions),
+		"open_statements":   len(ir.statements),
+	}).Info("Restored incremental reconciler from snapshot")
+
+	return ir, nil
+}
+
+// SaveSnapshot writes the open items and counters to a file. The snapshot is
+// written and synced to a temporary file first so a crash never leaves a
+// partial one. Saves are serialised from capture to rename, so a save never
+// replaces the snapshot of a later one with older state.
+func (ir *IncrementalReconciler) SaveSnapshot(path string) error {
+	ir.saveMu.Lock()
+	defer ir.saveMu.Unlock()
+
+	ir.mu.Lock()
+	snapshot := incrementalSnapshot{
+		Version:      snapshotVersion,
+		SavedAt:      time.Now().UTC(),
+		Stats:        ir.stats,
+		Transactions: ir.openTransactions(),
+		Statements:   ir.openStatements(),
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	ir.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := writeSnapshotFile(tmp, data); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// writeSnapshotFile writes data to path and syncs it to disk before closing,
+// so the rename that follows never exposes an empty or partial file
+func writeSnapshotFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// AddTransaction matches a transaction against the open bank statements. It
+// returns the match, or nil when the transaction was left open.
+func (ir *IncrementalReconciler) AddTransaction(tx *models.Transaction) (*MatchResult, error) {
+	if tx == nil {
+		return nil, errors.ValidationError(
+			errors.CodeMissingField,
+			"transaction",
+			nil,
+			nil,
+		).WithSuggestion("Provide a valid transaction")
+	}
+
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	if _, exists := ir.transactionsByID[tx.TrxID]; exists {
+		return nil, errors.ValidationError(
+			errors.CodeInvalidData,
+			"trx_id",
+			tx.TrxID,
+			fmt.Errorf("transaction %s is already open", tx.TrxID),
+		).WithSuggestion("Send each transaction once")
+	}
+	ir.stats.TransactionsReceived++
+
+	me := ir.engine
+	me.currency.addTransaction(tx)
+
+	result := ir.manualMatchForTransaction(tx)
+	if result == nil && len(ir.rulesByTransaction[tx.TrxID]) == 0 {
+		var best *models.BankStatement
+		var rejected []*RejectedCandidate
+		for _, stmt := range me.BankStatementIndex.GetCandidates(tx, me.Config) {
+			// A statement named by a match rule waits for its own transaction
+			if !me.overrides.available(tx, stmt) || len(ir.rulesByStatement[stmt.UniqueIdentifier]) > 0 {
+				rejected = append(rejected, &RejectedCandidate{Statement: stmt, Reason: RejectionExcludedByRule})
+				continue
+			}
+			scored, err := me.scoreMatch(tx, stmt)
+			if err != nil {
+				ir.logger.WithError(err).WithFields(logger.Fields{
+					"transaction_id": tx.TrxID,
+					"statement_id":   stmt.UniqueIdentifier,
+				}).Warn("Failed to score match")
+				continue
+			}
+			if scored.ConfidenceScore < me.Config.MinConfidenceScore {
+				rejected = append(rejected, &RejectedCandidate{Statement: stmt, ConfidenceScore: scored.ConfidenceScore,
+					Reason: RejectionBelowMinConfidence})
+				continue
+			}
+			if result == nil || scored.ConfidenceScore > result.ConfidenceScore ||
+				(scored.ConfidenceScore == result.ConfidenceScore && ir.statements[stmt] < ir.statements[best]) {
+				scored, result, best = result, scored, stmt
+			}
+			if scored != nil {
+				rejected = append(rejected, &RejectedCandidate{Statement: scored.BankStatement, ConfidenceScore: scored.ConfidenceScore,
+					Reason: RejectionOutscored})
+			}
+		}
+		if result != nil {
+			sortRejected(rejected)
+			result.Breakdown.Rejected = rejected
+		}
+	}
+
+	if result == nil {
+		ir.open(tx)
+		return nil, nil
+	}
+
+	ir.closeStatement(result.BankStatement)
+	me.currency.forgetTransaction(tx)
+	ir.recordMatch(result)
+	return result, nil
+}
+
+// AddStatement matches a bank statement against the open transactions. It
+// returns the match, or nil when the statement was left open or an ignore
+// rule selected it.
+func (ir *IncrementalReconciler) AddStatement(stmt *models.BankStatement) (*MatchResult, error) {
+	if stmt == nil {
+		return nil, errors.ValidationError(
+			errors.CodeMissingField,
+			"bank_statement",
+			nil,
+			nil,
+		).WithSuggestion("Provide a valid bank statement")
+	}
+
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	for _, open := range ir.statementsByID[stmt.UniqueIdentifier] {
+		if open.BankName == stmt.BankName {
+			return nil, errors.ValidationError(
+				errors.CodeInvalidData,
+				"unique_identifier",
+				stmt.UniqueIdentifier,
+				fmt.Errorf("bank statement %s is already open", stmt.UniqueIdentifier),
+			).WithSuggestion("Send each bank statement once")
+		}
+	}
+	ir.stats.StatementsReceived++
+
+	me := ir.engine
+	me.currency.addStatement(stmt)
+	result := ir.manualMatchForStatement(stmt)
+	if result == nil && me.Config.Rules != nil {
+		if rule := me.Config.Rules.IgnoreRuleFor(stmt); rule != nil {
+			ir.stats.IgnoredStatements++
+			ir.logger.WithFields(logger.Fields{
+				"statement_id": stmt.UniqueIdentifier,
+				"rule":         rule.Name,
+			}).Debug("Ignored bank statement")
+			me.currency.forgetStatement(stmt)
+			return nil, nil
+		}
+	}
+
+	if result == nil && len(ir.rulesByStatement[stmt.UniqueIdentifier]) == 0 {
+		var best *models.Transaction
+		var rejected []*RejectedCandidate
+		for _, tx := range ir.transactionCandidates(stmt) {
+			// A transaction named by a match rule waits for its own statement
+			if !me.overrides.available(tx, stmt) || len(ir.rulesByTransaction[tx.TrxID]) > 0 {
+				rejected = append(rejected, &RejectedCandidate{Transaction: tx, Reason: RejectionExcludedByRule})
+				continue
+			}
+			scored, err := me.scoreMatch(tx, stmt)
+			if err != nil {
+				ir.logger.WithError(err).WithFields(logger.Fields{
+					"transaction_id": tx.TrxID,
+					"statement_id":   stmt.UniqueIdentifier,
+				}).Warn("Failed to score match")
+				continue
+			}
+			if scored.ConfidenceScore < me.Config.MinConfidenceScore {
+				rejected = append(rejected, &RejectedCandidate{Transaction: tx, ConfidenceScore: scored.ConfidenceScore,
+					Reason: RejectionBelowMinConfidence})
+				continue
+			}
+			if result == nil || scored.ConfidenceScore > result.ConfidenceScore ||
+				(scored.ConfidenceScore == result.ConfidenceScore && ir.transactions[tx] < ir.transactions[best]) {
+				scored, result, best = result, scored, tx
+			}
+			if scored != nil {
+				rejected = append(rejected, &RejectedCandidate{Transaction: scored.Transaction, ConfidenceScore: scored.ConfidenceScore,
+					Reason: RejectionOutscored})
+			}
+		}
+		if result != nil {
+			sortRejected(rejected)
+			result.Breakdown.Rejected = rejected
+		}
+	}
+
+	if result == nil {
+		ir.openStatement(stmt)
+		return nil, nil
+	}
+
+	ir.closeTransaction(result.Transaction)
+	me.currency.forgetStatement(stmt)
+	ir.recordMatch(result)
+	return result, nil
+}
+
+// transactionCandidates returns the open transactions a statement may match.
+// Transactions whose ID the statement carries come first, as they do for
+// transactions looking up statements.
+func (ir *IncrementalReconciler) transactionCandidates(stmt *models.BankStatement) []*models.Transaction {
+	me := ir.engine
+	candidates := me.TransactionIndex.GetCandidates(stmt, me.Config)
+	if me.Config.Weights.IdentifierWeight == 0 {
+		return candidates
+	}
+
+	var referenced []*models.Transaction
+	seen := make(map[*models.Transaction]bool)
+	for _, reference := range statementReferences(stmt) {
+		for _, tx := range ir.transactionsByReference[reference] {
+			if !seen[tx] && me.currency.comparable(tx, stmt) {
+				referenced = append(referenced, tx)
+				seen[tx] = true
+			}
+		}
+	}
+	for _, tx := range candidates {
+		if !seen[tx] {
+			referenced = append(referenced, tx)
+		}
+	}
+	return referenced
+}
+
+// manualMatchForTransaction pairs a transaction with an open statement named
+// by a match rule
+func (ir *IncrementalReconciler) manualMatchForTransaction(tx *models.Transaction) *MatchResult {
+	for _, rule := range ir.rulesByTransaction[tx.TrxID] {
+		if open := ir.statementsByID[rule.StatementID]; len(open) > 0 {
+			return ir.manualMatch(tx, open[0], rule)
+		}
+	}
+	return nil
+}
+
+// manualMatchForStatement pairs a statement with an open transaction named by
+// a match rule
+func (ir *IncrementalReconciler) manualMatchForStatement(stmt *models.BankStatement) *MatchResult {
+	for _, rule := range ir.rulesByStatement[stmt.UniqueIdentifier] {
+		if tx := ir.transactionsByID[rule.TrxID]; tx != nil {
+			return ir.manualMatch(tx, stmt, rule)
+		}
+	}
+	return nil
+}
+
+// manualMatch scores a pair forced by a match rule, as applyRules does
+func (ir *IncrementalReconciler) manualMatch(tx *models.Transaction, stmt *models.BankStatement, rule *rules.PairRule) *MatchResult {
+	result, err := ir.engine.scoreMatch(tx, stmt)
+	if err != nil {
+		ir.logger.WithError(err).WithField("rule", rule.Name).Warn("Failed to score manual match")
+		return nil
+	}
+	result.MatchType = MatchManual
+	result.Rule = rule.Name
+	result.Reasons = append([]string{fmt.Sprintf("Manual match by rule %q", rule.Name)}, result.Reasons...)
+	return result
+}
+
+func (ir *IncrementalReconciler) recordMatch(result *MatchResult) {
+	ir.stats.Matches++
+	if result.MatchType == MatchManual {
+		ir.stats.ManualMatches++
+	}
+	ir.stats.OpenTransactions = len(ir.transactions)
+	ir.stats.OpenStatements = len(ir.statements)
+
+	ir.logger.WithFields(logger.Fields{
+		"transaction_id":   result.Transaction.TrxID,
+		"statement_id":     result.BankStatement.UniqueIdentifier,
+		"match_type":       result.MatchType,
+		"confidence_score": result.ConfidenceScore,
+	}).Debug("Matched incoming item")
+}
+
+// open indexes a transaction that found no partner
+func (ir *IncrementalReconciler) open(tx *models.Transaction) {
+	ir.engine.TransactionIndex.AddTransaction(tx)
+	ir.sequence++
+	ir.transactions[tx] = ir.sequence
+	ir.transactionsByID[tx.TrxID] = tx
+	if reference := NormalizeReference(tx.TrxID); reference != "" {
+		ir.transactionsByReference[reference] = append(ir.transactionsByReference[reference], tx)
+	}
+	ir.stats.OpenTransactions = len(ir.transactions)
+}
+
+// openStatement indexes a bank statement that found no partner
+func (ir *IncrementalReconciler) openStatement(stmt *models.BankStatement) {
+	ir.engine.BankStatementIndex.AddStatement(stmt)
+	ir.sequence++
+	ir.statements[stmt] = ir.sequence
+	ir.statementsByID[stmt.UniqueIdentifier] = append(ir.statementsByID[stmt.UniqueIdentifier], stmt)
+	ir.stats.OpenStatements = len(ir.statements)
+}
+
+// closeTransaction removes a matched transaction from the open items
+func (ir *IncrementalReconciler) closeTransaction(tx *models.Transaction) {
+	ir.engine.TransactionIndex.RemoveTransaction(tx)
+	ir.engine.currency.forgetTransaction(tx)
+	delete(ir.transactions, tx)
+	delete(ir.transactionsByID, tx.TrxID)
+	if reference := NormalizeReference(tx.TrxID); reference != "" {
+		removeFromBucket(ir.transactionsByReference, reference, tx)
+	}
+}
+
+// closeStatement removes a matched bank statement from the open items
+func (ir *IncrementalReconciler) closeStatement(stmt *models.BankStatement) {
+	ir.engine.BankStatementIndex.RemoveStatement(stmt)
+	ir.engine.currency.forgetStatement(stmt)
+	delete(ir.statements, stmt)
+	removeFromBucket(ir.statementsByID, stmt.UniqueIdentifier, stmt)
+}
+
+// OpenTransactions returns the transactions still waiting for a bank
+// statement, in arrival order
+func (ir *IncrementalReconciler) OpenTransactions() []*models.Transaction {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	return ir.openTransactions()
+}
+
+// OpenStatements returns the bank statements still waiting for a
+// transaction, in arrival order
+func (ir *IncrementalReconciler) OpenStatements() []*models.BankStatement {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	return ir.openStatements()
+}
+
+func (ir *IncrementalReconciler) openTransactions() []*models.Transaction {
+	transactions := make([]*models.Transaction, 0, len(ir.transactions))
+	for tx := range ir.transactions {
+		transactions = append(transactions, tx)
+	}
+	slices.SortFunc(transactions, func(a, b *models.Transaction) int {
+		return int(ir.transactions[a] - ir.transactions[b])
+	})
+	return transactions
+}
+
+func (ir *IncrementalReconciler) openStatements() []*models.BankStatement {
+	statements := make([]*models.BankStatement, 0, len(ir.statements))
+	for stmt := range ir.statements {
+		statements = append(statements, stmt)
+	}
+	slices.SortFunc(statements, func(a, b *models.BankStatement) int {
+		return int(ir.statements[a] - ir.statements[b])
+	})
+	return statements
+}
+
+// Stats returns the counters and the number of open items
+func (ir *IncrementalReconciler) Stats() IncrementalStats {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	return ir.stats
+}
@@ -0,0 +1,290 @@
+package matcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/rules"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestIncrementalReconciler(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	tx := func(id string, amount int64, txType models.TransactionType) *models.Transaction {
+		return &models.Transaction{TrxID: id, Amount: decimal.NewFromInt(amount), Type: txType, TransactionTime: day}
+	}
+	stmt := func(id string, amount int64) *models.BankStatement {
+		return &models.BankStatement{UniqueIdentifier: id, Amount: decimal.NewFromInt(amount), Date: day}
+	}
+
+	set, err := rules.ReadCSV(strings.NewReader(`action,trx_id,statement_id,id_prefix,name
+match,TX010,BS010,,agreed with treasury
+exclude,TX020,BS020,,
+ignore,,,FEE-,bank fees
+`))
+	if err != nil {
+		t.Fatalf("Failed to read rules: %v", err)
+	}
+
+	// arrival is one item arriving: a transaction or a statement, and the
+	// identifier of the item it should be matched with, if any
+	type arrival struct {
+		tx      *models.Transaction
+		stmt    *models.BankStatement
+		partner string
+	}
+
+	tests := []struct {
+		name          string
+		configure     func(*MatchingConfig)
+		arrivals      []arrival
+		openTx        []string
+		openStmt      []string
+		wantManual    int64
+		wantIgnored   int64
+		wantErrorOnID string
+	}{
+		{
+			name: "statement before transaction",
+			arrivals: []arrival{
+				{stmt: stmt("BS001", 100)},
+				{tx: tx("TX001", 100, models.TransactionTypeCredit), partner: "BS001"},
+			},
+		},
+		{
+			name: "ties go to the first arrival",
+			arrivals: []arrival{
+				{tx: tx("TX001", 100, models.TransactionTypeCredit)},
+				{tx: tx("TX002", 100, models.TransactionTypeCredit)},
+				{stmt: stmt("BS001", 100), partner: "TX001"},
+				{stmt: stmt("BS002", 100), partner: "TX002"},
+			},
+		},
+		{
+			name: "best score wins over arrival order",
+			arrivals: []arrival{
+				{stmt: stmt("BS001", 99)},
+				{stmt: stmt("BS002", 100)},
+				{tx: tx("TX001", 100, models.TransactionTypeCredit), partner: "BS002"},
+			},
+			openStmt: []string{"BS001"},
+		},
+		{
+			name: "unmatched items stay open in arrival order",
+			arrivals: []arrival{
+				{tx: tx("TX002", 500, models.TransactionTypeCredit)},
+				{stmt: stmt("BS001", -300)},
+				{tx: tx("TX001", 300, models.TransactionTypeDebit), partner: "BS001"},
+				{tx: tx("TX003", 700, models.TransactionTypeCredit)},
+			},
+			openTx: []string{"TX002", "TX003"},
+		},
+		{
+			name:      "rules",
+			configure: func(c *MatchingConfig) { c.Rules = set },
+			arrivals: []arrival{
+				{stmt: stmt("BS011", 100)},
+				// TX010 waits for BS010 rather than taking the exact match
+				{tx: tx("TX010", 100, models.TransactionTypeCredit)},
+				{tx: tx("TX011", 100, models.TransactionTypeCredit), partner: "BS011"},
+				{stmt: stmt("BS010", 90), partner: "TX010"},
+				{stmt: stmt("FEE-01", 300)},
+				{tx: tx("TX020", 300, models.TransactionTypeCredit)},
+				{stmt: stmt("BS020", 300)},
+			},
+			openTx:      []string{"TX020"},
+			openStmt:    []string{"BS020"},
+			wantManual:  1,
+			wantIgnored: 1,
+		},
+		{
+			name:      "statement named by a match rule waits for its transaction",
+			configure: func(c *MatchingConfig) { c.Rules = set },
+			arrivals: []arrival{
+				{stmt: stmt("BS010", 100)},
+				{tx: tx("TX012", 100, models.TransactionTypeCredit)},
+				{tx: tx("TX010", 50, models.TransactionTypeCredit), partner: "BS010"},
+				{tx: tx("TX013", 70, models.TransactionTypeCredit)},
+			},
+			openTx:     []string{"TX012", "TX013"},
+			wantManual: 1,
+		},
+		{
+			name:      "transaction named by a match rule waits for its statement",
+			configure: func(c *MatchingConfig) { c.Rules = set },
+			arrivals: []arrival{
+				{tx: tx("TX010", 100, models.TransactionTypeCredit)},
+				{stmt: stmt("BS012", 100)},
+				{stmt: stmt("BS010", 50), partner: "TX010"},
+			},
+			openStmt:   []string{"BS012"},
+			wantManual: 1,
+		},
+		{
+			name: "statements carrying the transaction ID",
+			configure: func(c *MatchingConfig) {
+				c.Weights = c.Weights.WithIdentifierWeight(0.4)
+			},
+			arrivals: []arrival{
+				{tx: tx("INV-2024-0042", 100, models.TransactionTypeCredit)},
+				{tx: tx("TX002", 100, models.TransactionTypeCredit)},
+				{stmt: &models.BankStatement{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: day.AddDate(0, 0, 20),
+					Description: "Payment INV2024/0042"}, partner: "INV-2024-0042"},
+			},
+			openTx: []string{"TX002"},
+		},
+		{
+			name: "duplicate open transaction",
+			arrivals: []arrival{
+				{tx: tx("TX001", 100, models.TransactionTypeCredit)},
+				{tx: tx("TX001", 200, models.TransactionTypeCredit)},
+			},
+			openTx:        []string{"TX001"},
+			wantErrorOnID: "TX001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			if tt.configure != nil {
+				tt.configure(config)
+			}
+			ir, err := NewIncrementalReconciler(config)
+			if err != nil {
+				t.Fatalf("Failed to create incremental reconciler: %v", err)
+			}
+
+			var gotError string
+			var matches int64
+			for i, a := range tt.arrivals {
+				var match *MatchResult
+				var err error
+				var partner string
+				if a.tx != nil {
+					match, err = ir.AddTransaction(a.tx)
+					if match != nil {
+						partner = match.BankStatement.UniqueIdentifier
+					}
+				} else {
+					match, err = ir.AddStatement(a.stmt)
+					if match != nil {
+						partner = match.Transaction.TrxID
+					}
+				}
+				if err != nil {
+					if a.tx != nil {
+						gotError = a.tx.TrxID
+					}
+					continue
+				}
+				if partner != a.partner {
+					t.Errorf("Arrival %d matched %q, want %q", i, partner, a.partner)
+				}
+				if match != nil {
+					matches++
+				}
+			}
+			if gotError != tt.wantErrorOnID {
+				t.Errorf("Rejected %q, want %q", gotError, tt.wantErrorOnID)
+			}
+
+			var openTx, openStmt []string
+			for _, tx := range ir.OpenTransactions() {
+				openTx = append(openTx, tx.TrxID)
+			}
+			for _, stmt := range ir.OpenStatements() {
+				openStmt = append(openStmt, stmt.UniqueIdentifier)
+			}
+			if strings.Join(openTx, ",") != strings.Join(tt.openTx, ",") {
+				t.Errorf("Open transactions = %v, want %v", openTx, tt.openTx)
+			}
+			if strings.Join(openStmt, ",") != strings.Join(tt.openStmt, ",") {
+				t.Errorf("Open statements = %v, want %v", openStmt, tt.openStmt)
+			}
+
+			stats := ir.Stats()
+			if stats.Matches != matches || stats.ManualMatches != tt.wantManual || stats.IgnoredStatements != tt.wantIgnored {
+				t.Errorf("Stats = %+v, want %d matches, %d manual and %d ignored", stats, matches, tt.wantManual, tt.wantIgnored)
+			}
+			if stats.OpenTransactions != len(tt.openTx) || stats.OpenStatements != len(tt.openStmt) {
+				t.Errorf("Stats count %d/%d open items, want %d/%d", stats.OpenTransactions, stats.OpenStatements, len(tt.openTx), len(tt.openStmt))
+			}
+			if index := ir.engine.TransactionIndex; len(index.AllTransactions) != len(tt.openTx) {
+				t.Errorf("Transaction index holds %d items, want %d", len(index.AllTransactions), len(tt.openTx))
+			}
+			if index := ir.engine.BankStatementIndex; len(index.AllStatements) != len(tt.openStmt) {
+				t.Errorf("Statement index holds %d items, want %d", len(index.AllStatements), len(tt.openStmt))
+			}
+		})
+	}
+}
+
+func TestIncrementalReconciler_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	// A missing snapshot starts empty
+	ir, err := LoadIncrementalReconciler(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to start without a snapshot: %v", err)
+	}
+
+	for i, amount := range []int64{100, 200, 300} {
+		tx := &models.Transaction{TrxID: "TX00" + string(rune('1'+i)), Amount: decimal.NewFromInt(amount),
+			Type: models.TransactionTypeCredit, TransactionTime: day, Description: "order"}
+		if _, err := ir.AddTransaction(tx); err != nil {
+			t.Fatalf("Failed to add transaction: %v", err)
+		}
+	}
+	stmt := &models.BankStatement{UniqueIdentifier: "BS009", Amount: decimal.NewFromInt(-50), Date: day, BankName: "standard"}
+	if _, err := ir.AddStatement(stmt); err != nil {
+		t.Fatalf("Failed to add statement: %v", err)
+	}
+	if err := ir.SaveSnapshot(path); err != nil {
+		t.Fatalf("Failed to save snapshot: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected the temporary snapshot file to be renamed")
+	}
+
+	restored, err := LoadIncrementalReconciler(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to load snapshot: %v", err)
+	}
+	if restored.Stats() != ir.Stats() {
+		t.Errorf("Restored stats %+v, want %+v", restored.Stats(), ir.Stats())
+	}
+	open := restored.OpenTransactions()
+	if len(open) != 3 || open[0].TrxID != "TX001" || open[2].TrxID != "TX003" || open[1].Description != "order" {
+		t.Fatalf("Restored open transactions out of order or incomplete: %v", open)
+	}
+	if statements := restored.OpenStatements(); len(statements) != 1 || statements[0].BankName != "standard" {
+		t.Fatalf("Restored open statements incomplete: %v", statements)
+	}
+
+	// Restored items are indexed and matched as before
+	match, err := restored.AddStatement(&models.BankStatement{UniqueIdentifier: "BS002", Amount: decimal.NewFromInt(200), Date: day})
+	if err != nil {
+		t.Fatalf("Failed to add statement: %v", err)
+	}
+	if match == nil || match.Transaction.TrxID != "TX002" || match.MatchType != MatchExact {
+		t.Fatalf("Expected an exact match with TX002, got %+v", match)
+	}
+	if _, err := restored.AddTransaction(&models.Transaction{TrxID: "TX001", Amount: decimal.NewFromInt(1),
+		Type: models.TransactionTypeCredit, TransactionTime: day}); err == nil {
+		t.Error("Expected a restored open transaction to be rejected as a duplicate")
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version": 99}`), 0o644); err != nil {
+		t.Fatalf("Failed to write snapshot: %v", err)
+	}
+	if _, err := LoadIncrementalReconciler(path, nil); err == nil {
+		t.Error("Expected an unsupported snapshot version to be rejected")
+	}
+}
@@ -1,7 +1,7 @@
 package matcher
 
 import (
-	"sort"
+	"slices"
 	"time"
 
 	"golang-reconciliation-service/internal/models"
@@ -17,14 +17,27 @@ type TransactionIndex struct {
 	// DateIndex maps date strings (YYYY-MM-DD) to transaction slices
 	DateIndex map[string][]*models.Transaction
 	
-	// AmountRangeIndex provides sorted amounts for range-based lookups
-	AmountRangeIndex []*AmountIndexEntry
+	// AmountRangeIndex orders amounts for range-based lookups
+	AmountRangeIndex *AmountTree[*AmountIndexEntry]
 	
 	// TypeIndex maps transaction types to transaction slices
 	TypeIndex map[models.TransactionType][]*models.Transaction
 	
 	// AllTransactions holds all indexed transactions
 	AllTransactions []*models.Transaction
+	
+	// converter supplies base-currency amounts; nil indexes original amounts
+	converter *currencyConverter
+	
+	// positions locates transactions in AllTransactions and TypeIndex for
+	// removal; it is built on the first removal
+	positions map[*models.Transaction]transactionPosition
+}
+
+// transactionPosition is where a transaction sits in the slices it is listed in
+type transactionPosition struct {
+	all    int
+	byType int
 }
 
 // AmountIndexEntry represents an entry in the sorted amount index
@@ -41,11 +54,22 @@ type BankStatementIndex struct {
 	// DateIndex maps date strings (YYYY-MM-DD) to bank statement slices
 	DateIndex map[string][]*models.BankStatement
 	
-	// AmountRangeIndex provides sorted amounts for range-based lookups
-	AmountRangeIndex []*BankAmountIndexEntry
+	// AmountRangeIndex orders amounts for range-based lookups
+	AmountRangeIndex *AmountTree[*BankAmountIndexEntry]
 	
 	// AllStatements holds all indexed bank statements
 	AllStatements []*models.BankStatement
+	
+	// ReferenceIndex maps normalised references (identifiers and description
+	// words, see NormalizeReference) to bank statement slices
+	ReferenceIndex map[string][]*models.BankStatement
+	
+	// converter supplies base-currency amounts; nil indexes original amounts
+	converter *currencyConverter
+	
+	// positions locates statements in AllStatements for removal; it is built
+	// on the first removal
+	positions map[*models.BankStatement]int
 }
 
 // BankAmountIndexEntry represents an entry in the sorted bank statement amount index
@@ -56,11 +80,18 @@ type BankAmountIndexEntry struct {
 
 // NewTransactionIndex creates a new transaction index from a slice of transactions
 func NewTransactionIndex(transactions []*models.Transaction) *TransactionIndex {
+	return newTransactionIndex(transactions, nil)
+}
+
+// newTransactionIndex creates a transaction index keyed on the amounts
+// supplied by the converter
+func newTransactionIndex(transactions []*models.Transaction, converter *currencyConverter) *TransactionIndex {
 	index := &TransactionIndex{
 		ExactAmountIndex: make(map[string][]*models.Transaction),
 		DateIndex:        make(map[string][]*models.Transaction),
 		TypeIndex:        make(map[models.TransactionType][]*models.Transaction),
 		AllTransactions:  transactions,
+		converter:        converter,
 	}
 	
 	index.buildIndexes()
@@ -69,93 +100,89 @@ func NewTransactionIndex(transactions []*models.Transaction) *TransactionIndex {
 
 // NewBankStatementIndex creates a new bank statement index from a slice of statements
 func NewBankStatementIndex(statements []*models.BankStatement) *BankStatementIndex {
+	return newBankStatementIndex(statements, nil)
+}
+
+// newBankStatementIndex creates a bank statement index keyed on the amounts
+// supplied by the converter
+func newBankStatementIndex(statements []*models.BankStatement, converter *currencyConverter) *BankStatementIndex {
 	index := &BankStatementIndex{
 		ExactAmountIndex: make(map[string][]*models.BankStatement),
 		DateIndex:        make(map[string][]*models.BankStatement),
 		AllStatements:    statements,
+		converter:        converter,
 	}
 	
 	index.buildIndexes()
+	index.buildReferenceIndex()
 	return index
 }
 
 // buildIndexes constructs all internal indexes for transactions
 func (ti *TransactionIndex) buildIndexes() {
-	// Build amount range index with unique amounts
-	amountMap := make(map[string]*AmountIndexEntry)
+	ti.AmountRangeIndex = &AmountTree[*AmountIndexEntry]{}
 	
 	for _, tx := range ti.AllTransactions {
-		amountKey := tx.Amount.String()
-		dateKey := tx.TransactionTime.Format("2006-01-02")
-		
-		// Exact amount index
-		ti.ExactAmountIndex[amountKey] = append(ti.ExactAmountIndex[amountKey], tx)
-		
-		// Date index
-		ti.DateIndex[dateKey] = append(ti.DateIndex[dateKey], tx)
-		
-		// Type index
-		ti.TypeIndex[tx.Type] = append(ti.TypeIndex[tx.Type], tx)
-		
-		// Amount range index
-		if entry, exists := amountMap[amountKey]; exists {
-			entry.Transactions = append(entry.Transactions, tx)
-		} else {
-			amountMap[amountKey] = &AmountIndexEntry{
-				Amount:       tx.Amount,
-				Transactions: []*models.Transaction{tx},
-			}
-		}
+		ti.indexTransaction(tx)
 	}
+}
+
+// indexTransaction adds a transaction to every index except AllTransactions
+func (ti *TransactionIndex) indexTransaction(tx *models.Transaction) {
+	amount := ti.converter.transactionAmount(tx)
+	amountKey := amount.String()
+	dateKey := tx.TransactionTime.Format("2006-01-02")
 	
-	// Convert amount map to sorted slice
-	ti.AmountRangeIndex = make([]*AmountIndexEntry, 0, len(amountMap))
-	for _, entry := range amountMap {
-		ti.AmountRangeIndex = append(ti.AmountRangeIndex, entry)
-	}
+	// Exact amount index
+	ti.ExactAmountIndex[amountKey] = append(ti.ExactAmountIndex[amountKey], tx)
 	
-	// Sort by amount for efficient range queries
-	sort.Slice(ti.AmountRangeIndex, func(i, j int) bool {
-		return ti.AmountRangeIndex[i].Amount.LessThan(ti.AmountRangeIndex[j].Amount)
-	})
+	// Date index
+	ti.DateIndex[dateKey] = append(ti.DateIndex[dateKey], tx)
+	
+	// Type index
+	ti.TypeIndex[tx.Type] = append(ti.TypeIndex[tx.Type], tx)
+	
+	// Amount range index
+	if entry, exists := ti.AmountRangeIndex.Get(amount); exists {
+		entry.Transactions = append(entry.Transactions, tx)
+	} else {
+		ti.AmountRangeIndex.Put(amount, &AmountIndexEntry{
+			Amount:       amount,
+			Transactions: []*models.Transaction{tx},
+		})
+	}
 }
 
 // buildIndexes constructs all internal indexes for bank statements
 func (bsi *BankStatementIndex) buildIndexes() {
-	// Build amount range index with unique amounts
-	amountMap := make(map[string]*BankAmountIndexEntry)
+	bsi.AmountRangeIndex = &AmountTree[*BankAmountIndexEntry]{}
 	
 	for _, stmt := range bsi.AllStatements {
-		amountKey := stmt.Amount.String()
-		dateKey := stmt.Date.Format("2006-01-02")
-		
-		// Exact amount index
-		bsi.ExactAmountIndex[amountKey] = append(bsi.ExactAmountIndex[amountKey], stmt)
-		
-		// Date index
-		bsi.DateIndex[dateKey] = append(bsi.DateIndex[dateKey], stmt)
-		
-		// Amount range index
-		if entry, exists := amountMap[amountKey]; exists {
-			entry.Statements = append(entry.Statements, stmt)
-		} else {
-			amountMap[amountKey] = &BankAmountIndexEntry{
-				Amount:     stmt.Amount,
-				Statements: []*models.BankStatement{stmt},
-			}
-		}
+		bsi.indexStatement(stmt)
 	}
+}
+
+// indexStatement adds a statement to the amount and date indexes
+func (bsi *BankStatementIndex) indexStatement(stmt *models.BankStatement) {
+	amount := bsi.converter.statementAmount(stmt)
+	amountKey := amount.String()
+	dateKey := stmt.Date.Format("2006-01-02")
 	
-	// Convert amount map to sorted slice
-	bsi.AmountRangeIndex = make([]*BankAmountIndexEntry, 0, len(amountMap))
-	for _, entry := range amountMap {
-		bsi.AmountRangeIndex = append(bsi.AmountRangeIndex, entry)
-	}
+	// Exact amount index
+	bsi.ExactAmountIndex[amountKey] = append(bsi.ExactAmountIndex[amountKey], stmt)
 	
-	// Sort by amount for efficient range queries
-	sort.Slice(bsi.AmountRangeIndex, func(i, j int) bool {
-		return bsi.AmountRangeIndex[i].Amount.LessThan(bsi.AmountRangeIndex[j].Amount)
-	})
+	// Date index
+	bsi.DateIndex[dateKey] = append(bsi.DateIndex[dateKey], stmt)
+	
+	// Amount range index
+	if entry, exists := bsi.AmountRangeIndex.Get(amount); exists {
+		entry.Statements = append(entry.Statements, stmt)
+	} else {
+		bsi.AmountRangeIndex.Put(amount, &BankAmountIndexEntry{
+			Amount:     amount,
+			Statements: []*models.BankStatement{stmt},
+		})
+	}
 }
 
 // GetByExactAmount returns transactions with the exact amount
@@ -172,19 +199,11 @@ func (bsi *BankStatementIndex) GetByExactAmount(amount decimal.Decimal) []*model
 func (ti *TransactionIndex) GetByAmountRange(minAmount, maxAmount decimal.Decimal) []*models.Transaction {
 	var result []*models.Transaction
 	
-	// Find starting index using binary search
-	startIdx := sort.Search(len(ti.AmountRangeIndex), func(i int) bool {
-		return ti.AmountRangeIndex[i].Amount.GreaterThanOrEqual(minAmount)
-	})
-	
-	// Collect all transactions in range
-	for i := startIdx; i < len(ti.AmountRangeIndex); i++ {
-		entry := ti.AmountRangeIndex[i]
-		if entry.Amount.GreaterThan(maxAmount) {
-			break
-		}
+	// Collect all transactions in range, in ascending order of amount
+	ti.AmountRangeIndex.Ascend(minAmount, maxAmount, func(_ decimal.Decimal, entry *AmountIndexEntry) bool {
 		result = append(result, entry.Transactions...)
-	}
+		return true
+	})
 	
 	return result
 }
@@ -193,19 +212,11 @@ func (ti *TransactionIndex) GetByAmountRange(minAmount, maxAmount decimal.Decima
 func (bsi *BankStatementIndex) GetByAmountRange(minAmount, maxAmount decimal.Decimal) []*models.BankStatement {
 	var result []*models.BankStatement
 	
-	// Find starting index using binary search
-	startIdx := sort.Search(len(bsi.AmountRangeIndex), func(i int) bool {
-		return bsi.AmountRangeIndex[i].Amount.GreaterThanOrEqual(minAmount)
-	})
-	
-	// Collect all statements in range
-	for i := startIdx; i < len(bsi.AmountRangeIndex); i++ {
-		entry := bsi.AmountRangeIndex[i]
-		if entry.Amount.GreaterThan(maxAmount) {
-			break
-		}
+	// Collect all statements in range, in ascending order of amount
+	bsi.AmountRangeIndex.Ascend(minAmount, maxAmount, func(_ decimal.Decimal, entry *BankAmountIndexEntry) bool {
 		result = append(result, entry.Statements...)
-	}
+		return true
+	})
 	
 	return result
 }
@@ -265,23 +276,27 @@ func (ti *TransactionIndex) GetCandidates(stmt *models.BankStatement, config *Ma
 	var candidates []*models.Transaction
 	
 	// Calculate amount tolerance
-	tolerance := config.GetAmountTolerance(stmt.Amount.Abs())
-	minAmount := stmt.Amount.Abs().Sub(tolerance)
-	maxAmount := stmt.Amount.Abs().Add(tolerance)
-	
-	// Get transactions by amount range
-	amountCandidates := ti.GetByAmountRange(minAmount, maxAmount)
+	stmtAmount := ti.converter.statementAmount(stmt).Abs()
+	tolerance := config.GetAmountTolerance(stmtAmount)
+	minAmount := stmtAmount.Sub(tolerance)
+	maxAmount := stmtAmount.Add(tolerance)
+	
+	// Get transactions by amount range, skipping other currencies
+	var amountCandidates []*models.Transaction
+	for _, tx := range ti.GetByAmountRange(minAmount, maxAmount) {
+		if ti.converter.comparable(tx, stmt) {
+			amountCandidates = append(amountCandidates, tx)
+		}
+	}
 	
-	// Filter by date tolerance if specified
-	if config.DateToleranceDays > 0 {
+	// Filter by date tolerance or window if specified
+	if config.filtersByDate() {
 		var dateCandidates []*models.Transaction
 		
-		// Filter amount candidates by date range
+		// Filter amount candidates by date range, on the calendar of the statement's bank
+		// and in the date window of the pair
 		for _, tx := range amountCandidates {
-			normalizedTxDate := config.NormalizeTime(tx.TransactionTime)
-			normalizedStmtDate := config.NormalizeTime(stmt.Date)
-			
-			if config.IsWithinDateTolerance(normalizedTxDate, normalizedStmtDate) {
+			if config.isDateCandidate(tx, stmt) {
 				dateCandidates = append(dateCandidates, tx)
 			}
 		}
@@ -325,34 +340,38 @@ func (bsi *BankStatementIndex) GetCandidates(tx *models.Transaction, config *Mat
 	var candidates []*models.BankStatement
 	
 	// Calculate amount tolerance - need to consider both positive and negative amounts
-	tolerance := config.GetAmountTolerance(tx.Amount.Abs())
+	txAmount := bsi.converter.transactionAmount(tx)
+	tolerance := config.GetAmountTolerance(txAmount.Abs())
 	
 	// Bank statements may have negative amounts for debits
 	var minAmount, maxAmount decimal.Decimal
 	if tx.Type == models.TransactionTypeDebit {
 		// Look for negative amounts in bank statements
-		negAmount := tx.Amount.Neg()
+		negAmount := txAmount.Neg()
 		minAmount = negAmount.Sub(tolerance)
 		maxAmount = negAmount.Add(tolerance)
 	} else {
 		// Look for positive amounts in bank statements
-		minAmount = tx.Amount.Sub(tolerance)
-		maxAmount = tx.Amount.Add(tolerance)
+		minAmount = txAmount.Sub(tolerance)
+		maxAmount = txAmount.Add(tolerance)
 	}
 	
-	// Get statements by amount range
-	amountCandidates := bsi.GetByAmountRange(minAmount, maxAmount)
+	// Get statements by amount range, skipping other currencies
+	var amountCandidates []*models.BankStatement
+	for _, stmt := range bsi.GetByAmountRange(minAmount, maxAmount) {
+		if bsi.converter.comparable(tx, stmt) {
+			amountCandidates = append(amountCandidates, stmt)
+		}
+	}
 	
-	// Filter by date tolerance if specified
-	if config.DateToleranceDays > 0 {
+	// Filter by date tolerance or window if specified
+	if config.filtersByDate() {
 		var dateCandidates []*models.BankStatement
 		
-		// Filter amount candidates by date range
+		// Filter amount candidates by date range, on the calendar of the statement's bank
+		// and in the date window of the pair
 		for _, stmt := range amountCandidates {
-			normalizedTxDate := config.NormalizeTime(tx.TransactionTime)
-			normalizedStmtDate := config.NormalizeTime(stmt.Date)
-			
-			if config.IsWithinDateTolerance(normalizedTxDate, normalizedStmtDate) {
+			if config.isDateCandidate(tx, stmt) {
 				dateCandidates = append(dateCandidates, stmt)
 			}
 		}
@@ -362,6 +381,12 @@ func (bsi *BankStatementIndex) GetCandidates(tx *models.Transaction, config *Mat
 		candidates = amountCandidates
 	}
 	
+	// Statements carrying the transaction ID are candidates whatever their
+	// amount and date, and come first so the limit below cannot drop them
+	if config.Weights.IdentifierWeight > 0 {
+		candidates = bsi.prependReferenceCandidates(tx, candidates)
+	}
+	
 	// Limit number of candidates if specified
 	if config.MaxCandidatesPerTransaction > 0 && len(candidates) > config.MaxCandidatesPerTransaction {
 		candidates = candidates[:config.MaxCandidatesPerTransaction]
@@ -370,11 +395,34 @@ func (bsi *BankStatementIndex) GetCandidates(tx *models.Transaction, config *Mat
 	return candidates
 }
 
+// prependReferenceCandidates puts the statements that carry the transaction ID
+// ahead of the other candidates, without duplicates
+func (bsi *BankStatementIndex) prependReferenceCandidates(tx *models.Transaction, candidates []*models.BankStatement) []*models.BankStatement {
+	var referenced []*models.BankStatement
+	seen := make(map[*models.BankStatement]bool)
+	for _, stmt := range bsi.GetByReference(tx.TrxID) {
+		if bsi.converter.comparable(tx, stmt) {
+			referenced = append(referenced, stmt)
+			seen[stmt] = true
+		}
+	}
+	if len(referenced) == 0 {
+		return candidates
+	}
+	
+	for _, stmt := range candidates {
+		if !seen[stmt] {
+			referenced = append(referenced, stmt)
+		}
+	}
+	return referenced
+}
+
 // GetIndexStats returns statistics about the transaction index
 func (ti *TransactionIndex) GetIndexStats() IndexStats {
 	return IndexStats{
 		TotalTransactions:    len(ti.AllTransactions),
-		UniqueAmounts:        len(ti.AmountRangeIndex),
+		UniqueAmounts:        ti.AmountRangeIndex.Len(),
 		UniqueDates:         len(ti.DateIndex),
 		UniqueTypes:         len(ti.TypeIndex),
 	}
@@ -384,7 +432,7 @@ func (ti *TransactionIndex) GetIndexStats() IndexStats {
 func (bsi *BankStatementIndex) GetIndexStats() IndexStats {
 	return IndexStats{
 		TotalTransactions:    len(bsi.AllStatements),
-		UniqueAmounts:        len(bsi.AmountRangeIndex),
+		UniqueAmounts:        bsi.AmountRangeIndex.Len(),
 		UniqueDates:         len(bsi.DateIndex),
 		UniqueTypes:         0, // Bank statements don't have transaction types
 	}
@@ -398,91 +446,172 @@ type IndexStats struct {
 	UniqueTypes       int
 }
 
-// AddTransaction adds a new transaction to the index and rebuilds necessary indexes
+// AddTransaction adds a new transaction to the index. The amount range index
+// is a balanced tree, so this takes O(log n) in the number of distinct amounts.
 func (ti *TransactionIndex) AddTransaction(tx *models.Transaction) {
+	if ti.positions != nil {
+		ti.positions[tx] = transactionPosition{all: len(ti.AllTransactions), byType: len(ti.TypeIndex[tx.Type])}
+	}
 	ti.AllTransactions = append(ti.AllTransactions, tx)
-	
-	// Update exact amount index
-	amountKey := tx.Amount.String()
-	ti.ExactAmountIndex[amountKey] = append(ti.ExactAmountIndex[amountKey], tx)
-	
-	// Update date index
-	dateKey := tx.TransactionTime.Format("2006-01-02")
-	ti.DateIndex[dateKey] = append(ti.DateIndex[dateKey], tx)
-	
-	// Update type index
-	ti.TypeIndex[tx.Type] = append(ti.TypeIndex[tx.Type], tx)
-	
-	// Rebuild amount range index (could be optimized for better performance)
-	ti.buildAmountRangeIndex()
+	ti.indexTransaction(tx)
 }
 
-// AddStatement adds a new bank statement to the index and rebuilds necessary indexes
+// AddStatement adds a new bank statement to the index. The amount range index
+// is a balanced tree, so this takes O(log n) in the number of distinct amounts.
 func (bsi *BankStatementIndex) AddStatement(stmt *models.BankStatement) {
+	if bsi.positions != nil {
+		bsi.positions[stmt] = len(bsi.AllStatements)
+	}
 	bsi.AllStatements = append(bsi.AllStatements, stmt)
+	bsi.indexStatement(stmt)
+	
+	// Update reference index
+	seen := make(map[string]bool)
+	for _, reference := range statementReferences(stmt) {
+		if !seen[reference] {
+			seen[reference] = true
+			bsi.ReferenceIndex[reference] = append(bsi.ReferenceIndex[reference], stmt)
+		}
+	}
+}
+
+// RemoveTransaction removes a transaction from the index and reports whether
+// it was indexed. The amount range index is updated in O(log n); the exact
+// amount and date buckets keep their order. AllTransactions and TypeIndex are
+// not kept in input order: the last entry takes the removed one's place.
+func (ti *TransactionIndex) RemoveTransaction(tx *models.Transaction) bool {
+	if ti.positions == nil {
+		ti.buildPositions()
+	}
+	position, exists := ti.positions[tx]
+	if !exists {
+		return false
+	}
+	delete(ti.positions, tx)
+	
+	// All transactions
+	last := len(ti.AllTransactions) - 1
+	if moved := ti.AllTransactions[last]; position.all != last {
+		ti.AllTransactions[position.all] = moved
+		movedPosition := ti.positions[moved]
+		movedPosition.all = position.all
+		ti.positions[moved] = movedPosition
+	}
+	ti.AllTransactions = ti.AllTransactions[:last]
+	
+	// Type index
+	byType := ti.TypeIndex[tx.Type]
+	last = len(byType) - 1
+	if moved := byType[last]; position.byType != last {
+		byType[position.byType] = moved
+		movedPosition := ti.positions[moved]
+		movedPosition.byType = position.byType
+		ti.positions[moved] = movedPosition
+	}
+	if last == 0 {
+		delete(ti.TypeIndex, tx.Type)
+	} else {
+		ti.TypeIndex[tx.Type] = byType[:last]
+	}
 	
-	// Update exact amount index
-	amountKey := stmt.Amount.String()
-	bsi.ExactAmountIndex[amountKey] = append(bsi.ExactAmountIndex[amountKey], stmt)
+	amount := ti.converter.transactionAmount(tx)
+	removeFromBucket(ti.ExactAmountIndex, amount.String(), tx)
+	removeFromBucket(ti.DateIndex, tx.TransactionTime.Format("2006-01-02"), tx)
 	
-	// Update date index
-	dateKey := stmt.Date.Format("2006-01-02")
-	bsi.DateIndex[dateKey] = append(bsi.DateIndex[dateKey], stmt)
+	// Amount range index
+	if entry, exists := ti.AmountRangeIndex.Get(amount); exists {
+		entry.Transactions = removeItem(entry.Transactions, tx)
+		if len(entry.Transactions) == 0 {
+			ti.AmountRangeIndex.Delete(amount)
+		}
+	}
 	
-	// Rebuild amount range index (could be optimized for better performance)
-	bsi.buildAmountRangeIndex()
+	return true
 }
 
-// buildAmountRangeIndex rebuilds only the amount range index for transactions
-func (ti *TransactionIndex) buildAmountRangeIndex() {
-	amountMap := make(map[string]*AmountIndexEntry)
+// RemoveStatement removes a bank statement from the index and reports whether
+// it was indexed. The amount range index is updated in O(log n); the exact
+// amount, date and reference buckets keep their order. AllStatements is not
+// kept in input order: the last entry takes the removed one's place.
+func (bsi *BankStatementIndex) RemoveStatement(stmt *models.BankStatement) bool {
+	if bsi.positions == nil {
+		bsi.buildPositions()
+	}
+	position, exists := bsi.positions[stmt]
+	if !exists {
+		return false
+	}
+	delete(bsi.positions, stmt)
 	
-	for _, tx := range ti.AllTransactions {
-		amountKey := tx.Amount.String()
-		if entry, exists := amountMap[amountKey]; exists {
-			entry.Transactions = append(entry.Transactions, tx)
-		} else {
-			amountMap[amountKey] = &AmountIndexEntry{
-				Amount:       tx.Amount,
-				Transactions: []*models.Transaction{tx},
-			}
-		}
+	// All statements
+	last := len(bsi.AllStatements) - 1
+	if moved := bsi.AllStatements[last]; position != last {
+		bsi.AllStatements[position] = moved
+		bsi.positions[moved] = position
 	}
+	bsi.AllStatements = bsi.AllStatements[:last]
 	
-	// Convert to sorted slice
-	ti.AmountRangeIndex = make([]*AmountIndexEntry, 0, len(amountMap))
-	for _, entry := range amountMap {
-		ti.AmountRangeIndex = append(ti.AmountRangeIndex, entry)
+	amount := bsi.converter.statementAmount(stmt)
+	removeFromBucket(bsi.ExactAmountIndex, amount.String(), stmt)
+	removeFromBucket(bsi.DateIndex, stmt.Date.Format("2006-01-02"), stmt)
+	for _, reference := range statementReferences(stmt) {
+		removeFromBucket(bsi.ReferenceIndex, reference, stmt)
 	}
 	
-	sort.Slice(ti.AmountRangeIndex, func(i, j int) bool {
-		return ti.AmountRangeIndex[i].Amount.LessThan(ti.AmountRangeIndex[j].Amount)
-	})
+	// Amount range index
+	if entry, exists := bsi.AmountRangeIndex.Get(amount); exists {
+		entry.Statements = removeItem(entry.Statements, stmt)
+		if len(entry.Statements) == 0 {
+			bsi.AmountRangeIndex.Delete(amount)
+		}
+	}
+	
+	return true
 }
 
-// buildAmountRangeIndex rebuilds only the amount range index for bank statements
-func (bsi *BankStatementIndex) buildAmountRangeIndex() {
-	amountMap := make(map[string]*BankAmountIndexEntry)
-	
-	for _, stmt := range bsi.AllStatements {
-		amountKey := stmt.Amount.String()
-		if entry, exists := amountMap[amountKey]; exists {
-			entry.Statements = append(entry.Statements, stmt)
-		} else {
-			amountMap[amountKey] = &BankAmountIndexEntry{
-				Amount:     stmt.Amount,
-				Statements: []*models.BankStatement{stmt},
-			}
+// buildPositions records where every transaction sits. AllTransactions is
+// copied first, as it may share its backing array with the caller's slice.
+func (ti *TransactionIndex) buildPositions() {
+	ti.AllTransactions = slices.Clone(ti.AllTransactions)
+	ti.positions = make(map[*models.Transaction]transactionPosition, len(ti.AllTransactions))
+	for i, tx := range ti.AllTransactions {
+		ti.positions[tx] = transactionPosition{all: i}
+	}
+	for _, byType := range ti.TypeIndex {
+		for i, tx := range byType {
+			position := ti.positions[tx]
+			position.byType = i
+			ti.positions[tx] = position
 		}
 	}
-	
-	// Convert to sorted slice
-	bsi.AmountRangeIndex = make([]*BankAmountIndexEntry, 0, len(amountMap))
-	for _, entry := range amountMap {
-		bsi.AmountRangeIndex = append(bsi.AmountRangeIndex, entry)
+}
+
+// buildPositions records where every statement sits. AllStatements is copied
+// first, as it may share its backing array with the caller's slice.
+func (bsi *BankStatementIndex) buildPositions() {
+	bsi.AllStatements = slices.Clone(bsi.AllStatements)
+	bsi.positions = make(map[*models.BankStatement]int, len(bsi.AllStatements))
+	for i, stmt := range bsi.AllStatements {
+		bsi.positions[stmt] = i
 	}
-	
-	sort.Slice(bsi.AmountRangeIndex, func(i, j int) bool {
-		return bsi.AmountRangeIndex[i].Amount.LessThan(bsi.AmountRangeIndex[j].Amount)
-	})
-}
\ No newline at end of file
+}
+
+// removeFromBucket removes an item from a map bucket, dropping the bucket
+// once it is empty
+func removeFromBucket[K comparable, T comparable](buckets map[K][]T, key K, item T) {
+	bucket := removeItem(buckets[key], item)
+	if len(bucket) == 0 {
+		delete(buckets, key)
+	} else {
+		buckets[key] = bucket
+	}
+}
+
+// removeItem removes the first occurrence of an item, keeping the order of
+// the others
+func removeItem[T comparable](items []T, item T) []T {
+	if i := slices.Index(items, item); i >= 0 {
+		return slices.Delete(items, i, i+1)
+	}
+	return items
+}
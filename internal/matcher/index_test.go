@@ -88,7 +88,7 @@ func TestNewTransactionIndex(t *testing.T) {
 		t.Error("Expected type index to be populated")
 	}
 	
-	if len(index.AmountRangeIndex) == 0 {
+	if index.AmountRangeIndex.Len() == 0 {
 		t.Error("Expected amount range index to be populated")
 	}
 }
@@ -114,7 +114,7 @@ func TestNewBankStatementIndex(t *testing.T) {
 		t.Error("Expected date index to be populated")
 	}
 	
-	if len(index.AmountRangeIndex) == 0 {
+	if index.AmountRangeIndex.Len() == 0 {
 		t.Error("Expected amount range index to be populated")
 	}
 }
@@ -538,6 +538,110 @@ func TestBankStatementIndex_AddStatement(t *testing.T) {
 	}
 }
 
+func TestTransactionIndex_RemoveTransaction(t *testing.T) {
+	transactions := createTestTransactions()
+	original := append([]*models.Transaction(nil), transactions...)
+	index := NewTransactionIndex(transactions)
+	
+	// TX001 shares its amount with TX003
+	if !index.RemoveTransaction(transactions[0]) {
+		t.Fatal("Expected TX001 to be removed")
+	}
+	if index.RemoveTransaction(transactions[0]) {
+		t.Error("Expected a second removal of TX001 to report false")
+	}
+	
+	if len(index.AllTransactions) != 3 {
+		t.Errorf("Expected 3 transactions after removal, got %d", len(index.AllTransactions))
+	}
+	for i, tx := range transactions {
+		if tx != original[i] {
+			t.Fatal("Removal must not reorder the slice the index was built from")
+		}
+	}
+	
+	results := index.GetByExactAmount(decimal.NewFromFloat(100.50))
+	if len(results) != 1 || results[0].TrxID != "TX003" {
+		t.Errorf("Expected only TX003 at 100.50, got %v", results)
+	}
+	if dateResults := index.GetByDate(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)); len(dateResults) != 1 {
+		t.Errorf("Expected 1 transaction on 2024-01-15, got %d", len(dateResults))
+	}
+	if creditResults := index.GetByType(models.TransactionTypeCredit); len(creditResults) != 2 {
+		t.Errorf("Expected 2 credit transactions, got %d", len(creditResults))
+	}
+	
+	// Removing the last transaction with an amount drops it from the range index
+	index.RemoveTransaction(transactions[3])
+	if stats := index.GetIndexStats(); stats.UniqueAmounts != 2 {
+		t.Errorf("Expected 2 unique amounts, got %d", stats.UniqueAmounts)
+	}
+	if rangeResults := index.GetByAmountRange(decimal.NewFromFloat(70), decimal.NewFromFloat(80)); len(rangeResults) != 0 {
+		t.Errorf("Expected no transactions between 70 and 80, got %d", len(rangeResults))
+	}
+	
+	// Added transactions can be removed again
+	newTx := &models.Transaction{
+		TrxID:           "TX005",
+		Amount:          decimal.NewFromFloat(250.00),
+		Type:            models.TransactionTypeDebit,
+		TransactionTime: time.Date(2024, 1, 17, 10, 0, 0, 0, time.UTC),
+	}
+	index.AddTransaction(newTx)
+	if !index.RemoveTransaction(transactions[1]) || !index.RemoveTransaction(newTx) {
+		t.Fatal("Expected TX002 and TX005 to be removed")
+	}
+	if debitResults := index.GetByType(models.TransactionTypeDebit); len(debitResults) != 0 {
+		t.Errorf("Expected no debit transactions, got %d", len(debitResults))
+	}
+	if len(index.AllTransactions) != 1 || index.AllTransactions[0].TrxID != "TX003" {
+		t.Errorf("Expected only TX003 to be left, got %v", index.AllTransactions)
+	}
+}
+
+func TestBankStatementIndex_RemoveStatement(t *testing.T) {
+	statements := createTestBankStatements()
+	statements[0].Description = "Payment REF-778812"
+	index := NewBankStatementIndex(statements)
+	
+	if len(index.GetByReference("REF778812")) != 1 {
+		t.Fatal("Expected BS001 to be indexed by its reference")
+	}
+	
+	if !index.RemoveStatement(statements[0]) {
+		t.Fatal("Expected BS001 to be removed")
+	}
+	
+	if len(index.AllStatements) != 3 {
+		t.Errorf("Expected 3 statements after removal, got %d", len(index.AllStatements))
+	}
+	if len(index.GetByReference("REF778812")) != 0 || len(index.GetByReference("BS001")) != 0 {
+		t.Error("Expected BS001 to be removed from the reference index")
+	}
+	results := index.GetByAmountRange(decimal.NewFromFloat(100), decimal.NewFromFloat(101))
+	if len(results) != 1 || results[0].UniqueIdentifier != "BS004" {
+		t.Errorf("Expected only BS004 between 100 and 101, got %v", results)
+	}
+	
+	// Added statements are indexed by reference
+	newStmt := &models.BankStatement{
+		UniqueIdentifier: "BS005",
+		Amount:           decimal.NewFromFloat(300.00),
+		Date:             time.Date(2024, 1, 18, 0, 0, 0, 0, time.UTC),
+		Description:      "Invoice INV-99120",
+	}
+	index.AddStatement(newStmt)
+	if len(index.GetByReference("INV99120")) != 1 {
+		t.Error("Expected the added statement to be indexed by reference")
+	}
+	if !index.RemoveStatement(newStmt) || index.RemoveStatement(newStmt) {
+		t.Error("Expected BS005 to be removed exactly once")
+	}
+	if stats := index.GetIndexStats(); stats.TotalTransactions != 3 || stats.UniqueAmounts != 3 {
+		t.Errorf("Expected 3 statements over 3 amounts, got %+v", stats)
+	}
+}
+
 // Benchmark tests
 func BenchmarkTransactionIndex_GetByExactAmount(b *testing.B) {
 	// Create large dataset
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"time"
 
+	"golang-reconciliation-service/internal/fx"
 	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/pkg/errors"
 	"golang-reconciliation-service/pkg/logger"
@@ -36,6 +37,8 @@ type MatchingEngine struct {
 	TransactionIndex      *TransactionIndex
 	BankStatementIndex    *BankStatementIndex
 	logger                logger.Logger
+	currency              *currencyConverter
+	overrides             *ruleOverrides
 }
 
 // MatchResult represents the result of matching a transaction with a bank statement.
@@ -50,6 +53,11 @@ type MatchingEngine struct {
 //   - AmountDifference: Absolute difference between transaction and statement amounts
 //   - DateDifference: Time difference between transaction and statement dates
 //   - Reasons: Human-readable explanations for why this match was made
+//   - FX: Currency conversion details when either side was converted to the
+//     base currency; AmountDifference is then expressed in the base currency
+//   - Rule: Name of the match rule that forced the pair, for MatchManual
+//   - Breakdown: The weighted components behind ConfidenceScore, the
+//     tolerances applied and the candidates the match was chosen over
 //
 // The ConfidenceScore is calculated using weighted criteria and can be used
 // to filter matches or determine review requirements.
@@ -61,6 +69,13 @@ type MatchResult struct {
 	AmountDifference decimal.Decimal
 	DateDifference   time.Duration
 	Reasons          []string
+	FX               *FXDetails
+	Rule             string
+	Breakdown        *ScoreBreakdown
+	
+	// considered holds the statements scored alongside this pair until the
+	// matches are final and the rejected candidates are filled in
+	considered       []consideredStatement
 }
 
 // ReconciliationResult represents the complete result of a reconciliation process.
@@ -72,8 +87,13 @@ type MatchResult struct {
 // aggregate statistics for quick analysis and reporting.
 type ReconciliationResult struct {
 	Matches              []*MatchResult            // Successfully matched transaction pairs
+	GroupMatches         []*GroupMatch             // Matches between groups of transactions and statements
 	UnmatchedTransactions []*models.Transaction     // System transactions with no matches
 	UnmatchedStatements   []*models.BankStatement   // Bank statements with no matches
+	IgnoredStatements     []*IgnoredStatement       // Bank statements left out by ignore rules
+	NearMisses            []*NearMisses             // Nearest counterparts of the unmatched items, when NearMissLimit is set
+	PendingReview         []*PendingMatch           // Matches held for a reviewer to accept or reject
+	Passes                []*PassStats              // Per-pass statistics when strategies are configured
 	Summary              ReconciliationSummary     // Aggregate statistics and totals
 }
 
@@ -89,6 +109,11 @@ type ReconciliationSummary struct {
 	CloseMatches          int
 	FuzzyMatches          int
 	PossibleMatches       int
+	ManualMatches         int
+	IgnoredStatements     int
+	PendingReview         int
+	ManyToOneMatches      int
+	OneToManyMatches      int
 	TotalAmountMatched    decimal.Decimal
 	TotalAmountUnmatched  decimal.Decimal
 }
@@ -108,8 +133,9 @@ func NewMatchingEngine(config *MatchingConfig) *MatchingEngine {
 	}).Debug("Created matching engine")
 	
 	return &MatchingEngine{
-		Config: config,
-		logger: log,
+		Config:   config,
+		logger:   log,
+		currency: newCurrencyConverter(config, log),
 	}
 }
 
@@ -136,7 +162,8 @@ func (me *MatchingEngine) LoadTransactions(transactions []*models.Transaction) e
 		).WithSuggestion("Ensure there are transactions to reconcile")
 	}
 	
-	me.TransactionIndex = NewTransactionIndex(transactions)
+	me.currency.prepareTransactions(transactions)
+	me.TransactionIndex = newTransactionIndex(transactions, me.currency)
 	
 	me.logger.WithField("transaction_count", len(transactions)).Debug("Successfully loaded transactions into index")
 	return nil
@@ -165,7 +192,8 @@ func (me *MatchingEngine) LoadBankStatements(statements []*models.BankStatement)
 		).WithSuggestion("Ensure there are bank statements to reconcile")
 	}
 	
-	me.BankStatementIndex = NewBankStatementIndex(statements)
+	me.currency.prepareStatements(statements)
+	me.BankStatementIndex = newBankStatementIndex(statements, me.currency)
 	
 	me.logger.WithField("statement_count", len(statements)).Debug("Successfully loaded bank statements into index")
 	return nil
@@ -204,54 +232,75 @@ func (me *MatchingEngine) Reconcile() (*ReconciliationResult, error) {
 		"statement_count":   statementCount,
 	}).Info("Beginning reconciliation of transactions and bank statements")
 	
-	var matches []*MatchResult
-	matchedTransactionIDs := make(map[string]bool)
-	matchedStatementIDs := make(map[string]bool)
-	
-	// Find matches for each transaction
-	for i, tx := range me.TransactionIndex.AllTransactions {
-		if i%100 == 0 && i > 0 {
-			me.logger.WithFields(logger.Fields{
-				"processed": i,
-				"total":     transactionCount,
-				"progress":  float64(i) / float64(transactionCount) * 100,
-			}).Debug("Transaction matching progress")
-		}
-		
-		if matchedTransactionIDs[tx.TrxID] {
-			continue // Already matched
-		}
-		
-		candidates := me.BankStatementIndex.GetCandidates(tx, me.Config)
-		if len(candidates) == 0 {
-			continue // No candidates found
-		}
-		
-		// Score and rank candidates
-		scores, err := me.scoreTransactionCandidates(tx, candidates)
-		if err != nil {
-			me.logger.WithError(err).WithField("transaction_id", tx.TrxID).Warn("Failed to score transaction candidates")
-			continue
-		}
-		
-		// Find best match above confidence threshold
-		if len(scores) > 0 && scores[0].ConfidenceScore >= me.Config.MinConfidenceScore {
-			bestMatch := scores[0]
-			
-			// Check if bank statement is already matched
-			if !matchedStatementIDs[bestMatch.BankStatement.UniqueIdentifier] {
-				matches = append(matches, bestMatch)
-				matchedTransactionIDs[tx.TrxID] = true
-				matchedStatementIDs[bestMatch.BankStatement.UniqueIdentifier] = true
-				
-				me.logger.WithFields(logger.Fields{
-					"transaction_id":    tx.TrxID,
-					"statement_id":      bestMatch.BankStatement.UniqueIdentifier,
-					"match_type":        bestMatch.MatchType,
-					"confidence_score":  bestMatch.ConfidenceScore,
-				}).Debug("Found transaction match")
-			}
+	// Manual overrides are settled before scoring so the pairs and ignored
+	// statements they take cannot be claimed by the matching passes
+	me.overrides = me.applyRules()
+	defer func() { me.overrides = nil }()
+	
+	var result *ReconciliationResult
+	if len(me.Config.Strategies) > 0 {
+		var err error
+		if result, err = me.reconcileWithStrategies(); err != nil {
+			return nil, err
 		}
+	} else {
+		result = me.reconcileSinglePass()
+	}
+	result.IgnoredStatements = me.overrides.ignored
+	me.explainRejections(result)
+	me.holdForReview(result)
+	result.NearMisses = me.findNearMisses(result)
+	
+	// Calculate summary statistics
+	result.Summary = me.calculateSummary(result.Matches, result.GroupMatches, result.UnmatchedTransactions, result.UnmatchedStatements)
+	result.Summary.IgnoredStatements = len(me.overrides.ignored)
+	result.Summary.PendingReview = len(result.PendingReview)
+	
+	// Log reconciliation completion with summary
+	matchRate := 0.0
+	if transactionCount > 0 {
+		matchRate = float64(len(result.Matches)) / float64(transactionCount) * 100
+	}
+	me.logger.WithFields(logger.Fields{
+		"total_transactions":     transactionCount,
+		"total_statements":       statementCount,
+		"matches_found":          len(result.Matches),
+		"group_matches_found":    len(result.GroupMatches),
+		"unmatched_transactions": len(result.UnmatchedTransactions),
+		"unmatched_statements":   len(result.UnmatchedStatements),
+		"match_rate":             matchRate,
+	}).Info("Reconciliation process completed successfully")
+	
+	return result, nil
+}
+
+// ReconcilePartition loads one piece of a larger input and reconciles it.
+// Unlike LoadTransactions and LoadBankStatements it accepts a piece without
+// transactions or without statements, which is common when an input is split
+// at the gaps MatchingWindowDays allows; the items it does have are then
+// reported unmatched, or ignored by the rules.
+func (me *MatchingEngine) ReconcilePartition(transactions []*models.Transaction, statements []*models.BankStatement) (*ReconciliationResult, error) {
+	me.currency.prepareTransactions(transactions)
+	me.TransactionIndex = newTransactionIndex(transactions, me.currency)
+	me.currency.prepareStatements(statements)
+	me.BankStatementIndex = newBankStatementIndex(statements, me.currency)
+	
+	return me.Reconcile()
+}
+
+// reconcileSinglePass settles reference ID matches, chooses one-to-one pairs
+// from every scored candidate in one assignment pass, then tries the enabled
+// group passes on what is left
+func (me *MatchingEngine) reconcileSinglePass() *ReconciliationResult {
+	referenceMatches := me.selectIdentifierMatches()
+	matches := me.selectMatches(nil)
+	matches = append(append(me.overrides.manualMatches, referenceMatches...), matches...)
+	
+	matchedTransactionIDs := make(map[string]bool, len(matches))
+	matchedStatementIDs := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		matchedTransactionIDs[match.Transaction.TrxID] = true
+		matchedStatementIDs[match.BankStatement.UniqueIdentifier] = true
 	}
 	
 	// Collect unmatched transactions and statements
@@ -265,30 +314,22 @@ func (me *MatchingEngine) Reconcile() (*ReconciliationResult, error) {
 	}
 	
 	for _, stmt := range me.BankStatementIndex.AllStatements {
-		if !matchedStatementIDs[stmt.UniqueIdentifier] {
+		if !matchedStatementIDs[stmt.UniqueIdentifier] && !me.overrides.isRemoved(stmt) {
 			unmatchedStatements = append(unmatchedStatements, stmt)
 		}
 	}
 	
-	// Calculate summary statistics
-	summary := me.calculateSummary(matches, unmatchedTransactions, unmatchedStatements)
-	
-	// Log reconciliation completion with summary
-	me.logger.WithFields(logger.Fields{
-		"total_transactions":     transactionCount,
-		"total_statements":       statementCount,
-		"matches_found":          len(matches),
-		"unmatched_transactions": len(unmatchedTransactions),
-		"unmatched_statements":   len(unmatchedStatements),
-		"match_rate":             float64(len(matches)) / float64(transactionCount) * 100,
-	}).Info("Reconciliation process completed successfully")
+	// Try to settle leftovers with grouped matches
+	groupMatches, unmatchedTransactions, unmatchedStatements := me.matchPartialGroups(unmatchedTransactions, unmatchedStatements)
+	batchMatches, unmatchedTransactions, unmatchedStatements := me.matchBatchedStatements(unmatchedTransactions, unmatchedStatements)
+	groupMatches = append(groupMatches, batchMatches...)
 	
 	return &ReconciliationResult{
 		Matches:              matches,
+		GroupMatches:         groupMatches,
 		UnmatchedTransactions: unmatchedTransactions,
 		UnmatchedStatements:   unmatchedStatements,
-		Summary:              summary,
-	}, nil
+	}
 }
 
 // FindMatches finds potential matches for a specific transaction
@@ -336,22 +377,7 @@ func (me *MatchingEngine) scoreTransactionCandidates(tx *models.Transaction, can
 	}
 	
 	var results []*MatchResult
-	
-	for _, stmt := range candidates {
-		if stmt == nil {
-			me.logger.WithField("transaction_id", tx.TrxID).Warn("Encountered nil bank statement candidate")
-			continue
-		}
-		
-		result, err := me.scoreMatch(tx, stmt)
-		if err != nil {
-			me.logger.WithError(err).WithFields(logger.Fields{
-				"transaction_id": tx.TrxID,
-				"statement_id":   stmt.UniqueIdentifier,
-			}).Warn("Failed to score match")
-			continue
-		}
-		
+	for _, result := range me.scorePairs(tx, candidates) {
 		if result.ConfidenceScore >= me.Config.MinConfidenceScore {
 			results = append(results, result)
 		}
@@ -371,6 +397,29 @@ func (me *MatchingEngine) scoreTransactionCandidates(tx *models.Transaction, can
 	return results, nil
 }
 
+// scorePairs scores a transaction against each of its candidates, in
+// candidate order. Pairs that cannot be scored are logged and left out.
+func (me *MatchingEngine) scorePairs(tx *models.Transaction, candidates []*models.BankStatement) []*MatchResult {
+	results := make([]*MatchResult, 0, len(candidates))
+	for _, stmt := range candidates {
+		if stmt == nil {
+			me.logger.WithField("transaction_id", tx.TrxID).Warn("Encountered nil bank statement candidate")
+			continue
+		}
+		
+		result, err := me.scoreMatch(tx, stmt)
+		if err != nil {
+			me.logger.WithError(err).WithFields(logger.Fields{
+				"transaction_id": tx.TrxID,
+				"statement_id":   stmt.UniqueIdentifier,
+			}).Warn("Failed to score match")
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 // scoreStatementCandidates scores transaction candidates for a bank statement
 func (me *MatchingEngine) scoreStatementCandidates(stmt *models.BankStatement, candidates []*models.Transaction) []*MatchResult {
 	var results []*MatchResult
@@ -439,39 +488,101 @@ func (me *MatchingEngine) scoreMatch(tx *models.Transaction, stmt *models.BankSt
 	}
 	result.AmountDifference = me.calculateAmountDifference(tx, stmt)
 	
-	// Calculate date score
-	dateScore := me.calculateDateScore(normalizedTxTime, normalizedStmtTime)
+	// Calculate date score, on the calendar of the statement's bank and in
+	// the date window of the pair
+	dateRule := me.Config.dateRuleFor(tx, stmt)
+	dateScore := dateRule.score(normalizedTxTime, normalizedStmtTime)
 	result.DateDifference = me.calculateDateDifference(normalizedTxTime, normalizedStmtTime)
 	
 	// Calculate type score
 	typeScore := me.calculateTypeScore(tx, stmt)
 	
-	// Calculate weighted confidence score
+	// Calculate identifier and description scores, only when they are weighted
 	weights := me.Config.Weights
+	identifierScore, identifierKind := 0.0, IdentifierNone
+	if weights.IdentifierWeight > 0 {
+		identifierScore, identifierKind = calculateIdentifierScore(tx, stmt)
+	}
+	descriptionScore, descriptionCompared := 0.0, false
+	if weights.DescriptionWeight > 0 {
+		descriptionScore, descriptionCompared = calculateDescriptionScore(tx, stmt)
+	}
+	
+	// Calculate weighted confidence score
 	result.ConfidenceScore = (amountScore * weights.AmountWeight) + 
 							(dateScore * weights.DateWeight) + 
 							(typeScore * weights.TypeWeight)
+	if weights.DescriptionWeight > 0 {
+		result.ConfidenceScore = me.applyDescriptionScore(result.ConfidenceScore, descriptionScore, descriptionCompared)
+	}
+	if weights.IdentifierWeight > 0 {
+		result.ConfidenceScore = me.applyIdentifierScore(result.ConfidenceScore, identifierScore)
+	}
+	
+	// Record the components behind the score
+	breakdown := me.newScoreBreakdown(tx)
+	breakdown.DateWindow = dateRule.window
+	breakdown.add(ComponentAmount, amountScore, weights.AmountWeight, me.amountNote(tx, stmt))
+	breakdown.add(ComponentDate, dateScore, weights.DateWeight, dateRule.note(normalizedTxTime, normalizedStmtTime))
+	breakdown.add(ComponentType, typeScore, weights.TypeWeight, me.typeNote())
+	if weights.DescriptionWeight > 0 {
+		note := ""
+		if !descriptionCompared {
+			note = "not compared, weight spread over the other components"
+		}
+		breakdown.add(ComponentDescription, descriptionScore, weights.DescriptionWeight, note)
+	}
+	if weights.IdentifierWeight > 0 {
+		note := ""
+		if identifierKind != IdentifierNone {
+			note = string(identifierKind)
+		}
+		breakdown.add(ComponentIdentifier, identifierScore, weights.IdentifierWeight, note)
+	}
+	breakdown.settle(result.ConfidenceScore)
+	result.Breakdown = breakdown
 	
 	// Determine match type and add reasons
 	result.MatchType = me.determineMatchType(result.ConfidenceScore, amountScore, dateScore, typeScore)
 	result.Reasons = me.generateMatchReasons(tx, stmt, amountScore, dateScore, typeScore)
+	if identifierKind != IdentifierNone {
+		result.Reasons = append(result.Reasons, describeIdentifierMatch(identifierKind))
+	}
+	if reason := describeDescriptionMatch(descriptionScore); reason != "" {
+		result.Reasons = append(result.Reasons, reason)
+	}
+	
+	// Record currency conversions so reports can show original and converted amounts
+	result.FX = me.currency.details(tx, stmt, result.AmountDifference)
+	if result.FX != nil {
+		for _, conversion := range []*fx.Conversion{result.FX.Transaction, result.FX.Statement} {
+			if conversion != nil {
+				result.Reasons = append(result.Reasons, describeConversion(conversion))
+			}
+		}
+	}
 	
 	return result, nil
 }
 
 // calculateAmountScore calculates the score based on amount matching
 func (me *MatchingEngine) calculateAmountScore(tx *models.Transaction, stmt *models.BankStatement) (float64, error) {
-	if tx.Amount.IsZero() && stmt.Amount.IsZero() {
+	if !me.currency.comparable(tx, stmt) {
+		return 0.0, nil // Amounts in different currencies cannot be compared
+	}
+	
+	// Compare in the base currency when conversion is enabled
+	txAmount := me.currency.transactionAmount(tx).Abs()
+	stmtAmount := me.currency.statementAmount(stmt).Abs()
+	
+	if txAmount.IsZero() && stmtAmount.IsZero() {
 		return 1.0, nil // Both zero amounts match exactly
 	}
 	
-	if tx.Amount.IsZero() || stmt.Amount.IsZero() {
+	if txAmount.IsZero() || stmtAmount.IsZero() {
 		return 0.0, nil // One zero, one non-zero cannot match
 	}
 	
-	txAmount := tx.Amount.Abs()
-	stmtAmount := stmt.Amount.Abs()
-	
 	// Check for exact match first
 	if txAmount.Equal(stmtAmount) {
 		return 1.0, nil
@@ -493,30 +604,10 @@ func (me *MatchingEngine) calculateAmountScore(tx *models.Transaction, stmt *mod
 	return 0.0, nil
 }
 
-// calculateDateScore calculates the score based on date proximity
+// calculateDateScore calculates the score based on date proximity, on the
+// default calendar and in the default date window
 func (me *MatchingEngine) calculateDateScore(txTime, stmtTime time.Time) float64 {
-	if me.Config.DateToleranceDays == 0 {
-		// Exact date match required
-		if txTime.Format("2006-01-02") == stmtTime.Format("2006-01-02") {
-			return 1.0
-		}
-		return 0.0
-	}
-	
-	if me.Config.IsWithinDateTolerance(txTime, stmtTime) {
-		// Calculate decay based on distance from exact match
-		diff := txTime.Sub(stmtTime)
-		if diff < 0 {
-			diff = -diff
-		}
-		
-		maxDiff := time.Duration(me.Config.DateToleranceDays) * 24 * time.Hour
-		diffRatio := float64(diff) / float64(maxDiff)
-		
-		return math.Max(0.0, 1.0-diffRatio)
-	}
-	
-	return 0.0
+	return me.Config.dateRuleFor(nil, nil).score(txTime, stmtTime)
 }
 
 // calculateTypeScore calculates the score based on transaction type compatibility
@@ -540,14 +631,14 @@ func (me *MatchingEngine) calculateTypeScore(tx *models.Transaction, stmt *model
 
 // calculateAmountDifference calculates the absolute difference between amounts
 func (me *MatchingEngine) calculateAmountDifference(tx *models.Transaction, stmt *models.BankStatement) decimal.Decimal {
-	txAmount := tx.Amount
-	stmtAmount := stmt.Amount
+	txAmount := me.currency.transactionAmount(tx)
+	stmtAmount := me.currency.statementAmount(stmt)
 	
 	// Handle different conventions (positive vs negative for debits)
-	if tx.Type == models.TransactionTypeDebit && stmt.Amount.IsPositive() {
-		stmtAmount = stmt.Amount.Neg()
-	} else if tx.Type == models.TransactionTypeCredit && stmt.Amount.IsNegative() {
-		stmtAmount = stmt.Amount.Neg()
+	if tx.Type == models.TransactionTypeDebit && stmtAmount.IsPositive() {
+		stmtAmount = stmtAmount.Neg()
+	} else if tx.Type == models.TransactionTypeCredit && stmtAmount.IsNegative() {
+		stmtAmount = stmtAmount.Neg()
 	}
 	
 	return txAmount.Sub(stmtAmount).Abs()
@@ -616,7 +707,7 @@ func (me *MatchingEngine) generateMatchReasons(tx *models.Transaction, stmt *mod
 }
 
 // calculateSummary calculates summary statistics for the reconciliation result
-func (me *MatchingEngine) calculateSummary(matches []*MatchResult, unmatchedTx []*models.Transaction, unmatchedStmt []*models.BankStatement) ReconciliationSummary {
+func (me *MatchingEngine) calculateSummary(matches []*MatchResult, groups []*GroupMatch, unmatchedTx []*models.Transaction, unmatchedStmt []*models.BankStatement) ReconciliationSummary {
 	summary := ReconciliationSummary{
 		TotalTransactions:     len(me.TransactionIndex.AllTransactions),
 		TotalBankStatements:   len(me.BankStatementIndex.AllStatements),
@@ -639,14 +730,30 @@ func (me *MatchingEngine) calculateSummary(matches []*MatchResult, unmatchedTx [
 			summary.FuzzyMatches++
 		case MatchPossible:
 			summary.PossibleMatches++
+		case MatchManual:
+			summary.ManualMatches++
 		}
 		
-		summary.TotalAmountMatched = summary.TotalAmountMatched.Add(match.Transaction.Amount.Abs())
+		summary.TotalAmountMatched = summary.TotalAmountMatched.Add(me.currency.transactionAmount(match.Transaction).Abs())
+	}
+	
+	// Count grouped matches and their members
+	for _, group := range groups {
+		switch group.GroupType {
+		case GroupManyToOne:
+			summary.ManyToOneMatches++
+		case GroupOneToMany:
+			summary.OneToManyMatches++
+		}
+		
+		summary.MatchedTransactions += len(group.Transactions)
+		summary.MatchedStatements += len(group.Statements)
+		summary.TotalAmountMatched = summary.TotalAmountMatched.Add(group.TransactionTotal)
 	}
 	
 	// Calculate unmatched amounts
 	for _, tx := range unmatchedTx {
-		summary.TotalAmountUnmatched = summary.TotalAmountUnmatched.Add(tx.Amount.Abs())
+		summary.TotalAmountUnmatched = summary.TotalAmountUnmatched.Add(me.currency.transactionAmount(tx).Abs())
 	}
 	
 	return summary
@@ -669,6 +776,18 @@ func (me *MatchingEngine) UpdateConfiguration(config *MatchingConfig) error {
 	}
 	
 	me.Config = config.Clone()
+	
+	// Conversions depend on the base currency and rate table, so rebuild them
+	// together with any indexes that were built from converted amounts
+	me.currency = newCurrencyConverter(me.Config, me.logger)
+	if me.TransactionIndex != nil {
+		me.currency.prepareTransactions(me.TransactionIndex.AllTransactions)
+		me.TransactionIndex = newTransactionIndex(me.TransactionIndex.AllTransactions, me.currency)
+	}
+	if me.BankStatementIndex != nil {
+		me.currency.prepareStatements(me.BankStatementIndex.AllStatements)
+		me.BankStatementIndex = newBankStatementIndex(me.BankStatementIndex.AllStatements, me.currency)
+	}
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/rules"
 
 	"github.com/shopspring/decimal"
 )
@@ -448,6 +449,43 @@ func TestMatchingEngine_UpdateConfiguration(t *testing.T) {
 	}
 }
 
+func TestMatchingConfig_MatchingWindowDays(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*MatchingConfig)
+		days      int
+		bounded   bool
+	}{
+		{"date tolerance", func(c *MatchingConfig) { c.DateToleranceDays = 3 }, 3, true},
+		{"business days", func(c *MatchingConfig) {
+			c.DateToleranceDays = 3
+			c.IgnoreWeekends = true
+		}, 6, true},
+		{"no date tolerance", func(c *MatchingConfig) { c.DateToleranceDays = 0 }, 0, false},
+		{"identifier weight", func(c *MatchingConfig) {
+			c.Weights = c.Weights.WithIdentifierWeight(0.3)
+		}, 0, false},
+		{"manual matches", func(c *MatchingConfig) {
+			c.Rules = &rules.Set{Matches: []*rules.PairRule{{TrxID: "TX001", StatementID: "BS001"}}}
+		}, 0, false},
+		{"exclusions only", func(c *MatchingConfig) {
+			c.Rules = &rules.Set{Exclusions: []*rules.PairRule{{TrxID: "TX001", StatementID: "BS001"}}}
+		}, 1, true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			tt.configure(config)
+			
+			days, bounded := config.MatchingWindowDays()
+			if days != tt.days || bounded != tt.bounded {
+				t.Errorf("Expected (%d, %v), got (%d, %v)", tt.days, tt.bounded, days, bounded)
+			}
+		})
+	}
+}
+
 func TestMatchingEngine_GetStats(t *testing.T) {
 	engine := NewMatchingEngine(nil)
 	transactions, statements := createTestMatchingData()
@@ -0,0 +1,345 @@
+package matcher
+
+import (
+	"sort"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reasons a near miss was not matched that come from the tolerances rather
+// than from the competition for candidates
+const (
+	RejectionTypeMismatch           RejectionReason = "type_mismatch"            // the counterpart goes in the other direction
+	RejectionAmountOutsideTolerance RejectionReason = "amount_outside_tolerance" // the amounts differ by more than the amount tolerance
+	RejectionDateOutsideTolerance   RejectionReason = "date_outside_tolerance"   // the dates are further apart than the date tolerance
+	RejectionNotSelected            RejectionReason = "not_selected"             // within every limit, but not picked by the passes that ran
+	RejectionPendingReview          RejectionReason = "pending_review"           // the counterpart is held for review with another item
+)
+
+// NearMiss is a counterpart an unmatched item came close to matching. For an
+// unmatched transaction the counterpart is a statement, for an unmatched
+// statement it is a transaction.
+type NearMiss struct {
+	Statement       *models.BankStatement `json:"statement,omitempty"`
+	Transaction     *models.Transaction   `json:"transaction,omitempty"`
+	ConfidenceScore float64               `json:"confidence_score"`
+
+	// AmountDifference is the counterpart amount less the item amount, both
+	// taken as absolute values in the compared currency
+	AmountDifference decimal.Decimal `json:"amount_difference"`
+
+	// DateDifferenceDays is the counterpart date less the item date, in days
+	DateDifferenceDays int `json:"date_difference_days"`
+
+	Reason    RejectionReason `json:"reason"`               // what blocked the match
+	Rule      string          `json:"rule,omitempty"`       // the rule behind an exclusion or ignore
+	MatchedTo string          `json:"matched_to,omitempty"` // the item the counterpart went to, for RejectionMatchedElsewhere and RejectionPendingReview
+}
+
+// NearMisses lists the counterparts an unmatched item came closest to
+// matching, nearest first. Exactly one of Transaction and Statement is set.
+type NearMisses struct {
+	Transaction *models.Transaction   `json:"transaction,omitempty"`
+	Statement   *models.BankStatement `json:"statement,omitempty"`
+	Candidates  []*NearMiss           `json:"candidates"`
+}
+
+// nearMissScoreLimit caps how many counterparts of one unmatched item are
+// scored. Counterparts are tried closest first, and only those within the
+// type, amount and date tolerances are scored, so dense inputs where many
+// counterparts share an amount and a day cost a bounded amount of work.
+const nearMissScoreLimit = 20
+
+// nearMissState is what the blocking reason of a near miss is read from once
+// the matches are final
+type nearMissState struct {
+	statementMatchedTo   map[*models.BankStatement]string
+	transactionMatchedTo map[*models.Transaction]string
+	groupedStatements    map[*models.BankStatement]bool
+	groupedTransactions  map[*models.Transaction]bool
+	statementPendingTo   map[*models.BankStatement]string
+	transactionPendingTo map[*models.Transaction]string
+	ignoreRules          map[*models.BankStatement]string
+}
+
+func newNearMissState(result *ReconciliationResult) *nearMissState {
+	state := &nearMissState{
+		statementMatchedTo:   make(map[*models.BankStatement]string, len(result.Matches)),
+		transactionMatchedTo: make(map[*models.Transaction]string, len(result.Matches)),
+		groupedStatements:    make(map[*models.BankStatement]bool),
+		groupedTransactions:  make(map[*models.Transaction]bool),
+		statementPendingTo:   make(map[*models.BankStatement]string, len(result.PendingReview)),
+		transactionPendingTo: make(map[*models.Transaction]string, len(result.PendingReview)),
+		ignoreRules:          make(map[*models.BankStatement]string, len(result.IgnoredStatements)),
+	}
+	for _, match := range result.Matches {
+		state.statementMatchedTo[match.BankStatement] = match.Transaction.TrxID
+		state.transactionMatchedTo[match.Transaction] = match.BankStatement.UniqueIdentifier
+	}
+	for _, group := range result.GroupMatches {
+		for _, stmt := range group.Statements {
+			state.groupedStatements[stmt] = true
+		}
+		for _, tx := range group.Transactions {
+			state.groupedTransactions[tx] = true
+		}
+	}
+	for _, pending := range result.PendingReview {
+		state.statementPendingTo[pending.Match.BankStatement] = pending.Match.Transaction.TrxID
+		state.transactionPendingTo[pending.Match.Transaction] = pending.Match.BankStatement.UniqueIdentifier
+	}
+	for _, ignored := range result.IgnoredStatements {
+		state.ignoreRules[ignored.Statement] = ignored.Rule
+	}
+	return state
+}
+
+// findNearMisses looks up the nearest counterparts of every unmatched item.
+// Counterparts are searched within NearMissAmountPercent of the item amount,
+// in either direction, and NearMissDays beyond the date tolerance, whether
+// they were matched or not, and are ranked by confidence score, then by how
+// close the amounts and dates are. Counterparts outside the tolerances are
+// not scored and rank after the scored ones.
+func (me *MatchingEngine) findNearMisses(result *ReconciliationResult) []*NearMisses {
+	if me.Config.NearMissLimit <= 0 {
+		return nil
+	}
+
+	state := newNearMissState(result)
+	var nearMisses []*NearMisses
+	for _, tx := range result.UnmatchedTransactions {
+		var candidates []*NearMiss
+		budget := nearMissScoreLimit
+		for _, stmt := range me.nearStatements(tx) {
+			if candidate := me.nearMiss(tx, stmt, state, false, &budget); candidate != nil {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if candidates = me.nearest(candidates); len(candidates) > 0 {
+			nearMisses = append(nearMisses, &NearMisses{Transaction: tx, Candidates: candidates})
+		}
+	}
+	for _, stmt := range result.UnmatchedStatements {
+		var candidates []*NearMiss
+		budget := nearMissScoreLimit
+		for _, tx := range me.nearTransactions(stmt) {
+			if candidate := me.nearMiss(tx, stmt, state, true, &budget); candidate != nil {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if candidates = me.nearest(candidates); len(candidates) > 0 {
+			nearMisses = append(nearMisses, &NearMisses{Statement: stmt, Candidates: candidates})
+		}
+	}
+	return nearMisses
+}
+
+// nearMissWindow returns how far an amount may be from the item amount to be
+// searched as a near miss
+func (me *MatchingEngine) nearMissWindow(amount decimal.Decimal) decimal.Decimal {
+	window := amount.Abs().Mul(decimal.NewFromFloat(me.Config.NearMissAmountPercent / 100.0)).Round(int32(me.Config.AmountPrecision))
+	if tolerance := me.Config.GetAmountTolerance(amount); tolerance.GreaterThan(window) {
+		return tolerance
+	}
+	return window
+}
+
+// nearMissDays returns how many days apart an item and a near miss may be
+func (me *MatchingEngine) nearMissDays() int64 {
+	return int64(me.Config.calendarToleranceDays() + me.Config.NearMissDays)
+}
+
+// nearStatements returns the statements searched as near misses of a
+// transaction, closest first
+func (me *MatchingEngine) nearStatements(tx *models.Transaction) []*models.BankStatement {
+	amount := me.currency.transactionAmount(tx).Abs()
+	window := me.nearMissWindow(amount)
+	day := me.Config.DayNumber(tx.TransactionTime)
+	maxDays := me.nearMissDays()
+
+	var statements []*models.BankStatement
+	var distances []nearMissDistance
+	seen := make(map[*models.BankStatement]bool)
+	for _, center := range []decimal.Decimal{amount, amount.Neg()} {
+		for _, stmt := range me.BankStatementIndex.GetByAmountRange(center.Sub(window), center.Add(window)) {
+			if seen[stmt] || !me.currency.comparable(tx, stmt) {
+				continue
+			}
+			days := absDays(me.Config.DayNumber(stmt.Date) - day)
+			if days > maxDays {
+				continue
+			}
+			seen[stmt] = true
+			statements = append(statements, stmt)
+			distances = append(distances, nearMissDistance{amount: me.currency.statementAmount(stmt).Abs().Sub(amount).Abs(), days: days})
+		}
+	}
+	sortByDistance(statements, distances)
+	return statements
+}
+
+// nearTransactions returns the transactions searched as near misses of a
+// statement, closest first
+func (me *MatchingEngine) nearTransactions(stmt *models.BankStatement) []*models.Transaction {
+	amount := me.currency.statementAmount(stmt).Abs()
+	window := me.nearMissWindow(amount)
+	day := me.Config.DayNumber(stmt.Date)
+	maxDays := me.nearMissDays()
+
+	var transactions []*models.Transaction
+	var distances []nearMissDistance
+	seen := make(map[*models.Transaction]bool)
+	for _, center := range []decimal.Decimal{amount, amount.Neg()} {
+		for _, tx := range me.TransactionIndex.GetByAmountRange(center.Sub(window), center.Add(window)) {
+			if seen[tx] || !me.currency.comparable(tx, stmt) {
+				continue
+			}
+			days := absDays(me.Config.DayNumber(tx.TransactionTime) - day)
+			if days > maxDays {
+				continue
+			}
+			seen[tx] = true
+			transactions = append(transactions, tx)
+			distances = append(distances, nearMissDistance{amount: me.currency.transactionAmount(tx).Abs().Sub(amount).Abs(), days: days})
+		}
+	}
+	sortByDistance(transactions, distances)
+	return transactions
+}
+
+// nearMissDistance is how far a counterpart is from an item
+type nearMissDistance struct {
+	amount decimal.Decimal
+	days   int64
+}
+
+// sortByDistance orders counterparts by their amount distance, then their
+// date distance, to the item
+func sortByDistance[T any](counterparts []T, distances []nearMissDistance) {
+	order := make([]int, len(counterparts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := distances[order[i]], distances[order[j]]
+		if cmp := a.amount.Cmp(b.amount); cmp != 0 {
+			return cmp < 0
+		}
+		return a.days < b.days
+	})
+
+	sorted := make([]T, len(counterparts))
+	for i, index := range order {
+		sorted[i] = counterparts[index]
+	}
+	copy(counterparts, sorted)
+}
+
+// nearMiss works out what blocked a pair. The pair is scored only when it is
+// within the type, amount and date tolerances, while budget lasts; a pair in
+// tolerance beyond the budget is dropped, and other pairs keep a zero score.
+// forStatement is set when the unmatched item is the statement and the
+// transaction the counterpart.
+func (me *MatchingEngine) nearMiss(tx *models.Transaction, stmt *models.BankStatement, state *nearMissState, forStatement bool, budget *int) *NearMiss {
+	txAmount := me.currency.transactionAmount(tx).Abs()
+	stmtAmount := me.currency.statementAmount(stmt).Abs()
+
+	var outsideTolerance RejectionReason
+	switch {
+	case (tx.Type == models.TransactionTypeDebit) != stmt.Amount.IsNegative():
+		outsideTolerance = RejectionTypeMismatch
+	case stmtAmount.Sub(txAmount).Abs().GreaterThan(me.Config.GetAmountTolerance(txAmount)):
+		outsideTolerance = RejectionAmountOutsideTolerance
+	case !me.Config.IsPairWithinDateTolerance(tx, stmt):
+		outsideTolerance = RejectionDateOutsideTolerance
+	}
+
+	days := int(me.Config.DayNumber(stmt.Date) - me.Config.DayNumber(tx.TransactionTime))
+	candidate := &NearMiss{}
+	if outsideTolerance == "" {
+		if *budget <= 0 {
+			return nil
+		}
+		*budget--
+		scored, err := me.scoreMatch(tx, stmt)
+		if err != nil {
+			return nil
+		}
+		candidate.ConfidenceScore = scored.ConfidenceScore
+	}
+	if forStatement {
+		candidate.Transaction = tx
+		candidate.AmountDifference = txAmount.Sub(stmtAmount)
+		candidate.DateDifferenceDays = -days
+	} else {
+		candidate.Statement = stmt
+		candidate.AmountDifference = stmtAmount.Sub(txAmount)
+		candidate.DateDifferenceDays = days
+	}
+
+	var exclusion string
+	if set := me.Config.Rules; set != nil {
+		if rule := set.ExclusionFor(tx.TrxID, stmt.UniqueIdentifier); rule != nil {
+			exclusion = rule.Name
+		}
+	}
+
+	switch {
+	case exclusion != "":
+		candidate.Reason = RejectionExcludedByRule
+		candidate.Rule = exclusion
+	case !forStatement && state.ignoreRules[stmt] != "":
+		candidate.Reason = RejectionIgnoredByRule
+		candidate.Rule = state.ignoreRules[stmt]
+	case !forStatement && state.statementMatchedTo[stmt] != "":
+		candidate.Reason = RejectionMatchedElsewhere
+		candidate.MatchedTo = state.statementMatchedTo[stmt]
+	case !forStatement && state.groupedStatements[stmt]:
+		candidate.Reason = RejectionMatchedInGroup
+	case !forStatement && state.statementPendingTo[stmt] != "":
+		candidate.Reason = RejectionPendingReview
+		candidate.MatchedTo = state.statementPendingTo[stmt]
+	case forStatement && state.transactionMatchedTo[tx] != "":
+		candidate.Reason = RejectionMatchedElsewhere
+		candidate.MatchedTo = state.transactionMatchedTo[tx]
+	case forStatement && state.groupedTransactions[tx]:
+		candidate.Reason = RejectionMatchedInGroup
+	case forStatement && state.transactionPendingTo[tx] != "":
+		candidate.Reason = RejectionPendingReview
+		candidate.MatchedTo = state.transactionPendingTo[tx]
+	case outsideTolerance != "":
+		candidate.Reason = outsideTolerance
+	case candidate.ConfidenceScore < me.Config.MinConfidenceScore:
+		candidate.Reason = RejectionBelowMinConfidence
+	default:
+		candidate.Reason = RejectionNotSelected
+	}
+	return candidate
+}
+
+// nearest orders near misses best first and keeps NearMissLimit of them
+func (me *MatchingEngine) nearest(candidates []*NearMiss) []*NearMiss {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.ConfidenceScore != b.ConfidenceScore {
+			return a.ConfidenceScore > b.ConfidenceScore
+		}
+		if cmp := a.AmountDifference.Abs().Cmp(b.AmountDifference.Abs()); cmp != 0 {
+			return cmp < 0
+		}
+		return absDays(int64(a.DateDifferenceDays)) < absDays(int64(b.DateDifferenceDays))
+	})
+	if len(candidates) > me.Config.NearMissLimit {
+		candidates = candidates[:me.Config.NearMissLimit]
+	}
+	return candidates
+}
+
+func absDays(days int64) int64 {
+	if days < 0 {
+		return -days
+	}
+	return days
+}
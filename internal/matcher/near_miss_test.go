@@ -0,0 +1,198 @@
+package matcher
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/rules"
+
+	"github.com/shopspring/decimal"
+)
+
+func createNearMissTestData(t *testing.T) (*MatchingConfig, []*models.Transaction, []*models.BankStatement) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	set, err := rules.ReadCSV(strings.NewReader(`action,trx_id,statement_id,id_prefix,name
+exclude,TX001,BS003,,wrong customer
+`))
+	if err != nil {
+		t.Fatalf("Failed to read rules: %v", err)
+	}
+
+	config := DefaultMatchingConfig()
+	config.Rules = set
+	config.NearMissLimit = 10
+
+	// TX002 takes BS001, which leaves TX001 with near misses only
+	transactions := []*models.Transaction{
+		{TrxID: "TX002", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "TX001", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day.AddDate(0, 0, 1)},
+	}
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: day},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromInt(-100), Date: day.AddDate(0, 0, 1)},
+		{UniqueIdentifier: "BS003", Amount: decimal.NewFromInt(98), Date: day.AddDate(0, 0, 1)},
+		{UniqueIdentifier: "BS004", Amount: decimal.NewFromInt(100), Date: day.AddDate(0, 0, 5)},
+		{UniqueIdentifier: "BS005", Amount: decimal.NewFromInt(99), Date: day.AddDate(0, 0, 1)},
+		{UniqueIdentifier: "BS006", Amount: decimal.NewFromInt(100), Date: day.AddDate(0, 0, 20)},
+		{UniqueIdentifier: "BS007", Amount: decimal.NewFromInt(50), Date: day.AddDate(0, 0, 1)},
+	}
+	return config, transactions, statements
+}
+
+func reconcileNearMisses(t *testing.T, config *MatchingConfig, transactions []*models.Transaction, statements []*models.BankStatement) *ReconciliationResult {
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	return result
+}
+
+func TestMatchingEngine_NearMisses(t *testing.T) {
+	config, transactions, statements := createNearMissTestData(t)
+	result := reconcileNearMisses(t, config, transactions, statements)
+
+	if len(result.Matches) != 1 || result.Matches[0].Transaction.TrxID != "TX002" {
+		t.Fatalf("Expected TX002 to match BS001 only, got %d matches", len(result.Matches))
+	}
+
+	var forTX001, forBS002 *NearMisses
+	for _, item := range result.NearMisses {
+		switch {
+		case item.Transaction != nil && item.Transaction.TrxID == "TX001":
+			forTX001 = item
+		case item.Statement != nil && item.Statement.UniqueIdentifier == "BS002":
+			forBS002 = item
+		}
+	}
+	if forTX001 == nil || forBS002 == nil {
+		t.Fatalf("Expected near misses for TX001 and BS002, got %d items", len(result.NearMisses))
+	}
+
+	tests := []struct {
+		stmt          string
+		wantReason    RejectionReason
+		wantRule      string
+		wantMatchedTo string
+		wantAmount    string
+		wantDays      int
+	}{
+		{stmt: "BS001", wantReason: RejectionMatchedElsewhere, wantMatchedTo: "TX002", wantAmount: "0", wantDays: -1},
+		{stmt: "BS002", wantReason: RejectionTypeMismatch, wantAmount: "0", wantDays: 0},
+		{stmt: "BS003", wantReason: RejectionExcludedByRule, wantRule: "wrong customer", wantAmount: "-2", wantDays: 0},
+		{stmt: "BS004", wantReason: RejectionDateOutsideTolerance, wantAmount: "0", wantDays: 4},
+		{stmt: "BS005", wantReason: RejectionAmountOutsideTolerance, wantAmount: "-1", wantDays: 0},
+	}
+
+	if len(forTX001.Candidates) != len(tests) {
+		t.Errorf("Expected %d near misses for TX001, got %d", len(tests), len(forTX001.Candidates))
+	}
+	for _, tt := range tests {
+		t.Run(tt.stmt, func(t *testing.T) {
+			var found *NearMiss
+			for _, candidate := range forTX001.Candidates {
+				if candidate.Statement.UniqueIdentifier == tt.stmt {
+					found = candidate
+				}
+			}
+			if found == nil {
+				t.Fatalf("Expected %s among the near misses of TX001", tt.stmt)
+			}
+			if found.Reason != tt.wantReason || found.Rule != tt.wantRule || found.MatchedTo != tt.wantMatchedTo {
+				t.Errorf("Near miss = %q, rule %q, matched to %q; want %q, %q, %q",
+					found.Reason, found.Rule, found.MatchedTo, tt.wantReason, tt.wantRule, tt.wantMatchedTo)
+			}
+			if found.AmountDifference.String() != tt.wantAmount || found.DateDifferenceDays != tt.wantDays {
+				t.Errorf("Differences = %s, %d days; want %s, %d days",
+					found.AmountDifference, found.DateDifferenceDays, tt.wantAmount, tt.wantDays)
+			}
+			// Counterparts outside the tolerances are reported without being scored
+			switch tt.wantReason {
+			case RejectionTypeMismatch, RejectionAmountOutsideTolerance, RejectionDateOutsideTolerance:
+				if found.ConfidenceScore != 0 {
+					t.Errorf("Expected no score outside the tolerances, got %f", found.ConfidenceScore)
+				}
+			}
+		})
+	}
+
+	for i := 1; i < len(forTX001.Candidates); i++ {
+		if forTX001.Candidates[i].ConfidenceScore > forTX001.Candidates[i-1].ConfidenceScore {
+			t.Errorf("Near misses of TX001 are not ordered by score")
+		}
+	}
+
+	// The unmatched debit statement is the opposite of both transactions
+	for _, candidate := range forBS002.Candidates {
+		switch candidate.Transaction.TrxID {
+		case "TX001":
+			if candidate.Reason != RejectionTypeMismatch {
+				t.Errorf("Expected TX001 to be a type mismatch for BS002, got %q", candidate.Reason)
+			}
+		case "TX002":
+			if candidate.Reason != RejectionMatchedElsewhere || candidate.MatchedTo != "BS001" {
+				t.Errorf("Expected TX002 to be matched to BS001, got %q to %q", candidate.Reason, candidate.MatchedTo)
+			}
+		}
+	}
+}
+
+func TestMatchingEngine_NearMissLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: 0},
+		{limit: 2, want: 2},
+	}
+
+	for _, tt := range tests {
+		config, transactions, statements := createNearMissTestData(t)
+		config.NearMissLimit = tt.limit
+		result := reconcileNearMisses(t, config, transactions, statements)
+
+		got := 0
+		for _, item := range result.NearMisses {
+			if item.Transaction != nil && item.Transaction.TrxID == "TX001" {
+				got = len(item.Candidates)
+			}
+		}
+		if got != tt.want {
+			t.Errorf("Limit %d: expected %d near misses for TX001, got %d", tt.limit, tt.want, got)
+		}
+	}
+}
+
+// BenchmarkMatchingEngine_Reconcile_NearMisses measures the near-miss search
+// on the dataset of TestPerformanceWithLargeDataset, where a third of the
+// statements are off by an amount outside the tolerance
+func BenchmarkMatchingEngine_Reconcile_NearMisses(b *testing.B) {
+	transactions := createLargeTransactionDataset(10000)
+	statements := createLargeBankStatementDataset(10000)
+
+	for _, limit := range []int{0, 3} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			config := DefaultMatchingConfig()
+			config.NearMissLimit = limit
+			engine := NewMatchingEngine(config)
+			engine.LoadTransactions(transactions)
+			engine.LoadBankStatements(statements)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := engine.Reconcile(); err != nil {
+					b.Fatalf("Reconcile failed: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package matcher
+
+import (
+	"fmt"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// IgnoredStatement is a bank statement left out of matching by an ignore rule
+type IgnoredStatement struct {
+	Statement *models.BankStatement `json:"statement"`
+	Rule      string                `json:"rule"`
+}
+
+// ruleOverrides is the outcome of applying the configured rules to the loaded
+// transactions and statements. Items in a manual match and ignored statements
+// are taken out of the scoring passes, and excluded pairs are never scored.
+// Pairs matched by the reference fast path or by a matching pass are claimed
+// here as well.
+type ruleOverrides struct {
+	manualMatches []*MatchResult
+	ignored       []*IgnoredStatement
+
+	claimedTransactions map[*models.Transaction]bool
+	removedStatements   map[*models.BankStatement]bool
+	excludedPairs       map[string]bool
+}
+
+// applyRules resolves the configured rules against the loaded data. Match
+// rules are resolved by identifier; a rule naming an unknown transaction or
+// statement is skipped with a warning, since rule files usually outlive the
+// period they were written for. A statement in a manual match is never
+// ignored.
+func (me *MatchingEngine) applyRules() *ruleOverrides {
+	overrides := &ruleOverrides{
+		claimedTransactions: make(map[*models.Transaction]bool),
+		removedStatements:   make(map[*models.BankStatement]bool),
+		excludedPairs:       make(map[string]bool),
+	}
+
+	set := me.Config.Rules
+	if set == nil {
+		return overrides
+	}
+
+	for _, rule := range set.Exclusions {
+		overrides.excludedPairs[pairKey(rule.TrxID, rule.StatementID)] = true
+	}
+
+	if len(set.Matches) > 0 {
+		transactions := make(map[string]*models.Transaction, len(me.TransactionIndex.AllTransactions))
+		for _, tx := range me.TransactionIndex.AllTransactions {
+			if _, exists := transactions[tx.TrxID]; !exists {
+				transactions[tx.TrxID] = tx
+			}
+		}
+		statements := make(map[string]*models.BankStatement, len(me.BankStatementIndex.AllStatements))
+		for _, stmt := range me.BankStatementIndex.AllStatements {
+			if _, exists := statements[stmt.UniqueIdentifier]; !exists {
+				statements[stmt.UniqueIdentifier] = stmt
+			}
+		}
+
+		for _, rule := range set.Matches {
+			tx, stmt := transactions[rule.TrxID], statements[rule.StatementID]
+			if tx == nil || stmt == nil {
+				me.logger.WithFields(logger.Fields{
+					"rule":           rule.Name,
+					"transaction_id": rule.TrxID,
+					"statement_id":   rule.StatementID,
+				}).Warn("Match rule refers to a transaction or statement that is not loaded")
+				continue
+			}
+
+			result, err := me.scoreMatch(tx, stmt)
+			if err != nil {
+				me.logger.WithError(err).WithField("rule", rule.Name).Warn("Failed to score manual match")
+				continue
+			}
+			result.MatchType = MatchManual
+			result.Rule = rule.Name
+			result.Reasons = append([]string{fmt.Sprintf("Manual match by rule %q", rule.Name)}, result.Reasons...)
+
+			overrides.manualMatches = append(overrides.manualMatches, result)
+			overrides.claim(tx, stmt)
+		}
+	}
+
+	if len(set.Ignores) > 0 {
+		for _, stmt := range me.BankStatementIndex.AllStatements {
+			if overrides.removedStatements[stmt] {
+				continue
+			}
+			if rule := set.IgnoreRuleFor(stmt); rule != nil {
+				overrides.ignored = append(overrides.ignored, &IgnoredStatement{Statement: stmt, Rule: rule.Name})
+				overrides.removedStatements[stmt] = true
+			}
+		}
+	}
+
+	me.logger.WithFields(logger.Fields{
+		"manual_matches":     len(overrides.manualMatches),
+		"excluded_pairs":     len(overrides.excludedPairs),
+		"ignored_statements": len(overrides.ignored),
+	}).Info("Applied matching rules")
+
+	return overrides
+}
+
+// claim takes a pair out of the scoring passes once it has been matched
+// ahead of them
+func (o *ruleOverrides) claim(tx *models.Transaction, stmt *models.BankStatement) {
+	o.claimedTransactions[tx] = true
+	o.removedStatements[stmt] = true
+}
+
+// candidatesFor removes the statements a transaction may not be scored
+// against. A transaction already matched ahead of scoring, manually or by
+// reference, gets no candidates at all.
+func (o *ruleOverrides) candidatesFor(tx *models.Transaction, candidates []*models.BankStatement) []*models.BankStatement {
+	if o == nil {
+		return candidates
+	}
+	if o.claimedTransactions[tx] {
+		return nil
+	}
+	if len(o.removedStatements) == 0 && len(o.excludedPairs) == 0 {
+		return candidates
+	}
+
+	allowed := make([]*models.BankStatement, 0, len(candidates))
+	for _, stmt := range candidates {
+		if o.removedStatements[stmt] || o.excludedPairs[pairKey(tx.TrxID, stmt.UniqueIdentifier)] {
+			continue
+		}
+		allowed = append(allowed, stmt)
+	}
+	return allowed
+}
+
+// available reports whether neither side of a pair has been taken and the
+// pair is not excluded
+func (o *ruleOverrides) available(tx *models.Transaction, stmt *models.BankStatement) bool {
+	return tx != nil && stmt != nil && !o.claimedTransactions[tx] && !o.removedStatements[stmt] &&
+		!o.excludedPairs[pairKey(tx.TrxID, stmt.UniqueIdentifier)]
+}
+
+// groupAvailable reports whether none of the items in a group has been taken
+func (o *ruleOverrides) groupAvailable(group *GroupMatch) bool {
+	for _, tx := range group.Transactions {
+		if o.claimedTransactions[tx] {
+			return false
+		}
+	}
+	for _, stmt := range group.Statements {
+		if o.removedStatements[stmt] {
+			return false
+		}
+	}
+	return len(group.Transactions) > 0 && len(group.Statements) > 0
+}
+
+// isRemoved reports whether a statement was taken out of matching by a rule,
+// either by a manual match or by an ignore rule
+func (o *ruleOverrides) isRemoved(stmt *models.BankStatement) bool {
+	return o != nil && o.removedStatements[stmt]
+}
+
+func pairKey(trxID, statementID string) string {
+	return trxID + "\x00" + statementID
+}
@@ -0,0 +1,119 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/rules"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestMatchingEngine_Reconcile_Rules(t *testing.T) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transactions := []*models.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromInt(200), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromInt(300), Type: models.TransactionTypeCredit, TransactionTime: day},
+	}
+	statements := []*models.BankStatement{
+		// BS001 would be an exact match for TX001 but is forced onto TX002
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: day},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromInt(100), Date: day},
+		{UniqueIdentifier: "BS003", Amount: decimal.NewFromInt(300), Date: day},
+		{UniqueIdentifier: "FEE-01", Amount: decimal.NewFromInt(300), Date: day, Description: "service fee"},
+	}
+
+	set, err := rules.ReadCSV(strings.NewReader(`action,trx_id,statement_id,id_prefix,name
+match,TX002,BS001,,agreed with treasury
+exclude,TX003,BS003,,
+ignore,,,FEE-,bank fees
+`))
+	if err != nil {
+		t.Fatalf("Failed to read rules: %v", err)
+	}
+
+	for _, mode := range []AssignmentMode{AssignmentGreedy, AssignmentOptimal} {
+		t.Run(mode.String(), func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			config.AssignmentMode = mode
+			config.Rules = set
+
+			engine := NewMatchingEngine(config)
+			if err := engine.LoadTransactions(transactions); err != nil {
+				t.Fatalf("Failed to load transactions: %v", err)
+			}
+			if err := engine.LoadBankStatements(statements); err != nil {
+				t.Fatalf("Failed to load bank statements: %v", err)
+			}
+
+			result, err := engine.Reconcile()
+			if err != nil {
+				t.Fatalf("Reconcile failed: %v", err)
+			}
+
+			pairs := make(map[string]*MatchResult)
+			for _, match := range result.Matches {
+				pairs[match.Transaction.TrxID] = match
+			}
+
+			manual := pairs["TX002"]
+			if manual == nil || manual.BankStatement.UniqueIdentifier != "BS001" {
+				t.Fatalf("Expected TX002 to be manually matched to BS001, got %+v", manual)
+			}
+			if manual.MatchType != MatchManual || manual.Rule != "agreed with treasury" {
+				t.Errorf("Expected a Manual match by 'agreed with treasury', got %s by %q", manual.MatchType, manual.Rule)
+			}
+			if !strings.Contains(manual.Reasons[0], "agreed with treasury") {
+				t.Errorf("Expected the first reason to name the rule, got %v", manual.Reasons)
+			}
+
+			if match := pairs["TX001"]; match == nil || match.BankStatement.UniqueIdentifier != "BS002" {
+				t.Errorf("Expected TX001 to fall back to BS002, got %+v", match)
+			}
+			if match := pairs["TX003"]; match != nil {
+				t.Errorf("Expected TX003 to stay unmatched (BS003 excluded, FEE-01 ignored), got %s", match.BankStatement.UniqueIdentifier)
+			}
+
+			if len(result.IgnoredStatements) != 1 || result.IgnoredStatements[0].Statement.UniqueIdentifier != "FEE-01" ||
+				result.IgnoredStatements[0].Rule != "bank fees" {
+				t.Errorf("Expected FEE-01 to be ignored by 'bank fees', got %+v", result.IgnoredStatements)
+			}
+			if len(result.UnmatchedStatements) != 1 || result.UnmatchedStatements[0].UniqueIdentifier != "BS003" {
+				t.Errorf("Expected only BS003 unmatched, got %d statements", len(result.UnmatchedStatements))
+			}
+
+			if result.Summary.ManualMatches != 1 || result.Summary.IgnoredStatements != 1 {
+				t.Errorf("Expected 1 manual match and 1 ignored statement in summary, got %d and %d",
+					result.Summary.ManualMatches, result.Summary.IgnoredStatements)
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_Reconcile_RuleForUnknownItem(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.Rules = &rules.Set{Matches: []*rules.PairRule{{Name: "stale", TrxID: "TX404", StatementID: "BS001"}}}
+	if err := config.Rules.Compile(); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	transactions, statements := createSplitSettlementData()
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if result.Summary.ManualMatches != 0 {
+		t.Errorf("Expected a rule for an unknown transaction to be skipped, got %d manual matches", result.Summary.ManualMatches)
+	}
+}
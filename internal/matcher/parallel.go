@@ -0,0 +1,155 @@
+package matcher
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// defaultPartitionDays is the width of a date partition when PartitionDays
+// is not set
+const defaultPartitionDays = 7
+
+// datePartition is a window of consecutive days whose transactions are
+// scored together by one worker. Candidates come from the shared statement
+// index, so the statement windows of neighbouring partitions overlap by the
+// date tolerance and a statement near a boundary can be scored in both.
+// Such conflicts are resolved afterwards by the same selection serial mode
+// uses, in input order, so the outcome does not depend on which worker
+// finished first.
+type datePartition struct {
+	start        time.Time
+	transactions []int // positions in the transaction list
+}
+
+// workers returns the number of workers scoring candidates
+func (me *MatchingEngine) workers() int {
+	if me.Config.Parallelism < 1 {
+		return 1
+	}
+	return me.Config.Parallelism
+}
+
+// partitionByDate groups transactions into windows of PartitionDays by their
+// normalised date. Partitions are ordered by date and keep the input order
+// of their transactions.
+func (me *MatchingEngine) partitionByDate(transactions []*models.Transaction) []*datePartition {
+	days := me.Config.PartitionDays
+	if days <= 0 {
+		days = defaultPartitionDays
+	}
+	width := time.Duration(days) * 24 * time.Hour
+
+	byStart := make(map[time.Time]*datePartition)
+	var partitions []*datePartition
+	for i, tx := range transactions {
+		normalized := me.Config.NormalizeTime(tx.TransactionTime)
+		day := time.Date(normalized.Year(), normalized.Month(), normalized.Day(), 0, 0, 0, 0, time.UTC)
+		start := day.Truncate(width)
+
+		partition, exists := byStart[start]
+		if !exists {
+			partition = &datePartition{start: start}
+			byStart[start] = partition
+			partitions = append(partitions, partition)
+		}
+		partition.transactions = append(partition.transactions, i)
+	}
+
+	sort.Slice(partitions, func(i, j int) bool {
+		return partitions[i].start.Before(partitions[j].start)
+	})
+	return partitions
+}
+
+// scoreTransactions scores the candidates of every transaction and returns
+// them by transaction position, best first, keeping only the pairs accept
+// accepts and at most limit of them when limit is positive. With more than
+// one worker the date partitions are scored concurrently; the result is the
+// same either way.
+func (me *MatchingEngine) scoreTransactions(transactions []*models.Transaction, accept func(*MatchResult) bool, limit int) [][]*MatchResult {
+	scored := make([][]*MatchResult, len(transactions))
+	score := func(i int) {
+		results := me.scoreCandidatesFor(transactions[i], accept)
+		if limit > 0 && len(results) > limit {
+			results = results[:limit]
+		}
+		scored[i] = results
+	}
+
+	workers := me.workers()
+	if workers == 1 {
+		for i := range transactions {
+			score(i)
+		}
+		return scored
+	}
+
+	partitions := me.partitionByDate(transactions)
+	me.logger.WithFields(logger.Fields{
+		"partitions": len(partitions),
+		"workers":    workers,
+	}).Debug("Scoring date partitions concurrently")
+
+	// Each transaction belongs to exactly one partition, so workers never
+	// write the same slot
+	queue := make(chan *datePartition)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for partition := range queue {
+				for _, i := range partition.transactions {
+					score(i)
+				}
+			}
+		}()
+	}
+	for _, partition := range partitions {
+		queue <- partition
+	}
+	close(queue)
+	wg.Wait()
+
+	return scored
+}
+
+// solveBuckets solves the assignment buckets, concurrently when more than one
+// worker is configured, and returns the selected pairs in bucket order
+func (me *MatchingEngine) solveBuckets(buckets []*assignmentBucket, transactions []*models.Transaction, statementPos map[*models.BankStatement]int) []*MatchResult {
+	solved := make([][]*MatchResult, len(buckets))
+
+	workers := me.workers()
+	if workers == 1 {
+		for i, bucket := range buckets {
+			solved[i] = me.solveBucket(bucket, transactions, statementPos)
+		}
+	} else {
+		queue := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range queue {
+					solved[i] = me.solveBucket(buckets[i], transactions, statementPos)
+				}
+			}()
+		}
+		for i := range buckets {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+	}
+
+	var matches []*MatchResult
+	for _, bucketMatches := range solved {
+		matches = append(matches, bucketMatches...)
+	}
+	return matches
+}
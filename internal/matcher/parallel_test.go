@@ -0,0 +1,176 @@
+package matcher
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// createConflictingDataset builds a dataset in which many transactions
+// compete for the same statements across partition boundaries: amounts come
+// from a small set and dates are spread over two months with posting delays
+func createConflictingDataset(size int) ([]*models.Transaction, []*models.BankStatement) {
+	random := rand.New(rand.NewSource(42))
+	baseTime := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	transactions := make([]*models.Transaction, size)
+	statements := make([]*models.BankStatement, size)
+	for i := 0; i < size; i++ {
+		amount := decimal.NewFromInt(int64(random.Intn(25)+1) * 10)
+		txType := models.TransactionTypeCredit
+		if random.Intn(4) == 0 {
+			txType = models.TransactionTypeDebit
+		}
+		txTime := baseTime.AddDate(0, 0, random.Intn(60))
+		transactions[i] = &models.Transaction{
+			TrxID:           fmt.Sprintf("TX%05d", i),
+			Amount:          amount,
+			Type:            txType,
+			TransactionTime: txTime,
+		}
+
+		stmtAmount := amount
+		if random.Intn(5) == 0 {
+			stmtAmount = stmtAmount.Add(decimal.NewFromFloat(0.05))
+		}
+		if txType == models.TransactionTypeDebit {
+			stmtAmount = stmtAmount.Neg()
+		}
+		statements[i] = &models.BankStatement{
+			UniqueIdentifier: fmt.Sprintf("BS%05d", i),
+			Amount:           stmtAmount,
+			Date:             txTime.AddDate(0, 0, random.Intn(3)),
+		}
+	}
+
+	// Shuffle statements so that input order does not mirror the transactions
+	random.Shuffle(len(statements), func(i, j int) { statements[i], statements[j] = statements[j], statements[i] })
+	return transactions, statements
+}
+
+// describeResult flattens a result into comparable lines
+func describeResult(result *ReconciliationResult) []string {
+	var lines []string
+	for _, match := range result.Matches {
+		lines = append(lines, fmt.Sprintf("match %s %s %s %.6f %v", match.Transaction.TrxID,
+			match.BankStatement.UniqueIdentifier, match.MatchType, match.ConfidenceScore, match.Reasons))
+	}
+	for _, group := range result.GroupMatches {
+		line := "group " + string(group.GroupType)
+		for _, tx := range group.Transactions {
+			line += " " + tx.TrxID
+		}
+		for _, stmt := range group.Statements {
+			line += " " + stmt.UniqueIdentifier
+		}
+		lines = append(lines, line)
+	}
+	for _, tx := range result.UnmatchedTransactions {
+		lines = append(lines, "unmatched "+tx.TrxID)
+	}
+	for _, stmt := range result.UnmatchedStatements {
+		lines = append(lines, "unmatched "+stmt.UniqueIdentifier)
+	}
+	return lines
+}
+
+func TestMatchingEngine_Reconcile_ParallelIsDeterministic(t *testing.T) {
+	transactions, statements := createConflictingDataset(1000)
+
+	configs := map[string]func(*MatchingConfig){
+		"greedy": func(config *MatchingConfig) {},
+		"optimal": func(config *MatchingConfig) {
+			config.AssignmentMode = AssignmentOptimal
+		},
+		"strategies": func(config *MatchingConfig) {
+			config.Strategies = []string{StrategyExact, StrategyTolerance, StrategyFuzzy, StrategyPartial}
+			config.EnablePartialMatching = true
+		},
+	}
+
+	for name, configure := range configs {
+		t.Run(name, func(t *testing.T) {
+			run := func(parallelism, partitionDays int) []string {
+				config := DefaultMatchingConfig()
+				config.DateToleranceDays = 3
+				config.Parallelism = parallelism
+				config.PartitionDays = partitionDays
+				configure(config)
+
+				engine := NewMatchingEngine(config)
+				if err := engine.LoadTransactions(transactions); err != nil {
+					t.Fatalf("Failed to load transactions: %v", err)
+				}
+				if err := engine.LoadBankStatements(statements); err != nil {
+					t.Fatalf("Failed to load bank statements: %v", err)
+				}
+				result, err := engine.Reconcile()
+				if err != nil {
+					t.Fatalf("Reconcile failed: %v", err)
+				}
+				return describeResult(result)
+			}
+
+			serial := run(1, 0)
+			if len(serial) == 0 {
+				t.Fatal("Expected a non-empty serial result")
+			}
+			for _, partitionDays := range []int{1, 2, 7} {
+				for attempt := 0; attempt < 2; attempt++ {
+					if parallel := run(8, partitionDays); !reflect.DeepEqual(serial, parallel) {
+						t.Fatalf("Parallel result with %d-day partitions differs from serial mode", partitionDays)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_PartitionByDate(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	transactions := []*models.Transaction{
+		{TrxID: "TX1", TransactionTime: day.AddDate(0, 0, 9)},
+		{TrxID: "TX2", TransactionTime: day},
+		{TrxID: "TX3", TransactionTime: day.Add(23 * time.Hour)},
+		{TrxID: "TX4", TransactionTime: day.AddDate(0, 0, 1)},
+	}
+
+	config := DefaultMatchingConfig()
+	config.PartitionDays = 2
+	engine := NewMatchingEngine(config)
+
+	var got [][]int
+	for _, partition := range engine.partitionByDate(transactions) {
+		got = append(got, partition.transactions)
+	}
+	// Partitions are aligned two-day windows ordered by date
+	want := [][]int{{1, 2, 3}, {0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected partitions %v, got %v", want, got)
+	}
+}
+
+func BenchmarkMatchingEngine_Reconcile_Parallel(b *testing.B) {
+	transactions, statements := createConflictingDataset(5000)
+
+	for _, parallelism := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", parallelism), func(b *testing.B) {
+			config := DefaultMatchingConfig()
+			config.Parallelism = parallelism
+			engine := NewMatchingEngine(config)
+			engine.LoadTransactions(transactions)
+			engine.LoadBankStatements(statements)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				engine.Reconcile()
+			}
+		})
+	}
+}
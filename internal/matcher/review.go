@@ -0,0 +1,120 @@
+package matcher
+
+import (
+	"fmt"
+
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// ReviewReason explains why a match was held for review
+type ReviewReason string
+
+const (
+	ReviewLowConfidence    ReviewReason = "low_confidence"     // scored below AutoAcceptScore
+	ReviewAmbiguousSameDay ReviewReason = "ambiguous_same_day" // made on a day at or above AmbiguityReviewScore
+)
+
+// PendingMatch is a match held for a reviewer to accept or reject. Its items
+// are neither matched nor unmatched until a decision is applied.
+type PendingMatch struct {
+	Match  *MatchResult `json:"match"`
+	Reason ReviewReason `json:"reason"`
+
+	// AmbiguityScore is the same-day ambiguity of the day the match was made
+	// on, for ReviewAmbiguousSameDay
+	AmbiguityScore float64 `json:"ambiguity_score,omitempty"`
+}
+
+// DescribeReason renders why the match was held, with the ambiguity of its
+// day when that was the reason
+func (p *PendingMatch) DescribeReason() string {
+	if p.Reason == ReviewAmbiguousSameDay {
+		return fmt.Sprintf("%s (ambiguity %.2f)", p.Reason, p.AmbiguityScore)
+	}
+	return string(p.Reason)
+}
+
+// holdForReview moves the matches that need a reviewer from the matches to
+// the pending review queue: matches scoring below AutoAcceptScore and matches
+// made on an ambiguous day. Manual matches are never held. The statistics of
+// the pass that made a held match are updated to match.
+func (me *MatchingEngine) holdForReview(result *ReconciliationResult) {
+	ambiguous := me.ambiguousDays()
+	if me.Config.AutoAcceptScore <= 0 && len(ambiguous) == 0 {
+		return
+	}
+
+	// Manual matches come first, then the matches of each pass in turn
+	passes := make([]*PassStats, len(result.Matches))
+	i := len(me.overrides.manualMatches)
+	for _, stats := range result.Passes {
+		for j := 0; j < stats.Matches && i < len(passes); j++ {
+			passes[i] = stats
+			i++
+		}
+	}
+
+	kept := result.Matches[:0]
+	for i, match := range result.Matches {
+		pending := me.reviewFor(match, ambiguous)
+		if pending == nil {
+			kept = append(kept, match)
+			continue
+		}
+		result.PendingReview = append(result.PendingReview, pending)
+		if stats := passes[i]; stats != nil {
+			stats.Matches--
+			stats.TransactionsMatched--
+			stats.StatementsMatched--
+			stats.PendingReview++
+		}
+	}
+	result.Matches = kept
+
+	if len(result.PendingReview) > 0 {
+		me.logger.WithFields(logger.Fields{
+			"pending_review":    len(result.PendingReview),
+			"ambiguous_days":    len(ambiguous),
+			"auto_accept_score": me.Config.AutoAcceptScore,
+		}).Info("Held matches for review")
+	}
+}
+
+// reviewFor returns the review a match needs, or nil when it can be accepted
+func (me *MatchingEngine) reviewFor(match *MatchResult, ambiguous map[string]float64) *PendingMatch {
+	if match.MatchType == MatchManual {
+		return nil
+	}
+
+	day := me.Config.NormalizeTime(match.Transaction.TransactionTime).Format("2006-01-02")
+	if score, ok := ambiguous[day]; ok && me.Config.NormalizeTime(match.BankStatement.Date).Format("2006-01-02") == day {
+		return &PendingMatch{Match: match, Reason: ReviewAmbiguousSameDay, AmbiguityScore: score}
+	}
+	if me.Config.AutoAcceptScore > 0 && match.ConfidenceScore < me.Config.AutoAcceptScore {
+		return &PendingMatch{Match: match, Reason: ReviewLowConfidence}
+	}
+	return nil
+}
+
+// ambiguousDays returns the same-day ambiguity score of each day at or above
+// AmbiguityReviewScore, keyed by normalised date
+func (me *MatchingEngine) ambiguousDays() map[string]float64 {
+	if me.Config.AmbiguityReviewScore <= 0 {
+		return nil
+	}
+
+	handler := NewEdgeCaseHandler(me.Config)
+	days, err := handler.HandleSameDayTransactions(me.TransactionIndex.AllTransactions, me.BankStatementIndex.AllStatements, me)
+	if err != nil {
+		me.logger.WithError(err).Warn("Failed to score same-day ambiguity")
+		return nil
+	}
+
+	ambiguous := make(map[string]float64)
+	for _, day := range days {
+		if day.AmbiguityScore >= me.Config.AmbiguityReviewScore {
+			ambiguous[day.Date.Format("2006-01-02")] = day.AmbiguityScore
+		}
+	}
+	return ambiguous
+}
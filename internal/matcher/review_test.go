@@ -0,0 +1,208 @@
+package matcher
+
+import (
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func createReviewTestData() ([]*models.Transaction, []*models.BankStatement) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	// TX001 and TX002 compete for the two statements of the 15th, TX003 is an
+	// exact match on its own day and TX004 settles a day late
+	transactions := []*models.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "TX002", Amount: decimal.NewFromInt(100), Type: models.TransactionTypeCredit, TransactionTime: day},
+		{TrxID: "TX003", Amount: decimal.NewFromInt(300), Type: models.TransactionTypeCredit, TransactionTime: day.AddDate(0, 0, 5)},
+		{TrxID: "TX004", Amount: decimal.NewFromInt(400), Type: models.TransactionTypeCredit, TransactionTime: day.AddDate(0, 0, 10)},
+	}
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromInt(100), Date: day},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromInt(100), Date: day},
+		{UniqueIdentifier: "BS003", Amount: decimal.NewFromInt(300), Date: day.AddDate(0, 0, 5)},
+		{UniqueIdentifier: "BS004", Amount: decimal.NewFromInt(400), Date: day.AddDate(0, 0, 11)},
+	}
+	return transactions, statements
+}
+
+func TestMatchingEngine_HoldForReview(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(config *MatchingConfig)
+		accepted  []string
+		pending   map[string]ReviewReason
+	}{
+		{
+			name:      "review disabled",
+			configure: func(config *MatchingConfig) {},
+			accepted:  []string{"TX001", "TX002", "TX003", "TX004"},
+		},
+		{
+			name:      "low confidence",
+			configure: func(config *MatchingConfig) { config.AutoAcceptScore = 0.95 },
+			accepted:  []string{"TX001", "TX002", "TX003"},
+			pending:   map[string]ReviewReason{"TX004": ReviewLowConfidence},
+		},
+		{
+			name:      "ambiguous same day",
+			configure: func(config *MatchingConfig) { config.AmbiguityReviewScore = 0.5 },
+			accepted:  []string{"TX003", "TX004"},
+			pending:   map[string]ReviewReason{"TX001": ReviewAmbiguousSameDay, "TX002": ReviewAmbiguousSameDay},
+		},
+		{
+			name: "both",
+			configure: func(config *MatchingConfig) {
+				config.AutoAcceptScore = 0.95
+				config.AmbiguityReviewScore = 0.5
+			},
+			accepted: []string{"TX003"},
+			pending: map[string]ReviewReason{
+				"TX001": ReviewAmbiguousSameDay,
+				"TX002": ReviewAmbiguousSameDay,
+				"TX004": ReviewLowConfidence,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, statements := createReviewTestData()
+			config := DefaultMatchingConfig()
+			config.DateToleranceDays = 3
+			config.AssignmentMode = AssignmentOptimal
+			tt.configure(config)
+			result := reconcileNearMisses(t, config, transactions, statements)
+
+			if len(result.Matches) != len(tt.accepted) {
+				t.Fatalf("Expected %d accepted matches, got %d", len(tt.accepted), len(result.Matches))
+			}
+			for i, match := range result.Matches {
+				if match.Transaction.TrxID != tt.accepted[i] {
+					t.Errorf("Match %d: expected %s, got %s", i, tt.accepted[i], match.Transaction.TrxID)
+				}
+			}
+
+			if len(result.PendingReview) != len(tt.pending) {
+				t.Fatalf("Expected %d pending matches, got %d", len(tt.pending), len(result.PendingReview))
+			}
+			for _, pending := range result.PendingReview {
+				want, ok := tt.pending[pending.Match.Transaction.TrxID]
+				if !ok || pending.Reason != want {
+					t.Errorf("Unexpected pending match %s: %s", pending.Match.Transaction.TrxID, pending.Reason)
+				}
+				if pending.Reason == ReviewAmbiguousSameDay && pending.AmbiguityScore != 1.0 {
+					t.Errorf("Expected ambiguity score 1.0 for %s, got %.2f", pending.Match.Transaction.TrxID, pending.AmbiguityScore)
+				}
+				if pending.Reason == ReviewLowConfidence && pending.Match.ConfidenceScore >= config.AutoAcceptScore {
+					t.Errorf("Expected %s to score below %.2f, got %.2f", pending.Match.Transaction.TrxID, config.AutoAcceptScore, pending.Match.ConfidenceScore)
+				}
+			}
+
+			// Pending items are neither matched nor unmatched
+			if result.Summary.PendingReview != len(tt.pending) {
+				t.Errorf("Expected %d pending in summary, got %d", len(tt.pending), result.Summary.PendingReview)
+			}
+			if result.Summary.MatchedTransactions != len(tt.accepted) {
+				t.Errorf("Expected %d matched transactions in summary, got %d", len(tt.accepted), result.Summary.MatchedTransactions)
+			}
+			if len(result.UnmatchedTransactions) != 0 || len(result.UnmatchedStatements) != 0 {
+				t.Errorf("Expected nothing unmatched, got %d transactions and %d statements",
+					len(result.UnmatchedTransactions), len(result.UnmatchedStatements))
+			}
+		})
+	}
+}
+
+func TestMatchingEngine_HoldForReview_PassStats(t *testing.T) {
+	transactions, statements := createReviewTestData()
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 3
+	config.AssignmentMode = AssignmentOptimal
+	config.AutoAcceptScore = 0.95
+	config.Strategies = []string{StrategyExact, StrategyTolerance}
+	result := reconcileNearMisses(t, config, transactions, statements)
+
+	wantPasses := []struct {
+		strategy string
+		matches  int
+		pending  int
+	}{{StrategyExact, 3, 0}, {StrategyTolerance, 0, 1}}
+	if len(result.Passes) != len(wantPasses) {
+		t.Fatalf("Expected %d passes, got %d", len(wantPasses), len(result.Passes))
+	}
+	for i, want := range wantPasses {
+		got := result.Passes[i]
+		if got.Strategy != want.strategy || got.Matches != want.matches || got.PendingReview != want.pending {
+			t.Errorf("Pass %d: expected %s with %d matches and %d pending, got %s with %d and %d",
+				i, want.strategy, want.matches, want.pending, got.Strategy, got.Matches, got.PendingReview)
+		}
+		if got.TransactionsMatched != want.matches || got.StatementsMatched != want.matches {
+			t.Errorf("Pass %d: expected %d items matched on each side, got %d and %d",
+				i, want.matches, got.TransactionsMatched, got.StatementsMatched)
+		}
+	}
+}
+
+func TestEdgeCaseHandler_AmbiguityScore(t *testing.T) {
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tx := func(id string) *models.Transaction {
+		return &models.Transaction{TrxID: id, TransactionTime: day}
+	}
+	stmt := func(id string) *models.BankStatement {
+		return &models.BankStatement{UniqueIdentifier: id, Date: day}
+	}
+	match := func(trxID, stmtID string) *MatchResult {
+		return &MatchResult{Transaction: tx(trxID), BankStatement: stmt(stmtID)}
+	}
+
+	tests := []struct {
+		name    string
+		matches []*MatchResult
+		want    float64
+	}{
+		{"no matches", nil, 0.0},
+		{"one to one", []*MatchResult{match("TX1", "BS1"), match("TX2", "BS2")}, 0.0},
+		{"one contested statement", []*MatchResult{match("TX1", "BS1"), match("TX2", "BS1"), match("TX3", "BS3")}, 2.0 / 3.0},
+		{"all contested", []*MatchResult{match("TX1", "BS1"), match("TX1", "BS2"), match("TX2", "BS1"), match("TX2", "BS2")}, 1.0},
+	}
+
+	handler := NewEdgeCaseHandler(DefaultMatchingConfig())
+	transactions := []*models.Transaction{tx("TX1"), tx("TX2"), tx("TX3")}
+	statements := []*models.BankStatement{stmt("BS1"), stmt("BS2"), stmt("BS3")}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handler.calculateAmbiguityScore(transactions, statements, tt.matches); got != tt.want {
+				t.Errorf("Expected ambiguity score %.2f, got %.2f", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchingConfig_Validate_Review(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(config *MatchingConfig)
+		wantErr   bool
+	}{
+		{"disabled", func(config *MatchingConfig) {}, false},
+		{"auto accept above minimum", func(config *MatchingConfig) { config.AutoAcceptScore = 0.95 }, false},
+		{"auto accept below minimum", func(config *MatchingConfig) { config.AutoAcceptScore = 0.5 }, true},
+		{"auto accept above one", func(config *MatchingConfig) { config.AutoAcceptScore = 1.5 }, true},
+		{"ambiguity score", func(config *MatchingConfig) { config.AmbiguityReviewScore = 0.5 }, false},
+		{"negative ambiguity score", func(config *MatchingConfig) { config.AmbiguityReviewScore = -0.1 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			tt.configure(config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package matcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/errors"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// Names of the built-in matching strategies
+const (
+	StrategyExactID   = "exact_id"  // transaction ID carried by exactly one statement
+	StrategyExact     = "exact"     // exact amount on the same date
+	StrategyTolerance = "tolerance" // amount and date within tolerance
+	StrategyFuzzy     = "fuzzy"     // anything meeting the minimum confidence score
+	StrategyPartial   = "partial"   // grouped many-to-one and one-to-many matches
+
+	// StrategyAmountDate is the older name of StrategyTolerance
+	StrategyAmountDate = "amount_date"
+)
+
+// DefaultStrategies runs the built-in passes from the most to the least certain
+var DefaultStrategies = []string{StrategyExactID, StrategyExact, StrategyTolerance, StrategyFuzzy, StrategyPartial}
+
+// MatchingStrategy is one pass of the multi-pass matching engine. Passes run
+// in the order of MatchingConfig.Strategies and each one only sees the items
+// left unmatched by the passes before it, so the most certain pairs are
+// settled before looser criteria get a chance to claim their items.
+type MatchingStrategy interface {
+	// Name identifies the strategy in MatchingConfig.Strategies and in the
+	// pass statistics
+	Name() string
+
+	// Match proposes matches among the items the pass makes available. A
+	// proposed pair or group that reuses an item already taken is dropped.
+	Match(pass *MatchingPass) (*PassResult, error)
+}
+
+// PassResult holds the matches proposed by a single pass
+type PassResult struct {
+	Matches      []*MatchResult
+	GroupMatches []*GroupMatch
+}
+
+// PassStats records what a single pass contributed to a reconciliation
+type PassStats struct {
+	Strategy            string        `json:"strategy"`
+	Matches             int           `json:"matches"`
+	GroupMatches        int           `json:"group_matches"`
+	TransactionsMatched int           `json:"transactions_matched"`
+	StatementsMatched   int           `json:"statements_matched"`
+	Rejected            int           `json:"rejected,omitempty"`       // proposals reusing an item already taken
+	PendingReview       int           `json:"pending_review,omitempty"` // matches held for review, not counted in Matches
+	Duration            time.Duration `json:"duration"`
+}
+
+// MatchingPass gives a strategy access to the items still unmatched and to
+// the engine's candidate lookup, scoring and assignment
+type MatchingPass struct {
+	engine *MatchingEngine
+}
+
+// Config returns the engine configuration. It must not be modified.
+func (p *MatchingPass) Config() *MatchingConfig {
+	return p.engine.Config
+}
+
+// Transactions returns the transactions not matched yet, in input order
+func (p *MatchingPass) Transactions() []*models.Transaction {
+	var transactions []*models.Transaction
+	for _, tx := range p.engine.TransactionIndex.AllTransactions {
+		if !p.engine.overrides.claimedTransactions[tx] {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions
+}
+
+// Statements returns the bank statements not matched or ignored yet, in
+// input order
+func (p *MatchingPass) Statements() []*models.BankStatement {
+	var statements []*models.BankStatement
+	for _, stmt := range p.engine.BankStatementIndex.AllStatements {
+		if !p.engine.overrides.isRemoved(stmt) {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// Candidates returns the unmatched statements the index offers for a
+// transaction, leaving out pairs excluded by the matching rules
+func (p *MatchingPass) Candidates(tx *models.Transaction) []*models.BankStatement {
+	return p.engine.overrides.candidatesFor(tx, p.engine.BankStatementIndex.GetCandidates(tx, p.engine.Config))
+}
+
+// Score scores a transaction against a bank statement with the configured
+// criteria and weights
+func (p *MatchingPass) Score(tx *models.Transaction, stmt *models.BankStatement) (*MatchResult, error) {
+	return p.engine.scoreMatch(tx, stmt)
+}
+
+// SelectMatches chooses one-to-one pairs among the unmatched items with the
+// configured assignment mode, considering only the scored pairs accept
+// accepts. Pairs below the minimum confidence score are never selected.
+func (p *MatchingPass) SelectMatches(accept func(*MatchResult) bool) []*MatchResult {
+	return p.engine.selectMatches(accept)
+}
+
+// reconcileWithStrategies runs the configured strategies in order. After
+// each pass the items in its accepted matches are claimed, so later passes
+// cannot see them.
+func (me *MatchingEngine) reconcileWithStrategies() (*ReconciliationResult, error) {
+	strategies := make([]MatchingStrategy, 0, len(me.Config.Strategies))
+	for _, name := range me.Config.Strategies {
+		strategy := DefaultStrategyRegistry.Get(name)
+		if strategy == nil {
+			return nil, errors.ValidationError(
+				errors.CodeInvalidConfig,
+				"strategies",
+				name,
+				nil,
+			).WithSuggestion(fmt.Sprintf("Use one of the registered strategies: %s", strings.Join(DefaultStrategyRegistry.Names(), ", ")))
+		}
+		strategies = append(strategies, strategy)
+	}
+
+	result := &ReconciliationResult{Matches: append([]*MatchResult(nil), me.overrides.manualMatches...)}
+	pass := &MatchingPass{engine: me}
+
+	for _, strategy := range strategies {
+		started := time.Now()
+		proposed, err := strategy.Match(pass)
+		if err != nil {
+			return nil, errors.ReconciliationError(
+				errors.CodeProcessingError,
+				"matching_strategy",
+				fmt.Errorf("%s: %w", strategy.Name(), err),
+			).WithSuggestion("Check the configuration of the failing matching strategy")
+		}
+
+		stats := me.acceptPass(strategy.Name(), proposed, result)
+		stats.Duration = time.Since(started)
+		result.Passes = append(result.Passes, stats)
+
+		me.logger.WithFields(logger.Fields{
+			"strategy":             stats.Strategy,
+			"matches":              stats.Matches,
+			"group_matches":        stats.GroupMatches,
+			"transactions_matched": stats.TransactionsMatched,
+			"rejected":             stats.Rejected,
+			"duration":             stats.Duration,
+		}).Debug("Completed matching pass")
+	}
+
+	result.UnmatchedTransactions = pass.Transactions()
+	result.UnmatchedStatements = pass.Statements()
+	return result, nil
+}
+
+// acceptPass claims the items of the matches a pass proposed and adds them
+// to the result. Proposals reusing an item already taken, by this pass or an
+// earlier one, are dropped.
+func (me *MatchingEngine) acceptPass(name string, proposed *PassResult, result *ReconciliationResult) *PassStats {
+	stats := &PassStats{Strategy: name}
+	if proposed == nil {
+		return stats
+	}
+
+	for _, match := range proposed.Matches {
+		if match == nil || !me.overrides.available(match.Transaction, match.BankStatement) {
+			stats.Rejected++
+			continue
+		}
+		me.overrides.claim(match.Transaction, match.BankStatement)
+		result.Matches = append(result.Matches, match)
+		stats.Matches++
+		stats.TransactionsMatched++
+		stats.StatementsMatched++
+	}
+
+	for _, group := range proposed.GroupMatches {
+		if group == nil || !me.overrides.groupAvailable(group) {
+			stats.Rejected++
+			continue
+		}
+		for _, tx := range group.Transactions {
+			me.overrides.claimedTransactions[tx] = true
+		}
+		for _, stmt := range group.Statements {
+			me.overrides.removedStatements[stmt] = true
+		}
+		result.GroupMatches = append(result.GroupMatches, group)
+		stats.GroupMatches++
+		stats.TransactionsMatched += len(group.Transactions)
+		stats.StatementsMatched += len(group.Statements)
+	}
+
+	if stats.Rejected > 0 {
+		me.logger.WithFields(logger.Fields{
+			"strategy": name,
+			"rejected": stats.Rejected,
+		}).Warn("Matching strategy proposed items that were already matched")
+	}
+	return stats
+}
+
+// StrategyRegistry holds the matching strategies known by name. Names are
+// compared case-insensitively.
+type StrategyRegistry struct {
+	mu         sync.RWMutex
+	strategies map[string]MatchingStrategy
+}
+
+// DefaultStrategyRegistry contains the built-in strategies. Custom strategies
+// registered here can be named in MatchingConfig.Strategies.
+var DefaultStrategyRegistry = NewStrategyRegistry(builtinStrategies()...)
+
+// NewStrategyRegistry creates a registry containing the given strategies. It
+// panics if one of them is invalid, so it is meant for built-in strategies.
+func NewStrategyRegistry(strategies ...MatchingStrategy) *StrategyRegistry {
+	registry := &StrategyRegistry{strategies: make(map[string]MatchingStrategy)}
+	for _, strategy := range strategies {
+		if err := registry.Register(strategy); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// Register adds a strategy to the registry, replacing any strategy of the
+// same name
+func (r *StrategyRegistry) Register(strategy MatchingStrategy) error {
+	if strategy == nil {
+		return fmt.Errorf("matching strategy cannot be nil")
+	}
+	key := strategyKey(strategy.Name())
+	if key == "" {
+		return fmt.Errorf("matching strategy name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.strategies[key] = strategy
+	return nil
+}
+
+// Get returns the strategy with the given name, or nil when none is registered
+func (r *StrategyRegistry) Get(name string) MatchingStrategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.strategies[strategyKey(name)]
+}
+
+// Names returns the names of the registered strategies in alphabetical order
+func (r *StrategyRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.strategies))
+	for key := range r.strategies {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func strategyKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func builtinStrategies() []MatchingStrategy {
+	exact := func(result *MatchResult) bool { return result.MatchType == MatchExact }
+	tolerance := func(result *MatchResult) bool {
+		return result.MatchType == MatchExact || result.MatchType == MatchClose
+	}
+
+	return []MatchingStrategy{
+		referenceStrategy{},
+		&assignmentStrategy{name: StrategyExact, accept: exact},
+		&assignmentStrategy{name: StrategyTolerance, accept: tolerance},
+		&assignmentStrategy{name: StrategyAmountDate, accept: tolerance},
+		&assignmentStrategy{name: StrategyFuzzy},
+		groupStrategy{},
+	}
+}
+
+// referenceStrategy is the exact-ID fast path as a pass of its own. Like the
+// fast path, it only runs when identifiers are weighted.
+type referenceStrategy struct{}
+
+func (referenceStrategy) Name() string { return StrategyExactID }
+
+func (referenceStrategy) Match(pass *MatchingPass) (*PassResult, error) {
+	if pass.Config().Weights.IdentifierWeight <= 0 {
+		return &PassResult{}, nil
+	}
+	return &PassResult{Matches: pass.engine.matchByReference()}, nil
+}
+
+// assignmentStrategy runs the configured assignment over the scored pairs
+// its filter accepts; a nil filter accepts every pair
+type assignmentStrategy struct {
+	name   string
+	accept func(*MatchResult) bool
+}
+
+func (s *assignmentStrategy) Name() string { return s.name }
+
+func (s *assignmentStrategy) Match(pass *MatchingPass) (*PassResult, error) {
+	return &PassResult{Matches: pass.SelectMatches(s.accept)}, nil
+}
+
+// groupStrategy runs the many-to-one and one-to-many group passes, as far
+// as they are enabled in the configuration
+type groupStrategy struct{}
+
+func (groupStrategy) Name() string { return StrategyPartial }
+
+func (groupStrategy) Match(pass *MatchingPass) (*PassResult, error) {
+	groups, transactions, statements := pass.engine.matchPartialGroups(pass.Transactions(), pass.Statements())
+	batches, _, _ := pass.engine.matchBatchedStatements(transactions, statements)
+	return &PassResult{GroupMatches: append(groups, batches...)}, nil
+}
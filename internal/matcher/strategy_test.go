@@ -0,0 +1,139 @@
+package matcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// duplicateStrategy proposes the first remaining transaction with the last
+// remaining statement twice, so the second proposal must be rejected
+type duplicateStrategy struct{}
+
+func (duplicateStrategy) Name() string { return "test_duplicate" }
+
+func (duplicateStrategy) Match(pass *MatchingPass) (*PassResult, error) {
+	transactions, statements := pass.Transactions(), pass.Statements()
+	if len(transactions) == 0 || len(statements) == 0 {
+		return &PassResult{}, nil
+	}
+
+	result, err := pass.Score(transactions[0], statements[len(statements)-1])
+	if err != nil {
+		return nil, err
+	}
+	return &PassResult{Matches: []*MatchResult{result, result}}, nil
+}
+
+func createStrategyTestData() ([]*models.Transaction, []*models.BankStatement) {
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	transactions := []*models.Transaction{
+		{TrxID: "TX001", Amount: decimal.NewFromFloat(100.00), Type: models.TransactionTypeCredit, TransactionTime: day.AddDate(0, 0, 1)},
+		{TrxID: "TX002", Amount: decimal.NewFromFloat(100.00), Type: models.TransactionTypeCredit, TransactionTime: day},
+	}
+	statements := []*models.BankStatement{
+		{UniqueIdentifier: "BS001", Amount: decimal.NewFromFloat(100.00), Date: day},
+		{UniqueIdentifier: "BS002", Amount: decimal.NewFromFloat(100.00), Date: day.AddDate(0, 0, 2)},
+	}
+	return transactions, statements
+}
+
+func TestMatchingEngine_Reconcile_Strategies(t *testing.T) {
+	transactions, statements := createStrategyTestData()
+
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 3
+	config.Strategies = []string{StrategyExact, StrategyTolerance, StrategyFuzzy}
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	// The exact pass settles TX002 first, so TX001 cannot take BS001 from it
+	expected := map[string]string{"TX002": "BS001", "TX001": "BS002"}
+	if len(result.Matches) != len(expected) {
+		t.Fatalf("Expected %d matches, got %d", len(expected), len(result.Matches))
+	}
+	for _, match := range result.Matches {
+		if want := expected[match.Transaction.TrxID]; match.BankStatement.UniqueIdentifier != want {
+			t.Errorf("Expected %s to match %s, got %s", match.Transaction.TrxID, want, match.BankStatement.UniqueIdentifier)
+		}
+	}
+
+	wantPasses := []struct {
+		strategy string
+		matches  int
+	}{{StrategyExact, 1}, {StrategyTolerance, 1}, {StrategyFuzzy, 0}}
+	if len(result.Passes) != len(wantPasses) {
+		t.Fatalf("Expected %d passes, got %d", len(wantPasses), len(result.Passes))
+	}
+	for i, want := range wantPasses {
+		if got := result.Passes[i]; got.Strategy != want.strategy || got.Matches != want.matches {
+			t.Errorf("Pass %d: expected %s with %d matches, got %s with %d", i, want.strategy, want.matches, got.Strategy, got.Matches)
+		}
+	}
+	if len(result.UnmatchedTransactions) != 0 || len(result.UnmatchedStatements) != 0 {
+		t.Errorf("Expected nothing left unmatched, got %d transactions and %d statements",
+			len(result.UnmatchedTransactions), len(result.UnmatchedStatements))
+	}
+}
+
+func TestMatchingEngine_Reconcile_CustomStrategy(t *testing.T) {
+	if err := DefaultStrategyRegistry.Register(duplicateStrategy{}); err != nil {
+		t.Fatalf("Failed to register strategy: %v", err)
+	}
+	if DefaultStrategyRegistry.Get("TEST_DUPLICATE") == nil {
+		t.Fatal("Expected strategy names to be case-insensitive")
+	}
+
+	transactions, statements := createStrategyTestData()
+
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 3
+	config.Strategies = []string{"test_duplicate", StrategyFuzzy}
+	engine := NewMatchingEngine(config)
+	if err := engine.LoadTransactions(transactions); err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	if err := engine.LoadBankStatements(statements); err != nil {
+		t.Fatalf("Failed to load bank statements: %v", err)
+	}
+
+	result, err := engine.Reconcile()
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	custom := result.Passes[0]
+	if custom.Matches != 1 || custom.Rejected != 1 {
+		t.Errorf("Expected 1 accepted and 1 rejected proposal, got %+v", custom)
+	}
+	if result.Matches[0].Transaction.TrxID != "TX001" || result.Matches[0].BankStatement.UniqueIdentifier != "BS002" {
+		t.Errorf("Expected the custom pass to pair TX001 with BS002, got %s/%s",
+			result.Matches[0].Transaction.TrxID, result.Matches[0].BankStatement.UniqueIdentifier)
+	}
+	if result.Passes[1].Matches != 1 || len(result.Matches) != 2 {
+		t.Errorf("Expected the fuzzy pass to match the remaining pair, got %+v", result.Passes[1])
+	}
+}
+
+func TestMatchingConfig_Validate_UnknownStrategy(t *testing.T) {
+	config := DefaultMatchingConfig()
+	config.DateToleranceDays = 3
+	config.Strategies = []string{StrategyExact, "nearest"}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), `"nearest"`) {
+		t.Errorf("Expected an unknown strategy error, got %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// NumberLocale describes how amounts are written in a source file.
+//
+// Parsing with a locale is strict: separators must appear where the locale
+// expects them, so "1.234,56" is rejected by a US locale instead of being
+// read as 1.23456.
+type NumberLocale struct {
+	Name string `json:"name,omitempty"`
+
+	// DecimalSeparator separates the integer and fractional parts ("." or ",")
+	DecimalSeparator string `json:"decimal_separator"`
+
+	// GroupingSeparator separates thousands ("," "." " " "'"), or "" for none.
+	// When set, groups after the first must be exactly three digits long.
+	GroupingSeparator string `json:"grouping_separator,omitempty"`
+
+	// CurrencySymbols are symbols or codes that may prefix or suffix the
+	// amount, e.g. "Rp", "IDR", "€". Codes are matched case-insensitively.
+	CurrencySymbols []string `json:"currency_symbols,omitempty"`
+
+	// ParenthesesNegative reads "(1,234.56)" as a negative amount
+	ParenthesesNegative bool `json:"parentheses_negative,omitempty"`
+
+	// TrailingMinus reads "1,234.56-" as a negative amount
+	TrailingMinus bool `json:"trailing_minus,omitempty"`
+}
+
+// Predefined number locales
+var (
+	// NumberLocaleUS is the default: 1,234,567.89 with $ or USD
+	NumberLocaleUS = &NumberLocale{
+		Name:                "us",
+		DecimalSeparator:    ".",
+		GroupingSeparator:   ",",
+		CurrencySymbols:     []string{"$", "USD"},
+		ParenthesesNegative: true,
+		TrailingMinus:       true,
+	}
+
+	// NumberLocaleID is Indonesian: Rp 1.234.567,89
+	NumberLocaleID = &NumberLocale{
+		Name:                "id",
+		DecimalSeparator:    ",",
+		GroupingSeparator:   ".",
+		CurrencySymbols:     []string{"Rp.", "Rp", "IDR"},
+		ParenthesesNegative: true,
+		TrailingMinus:       true,
+	}
+
+	// NumberLocaleEU is continental European: 1.234.567,89 €
+	NumberLocaleEU = &NumberLocale{
+		Name:                "eu",
+		DecimalSeparator:    ",",
+		GroupingSeparator:   ".",
+		CurrencySymbols:     []string{"€", "EUR"},
+		ParenthesesNegative: true,
+		TrailingMinus:       true,
+	}
+
+	// DefaultNumberLocale is used when a parser has no locale configured
+	DefaultNumberLocale = NumberLocaleUS
+)
+
+// GetNumberLocale returns a predefined number locale by name
+func GetNumberLocale(name string) (*NumberLocale, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "us", "en-us":
+		return NumberLocaleUS, nil
+	case "id", "id-id":
+		return NumberLocaleID, nil
+	case "eu", "de-de", "nl-nl":
+		return NumberLocaleEU, nil
+	default:
+		return nil, fmt.Errorf("unknown number locale '%s': must be us, id or eu", name)
+	}
+}
+
+// UnmarshalText resolves a predefined locale by name, so configuration files
+// can write number_locale = "id"
+func (nl *NumberLocale) UnmarshalText(text []byte) error {
+	locale, err := GetNumberLocale(string(text))
+	if err != nil {
+		return err
+	}
+	*nl = *locale
+	nl.CurrencySymbols = append([]string(nil), locale.CurrencySymbols...)
+	return nil
+}
+
+// UnmarshalJSON accepts a predefined locale name or a locale object. An
+// object whose name is a predefined locale starts from that locale, so only
+// the settings that differ need to be given.
+func (nl *NumberLocale) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return nl.UnmarshalText([]byte(name))
+	}
+
+	var named struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+
+	type plain NumberLocale
+	locale := plain{}
+	if strings.TrimSpace(named.Name) != "" {
+		if base, err := GetNumberLocale(named.Name); err == nil {
+			locale = plain(*base)
+			locale.CurrencySymbols = append([]string(nil), base.CurrencySymbols...)
+		}
+	}
+	if err := json.Unmarshal(data, &locale); err != nil {
+		return err
+	}
+	*nl = NumberLocale(locale)
+	return nil
+}
+
+// Validate checks that the locale separators are usable
+func (nl *NumberLocale) Validate() error {
+	if nl.DecimalSeparator != "." && nl.DecimalSeparator != "," {
+		return fmt.Errorf("decimal separator must be '.' or ',', got '%s'", nl.DecimalSeparator)
+	}
+
+	switch nl.GroupingSeparator {
+	case "", ",", ".", " ", "'":
+	default:
+		return fmt.Errorf("unsupported grouping separator '%s'", nl.GroupingSeparator)
+	}
+
+	if nl.GroupingSeparator == nl.DecimalSeparator {
+		return fmt.Errorf("grouping and decimal separators cannot both be '%s'", nl.DecimalSeparator)
+	}
+
+	return nil
+}
+
+// ParseDecimalWithLocale parses an amount written according to locale.
+// A nil locale uses DefaultNumberLocale. Currency symbols, a leading sign,
+// and (when enabled) parentheses or a trailing minus are accepted; anything
+// else that is not a correctly grouped number is rejected.
+//
+// Example:
+//
+//	amount, err := ParseDecimalWithLocale("Rp 1.234.567,89", NumberLocaleID)  // 1234567.89
+func ParseDecimalWithLocale(s string, locale *NumberLocale) (decimal.Decimal, error) {
+	if locale == nil {
+		locale = DefaultNumberLocale
+	}
+
+	original := s
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return decimal.Zero, fmt.Errorf("amount string cannot be empty")
+	}
+
+	negative := false
+	markNegative := func() error {
+		if negative {
+			return fmt.Errorf("amount '%s' has more than one negative sign", original)
+		}
+		negative = true
+		return nil
+	}
+
+	if locale.ParenthesesNegative && strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+		if err := markNegative(); err != nil {
+			return decimal.Zero, err
+		}
+	}
+
+	s = locale.stripCurrency(s)
+
+	switch {
+	case strings.HasPrefix(s, "-"):
+		s = s[1:]
+		if err := markNegative(); err != nil {
+			return decimal.Zero, err
+		}
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if locale.TrailingMinus && strings.HasSuffix(s, "-") {
+		s = s[:len(s)-1]
+		if err := markNegative(); err != nil {
+			return decimal.Zero, err
+		}
+	}
+
+	s = locale.stripCurrency(s)
+
+	digits, err := locale.normalizeNumber(s)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount '%s' for %s number format: %w", original, locale.describe(), err)
+	}
+
+	if negative {
+		digits = "-" + digits
+	}
+
+	return decimal.NewFromString(digits)
+}
+
+// stripCurrency removes a currency symbol or code from either end of s
+func (nl *NumberLocale) stripCurrency(s string) string {
+	s = strings.TrimSpace(s)
+	for _, symbol := range nl.CurrencySymbols {
+		if symbol == "" || len(s) < len(symbol) {
+			continue
+		}
+		if strings.EqualFold(s[:len(symbol)], symbol) {
+			return strings.TrimSpace(s[len(symbol):])
+		}
+		if strings.EqualFold(s[len(s)-len(symbol):], symbol) {
+			return strings.TrimSpace(s[:len(s)-len(symbol)])
+		}
+	}
+	return s
+}
+
+// normalizeNumber validates the grouping of an unsigned number and returns it
+// in the plain "1234.56" form accepted by decimal.NewFromString
+func (nl *NumberLocale) normalizeNumber(s string) (string, error) {
+	if nl.GroupingSeparator == " " {
+		s = strings.ReplaceAll(s, "\u00a0", " ")
+	}
+
+	parts := strings.Split(s, nl.DecimalSeparator)
+	if len(parts) > 2 {
+		return "", fmt.Errorf("more than one decimal separator")
+	}
+
+	// A fractional part alone, as in ".50", has an integer part of zero
+	integerPart := parts[0]
+	if integerPart == "" {
+		if len(parts) == 1 {
+			return "", fmt.Errorf("missing integer digits")
+		}
+		integerPart = "0"
+	}
+
+	if nl.GroupingSeparator != "" && strings.Contains(integerPart, nl.GroupingSeparator) {
+		groups := strings.Split(integerPart, nl.GroupingSeparator)
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return "", fmt.Errorf("misplaced grouping separator")
+		}
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", fmt.Errorf("misplaced grouping separator")
+			}
+		}
+		integerPart = strings.Join(groups, "")
+	}
+
+	if !isDigits(integerPart) {
+		return "", fmt.Errorf("unexpected characters")
+	}
+
+	if len(parts) == 1 {
+		return integerPart, nil
+	}
+
+	if !isDigits(parts[1]) {
+		return "", fmt.Errorf("unexpected characters in fractional part")
+	}
+
+	return integerPart + "." + parts[1], nil
+}
+
+// describe returns a short description of the locale for error messages
+func (nl *NumberLocale) describe() string {
+	if nl.Name != "" {
+		return fmt.Sprintf("'%s'", nl.Name)
+	}
+	return fmt.Sprintf("decimal '%s' grouping '%s'", nl.DecimalSeparator, nl.GroupingSeparator)
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
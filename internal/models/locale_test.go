@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimalWithLocale_MissingIntegerPart(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		locale    *NumberLocale
+		expected  string
+		wantError bool
+	}{
+		{"missing integer part", ".50", NumberLocaleUS, "0.5", false},
+		{"negative missing integer part", "-.50", NumberLocaleUS, "-0.5", false},
+		{"missing integer part with symbol", "$.99", NumberLocaleUS, "0.99", false},
+		{"indonesian missing integer part", ",50", NumberLocaleID, "0.5", false},
+		{"separator alone", ".", NumberLocaleUS, "", true},
+		{"us rejects exponent", "1.5e3", NumberLocaleUS, "", true},
+		{"nil locale missing integer part", ".50", nil, "0.5", false},
+		{"nil locale rejects exponent", "1.5e3", nil, "", true},
+		{"nil locale rejects european format", "1.234,56", nil, "", true},
+		{"nil locale rejects misplaced grouping", "1,2,3", nil, "", true},
+		{"nil locale rejects ungrouped commas", "12,34.5", nil, "", true},
+		{"nil locale parentheses negative", "(1,234.56)", nil, "-1234.56", false},
+		{"nil locale letters", "12a.00", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDecimalWithLocale(tt.input, tt.locale)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseDecimalWithLocale() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && result.String() != tt.expected {
+				t.Errorf("ParseDecimalWithLocale() = %s, want %s", result.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestNumberLocale_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		amount    string
+		expected  string
+		wantError bool
+	}{
+		{"predefined name", `"id"`, "Rp 1.234,50", "1234.5", false},
+		{"predefined name in object", `{"name": "eu"}`, "1.234,50 €", "1234.5", false},
+		{"predefined name with override", `{"name": "id", "currency_symbols": ["IDR"]}`, "IDR 1.000", "1000", false},
+		{"custom locale", `{"name": "ch", "decimal_separator": ".", "grouping_separator": "'"}`, "1'234.50", "1234.5", false},
+		{"unknown name", `"xx"`, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config struct {
+				NumberLocale *NumberLocale `json:"number_locale"`
+			}
+			err := json.Unmarshal([]byte(`{"number_locale": `+tt.input+`}`), &config)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Unmarshal() error = %v, wantError %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if err := config.NumberLocale.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			result, err := ParseDecimalWithLocale(tt.amount, config.NumberLocale)
+			if err != nil || result.String() != tt.expected {
+				t.Errorf("ParseDecimalWithLocale() = %s, %v; want %s", result.String(), err, tt.expected)
+			}
+		})
+	}
+
+	// Overrides apply to a copy, never to the predefined locale
+	if len(NumberLocaleID.CurrencySymbols) != 3 {
+		t.Errorf("expected the predefined locale to be unchanged, got %v", NumberLocaleID.CurrencySymbols)
+	}
+}
@@ -64,6 +64,7 @@ func (t TransactionType) IsValid() bool {
 //   - Amount: Transaction amount using decimal.Decimal for precise financial calculations
 //   - Type: Transaction type (DEBIT or CREDIT)
 //   - TransactionTime: Timestamp when the transaction occurred
+//   - Currency: Optional ISO 4217 currency code; empty means the base currency
 //
 // The struct supports JSON marshaling/unmarshaling and CSV parsing through struct tags.
 // Amounts are always stored as positive values, with the Type field indicating direction.
@@ -72,6 +73,8 @@ type Transaction struct {
 	Amount          decimal.Decimal `json:"amount" csv:"amount"`
 	Type            TransactionType `json:"type" csv:"type"`
 	TransactionTime time.Time       `json:"transactionTime" csv:"transactionTime"`
+	Currency        string          `json:"currency,omitempty" csv:"currency"`
+	Description     string          `json:"description,omitempty" csv:"description"`
 }
 
 // NewTransaction creates a new Transaction instance
@@ -163,7 +166,8 @@ func (t *Transaction) Equals(other *Transaction) bool {
 	return t.TrxID == other.TrxID &&
 		t.Amount.Equal(other.Amount) &&
 		t.Type == other.Type &&
-		t.TransactionTime.Equal(other.TransactionTime)
+		t.TransactionTime.Equal(other.TransactionTime) &&
+		t.Currency == other.Currency
 }
 
 // GetAbsoluteAmount returns the absolute value of the transaction amount
@@ -189,6 +193,9 @@ func (t *Transaction) IsCredit() bool {
 //   - UniqueIdentifier: Bank's unique identifier for the transaction
 //   - Amount: Transaction amount as reported by the bank (may be negative for debits)
 //   - Date: Date when the transaction was processed by the bank
+//   - Currency: Optional ISO 4217 currency code; empty means the base currency
+//   - SourceFile, BankName, SourceLine: Where the record was read from, set by
+//     the bank statement parser and used to group report output by bank
 //
 // Bank statements may use different conventions for representing debits and credits:
 //   - Some banks use negative amounts for debits, positive for credits
@@ -201,6 +208,11 @@ type BankStatement struct {
 	UniqueIdentifier string          `json:"unique_identifier" csv:"unique_identifier"`
 	Amount           decimal.Decimal `json:"amount" csv:"amount"`
 	Date             time.Time       `json:"date" csv:"date"`
+	Currency         string          `json:"currency,omitempty" csv:"currency"`
+	Description      string          `json:"description,omitempty" csv:"description"`
+	SourceFile       string          `json:"source_file,omitempty" csv:"-"`
+	BankName         string          `json:"bank_name,omitempty" csv:"-"`
+	SourceLine       int             `json:"source_line,omitempty" csv:"-"`
 }
 
 // NewBankStatement creates a new BankStatement instance
@@ -336,7 +348,8 @@ func (bs *BankStatement) Equals(other *BankStatement) bool {
 	
 	return bs.UniqueIdentifier == other.UniqueIdentifier &&
 		bs.Amount.Equal(other.Amount) &&
-		bs.Date.Format("2006-01-02") == other.Date.Format("2006-01-02")
+		bs.Date.Format("2006-01-02") == other.Date.Format("2006-01-02") &&
+		bs.Currency == other.Currency
 }
 
 // GetAbsoluteAmount returns the absolute value of the bank statement amount
@@ -423,6 +436,93 @@ func ParseTransactionType(s string) (TransactionType, error) {
 	}
 }
 
+// ParseCurrencyCode parses a three-letter ISO 4217 currency code.
+// The code is upper-cased; an empty string is returned unchanged.
+//
+// Example:
+//	code, err := ParseCurrencyCode(" idr ")  // Returns "IDR"
+func ParseCurrencyCode(s string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if code == "" {
+		return "", nil
+	}
+	
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency code '%s': must be a three-letter ISO 4217 code", s)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency code '%s': must be a three-letter ISO 4217 code", s)
+		}
+	}
+	
+	return code, nil
+}
+
+// DateOrder controls how ambiguous numeric dates such as "03/04/2024" are read.
+type DateOrder string
+
+const (
+	// DateOrderAuto keeps the historical behaviour of ParseTimeWithFormats:
+	// month-first for slash dates, day-first for dash dates.
+	DateOrderAuto DateOrder = ""
+	// DateOrderMonthFirst reads numeric dates as MM/DD/YYYY.
+	DateOrderMonthFirst DateOrder = "month_first"
+	// DateOrderDayFirst reads numeric dates as DD/MM/YYYY.
+	DateOrderDayFirst DateOrder = "day_first"
+)
+
+// ParseDateOrder parses a date order policy from string.
+// An empty string selects DateOrderAuto.
+func ParseDateOrder(s string) (DateOrder, error) {
+	switch DateOrder(strings.TrimSpace(s)) {
+	case DateOrderAuto:
+		return DateOrderAuto, nil
+	case DateOrderMonthFirst:
+		return DateOrderMonthFirst, nil
+	case DateOrderDayFirst:
+		return DateOrderDayFirst, nil
+	default:
+		return DateOrderAuto, fmt.Errorf("invalid date order '%s': must be %s or %s", s, DateOrderMonthFirst, DateOrderDayFirst)
+	}
+}
+
+// Unambiguous layouts shared by every date order
+var (
+	isoTimeLayouts = []string{
+		time.RFC3339,          // "2006-01-02T15:04:05Z07:00"
+		"2006-01-02 15:04:05", // "2006-01-02 15:04:05"
+		"2006-01-02T15:04:05", // "2006-01-02T15:04:05"
+		"2006-01-02",          // "2006-01-02"
+	}
+	namedMonthTimeLayouts = []string{
+		"2006/01/02",      // "2006/01/02"
+		"Jan 2, 2006",     // "Jan 2, 2006"
+		"January 2, 2006", // "January 2, 2006"
+	}
+)
+
+// TimeLayoutsForDateOrder returns the layouts tried for the given date order.
+// Month-first and day-first only include numeric layouts consistent with that
+// order, so an ambiguous date can never be read the other way round.
+func TimeLayoutsForDateOrder(order DateOrder) []string {
+	var numeric []string
+	switch order {
+	case DateOrderMonthFirst:
+		numeric = []string{"01/02/2006 15:04:05", "01/02/2006", "01-02-2006"}
+	case DateOrderDayFirst:
+		numeric = []string{"02/01/2006 15:04:05", "02/01/2006", "02-01-2006", "02.01.2006"}
+	default:
+		numeric = []string{"01/02/2006 15:04:05", "01/02/2006", "02-01-2006"}
+	}
+	
+	layouts := make([]string, 0, len(isoTimeLayouts)+len(numeric)+len(namedMonthTimeLayouts))
+	layouts = append(layouts, isoTimeLayouts...)
+	layouts = append(layouts, numeric...)
+	layouts = append(layouts, namedMonthTimeLayouts...)
+	return layouts
+}
+
 // ParseTimeWithFormats attempts to parse time from string using multiple common formats.
 // This function tries various date/time formats commonly found in CSV files:
 //   - RFC3339: "2006-01-02T15:04:05Z07:00"
@@ -432,32 +532,37 @@ func ParseTransactionType(s string) (TransactionType, error) {
 //   - Human readable: "Jan 2, 2006"
 //
 // Returns the first successfully parsed time or an error if none match.
+// Use ParseTimeWithDateOrder when the day/month order of the source is known.
 //
 // Example:
 //	t, err := ParseTimeWithFormats("2024-01-15T10:30:00Z")  // Parses as RFC3339
 func ParseTimeWithFormats(s string) (time.Time, error) {
+	return ParseTimeWithLayouts(s, TimeLayoutsForDateOrder(DateOrderAuto))
+}
+
+// ParseTimeWithDateOrder parses time like ParseTimeWithFormats, but resolves
+// ambiguous numeric dates according to the given order.
+//
+// Example:
+//	t, err := ParseTimeWithDateOrder("03/04/2024", DateOrderDayFirst)  // 3 April 2024
+func ParseTimeWithDateOrder(s string, order DateOrder) (time.Time, error) {
+	return ParseTimeWithLayouts(s, TimeLayoutsForDateOrder(order))
+}
+
+// ParseTimeWithLayouts parses time using only the given Go layouts, tried in order.
+// No other formats are attempted, so a value that does not match is an error.
+func ParseTimeWithLayouts(s string, layouts []string) (time.Time, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return time.Time{}, fmt.Errorf("time string cannot be empty")
 	}
-	
-	// Common time formats used in CSV files
-	formats := []string{
-		time.RFC3339,                // "2006-01-02T15:04:05Z07:00"
-		"2006-01-02 15:04:05",      // "2006-01-02 15:04:05"
-		"2006-01-02T15:04:05",      // "2006-01-02T15:04:05"
-		"2006-01-02",               // "2006-01-02"
-		"01/02/2006 15:04:05",      // "01/02/2006 15:04:05"
-		"01/02/2006",               // "01/02/2006"
-		"02-01-2006",               // "02-01-2006"
-		"2006/01/02",               // "2006/01/02"
-		"Jan 2, 2006",              // "Jan 2, 2006"
-		"January 2, 2006",          // "January 2, 2006"
+	if len(layouts) == 0 {
+		return time.Time{}, fmt.Errorf("no time layouts given for '%s'", s)
 	}
 	
 	var lastErr error
-	for _, format := range formats {
-		if t, err := time.Parse(format, s); err == nil {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
 			return t, nil
 		} else {
 			lastErr = err
@@ -549,6 +654,13 @@ func CreateTransactionFromCSV(trxID, amountStr, typeStr, timeStr string) (*Trans
 		return nil, fmt.Errorf("invalid amount in CSV: %w", err)
 	}
 	
+	return CreateTransactionWithAmount(trxID, amount, typeStr, timeStr)
+}
+
+// CreateTransactionWithAmount creates a Transaction from an already parsed amount
+// and the remaining CSV field values. It is used by parsers that parse amounts
+// with a specific NumberLocale.
+func CreateTransactionWithAmount(trxID string, amount decimal.Decimal, typeStr, timeStr string) (*Transaction, error) {
 	// Parse transaction type
 	txType, err := ParseTransactionType(typeStr)
 	if err != nil {
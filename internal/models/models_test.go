@@ -392,6 +392,66 @@ func TestParseDecimalFromString(t *testing.T) {
 	}
 }
 
+func TestParseDecimalWithLocale(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		locale    *NumberLocale
+		expected  string
+		wantError bool
+	}{
+		{"us grouping", "$1,234,567.89", NumberLocaleUS, "1234567.89", false},
+		{"nil locale is us", "1,250.75", nil, "1250.75", false},
+		{"us rejects european format", "1.234,56", NumberLocaleUS, "", true},
+		{"us rejects misplaced grouping", "1,23.00", NumberLocaleUS, "", true},
+		{"indonesian rupiah", "Rp 1.234.567,89", NumberLocaleID, "1234567.89", false},
+		{"indonesian code suffix", "1.500.000 IDR", NumberLocaleID, "1500000", false},
+		{"indonesian rejects us format", "1,234.56", NumberLocaleID, "", true},
+		{"euro symbol suffix", "1.234,56 €", NumberLocaleEU, "1234.56", false},
+		{"parentheses negative", "(Rp 2.500,00)", NumberLocaleID, "-2500", false},
+		{"trailing minus", "2.500,00-", NumberLocaleID, "-2500", false},
+		{"leading minus after symbol", "Rp -2.500", NumberLocaleID, "-2500", false},
+		{"double negative", "(-2.500)", NumberLocaleID, "", true},
+		{"parentheses disabled", "(100)", &NumberLocale{DecimalSeparator: "."}, "", true},
+		{"empty", "  ", NumberLocaleID, "", true},
+		{"letters", "12a.00", NumberLocaleUS, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseDecimalWithLocale(tt.input, tt.locale)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseDecimalWithLocale() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && result.String() != tt.expected {
+				t.Errorf("ParseDecimalWithLocale() = %s, want %s", result.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestNumberLocale_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		locale    *NumberLocale
+		wantError bool
+	}{
+		{"us", NumberLocaleUS, false},
+		{"indonesian", NumberLocaleID, false},
+		{"same separators", &NumberLocale{DecimalSeparator: ",", GroupingSeparator: ","}, true},
+		{"unsupported decimal separator", &NumberLocale{DecimalSeparator: ";"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.locale.Validate()
+			if (err != nil) != tt.wantError {
+				t.Errorf("Validate() error = %v, wantError %v", err, tt.wantError)
+			}
+		})
+	}
+}
+
 func TestParseTransactionType(t *testing.T) {
 	tests := []struct {
 		input     string
@@ -451,6 +511,60 @@ func TestParseTimeWithFormats(t *testing.T) {
 	}
 }
 
+func TestParseTimeWithDateOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		order     DateOrder
+		expected  time.Time
+		wantError bool
+	}{
+		{"auto is month first for slashes", "03/04/2024", DateOrderAuto, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), false},
+		{"month first", "03/04/2024", DateOrderMonthFirst, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), false},
+		{"day first", "03/04/2024", DateOrderDayFirst, time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), false},
+		{"day first rejects month first date", "01/15/2024", DateOrderDayFirst, time.Time{}, true},
+		{"month first rejects day first date", "15/01/2024", DateOrderMonthFirst, time.Time{}, true},
+		{"iso date is unaffected", "2024-01-15", DateOrderDayFirst, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTimeWithDateOrder(tt.input, tt.order)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseTimeWithDateOrder() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !tt.wantError && !got.Equal(tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseDateOrder(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  DateOrder
+		wantError bool
+	}{
+		{"", DateOrderAuto, false},
+		{"month_first", DateOrderMonthFirst, false},
+		{"day_first", DateOrderDayFirst, false},
+		{"year_first", DateOrderAuto, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			order, err := ParseDateOrder(tt.input)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParseDateOrder() error = %v, wantError %v", err, tt.wantError)
+			}
+			if order != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, order)
+			}
+		})
+	}
+}
+
 func TestCompareAmountsWithTolerance(t *testing.T) {
 	amount1 := decimal.NewFromFloat(100.50)
 	amount2 := decimal.NewFromFloat(100.52)
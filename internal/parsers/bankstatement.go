@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
 )
 
 // BankStatementParser handles parsing of bank statement CSV files with multi-format support
@@ -87,7 +90,7 @@ func (bsp *BankStatementParser) ParseBankStatementsWithContext(ctx context.Conte
 		stats.RecordsParsed++
 		
 		// Parse bank statement from record
-		bankStatement, parseErr := bsp.parseBankStatementFromRecord(record, parseCtx)
+		bankStatement, parseErr := bsp.parseBankStatementFromRecord(record, parseCtx, filePath)
 		if parseErr != nil {
 			stats.AddError(parseErr)
 			continue
@@ -114,15 +117,22 @@ func (bsp *BankStatementParser) ParseBankStatementsWithContext(ctx context.Conte
 
 // getRequiredHeaders returns the list of required header names for the configured bank
 func (bsp *BankStatementParser) getRequiredHeaders() []string {
-	return []string{
-		bsp.bankConfig.GetColumnName("identifier"),
-		bsp.bankConfig.GetColumnName("amount"),
-		bsp.bankConfig.GetColumnName("date"),
+	headers := []string{bsp.bankConfig.GetColumnName("identifier")}
+	
+	switch bsp.bankConfig.AmountLayout() {
+	case AmountLayoutSplit:
+		headers = append(headers, bsp.bankConfig.GetColumnName("debit"), bsp.bankConfig.GetColumnName("credit"))
+	case AmountLayoutIndicator:
+		headers = append(headers, bsp.bankConfig.GetColumnName("amount"), bsp.bankConfig.GetColumnName("indicator"))
+	default:
+		headers = append(headers, bsp.bankConfig.GetColumnName("amount"))
 	}
+	
+	return append(headers, bsp.bankConfig.GetColumnName("date"))
 }
 
 // parseBankStatementFromRecord creates a BankStatement from a CSV record
-func (bsp *BankStatementParser) parseBankStatementFromRecord(record []string, parseCtx *ParseContext) (*models.BankStatement, *ParseError) {
+func (bsp *BankStatementParser) parseBankStatementFromRecord(record []string, parseCtx *ParseContext, filePath string) (*models.BankStatement, *ParseError) {
 	// Extract field values
 	identifier, err := bsp.GetFieldValue(record, parseCtx, bsp.bankConfig.GetColumnName("identifier"))
 	if err != nil {
@@ -134,38 +144,40 @@ func (bsp *BankStatementParser) parseBankStatementFromRecord(record []string, pa
 		}
 	}
 	
-	amountStr, err := bsp.GetFieldValue(record, parseCtx, bsp.bankConfig.GetColumnName("amount"))
+	amount, parseErr := bsp.parseAmount(record, parseCtx, filePath)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	
+	dateStr, err := bsp.GetFieldValue(record, parseCtx, bsp.bankConfig.GetColumnName("date"))
 	if err != nil {
 		return nil, &ParseError{
 			Line:    parseCtx.LineNumber,
-			Field:   bsp.bankConfig.GetColumnName("amount"),
-			Message: "failed to get amount",
+			Field:   bsp.bankConfig.GetColumnName("date"),
+			Message: "failed to get date",
 			Err:     err,
 		}
 	}
 	
-	dateStr, err := bsp.GetFieldValue(record, parseCtx, bsp.bankConfig.GetColumnName("date"))
+	// Parse the date with the bank's configured layouts. A row that does not
+	// match is rejected rather than re-read with a different day/month order.
+	date, err := bsp.parseDate(dateStr)
 	if err != nil {
 		return nil, &ParseError{
 			Line:    parseCtx.LineNumber,
 			Field:   bsp.bankConfig.GetColumnName("date"),
-			Message: "failed to get date",
+			Value:   dateStr,
+			Message: "date does not match the configured bank date format",
 			Err:     err,
 		}
 	}
 	
-	// Parse using bank-specific date format if specified
-	if strings.TrimSpace(bsp.bankConfig.DateFormat) != "" {
-		// Try bank-specific format first, then fall back to standard parsing
-		bankStatement, err := bsp.createBankStatementWithFormat(identifier, amountStr, dateStr)
-		if err == nil {
-			return bankStatement, nil
-		}
-		// If bank-specific format fails, continue with standard parsing
+	currency, parseErr := bsp.parseCurrency(record, parseCtx, bsp.bankConfig.GetColumnName("currency"), bsp.bankConfig.Currency)
+	if parseErr != nil {
+		return nil, parseErr
 	}
 	
-	// Use models helper to create bank statement from CSV values
-	bankStatement, err := models.CreateBankStatementFromCSV(identifier, amountStr, dateStr)
+	bankStatement, err := bsp.createBankStatement(identifier, amount, date)
 	if err != nil {
 		return nil, &ParseError{
 			Line:    parseCtx.LineNumber,
@@ -173,24 +185,133 @@ func (bsp *BankStatementParser) parseBankStatementFromRecord(record []string, pa
 			Err:     err,
 		}
 	}
+	bankStatement.Currency = currency
+	
+	// The description is optional and only read when the file has the column
+	if column := bsp.bankConfig.GetColumnName("description"); parseCtx.GetColumnIndex(column) != -1 {
+		if description, err := bsp.GetFieldValue(record, parseCtx, column); err == nil {
+			bankStatement.Description = strings.TrimSpace(description)
+		}
+	}
+	
+	// Record where the statement came from so reports can group by bank
+	bankStatement.SourceFile = filePath
+	bankStatement.BankName = bsp.bankConfig.Name
+	bankStatement.SourceLine = parseCtx.LineNumber
 	
 	return bankStatement, nil
 }
 
-// createBankStatementWithFormat creates a bank statement using bank-specific date format
-func (bsp *BankStatementParser) createBankStatementWithFormat(identifier, amountStr, dateStr string) (*models.BankStatement, error) {
-	// Parse amount
-	amount, err := models.ParseDecimalFromString(amountStr)
+// parseDate parses a statement date using the bank-specific layouts if any are
+// configured, or the bank's day/month order policy otherwise
+func (bsp *BankStatementParser) parseDate(dateStr string) (time.Time, error) {
+	if layouts := bsp.bankConfig.DateLayouts(); len(layouts) > 0 {
+		return models.ParseTimeWithLayouts(dateStr, layouts)
+	}
+	return models.ParseTimeWithDateOrder(dateStr, bsp.bankConfig.DateOrder)
+}
+
+// parseAmount reads the signed statement amount from a record according to
+// the bank's amount layout. Debits are returned as negative amounts.
+func (bsp *BankStatementParser) parseAmount(record []string, parseCtx *ParseContext, filePath string) (decimal.Decimal, *ParseError) {
+	switch bsp.bankConfig.AmountLayout() {
+	case AmountLayoutSplit:
+		debit, parseErr := bsp.parseAmountField(record, parseCtx, filePath, "debit", true)
+		if parseErr != nil {
+			return decimal.Zero, parseErr
+		}
+		credit, parseErr := bsp.parseAmountField(record, parseCtx, filePath, "credit", true)
+		if parseErr != nil {
+			return decimal.Zero, parseErr
+		}
+		
+		switch {
+		case !debit.IsZero() && !credit.IsZero():
+			return decimal.Zero, &ParseError{
+				Line:    parseCtx.LineNumber,
+				Field:   bsp.bankConfig.GetColumnName("debit"),
+				Value:   debit.String(),
+				Message: "record has both a debit and a credit amount",
+			}
+		case !debit.IsZero():
+			return debit.Abs().Neg(), nil
+		case !credit.IsZero():
+			return credit.Abs(), nil
+		default:
+			return decimal.Zero, &ParseError{
+				Line:    parseCtx.LineNumber,
+				Field:   bsp.bankConfig.GetColumnName("debit"),
+				Message: "record has neither a debit nor a credit amount",
+			}
+		}
+		
+	case AmountLayoutIndicator:
+		amount, parseErr := bsp.parseAmountField(record, parseCtx, filePath, "amount", false)
+		if parseErr != nil {
+			return decimal.Zero, parseErr
+		}
+		
+		indicatorColumn := bsp.bankConfig.GetColumnName("indicator")
+		indicator, err := bsp.GetFieldValue(record, parseCtx, indicatorColumn)
+		if err != nil {
+			return decimal.Zero, &ParseError{
+				Line:    parseCtx.LineNumber,
+				Field:   indicatorColumn,
+				Message: "failed to get debit/credit indicator",
+				Err:     err,
+			}
+		}
+		
+		txType, err := bsp.bankConfig.ParseIndicator(indicator)
+		if err != nil {
+			return decimal.Zero, &ParseError{
+				Line:    parseCtx.LineNumber,
+				Field:   indicatorColumn,
+				Value:   indicator,
+				Message: "invalid debit/credit indicator",
+				Err:     err,
+			}
+		}
+		
+		if txType == models.TransactionTypeDebit {
+			return amount.Abs().Neg(), nil
+		}
+		return amount.Abs(), nil
+		
+	default:
+		return bsp.parseAmountField(record, parseCtx, filePath, "amount", false)
+	}
+}
+
+// parseAmountField parses a single amount column. When allowBlank is set an
+// empty cell is read as zero, as used by the debit/credit column layout.
+func (bsp *BankStatementParser) parseAmountField(record []string, parseCtx *ParseContext, filePath, standardName string, allowBlank bool) (decimal.Decimal, *ParseError) {
+	column := bsp.bankConfig.GetColumnName(standardName)
+	
+	amountStr, err := bsp.GetFieldValue(record, parseCtx, column)
 	if err != nil {
-		return nil, fmt.Errorf("invalid amount: %w", err)
+		return decimal.Zero, &ParseError{
+			Line:    parseCtx.LineNumber,
+			Field:   column,
+			Message: fmt.Sprintf("failed to get %s", standardName),
+			Err:     err,
+		}
 	}
 	
-	// Parse date with bank-specific format
-	date, err := models.ParseTimeWithFormats(dateStr)
+	if allowBlank && strings.TrimSpace(amountStr) == "" {
+		return decimal.Zero, nil
+	}
+	
+	amount, err := models.ParseDecimalWithLocale(amountStr, bsp.bankConfig.NumberLocale)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date: %w", err)
+		return decimal.Zero, newInvalidAmountParseError(filePath, parseCtx.LineNumber, column, amountStr, err)
 	}
 	
+	return amount, nil
+}
+
+// createBankStatement creates and validates a bank statement from parsed values
+func (bsp *BankStatementParser) createBankStatement(identifier string, amount decimal.Decimal, date time.Time) (*models.BankStatement, error) {
 	bankStatement := models.NewBankStatement(strings.TrimSpace(identifier), amount, date)
 	
 	// Validate the created bank statement
@@ -269,7 +390,7 @@ func (bsp *BankStatementParser) ParseBankStatementsStreamWithContext(
 		stats.RecordsParsed++
 		
 		// Parse bank statement from record
-		bankStatement, parseErr := bsp.parseBankStatementFromRecord(record, parseCtx)
+		bankStatement, parseErr := bsp.parseBankStatementFromRecord(record, parseCtx, filePath)
 		if parseErr != nil {
 			stats.AddError(parseErr)
 			continue
@@ -354,7 +475,7 @@ func (bsp *BankStatementParser) ValidateBankStatementFile(filePath string) error
 		recordCount++
 		
 		// Try to parse the record
-		_, parseErr := bsp.parseBankStatementFromRecord(record, parseCtx)
+		_, parseErr := bsp.parseBankStatementFromRecord(record, parseCtx, filePath)
 		if parseErr != nil {
 			return fmt.Errorf("failed to parse record %d: %w", recordCount, parseErr)
 		}
@@ -49,6 +49,7 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/pkg/errors"
 	"golang-reconciliation-service/pkg/logger"
 )
@@ -76,6 +77,57 @@ func (e *ParseError) Unwrap() error {
 	return e.Err
 }
 
+// parseCurrency reads the currency of a record from an optional currency
+// column, falling back to the configured default currency when the column is
+// not configured, not present in the file, or empty
+func (bp *BaseParser) parseCurrency(record []string, parseCtx *ParseContext, column, defaultCurrency string) (string, *ParseError) {
+	value := ""
+	if column != "" && parseCtx.GetColumnIndex(column) != -1 {
+		fieldValue, err := bp.GetFieldValue(record, parseCtx, column)
+		if err != nil {
+			return "", &ParseError{
+				Line:    parseCtx.LineNumber,
+				Field:   column,
+				Message: "failed to get currency",
+				Err:     err,
+			}
+		}
+		value = fieldValue
+	}
+	
+	if strings.TrimSpace(value) == "" {
+		value = defaultCurrency
+	}
+	
+	currency, err := models.ParseCurrencyCode(value)
+	if err != nil {
+		return "", &ParseError{
+			Line:    parseCtx.LineNumber,
+			Field:   column,
+			Value:   value,
+			Message: "invalid currency",
+			Err:     err,
+		}
+	}
+	
+	return currency, nil
+}
+
+// newInvalidAmountParseError reports an amount that could not be parsed with
+// the configured number locale
+func newInvalidAmountParseError(filePath string, line int, column, value string, cause error) *ParseError {
+	amountErr := errors.InvalidAmountError(filePath, line, column, value).
+		WithSuggestion("Check the amount matches the configured number locale (decimal and grouping separators)")
+	
+	return &ParseError{
+		Line:    line,
+		Field:   column,
+		Value:   value,
+		Message: cause.Error(),
+		Err:     amountErr,
+	}
+}
+
 // ValidationError represents a validation error for a specific record
 type ValidationError struct {
 	Line   int
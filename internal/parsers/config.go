@@ -3,6 +3,9 @@ package parsers
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
 )
 
 // BankConfig represents configuration for parsing bank-specific CSV formats
@@ -12,6 +15,16 @@ type BankConfig struct {
 	AmountColumn     string            `json:"amount_column"`
 	DateColumn       string            `json:"date_column"`
 	DateFormat       string            `json:"date_format"`
+	DateFormats      []string          `json:"date_formats,omitempty"`
+	DateOrder        models.DateOrder  `json:"date_order,omitempty"`
+	DebitColumn      string            `json:"debit_column,omitempty"`
+	CreditColumn     string            `json:"credit_column,omitempty"`
+	IndicatorColumn  string            `json:"indicator_column,omitempty"`
+	DebitIndicators  []string          `json:"debit_indicators,omitempty"`
+	CreditIndicators []string          `json:"credit_indicators,omitempty"`
+	NumberLocale     *models.NumberLocale `json:"number_locale,omitempty"`
+	CurrencyColumn   string            `json:"currency_column,omitempty"`
+	Currency         string            `json:"currency,omitempty"`
 	HasHeader        bool              `json:"has_header"`
 	Delimiter        rune              `json:"delimiter"`
 	ColumnAliases    map[string]string `json:"column_aliases,omitempty"`
@@ -28,17 +41,63 @@ func (bc *BankConfig) Validate() error {
 		return fmt.Errorf("identifier column cannot be empty")
 	}
 	
-	if strings.TrimSpace(bc.AmountColumn) == "" {
-		return fmt.Errorf("amount column cannot be empty")
+	switch bc.AmountLayout() {
+	case AmountLayoutSplit:
+		if strings.TrimSpace(bc.DebitColumn) == "" || strings.TrimSpace(bc.CreditColumn) == "" {
+			return fmt.Errorf("debit and credit columns must both be set")
+		}
+		if strings.TrimSpace(bc.IndicatorColumn) != "" {
+			return fmt.Errorf("indicator column cannot be combined with debit and credit columns")
+		}
+	default:
+		if strings.TrimSpace(bc.AmountColumn) == "" {
+			return fmt.Errorf("amount column cannot be empty")
+		}
 	}
 	
 	if strings.TrimSpace(bc.DateColumn) == "" {
 		return fmt.Errorf("date column cannot be empty")
 	}
 	
+	for i, layout := range bc.DateFormats {
+		if strings.TrimSpace(layout) == "" {
+			return fmt.Errorf("date format %d cannot be empty", i+1)
+		}
+	}
+	
+	if _, err := models.ParseDateOrder(string(bc.DateOrder)); err != nil {
+		return err
+	}
+	
+	if bc.NumberLocale != nil {
+		if err := bc.NumberLocale.Validate(); err != nil {
+			return fmt.Errorf("invalid number locale: %w", err)
+		}
+	}
+	
+	if _, err := models.ParseCurrencyCode(bc.Currency); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
+// DateLayouts returns the configured date layouts in the order they are tried:
+// DateFormat first, followed by DateFormats. An empty result means no layout
+// is configured and dates are parsed according to DateOrder.
+func (bc *BankConfig) DateLayouts() []string {
+	var layouts []string
+	if strings.TrimSpace(bc.DateFormat) != "" {
+		layouts = append(layouts, bc.DateFormat)
+	}
+	for _, layout := range bc.DateFormats {
+		if layout != bc.DateFormat {
+			layouts = append(layouts, layout)
+		}
+	}
+	return layouts
+}
+
 // GetColumnName returns the actual column name, checking aliases first
 func (bc *BankConfig) GetColumnName(standardName string) string {
 	if alias, exists := bc.ColumnAliases[standardName]; exists {
@@ -52,11 +111,77 @@ func (bc *BankConfig) GetColumnName(standardName string) string {
 		return bc.AmountColumn
 	case "date":
 		return bc.DateColumn
+	case "debit":
+		return bc.DebitColumn
+	case "credit":
+		return bc.CreditColumn
+	case "indicator":
+		return bc.IndicatorColumn
+	case "currency":
+		return bc.CurrencyColumn
 	default:
 		return standardName
 	}
 }
 
+// AmountLayout describes how a bank file encodes the direction of an amount
+type AmountLayout int
+
+const (
+	// AmountLayoutSigned is a single amount column where debits are negative
+	AmountLayoutSigned AmountLayout = iota
+	// AmountLayoutIndicator is an unsigned amount column plus a debit/credit indicator column
+	AmountLayoutIndicator
+	// AmountLayoutSplit is a pair of separate debit and credit amount columns
+	AmountLayoutSplit
+)
+
+// Default indicator values, covering English and Indonesian (Debet/Kredit) exports
+var (
+	DefaultDebitIndicators  = []string{"D", "DR", "DB", "DEBIT", "DEBET"}
+	DefaultCreditIndicators = []string{"C", "CR", "K", "KR", "CREDIT", "KREDIT"}
+)
+
+// AmountLayout returns the amount layout implied by the configured columns
+func (bc *BankConfig) AmountLayout() AmountLayout {
+	if strings.TrimSpace(bc.DebitColumn) != "" || strings.TrimSpace(bc.CreditColumn) != "" {
+		return AmountLayoutSplit
+	}
+	if strings.TrimSpace(bc.IndicatorColumn) != "" {
+		return AmountLayoutIndicator
+	}
+	return AmountLayoutSigned
+}
+
+// ParseIndicator maps an indicator column value to a transaction type.
+// Values are compared case-insensitively against the configured debit and
+// credit indicators, or the defaults when none are configured.
+func (bc *BankConfig) ParseIndicator(value string) (models.TransactionType, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	
+	debitIndicators := bc.DebitIndicators
+	if len(debitIndicators) == 0 {
+		debitIndicators = DefaultDebitIndicators
+	}
+	creditIndicators := bc.CreditIndicators
+	if len(creditIndicators) == 0 {
+		creditIndicators = DefaultCreditIndicators
+	}
+	
+	for _, indicator := range debitIndicators {
+		if value == strings.ToUpper(strings.TrimSpace(indicator)) {
+			return models.TransactionTypeDebit, nil
+		}
+	}
+	for _, indicator := range creditIndicators {
+		if value == strings.ToUpper(strings.TrimSpace(indicator)) {
+			return models.TransactionTypeCredit, nil
+		}
+	}
+	
+	return "", fmt.Errorf("unknown debit/credit indicator '%s'", value)
+}
+
 // TransactionParserConfig holds configuration for parsing transaction CSV files
 type TransactionParserConfig struct {
 	TrxIDColumn           string            `json:"trx_id_column"`
@@ -66,6 +191,9 @@ type TransactionParserConfig struct {
 	HasHeader             bool              `json:"has_header"`
 	Delimiter             rune              `json:"delimiter"`
 	ColumnAliases         map[string]string `json:"column_aliases,omitempty"`
+	NumberLocale          *models.NumberLocale `json:"number_locale,omitempty"`
+	CurrencyColumn        string            `json:"currency_column,omitempty"`
+	Currency              string            `json:"currency,omitempty"`
 }
 
 // Validate checks if the transaction parser configuration is valid
@@ -86,6 +214,16 @@ func (tpc *TransactionParserConfig) Validate() error {
 		return fmt.Errorf("transaction time column cannot be empty")
 	}
 	
+	if tpc.NumberLocale != nil {
+		if err := tpc.NumberLocale.Validate(); err != nil {
+			return fmt.Errorf("invalid number locale: %w", err)
+		}
+	}
+	
+	if _, err := models.ParseCurrencyCode(tpc.Currency); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
@@ -104,6 +242,8 @@ func (tpc *TransactionParserConfig) GetColumnName(standardName string) string {
 		return tpc.TypeColumn
 	case "transaction_time":
 		return tpc.TransactionTimeColumn
+	case "currency":
+		return tpc.CurrencyColumn
 	default:
 		return standardName
 	}
@@ -124,13 +264,15 @@ func DefaultTransactionParserConfig() *TransactionParserConfig {
 
 // Predefined bank configurations for common banks
 var (
-	// StandardBankConfig represents a generic bank statement format
+	// StandardBankConfig represents a generic bank statement format. Dates
+	// may carry a time of day in ISO form, as exports often do.
 	StandardBankConfig = &BankConfig{
 		Name:             "Standard",
 		IdentifierColumn: "unique_identifier",
 		AmountColumn:     "amount",
 		DateColumn:       "date",
 		DateFormat:       "2006-01-02",
+		DateFormats:      []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339},
 		HasHeader:        true,
 		Delimiter:        ',',
 		Description:      "Standard bank statement format",
@@ -160,67 +302,123 @@ var (
 		DateFormat:       "2006-01-02",
 		HasHeader:        true,
 		Delimiter:        ';',
+		IndicatorColumn:  "debit_credit_indicator",
 		ColumnAliases: map[string]string{
-			"type":        "debit_credit_indicator",
 			"description": "transaction_details",
 		},
 		Description: "Bank2 statement format with semicolon delimiter",
 	}
-)
-
-// GetBankConfig returns a predefined bank configuration by name
-func GetBankConfig(name string) *BankConfig {
-	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "standard":
-		return StandardBankConfig
-	case "bank1":
-		return SampleBank1Config
-	case "bank2":
-		return SampleBank2Config
-	default:
-		return nil
+	
+	// ChaseBankConfig represents the Chase statement export format
+	ChaseBankConfig = &BankConfig{
+		Name:             "Chase",
+		IdentifierColumn: "transaction_id",
+		AmountColumn:     "amount",
+		DateColumn:       "posting_date",
+		DateFormat:       "01/02/2006",
+		HasHeader:        true,
+		Delimiter:        ',',
+		Description:      "Chase Bank statement format",
 	}
-}
+	
+	// WellsFargoBankConfig represents the Wells Fargo statement export format
+	WellsFargoBankConfig = &BankConfig{
+		Name:             "Wells Fargo",
+		IdentifierColumn: "reference_number",
+		AmountColumn:     "amount",
+		DateColumn:       "date",
+		DateFormat:       "01/02/2006",
+		HasHeader:        true,
+		Delimiter:        ',',
+		Description:      "Wells Fargo statement format",
+	}
+	
+	// BankOfAmericaBankConfig represents the Bank of America statement export format
+	BankOfAmericaBankConfig = &BankConfig{
+		Name:             "Bank of America",
+		IdentifierColumn: "reference_id",
+		AmountColumn:     "amount",
+		DateColumn:       "posted_date",
+		DateFormat:       "01/02/2006",
+		HasHeader:        true,
+		Delimiter:        ',',
+		Description:      "Bank of America statement format",
+	}
+)
 
-// ListAvailableBankConfigs returns all available predefined bank configurations
-func ListAvailableBankConfigs() []*BankConfig {
+// BuiltinBankConfigs returns the bank configurations shipped with the parser
+func BuiltinBankConfigs() []*BankConfig {
 	return []*BankConfig{
 		StandardBankConfig,
 		SampleBank1Config,
 		SampleBank2Config,
+		ChaseBankConfig,
+		WellsFargoBankConfig,
+		BankOfAmericaBankConfig,
 	}
 }
 
-// AutoDetectBankConfig attempts to detect the bank format from headers
-func AutoDetectBankConfig(headers []string) *BankConfig {
-	headerMap := make(map[string]bool)
+// GetBankConfig returns a registered bank configuration by name, matched
+// case-insensitively. Built-in names and profiles loaded into
+// DefaultProfileRegistry are both recognised.
+func GetBankConfig(name string) *BankConfig {
+	return DefaultProfileRegistry.Get(name)
+}
+
+// ListAvailableBankConfigs returns all registered bank configurations
+func ListAvailableBankConfigs() []*BankConfig {
+	return DefaultProfileRegistry.List()
+}
+
+// HeaderMatchScore returns the fraction of the columns this configuration
+// needs that are present in the headers, between 0 and 1. Headers are
+// compared case-insensitively.
+func (bc *BankConfig) HeaderMatchScore(headers []string) float64 {
+	present := make(map[string]bool, len(headers))
 	for _, header := range headers {
-		headerMap[strings.ToLower(strings.TrimSpace(header))] = true
+		present[strings.ToLower(strings.TrimSpace(header))] = true
 	}
 	
-	configs := ListAvailableBankConfigs()
-	
-	for _, config := range configs {
-		score := 0
-		totalFields := 3 // identifier, amount, date
-		
-		// Check if key columns are present
-		if headerMap[strings.ToLower(config.IdentifierColumn)] {
-			score++
-		}
-		if headerMap[strings.ToLower(config.AmountColumn)] {
-			score++
-		}
-		if headerMap[strings.ToLower(config.DateColumn)] {
-			score++
-		}
-		
-		// If all key fields match, this is likely the right config
-		if score == totalFields {
-			return config
+	required := bc.RequiredColumns()
+	matched := 0
+	for _, column := range required {
+		if present[strings.ToLower(strings.TrimSpace(column))] {
+			matched++
 		}
 	}
 	
+	return float64(matched) / float64(len(required))
+}
+
+// RequiredColumns returns the names of the columns a file needs for this
+// configuration, taking aliases and the amount layout into account
+func (bc *BankConfig) RequiredColumns() []string {
+	required := []string{"identifier", "date"}
+	switch bc.AmountLayout() {
+	case AmountLayoutSplit:
+		required = append(required, "debit", "credit")
+	case AmountLayoutIndicator:
+		required = append(required, "amount", "indicator")
+	default:
+		required = append(required, "amount")
+	}
+	
+	columns := make([]string, 0, len(required))
+	for _, name := range required {
+		columns = append(columns, bc.GetColumnName(name))
+	}
+	return columns
+}
+
+// AutoDetectBankConfig detects the bank format from headers by scoring every
+// registered profile on the share of its columns present. The best profile
+// scoring at least MinDetectionScore wins; otherwise the standard config is
+// returned as a fallback.
+func AutoDetectBankConfig(headers []string) *BankConfig {
+	if config, _ := DefaultProfileRegistry.Detect(headers); config != nil {
+		return config
+	}
+	
 	// Return standard config as fallback
 	return StandardBankConfig
 }
@@ -2,13 +2,16 @@ package parsers
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/pkg/errors"
 )
 
 const testDataDir = "../../test/examples"
@@ -139,6 +142,38 @@ func TestBankConfig_Validate(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "Invalid date order",
+			config: &BankConfig{
+				Name:             "Test",
+				IdentifierColumn: "id",
+				AmountColumn:     "amount",
+				DateColumn:       "date",
+				DateOrder:        "year_first",
+			},
+			wantError: true,
+		},
+		{
+			name: "Debit and credit columns without amount column",
+			config: &BankConfig{
+				Name:             "Test",
+				IdentifierColumn: "id",
+				DebitColumn:      "debit",
+				CreditColumn:     "credit",
+				DateColumn:       "date",
+			},
+			wantError: false,
+		},
+		{
+			name: "Debit column without credit column",
+			config: &BankConfig{
+				Name:             "Test",
+				IdentifierColumn: "id",
+				DebitColumn:      "debit",
+				DateColumn:       "date",
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -312,6 +347,449 @@ BS002,-250.00,2024-01-15`
 	if !bs1.Amount.Equal(expectedAmount) {
 		t.Errorf("Expected amount %s, got %s", expectedAmount.String(), bs1.Amount.String())
 	}
+	
+	// Each statement records where it was read from
+	for i, stmt := range statements {
+		if stmt.SourceFile != filePath || stmt.BankName != StandardBankConfig.Name || stmt.SourceLine != i+2 {
+			t.Errorf("Statement %d: unexpected source %s (%s) line %d", i, stmt.SourceFile, stmt.BankName, stmt.SourceLine)
+		}
+	}
+}
+
+func TestBankStatementParser_DateFormats(t *testing.T) {
+	tests := []struct {
+		name          string
+		dateFormat    string
+		dateFormats   []string
+		dateOrder     models.DateOrder
+		csvContent    string
+		expectedDates []time.Time
+		expectedErrs  int
+	}{
+		{
+			name:       "day first layout is applied",
+			dateFormat: "02/01/2006",
+			csvContent: `unique_identifier,amount,date
+BS001,100.50,03/04/2024
+BS002,-250.00,15/01/2024`,
+			expectedDates: []time.Time{
+				time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "row not matching the layout is rejected",
+			dateFormat: "02/01/2006",
+			csvContent: `unique_identifier,amount,date
+BS001,100.50,03/04/2024
+BS002,-250.00,2024-01-15`,
+			expectedDates: []time.Time{
+				time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC),
+			},
+			expectedErrs: 1,
+		},
+		{
+			name:        "layouts are tried in order",
+			dateFormat:  "02/01/2006",
+			dateFormats: []string{"2006-01-02"},
+			csvContent: `unique_identifier,amount,date
+BS001,100.50,03/04/2024
+BS002,-250.00,2024-01-15`,
+			expectedDates: []time.Time{
+				time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:      "day first policy without layouts",
+			dateOrder: models.DateOrderDayFirst,
+			csvContent: `unique_identifier,amount,date
+BS001,100.50,03/04/2024
+BS002,-250.00,01/15/2024`,
+			expectedDates: []time.Time{
+				time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC),
+			},
+			expectedErrs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &BankConfig{
+				Name:             "Test",
+				IdentifierColumn: "unique_identifier",
+				AmountColumn:     "amount",
+				DateColumn:       "date",
+				DateFormat:       tt.dateFormat,
+				DateFormats:      tt.dateFormats,
+				DateOrder:        tt.dateOrder,
+				HasHeader:        true,
+				Delimiter:        ',',
+			}
+			parser, err := NewBankStatementParser(config)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+
+			filePath := createTempCSVFile(t, tt.csvContent)
+			statements, stats, err := parser.ParseBankStatements(filePath)
+			if err != nil {
+				t.Fatalf("Failed to parse bank statements: %v", err)
+			}
+
+			if len(statements) != len(tt.expectedDates) {
+				t.Fatalf("Expected %d bank statements, got %d", len(tt.expectedDates), len(statements))
+			}
+			for i, expected := range tt.expectedDates {
+				if !statements[i].Date.Equal(expected) {
+					t.Errorf("Statement %d: expected date %s, got %s", i, expected, statements[i].Date)
+				}
+			}
+
+			if len(stats.Errors) != tt.expectedErrs {
+				t.Fatalf("Expected %d parse errors, got %d", tt.expectedErrs, len(stats.Errors))
+			}
+			for _, parseErr := range stats.Errors {
+				if parseErr.Field != "date" {
+					t.Errorf("Expected parse error on date field, got %q", parseErr.Field)
+				}
+			}
+		})
+	}
+}
+
+func TestStandardBankConfig_DateTimes(t *testing.T) {
+	parser, err := NewBankStatementParser(StandardBankConfig)
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+
+	filePath := createTempCSVFile(t, `unique_identifier,amount,date
+BS001,100.50,2024-01-15
+BS002,-250.00,2024-01-15 10:30:00
+BS003,75.00,2024-01-15T10:30:00
+BS004,20.00,2024-01-15T10:30:00Z`)
+	statements, stats, err := parser.ParseBankStatements(filePath)
+	if err != nil {
+		t.Fatalf("Failed to parse bank statements: %v", err)
+	}
+	if len(stats.Errors) != 0 {
+		t.Fatalf("Expected no parse errors, got %d: %v", len(stats.Errors), stats.Errors[0])
+	}
+	if len(statements) != 4 {
+		t.Fatalf("Expected 4 bank statements, got %d", len(statements))
+	}
+	for _, stmt := range statements {
+		if stmt.Date.Year() != 2024 || stmt.Date.Month() != time.January || stmt.Date.Day() != 15 {
+			t.Errorf("Statement %s: expected 2024-01-15, got %s", stmt.UniqueIdentifier, stmt.Date)
+		}
+	}
+}
+
+func TestBankStatementParser_AmountLayouts(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          *BankConfig
+		csvContent      string
+		expectedAmounts []string
+		expectedErrs    int
+	}{
+		{
+			name: "debit/kredit indicator column",
+			config: &BankConfig{
+				Name:             "Indicator",
+				IdentifierColumn: "ref",
+				AmountColumn:     "amount",
+				DateColumn:       "date",
+				IndicatorColumn:  "dk",
+				HasHeader:        true,
+				Delimiter:        ',',
+			},
+			csvContent: `ref,amount,dk,date
+BS001,100.50,K,2024-01-15
+BS002,250.00,D,2024-01-15
+BS003,75.00,dr,2024-01-15
+BS004,10.00,X,2024-01-15`,
+			expectedAmounts: []string{"100.5", "-250", "-75"},
+			expectedErrs:    1,
+		},
+		{
+			name: "custom indicator values",
+			config: &BankConfig{
+				Name:             "Custom",
+				IdentifierColumn: "ref",
+				AmountColumn:     "amount",
+				DateColumn:       "date",
+				IndicatorColumn:  "direction",
+				DebitIndicators:  []string{"OUT"},
+				CreditIndicators: []string{"IN"},
+				HasHeader:        true,
+				Delimiter:        ',',
+			},
+			csvContent: `ref,amount,direction,date
+BS001,100.50,in,2024-01-15
+BS002,-250.00,OUT,2024-01-15`,
+			expectedAmounts: []string{"100.5", "-250"},
+		},
+		{
+			name: "separate debit and credit columns",
+			config: &BankConfig{
+				Name:             "Split",
+				IdentifierColumn: "ref",
+				DebitColumn:      "debit",
+				CreditColumn:     "credit",
+				DateColumn:       "date",
+				HasHeader:        true,
+				Delimiter:        ',',
+			},
+			csvContent: `ref,debit,credit,date
+BS001,,100.50,2024-01-15
+BS002,250.00,,2024-01-15
+BS003,0.00,30.00,2024-01-15
+BS004,10.00,20.00,2024-01-15
+BS005,,,2024-01-15`,
+			expectedAmounts: []string{"100.5", "-250", "30"},
+			expectedErrs:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewBankStatementParser(tt.config)
+			if err != nil {
+				t.Fatalf("Failed to create parser: %v", err)
+			}
+
+			filePath := createTempCSVFile(t, tt.csvContent)
+			statements, stats, err := parser.ParseBankStatements(filePath)
+			if err != nil {
+				t.Fatalf("Failed to parse bank statements: %v", err)
+			}
+
+			if len(statements) != len(tt.expectedAmounts) {
+				t.Fatalf("Expected %d bank statements, got %d", len(tt.expectedAmounts), len(statements))
+			}
+			for i, expected := range tt.expectedAmounts {
+				if statements[i].Amount.String() != expected {
+					t.Errorf("Statement %d: expected amount %s, got %s", i, expected, statements[i].Amount.String())
+				}
+			}
+
+			if len(stats.Errors) != tt.expectedErrs {
+				t.Errorf("Expected %d parse errors, got %d", tt.expectedErrs, len(stats.Errors))
+			}
+		})
+	}
+}
+
+func TestParsers_NumberLocale(t *testing.T) {
+	t.Run("bank statements in rupiah", func(t *testing.T) {
+		config := &BankConfig{
+			Name:             "IDR",
+			IdentifierColumn: "ref",
+			AmountColumn:     "amount",
+			DateColumn:       "date",
+			HasHeader:        true,
+			Delimiter:        ';',
+			NumberLocale:     models.NumberLocaleID,
+		}
+		parser, err := NewBankStatementParser(config)
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `ref;amount;date
+BS001;Rp 1.234.567,89;2024-01-15
+BS002;(Rp 250.000);2024-01-15
+BS003;1,234.56;2024-01-15`)
+
+		statements, stats, err := parser.ParseBankStatements(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse bank statements: %v", err)
+		}
+
+		expected := []string{"1234567.89", "-250000"}
+		if len(statements) != len(expected) {
+			t.Fatalf("Expected %d bank statements, got %d", len(expected), len(statements))
+		}
+		for i, amount := range expected {
+			if statements[i].Amount.String() != amount {
+				t.Errorf("Statement %d: expected amount %s, got %s", i, amount, statements[i].Amount.String())
+			}
+		}
+
+		if len(stats.Errors) != 1 {
+			t.Fatalf("Expected 1 parse error, got %d", len(stats.Errors))
+		}
+		var amountErr *errors.EnhancedParseError
+		if !stderrors.As(stats.Errors[0], &amountErr) || amountErr.Code != errors.CodeInvalidAmount {
+			t.Errorf("Expected an invalid amount error, got %v", stats.Errors[0])
+		}
+	})
+
+	t.Run("transactions in euro", func(t *testing.T) {
+		config := DefaultTransactionParserConfig()
+		config.Delimiter = ';'
+		config.NumberLocale = models.NumberLocaleEU
+
+		parser, err := NewTransactionParser(config)
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `trxID;amount;type;transactionTime
+TX001;1.250,75 €;CREDIT;2024-01-15T10:30:00Z
+TX002;1.250.75;DEBIT;2024-01-15T10:30:00Z`)
+
+		transactions, stats, err := parser.ParseTransactions(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse transactions: %v", err)
+		}
+
+		if len(transactions) != 1 {
+			t.Fatalf("Expected 1 transaction, got %d", len(transactions))
+		}
+		if transactions[0].Amount.String() != "1250.75" {
+			t.Errorf("Expected amount 1250.75, got %s", transactions[0].Amount.String())
+		}
+		if len(stats.Errors) != 1 {
+			t.Errorf("Expected 1 parse error, got %d", len(stats.Errors))
+		}
+	})
+}
+
+func TestParsers_Currency(t *testing.T) {
+	t.Run("bank statements with currency column", func(t *testing.T) {
+		config := &BankConfig{
+			Name:             "Multi",
+			IdentifierColumn: "ref",
+			AmountColumn:     "amount",
+			DateColumn:       "date",
+			CurrencyColumn:   "ccy",
+			Currency:         "USD",
+			HasHeader:        true,
+			Delimiter:        ',',
+		}
+		parser, err := NewBankStatementParser(config)
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `ref,amount,date,ccy
+BS001,100.00,2024-01-15,eur
+BS002,200.00,2024-01-15,
+BS003,300.00,2024-01-15,EURO`)
+
+		statements, stats, err := parser.ParseBankStatements(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse bank statements: %v", err)
+		}
+
+		expected := []string{"EUR", "USD"}
+		if len(statements) != len(expected) {
+			t.Fatalf("Expected %d bank statements, got %d", len(expected), len(statements))
+		}
+		for i, currency := range expected {
+			if statements[i].Currency != currency {
+				t.Errorf("Statement %d: expected currency %s, got %s", i, currency, statements[i].Currency)
+			}
+		}
+		if len(stats.Errors) != 1 {
+			t.Errorf("Expected 1 parse error for the invalid currency, got %d", len(stats.Errors))
+		}
+	})
+
+	t.Run("transactions with default currency", func(t *testing.T) {
+		config := DefaultTransactionParserConfig()
+		config.Currency = "idr"
+
+		parser, err := NewTransactionParser(config)
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `trxID,amount,type,transactionTime
+TX001,100.00,CREDIT,2024-01-15T10:30:00Z`)
+
+		transactions, _, err := parser.ParseTransactions(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse transactions: %v", err)
+		}
+		if len(transactions) != 1 || transactions[0].Currency != "IDR" {
+			t.Fatalf("Expected 1 transaction in IDR, got %+v", transactions)
+		}
+	})
+
+	t.Run("invalid default currency", func(t *testing.T) {
+		config := DefaultTransactionParserConfig()
+		config.Currency = "DOLLAR"
+		if err := config.Validate(); err == nil {
+			t.Error("Expected validation error for invalid default currency")
+		}
+	})
+}
+
+func TestParsers_Description(t *testing.T) {
+	t.Run("bank statements with description alias", func(t *testing.T) {
+		parser, err := NewBankStatementParser(SampleBank1Config)
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `transaction_id,transaction_amount,posting_date,transaction_description
+BS001,100.00,01/15/2024,  ACME Corp invoice 1001 
+BS002,200.00,01/15/2024,`)
+
+		statements, _, err := parser.ParseBankStatements(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse bank statements: %v", err)
+		}
+		if len(statements) != 2 {
+			t.Fatalf("Expected 2 bank statements, got %d", len(statements))
+		}
+		if statements[0].Description != "ACME Corp invoice 1001" || statements[1].Description != "" {
+			t.Errorf("Unexpected descriptions %q and %q", statements[0].Description, statements[1].Description)
+		}
+	})
+
+	t.Run("transactions with description alias", func(t *testing.T) {
+		config := DefaultTransactionParserConfig()
+		config.ColumnAliases = map[string]string{"description": "narrative"}
+
+		parser, err := NewTransactionParser(config)
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `trxID,amount,type,transactionTime,narrative
+TX001,100.00,CREDIT,2024-01-15T10:30:00Z,Invoice 1001 ACME Corp`)
+
+		transactions, _, err := parser.ParseTransactions(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse transactions: %v", err)
+		}
+		if len(transactions) != 1 || transactions[0].Description != "Invoice 1001 ACME Corp" {
+			t.Fatalf("Expected 1 transaction with a description, got %+v", transactions)
+		}
+	})
+
+	t.Run("transactions without description column", func(t *testing.T) {
+		parser, err := NewTransactionParser(DefaultTransactionParserConfig())
+		if err != nil {
+			t.Fatalf("Failed to create parser: %v", err)
+		}
+
+		filePath := createTempCSVFile(t, `trxID,amount,type,transactionTime
+TX001,100.00,CREDIT,2024-01-15T10:30:00Z`)
+
+		transactions, _, err := parser.ParseTransactions(filePath)
+		if err != nil {
+			t.Fatalf("Failed to parse transactions: %v", err)
+		}
+		if len(transactions) != 1 || transactions[0].Description != "" {
+			t.Fatalf("Expected 1 transaction without a description, got %+v", transactions)
+		}
+	})
 }
 
 func TestAutoDetectBankConfig(t *testing.T) {
@@ -330,6 +808,16 @@ func TestAutoDetectBankConfig(t *testing.T) {
 			headers:  []string{"transaction_id", "transaction_amount", "posting_date"},
 			expected: "Bank1",
 		},
+		{
+			name:     "Indicator format",
+			headers:  []string{"ref_number", "debit_credit_amount", "value_date", "debit_credit_indicator"},
+			expected: "Bank2",
+		},
+		{
+			name:     "Partial match",
+			headers:  []string{"Transaction_ID", "amount", "value_date", "memo"},
+			expected: "Chase",
+		},
 		{
 			name:     "Unknown format",
 			headers:  []string{"id", "value", "timestamp"},
@@ -347,6 +835,121 @@ func TestAutoDetectBankConfig(t *testing.T) {
 	}
 }
 
+func TestProfileRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"acme.yaml": "name: Acme\nidentifier_column: ref\namount_column: amount\ndate_column: posted\ndate_format: 2006-01-02\ndelimiter: \";\"\n",
+		"globex.toml": "identifier_column = \"txn\"\ndebit_column = \"debit\"\ncredit_column = \"credit\"\ndate_column = \"booked\"\ndate_format = \"02/01/2006\"\n",
+		"standard.json": `{"name": "standard", "identifier_column": "id", "amount_column": "value", "date_column": "day", "has_header": false}`,
+		"notes.txt": "not a profile",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	registry := NewProfileRegistry(BuiltinBankConfigs()...)
+	loaded, err := registry.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(loaded) != 3 {
+		t.Fatalf("Expected 3 loaded profiles, got %d", len(loaded))
+	}
+
+	acme := registry.Get("ACME")
+	if acme == nil {
+		t.Fatal("Expected YAML profile to be registered")
+	}
+	if acme.Delimiter != ';' || acme.DateFormat != "2006-01-02" || !acme.HasHeader {
+		t.Errorf("Unexpected YAML profile: delimiter %q, date format %q, header %v", acme.Delimiter, acme.DateFormat, acme.HasHeader)
+	}
+
+	globex := registry.Get("globex")
+	if globex == nil {
+		t.Fatal("Expected TOML profile to be registered under its file name")
+	}
+	if globex.AmountLayout() != AmountLayoutSplit || globex.Delimiter != ',' {
+		t.Errorf("Unexpected TOML profile: layout %v, delimiter %q", globex.AmountLayout(), globex.Delimiter)
+	}
+
+	// A loaded profile replaces the built-in with the same name
+	standard := registry.Get("Standard")
+	if standard == nil || standard.IdentifierColumn != "id" || standard.HasHeader {
+		t.Errorf("Expected JSON profile to override the built-in standard profile, got %+v", standard)
+	}
+	if StandardBankConfig.IdentifierColumn != "unique_identifier" {
+		t.Error("Loading profiles should not modify the built-in definitions")
+	}
+	if len(registry.List()) != len(BuiltinBankConfigs())+2 {
+		t.Errorf("Expected %d registered profiles, got %d", len(BuiltinBankConfigs())+2, len(registry.List()))
+	}
+
+	// Loaded profiles take part in detection
+	if config, score := registry.Detect([]string{"ref", "amount", "posted"}); config != acme || score != 1 {
+		t.Errorf("Expected headers to be detected as Acme, got %v (score %.2f)", config, score)
+	}
+	if config, _ := registry.Detect([]string{"foo", "bar"}); config != nil {
+		t.Errorf("Expected no profile for unknown headers, got %s", config.Name)
+	}
+}
+
+func TestProfileRegistry_LoadDirInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "fails validation",
+			files:   map[string]string{"broken.yaml": "name: Broken\namount_column: amount\ndate_column: date\n"},
+			wantErr: "identifier column cannot be empty",
+		},
+		{
+			name:    "malformed file",
+			files:   map[string]string{"broken.json": `{"name": "Broken",`},
+			wantErr: "broken.json",
+		},
+		{
+			name:    "bad delimiter",
+			files:   map[string]string{"broken.toml": "identifier_column = \"id\"\namount_column = \"amount\"\ndate_column = \"date\"\ndelimiter = \";;\"\n"},
+			wantErr: "single character",
+		},
+		{
+			name: "duplicate names",
+			files: map[string]string{
+				"a.yaml": "name: Same\nidentifier_column: id\namount_column: amount\ndate_column: date\n",
+				"b.json": `{"name": "same", "identifier_column": "id", "amount_column": "amount", "date_column": "date"}`,
+			},
+			wantErr: "defined in both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+
+			registry := NewProfileRegistry()
+			_, err := registry.LoadDir(dir)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if len(registry.List()) != 0 {
+				t.Errorf("Expected nothing to be registered, got %d profiles", len(registry.List()))
+			}
+		})
+	}
+}
+
 func TestTransactionParser_ParseTransactionsStream(t *testing.T) {
 	parser, err := NewTransactionParser(nil)
 	if err != nil {
@@ -528,6 +1131,32 @@ func TestStreamingConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestMemoryMonitor_Check(t *testing.T) {
+	alerts := 0
+	monitor := NewMemoryMonitor(1, 1, func(int) { alerts++ })
+
+	// Keep 8 MB live while checking, so the heap is over the 1 MB limit
+	ballast := make([]byte, 8*1024*1024)
+	for i := range ballast {
+		ballast[i] = byte(i)
+	}
+	if !monitor.Check() {
+		t.Errorf("Expected Check to report usage over the limit, current %d MB", monitor.CurrentMemoryMB())
+	}
+	if alerts != 1 {
+		t.Errorf("Expected 1 alert, got %d", alerts)
+	}
+	if monitor.PeakMemoryMB() < 8 {
+		t.Errorf("Expected a peak of at least 8 MB, got %d", monitor.PeakMemoryMB())
+	}
+	ballast[0]++
+
+	unlimited := NewMemoryMonitor(0, 1, nil)
+	if unlimited.Check() {
+		t.Errorf("Expected no limit to be enforced when the limit is 0")
+	}
+}
+
 func TestParseContext_GetColumnIndex(t *testing.T) {
 	parseCtx := NewParseContext(context.Background())
 	parseCtx.Headers = []string{"trxID", "amount", "type"}
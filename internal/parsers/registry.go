@@ -0,0 +1,266 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// MinDetectionScore is the fraction of a profile's required columns that must
+// be present in a header row for AutoDetectBankConfig to pick that profile
+const MinDetectionScore = 0.5
+
+// ProfileRegistry holds the bank configurations known by name. Profiles are
+// kept in registration order, which also breaks ties during detection.
+type ProfileRegistry struct {
+	mu       sync.RWMutex
+	profiles map[string]*BankConfig
+	order    []string
+}
+
+// DefaultProfileRegistry contains the built-in bank configurations together
+// with any profiles loaded at runtime
+var DefaultProfileRegistry = NewProfileRegistry(BuiltinBankConfigs()...)
+
+// NewProfileRegistry creates a registry containing the given configurations.
+// It panics if one of them is invalid, so it is meant for built-in profiles.
+func NewProfileRegistry(configs ...*BankConfig) *ProfileRegistry {
+	registry := &ProfileRegistry{profiles: make(map[string]*BankConfig)}
+	for _, config := range configs {
+		if err := registry.Register(config); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// Register validates a configuration and adds it to the registry. A profile
+// with the same name, compared case-insensitively, is replaced in place.
+func (r *ProfileRegistry) Register(config *BankConfig) error {
+	if config == nil {
+		return fmt.Errorf("bank config cannot be nil")
+	}
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid bank profile %s: %w", config.Name, err)
+	}
+
+	key := profileKey(config.Name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.profiles[key]; !exists {
+		r.order = append(r.order, key)
+	}
+	r.profiles[key] = config
+	return nil
+}
+
+// Get returns the profile with the given name, or nil when none is registered
+func (r *ProfileRegistry) Get(name string) *BankConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.profiles[profileKey(name)]
+}
+
+// List returns every registered profile in registration order
+func (r *ProfileRegistry) List() []*BankConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	configs := make([]*BankConfig, 0, len(r.order))
+	for _, key := range r.order {
+		configs = append(configs, r.profiles[key])
+	}
+	return configs
+}
+
+// LoadDir registers every .yaml, .yml, .toml and .json profile in dir and
+// returns the loaded profiles in file name order. Other files are ignored.
+// Nothing is registered unless all profiles in the directory are valid.
+func (r *ProfileRegistry) LoadDir(dir string) ([]*BankConfig, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+
+	var configs []*BankConfig
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || profileFormat(entry.Name()) == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		config, err := LoadBankConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key := profileKey(config.Name)
+		if previous, exists := seen[key]; exists {
+			return nil, fmt.Errorf("bank profile %s is defined in both %s and %s", config.Name, previous, entry.Name())
+		}
+		seen[key] = entry.Name()
+		configs = append(configs, config)
+	}
+
+	for _, config := range configs {
+		if err := r.Register(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return configs, nil
+}
+
+// Detect scores every registered profile against a header row and returns
+// the best one with its score, the fraction of required columns present.
+// Ties go to the profile registered first. It returns nil when no profile
+// reaches MinDetectionScore.
+func (r *ProfileRegistry) Detect(headers []string) (*BankConfig, float64) {
+	var best *BankConfig
+	bestScore := 0.0
+
+	for _, config := range r.List() {
+		score := config.HeaderMatchScore(headers)
+		if score > bestScore {
+			best = config
+			bestScore = score
+		}
+	}
+
+	if bestScore < MinDetectionScore {
+		return nil, bestScore
+	}
+	return best, bestScore
+}
+
+// LoadBankConfigFile reads a single bank profile. The format is taken from the
+// file extension and the profile name defaults to the file name without it.
+func LoadBankConfigFile(path string) (*BankConfig, error) {
+	format := profileFormat(path)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported bank profile file %s: expected .yaml, .yml, .toml or .json", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bank profile %s: %w", path, err)
+	}
+
+	values, err := decodeProfileData(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bank profile %s: %w", path, err)
+	}
+
+	config, err := BankConfigFromMap(values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bank profile %s: %w", path, err)
+	}
+	if strings.TrimSpace(config.Name) == "" {
+		base := filepath.Base(path)
+		config.Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bank profile %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// bankConfigFile is the file form of a BankConfig, where the delimiter is
+// written as a single-character string
+type bankConfigFile struct {
+	BankConfig
+	Delimiter string `json:"delimiter"`
+}
+
+// BankConfigFromMap converts decoded profile values using the BankConfig keys.
+// HasHeader defaults to true and the delimiter to a comma. The result is not
+// validated.
+func BankConfigFromMap(values map[string]interface{}) (*BankConfig, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	file := bankConfigFile{Delimiter: ","}
+	file.HasHeader = true
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if utf8.RuneCountInString(file.Delimiter) != 1 {
+		return nil, fmt.Errorf("delimiter must be a single character")
+	}
+
+	config := file.BankConfig
+	config.Delimiter, _ = utf8.DecodeRuneInString(file.Delimiter)
+	return &config, nil
+}
+
+// decodeProfileData decodes a YAML, TOML or JSON document into a map
+func decodeProfileData(data []byte, format string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	switch format {
+	case "yaml":
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		// Layouts such as 2006-01-02 are valid YAML timestamps; keep them as text
+		keepTimestampsAsStrings(&node)
+		if err := node.Decode(&values); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	default:
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func keepTimestampsAsStrings(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!timestamp" {
+		node.Tag = "!!str"
+	}
+	for _, child := range node.Content {
+		keepTimestampsAsStrings(child)
+	}
+}
+
+// profileFormat returns the profile format for a file name, or "" when the
+// extension is not supported
+func profileFormat(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".json":
+		return "json"
+	default:
+		return ""
+	}
+}
+
+func profileKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
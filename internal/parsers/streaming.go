@@ -3,7 +3,9 @@ package parsers
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang-reconciliation-service/internal/models"
@@ -461,12 +463,15 @@ func (cp *ConcurrentParser) ParseBankStatementsConcurrently(
 	return results
 }
 
-// MemoryMonitor tracks memory usage during parsing operations
+// MemoryMonitor tracks memory usage during parsing operations. Usage is the
+// heap in use as reported by the Go runtime, which includes garbage not yet
+// collected.
 type MemoryMonitor struct {
 	maxMemoryMB      int
 	checkIntervalSec int
 	stopChan         chan bool
 	alertCallback    func(memoryMB int)
+	peakMemoryMB     atomic.Int64
 }
 
 // NewMemoryMonitor creates a new memory monitor
@@ -491,13 +496,7 @@ func (mm *MemoryMonitor) Start(ctx context.Context) {
 		case <-mm.stopChan:
 			return
 		case <-ticker.C:
-			// Note: This is a simplified memory check
-			// In production, you might want to use runtime.MemStats
-			// for more accurate memory monitoring
-			if mm.alertCallback != nil {
-				// Placeholder for memory check
-				// mm.alertCallback(currentMemoryMB)
-			}
+			mm.Check()
 		}
 	}
 }
@@ -505,4 +504,37 @@ func (mm *MemoryMonitor) Start(ctx context.Context) {
 // Stop stops memory monitoring
 func (mm *MemoryMonitor) Stop() {
 	close(mm.stopChan)
-}
\ No newline at end of file
+}
+
+// Check samples memory usage now, calls the alert callback when it is above
+// the limit and reports whether it is. Callers that must stay within the
+// limit check at their own pace instead of waiting for the next tick.
+func (mm *MemoryMonitor) Check() bool {
+	current := mm.CurrentMemoryMB()
+	for {
+		peak := mm.peakMemoryMB.Load()
+		if int64(current) <= peak || mm.peakMemoryMB.CompareAndSwap(peak, int64(current)) {
+			break
+		}
+	}
+	
+	if mm.maxMemoryMB <= 0 || current <= mm.maxMemoryMB {
+		return false
+	}
+	if mm.alertCallback != nil {
+		mm.alertCallback(current)
+	}
+	return true
+}
+
+// CurrentMemoryMB returns the heap currently in use, in megabytes
+func (mm *MemoryMonitor) CurrentMemoryMB() int {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int(stats.HeapAlloc / (1024 * 1024))
+}
+
+// PeakMemoryMB returns the highest usage seen by Check
+func (mm *MemoryMonitor) PeakMemoryMB() int {
+	return int(mm.peakMemoryMB.Load())
+}
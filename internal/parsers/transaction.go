@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/pkg/errors"
@@ -278,8 +279,20 @@ func (tp *TransactionParser) parseTransactionFromRecord(record []string, parseCt
 		}
 	}
 	
+	currency, parseErr := tp.parseCurrency(record, parseCtx, tp.config.GetColumnName("currency"), tp.config.Currency)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	
+	// Parse the amount with the configured number locale; values that do
+	// not fit the locale are rejected rather than guessed
+	amount, err := models.ParseDecimalWithLocale(amountStr, tp.config.NumberLocale)
+	if err != nil {
+		return nil, newInvalidAmountParseError(filePath, parseCtx.LineNumber, tp.config.GetColumnName("amount"), amountStr, err)
+	}
+	
 	// Use models helper to create transaction from CSV values
-	transaction, err := models.CreateTransactionFromCSV(trxID, amountStr, typeStr, timeStr)
+	transaction, err := models.CreateTransactionWithAmount(trxID, amount, typeStr, timeStr)
 	if err != nil {
 		tp.logger.WithError(err).WithFields(logger.Fields{
 			"line_number": parseCtx.LineNumber,
@@ -324,6 +337,15 @@ func (tp *TransactionParser) parseTransactionFromRecord(record []string, parseCt
 		}
 	}
 	
+	transaction.Currency = currency
+	
+	// The description is optional and only read when the file has the column
+	if column := tp.config.GetColumnName("description"); parseCtx.GetColumnIndex(column) != -1 {
+		if description, err := tp.GetFieldValue(record, parseCtx, column); err == nil {
+			transaction.Description = strings.TrimSpace(description)
+		}
+	}
+	
 	return transaction, nil
 }
 
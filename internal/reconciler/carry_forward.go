@@ -0,0 +1,215 @@
+package reconciler
+
+import (
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+)
+
+// AgeingSummary counts open (unmatched) items by age. Age is measured in
+// calendar days from the item's date to AsOf, the end of the period.
+type AgeingSummary struct {
+	AsOf         time.Time     `json:"as_of"`
+	Transactions AgeingBuckets `json:"transactions"`
+	Statements   AgeingBuckets `json:"statements"`
+}
+
+// AgeingBuckets holds item counts per age bucket
+type AgeingBuckets struct {
+	Days0To7  int `json:"0-7"`
+	Days8To30 int `json:"8-30"`
+	Over30    int `json:"30+"`
+}
+
+// Total returns the number of items in all buckets
+func (b AgeingBuckets) Total() int {
+	return b.Days0To7 + b.Days8To30 + b.Over30
+}
+
+func (b *AgeingBuckets) add(ageDays int) {
+	switch {
+	case ageDays <= 7:
+		b.Days0To7++
+	case ageDays <= 30:
+		b.Days8To30++
+	default:
+		b.Over30++
+	}
+}
+
+// addCarriedItems appends open items carried forward from a previous run to
+// the candidate pools. They are added after date filtering, since they are
+// by definition from an earlier period. An item that is also in the current
+// input, e.g. when periods overlap, is not added twice.
+func (rs *ReconciliationService) addCarriedItems(
+	transactions []*models.Transaction,
+	statements []*models.BankStatement,
+	request *ReconciliationRequest,
+) ([]*models.Transaction, []*models.BankStatement) {
+	if len(request.CarriedTransactions) > 0 {
+		seen := make(map[string]bool, len(transactions))
+		for _, tx := range transactions {
+			seen[tx.TrxID] = true
+		}
+		for _, tx := range request.CarriedTransactions {
+			if !seen[tx.TrxID] {
+				seen[tx.TrxID] = true
+				transactions = append(transactions, tx)
+			}
+		}
+	}
+
+	if len(request.CarriedStatements) > 0 {
+		seen := make(map[string]bool, len(statements))
+		for _, stmt := range statements {
+			seen[StatementKey(stmt)] = true
+		}
+		for _, stmt := range request.CarriedStatements {
+			if key := StatementKey(stmt); !seen[key] {
+				seen[key] = true
+				statements = append(statements, stmt)
+			}
+		}
+	}
+
+	return transactions, statements
+}
+
+// StatementKey identifies a bank statement across runs. Identifiers are only
+// unique within a bank, so the bank name is part of the key.
+func StatementKey(stmt *models.BankStatement) string {
+	return stmt.BankName + "/" + stmt.UniqueIdentifier
+}
+
+// summarizeCarriedItems counts the carried items that entered the pools and
+// how many of them were cleared by a match in this run
+func (rs *ReconciliationService) summarizeCarriedItems(
+	result *ReconciliationResult,
+	matchingResult *matcher.ReconciliationResult,
+) {
+	request := result.Request
+	if request == nil || len(request.CarriedTransactions)+len(request.CarriedStatements) == 0 {
+		return
+	}
+
+	carriedTx := make(map[*models.Transaction]bool, len(request.CarriedTransactions))
+	for _, tx := range request.CarriedTransactions {
+		carriedTx[tx] = true
+	}
+	carriedStmt := make(map[*models.BankStatement]bool, len(request.CarriedStatements))
+	for _, stmt := range request.CarriedStatements {
+		carriedStmt[stmt] = true
+	}
+
+	cleared := 0
+	countTx := func(tx *models.Transaction) {
+		if carriedTx[tx] {
+			cleared++
+		}
+	}
+	countStmt := func(stmt *models.BankStatement) {
+		if carriedStmt[stmt] {
+			cleared++
+		}
+	}
+	for _, match := range matchingResult.Matches {
+		countTx(match.Transaction)
+		countStmt(match.BankStatement)
+	}
+	for _, group := range matchingResult.GroupMatches {
+		for _, tx := range group.Transactions {
+			countTx(tx)
+		}
+		for _, stmt := range group.Statements {
+			countStmt(stmt)
+		}
+	}
+
+	open := 0
+	for _, tx := range matchingResult.UnmatchedTransactions {
+		if carriedTx[tx] {
+			open++
+		}
+	}
+	for _, stmt := range matchingResult.UnmatchedStatements {
+		if carriedStmt[stmt] {
+			open++
+		}
+	}
+	// Items pending review stay open until a reviewer accepts their match
+	for _, pending := range matchingResult.PendingReview {
+		if carriedTx[pending.Match.Transaction] {
+			open++
+		}
+		if carriedStmt[pending.Match.BankStatement] {
+			open++
+		}
+	}
+
+	result.Summary.CarriedForward = cleared + open
+	result.Summary.CarriedCleared = cleared
+}
+
+// calculateAgeing buckets the unmatched items by age. The period ends at the
+// request's end date or, without one, at the latest item date in the run.
+func (rs *ReconciliationService) calculateAgeing(
+	result *ReconciliationResult,
+	matchingResult *matcher.ReconciliationResult,
+) {
+	var asOf time.Time
+	if result.Request != nil && result.Request.EndDate != nil {
+		asOf = *result.Request.EndDate
+	} else {
+		latest := func(date time.Time) {
+			if date.After(asOf) {
+				asOf = date
+			}
+		}
+		for _, tx := range matchingResult.UnmatchedTransactions {
+			latest(tx.TransactionTime)
+		}
+		for _, stmt := range matchingResult.UnmatchedStatements {
+			latest(stmt.Date)
+		}
+		for _, match := range matchingResult.Matches {
+			latest(match.Transaction.TransactionTime)
+			latest(match.BankStatement.Date)
+		}
+		for _, group := range matchingResult.GroupMatches {
+			for _, tx := range group.Transactions {
+				latest(tx.TransactionTime)
+			}
+			for _, stmt := range group.Statements {
+				latest(stmt.Date)
+			}
+		}
+		for _, pending := range matchingResult.PendingReview {
+			latest(pending.Match.Transaction.TransactionTime)
+			latest(pending.Match.BankStatement.Date)
+		}
+	}
+	if asOf.IsZero() {
+		return
+	}
+
+	ageing := &AgeingSummary{AsOf: asOf}
+	for _, tx := range matchingResult.UnmatchedTransactions {
+		ageing.Transactions.add(ageInDays(tx.TransactionTime, asOf))
+	}
+	for _, stmt := range matchingResult.UnmatchedStatements {
+		ageing.Statements.add(ageInDays(stmt.Date, asOf))
+	}
+	result.Summary.Ageing = ageing
+}
+
+// ageInDays returns the number of calendar days from date to asOf, never
+// negative
+func ageInDays(date, asOf time.Time) int {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	if days := int(to.Sub(from).Hours() / 24); days > 0 {
+		return days
+	}
+	return 0
+}
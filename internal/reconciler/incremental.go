@@ -0,0 +1,313 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// Inbox subdirectories. System files are dropped into transactions, bank
+// files into statements; files are moved to processed or failed once read.
+const (
+	InboxTransactionsDir = "transactions"
+	InboxStatementsDir   = "statements"
+	InboxProcessedDir    = "processed"
+	InboxFailedDir       = "failed"
+)
+
+// IncrementalConfig configures an IncrementalService
+type IncrementalConfig struct {
+	// SnapshotPath is where the open items are saved after every change and
+	// restored from on start. Leave empty to keep them in memory only.
+	SnapshotPath string
+
+	// InboxDir, when set, is polled for new files. Files are picked up in
+	// name order; write them elsewhere and move them in, or give them a
+	// .tmp or .part suffix until they are complete.
+	InboxDir string
+
+	// PollInterval is how often the inbox is scanned
+	PollInterval time.Duration
+
+	// TransactionConfig is used to parse system files from the inbox
+	TransactionConfig *parsers.TransactionParserConfig
+
+	// ResolveBankConfig chooses the configuration of a bank file from the inbox
+	ResolveBankConfig func(path string) (*parsers.BankConfig, error)
+
+	// OnMatch, when set, is called for every match as it is made
+	OnMatch func(*matcher.MatchResult)
+}
+
+// DefaultIncrementalConfig returns an in-memory configuration without an inbox
+func DefaultIncrementalConfig() *IncrementalConfig {
+	return &IncrementalConfig{
+		PollInterval:      5 * time.Second,
+		TransactionConfig: parsers.DefaultTransactionParserConfig(),
+		ResolveBankConfig: func(string) (*parsers.BankConfig, error) {
+			return parsers.StandardBankConfig, nil
+		},
+	}
+}
+
+// Validate checks if the incremental configuration is valid
+func (c *IncrementalConfig) Validate() error {
+	if c.InboxDir == "" {
+		return nil
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll interval must be positive, got %s", c.PollInterval)
+	}
+	if c.TransactionConfig == nil || c.ResolveBankConfig == nil {
+		return fmt.Errorf("transaction config and bank config resolver are required to read the inbox")
+	}
+	return nil
+}
+
+// IncrementalService keeps a matcher.IncrementalReconciler running: it feeds
+// it items from the API or the inbox and saves its open items after every
+// change, so a restart picks up where the last run stopped.
+type IncrementalService struct {
+	reconciler *matcher.IncrementalReconciler
+	config     *IncrementalConfig
+	logger     logger.Logger
+}
+
+// IncrementalBatch is the outcome of adding a batch of items
+type IncrementalBatch struct {
+	Matches  []*matcher.MatchResult
+	Open     int      // items left open
+	Rejected []string // items refused, with the reason
+}
+
+// NewIncrementalService creates an incremental service, restoring the open
+// items from the snapshot when there is one
+func NewIncrementalService(matchingConfig *matcher.MatchingConfig, config *IncrementalConfig) (*IncrementalService, error) {
+	if config == nil {
+		config = DefaultIncrementalConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid incremental config: %w", err)
+	}
+
+	var incremental *matcher.IncrementalReconciler
+	var err error
+	if config.SnapshotPath != "" {
+		incremental, err = matcher.LoadIncrementalReconciler(config.SnapshotPath, matchingConfig)
+	} else {
+		incremental, err = matcher.NewIncrementalReconciler(matchingConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.InboxDir != "" {
+		for _, dir := range []string{InboxTransactionsDir, InboxStatementsDir, InboxProcessedDir, InboxFailedDir} {
+			if err := os.MkdirAll(filepath.Join(config.InboxDir, dir), 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create inbox directory: %w", err)
+			}
+		}
+	}
+
+	return &IncrementalService{
+		reconciler: incremental,
+		config:     config,
+		logger:     logger.GetGlobalLogger().WithComponent("incremental_service"),
+	}, nil
+}
+
+// Reconciler returns the underlying incremental reconciler
+func (s *IncrementalService) Reconciler() *matcher.IncrementalReconciler {
+	return s.reconciler
+}
+
+// AddTransactions matches transactions against the open bank statements and
+// saves the snapshot. Invalid and duplicate transactions are rejected without
+// stopping the batch.
+func (s *IncrementalService) AddTransactions(transactions []*models.Transaction) (*IncrementalBatch, error) {
+	batch := &IncrementalBatch{}
+	for _, tx := range transactions {
+		if tx == nil {
+			batch.Rejected = append(batch.Rejected, "empty item")
+			continue
+		}
+		if err := tx.Validate(); err != nil {
+			batch.Rejected = append(batch.Rejected, fmt.Sprintf("%s: %v", tx.TrxID, err))
+			continue
+		}
+		match, err := s.reconciler.AddTransaction(tx)
+		s.record(batch, tx.TrxID, match, err)
+	}
+	return batch, s.save()
+}
+
+// AddStatements matches bank statements against the open transactions and
+// saves the snapshot. Invalid and duplicate statements are rejected without
+// stopping the batch.
+func (s *IncrementalService) AddStatements(statements []*models.BankStatement) (*IncrementalBatch, error) {
+	batch := &IncrementalBatch{}
+	for _, stmt := range statements {
+		if stmt == nil {
+			batch.Rejected = append(batch.Rejected, "empty item")
+			continue
+		}
+		if err := stmt.Validate(); err != nil {
+			batch.Rejected = append(batch.Rejected, fmt.Sprintf("%s: %v", stmt.UniqueIdentifier, err))
+			continue
+		}
+		match, err := s.reconciler.AddStatement(stmt)
+		s.record(batch, stmt.UniqueIdentifier, match, err)
+	}
+	return batch, s.save()
+}
+
+func (s *IncrementalService) record(batch *IncrementalBatch, id string, match *matcher.MatchResult, err error) {
+	switch {
+	case err != nil:
+		batch.Rejected = append(batch.Rejected, fmt.Sprintf("%s: %v", id, err))
+	case match != nil:
+		batch.Matches = append(batch.Matches, match)
+		if s.config.OnMatch != nil {
+			s.config.OnMatch(match)
+		}
+	default:
+		batch.Open++
+	}
+}
+
+func (s *IncrementalService) save() error {
+	if s.config.SnapshotPath == "" {
+		return nil
+	}
+	return s.reconciler.SaveSnapshot(s.config.SnapshotPath)
+}
+
+// Run polls the inbox until the context is cancelled
+func (s *IncrementalService) Run(ctx context.Context) error {
+	if s.config.InboxDir == "" {
+		return fmt.Errorf("no inbox directory configured")
+	}
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.ProcessInbox(ctx); err != nil {
+			s.logger.WithError(err).Error("Failed to process inbox")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessInbox reads every complete file in the inbox: system files first,
+// then bank files, each in name order. A file is moved to the processed
+// directory once its items have been added and the snapshot saved, or to the
+// failed directory when it cannot be parsed.
+func (s *IncrementalService) ProcessInbox(ctx context.Context) error {
+	for _, dir := range []string{InboxTransactionsDir, InboxStatementsDir} {
+		files, err := s.inboxFiles(filepath.Join(s.config.InboxDir, dir))
+		if err != nil {
+			return err
+		}
+		for _, path := range files {
+			if err := ctx.Err(); err != nil {
+				return nil
+			}
+
+			var batch *IncrementalBatch
+			if dir == InboxTransactionsDir {
+				batch, err = s.processTransactionFile(ctx, path)
+			} else {
+				batch, err = s.processStatementFile(ctx, path)
+			}
+
+			target := InboxProcessedDir
+			if err != nil {
+				s.logger.WithError(err).WithField("file", path).Error("Failed to process inbox file")
+				target = InboxFailedDir
+			} else {
+				s.logger.WithFields(logger.Fields{
+					"file":     path,
+					"matches":  len(batch.Matches),
+					"open":     batch.Open,
+					"rejected": len(batch.Rejected),
+				}).Info("Processed inbox file")
+			}
+			if err := s.moveInboxFile(path, target); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *IncrementalService) processTransactionFile(ctx context.Context, path string) (*IncrementalBatch, error) {
+	parser, err := parsers.NewTransactionParser(s.config.TransactionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction parser: %w", err)
+	}
+	transactions, _, err := parser.ParseTransactionsWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transactions from %s: %w", path, err)
+	}
+	return s.AddTransactions(transactions)
+}
+
+func (s *IncrementalService) processStatementFile(ctx context.Context, path string) (*IncrementalBatch, error) {
+	bankConfig, err := s.config.ResolveBankConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	parser, err := parsers.NewBankStatementParser(bankConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parser for %s: %w", path, err)
+	}
+	statements, _, err := parser.ParseBankStatementsWithContext(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bank statements from %s: %w", path, err)
+	}
+	return s.AddStatements(statements)
+}
+
+// inboxFiles lists the complete files in an inbox directory in name order,
+// skipping hidden files and files still being written
+func (s *IncrementalService) inboxFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inbox: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") ||
+			strings.HasSuffix(name, ".tmp") || strings.HasSuffix(name, ".part") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// moveInboxFile moves a file out of the inbox. The name is prefixed with the
+// time so a file dropped twice does not overwrite the first copy.
+func (s *IncrementalService) moveInboxFile(path, target string) error {
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-" + filepath.Base(path)
+	if err := os.Rename(path, filepath.Join(s.config.InboxDir, target, name)); err != nil {
+		return fmt.Errorf("failed to move %s out of the inbox: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/parsers"
+)
+
+func TestIncrementalService_Inbox(t *testing.T) {
+	inbox := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	newService := func() (*IncrementalService, *[]string) {
+		var matched []string
+		config := DefaultIncrementalConfig()
+		config.SnapshotPath = statePath
+		config.InboxDir = inbox
+		config.OnMatch = func(match *matcher.MatchResult) {
+			matched = append(matched, match.Transaction.TrxID+"="+match.BankStatement.UniqueIdentifier)
+		}
+		service, err := NewIncrementalService(matcher.DefaultMatchingConfig(), config)
+		if err != nil {
+			t.Fatalf("Failed to create incremental service: %v", err)
+		}
+		return service, &matched
+	}
+	drop := func(dir, name, content string) {
+		if err := os.WriteFile(filepath.Join(inbox, dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	files := func(dir string) int {
+		entries, err := os.ReadDir(filepath.Join(inbox, dir))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", dir, err)
+		}
+		return len(entries)
+	}
+
+	service, matched := newService()
+	drop(InboxTransactionsDir, "day1.csv", `trxID,amount,type,transactionTime
+TX001,100.50,CREDIT,2024-01-15T10:30:00Z
+TX002,250.00,DEBIT,2024-01-16T14:20:00Z
+TX003,75.25,CREDIT,2024-01-17T09:15:00Z
+`)
+	drop(InboxStatementsDir, "bank1.csv", `unique_identifier,amount,date
+BS001,100.50,2024-01-15
+`)
+	drop(InboxStatementsDir, "bank2.csv.part", `unique_identifier,amount,date
+BS002,-250.00,2024-01-16
+`)
+	drop(InboxStatementsDir, "broken.csv", "not,a,bank,file\n1,2,3,4\n")
+
+	if err := service.ProcessInbox(context.Background()); err != nil {
+		t.Fatalf("Failed to process inbox: %v", err)
+	}
+	if strings.Join(*matched, ",") != "TX001=BS001" {
+		t.Errorf("Expected TX001 to match BS001, got %v", *matched)
+	}
+	if files(InboxProcessedDir) != 2 || files(InboxFailedDir) != 1 || files(InboxStatementsDir) != 1 {
+		t.Errorf("Expected 2 processed, 1 failed and the partial file left, got %d/%d/%d",
+			files(InboxProcessedDir), files(InboxFailedDir), files(InboxStatementsDir))
+	}
+
+	// A restart picks up the open transactions from the snapshot
+	restarted, matched := newService()
+	if stats := restarted.Reconciler().Stats(); stats.OpenTransactions != 2 || stats.Matches != 1 {
+		t.Fatalf("Expected 2 open transactions and 1 match after restart, got %+v", stats)
+	}
+	if err := os.Rename(filepath.Join(inbox, InboxStatementsDir, "bank2.csv.part"),
+		filepath.Join(inbox, InboxStatementsDir, "bank2.csv")); err != nil {
+		t.Fatalf("Failed to complete bank file: %v", err)
+	}
+	if err := restarted.ProcessInbox(context.Background()); err != nil {
+		t.Fatalf("Failed to process inbox: %v", err)
+	}
+	if strings.Join(*matched, ",") != "TX002=BS002" {
+		t.Errorf("Expected TX002 to match BS002 after restart, got %v", *matched)
+	}
+	open := restarted.Reconciler().OpenTransactions()
+	if len(open) != 1 || open[0].TrxID != "TX003" {
+		t.Errorf("Expected TX003 to be left open, got %v", open)
+	}
+	if statements := restarted.Reconciler().OpenStatements(); len(statements) != 0 {
+		t.Errorf("Expected no open statements, got %d", len(statements))
+	}
+}
+
+func TestIncrementalService_RejectsInvalidItems(t *testing.T) {
+	service, err := NewIncrementalService(nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create incremental service: %v", err)
+	}
+
+	parser, err := parsers.NewTransactionParser(parsers.DefaultTransactionParserConfig())
+	if err != nil {
+		t.Fatalf("Failed to create parser: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "tx.csv")
+	if err := os.WriteFile(path, []byte(`trxID,amount,type,transactionTime
+TX001,100.50,CREDIT,2024-01-15T10:30:00Z
+`), 0o644); err != nil {
+		t.Fatalf("Failed to write transactions: %v", err)
+	}
+	transactions, _, err := parser.ParseTransactions(path)
+	if err != nil {
+		t.Fatalf("Failed to parse transactions: %v", err)
+	}
+
+	// The same transaction twice and an empty item
+	batch, err := service.AddTransactions(append(transactions, transactions[0], nil))
+	if err != nil {
+		t.Fatalf("Failed to add transactions: %v", err)
+	}
+	if batch.Open != 1 || len(batch.Rejected) != 2 {
+		t.Errorf("Expected 1 open and 2 rejected transactions, got %+v", batch)
+	}
+}
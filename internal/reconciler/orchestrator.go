@@ -147,7 +147,7 @@ func (ro *ReconciliationOrchestrator) ProcessReconciliationWithAdvancedFeatures(
 	}).Info("Starting advanced reconciliation process")
 	
 	// Initialize progress tracking
-	ro.initializeProgress()
+	ro.initializeProgress(1 + len(request.BankFiles))
 	
 	startTime := time.Now()
 	defer func() {
@@ -188,6 +188,7 @@ func (ro *ReconciliationOrchestrator) ProcessReconciliationWithAdvancedFeatures(
 		"transactions_count": len(transactions),
 		"parse_stats":        txStats,
 	}).Info("Successfully parsed system transactions")
+	ro.recordFilesParsed(1, len(transactions))
 	
 	// Step 3: Parse bank statements with preprocessing
 	ro.updateProgress("Parsing bank statements", 2, time.Since(startTime))
@@ -195,6 +196,7 @@ func (ro *ReconciliationOrchestrator) ProcessReconciliationWithAdvancedFeatures(
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse bank statements: %w", err)
 	}
+	ro.recordFilesParsed(len(request.BankFiles), len(statements))
 	
 	// Step 4: Apply filters and transformations
 	ro.updateProgress("Applying filters", 3, time.Since(startTime))
@@ -202,14 +204,18 @@ func (ro *ReconciliationOrchestrator) ProcessReconciliationWithAdvancedFeatures(
 	
 	// Step 5: Perform reconciliation with enhanced matching
 	ro.updateProgress("Performing reconciliation", 4, time.Since(startTime))
+	matchingStartTime := time.Now()
 	reconciliationResult, err := ro.performAdvancedMatching(ctx, transactions, statements, options)
 	if err != nil {
 		return nil, fmt.Errorf("reconciliation failed: %w", err)
 	}
+	matchingDuration := time.Since(matchingStartTime)
+	ro.setMatchesFound(len(reconciliationResult.Matches))
 	
 	// Step 6: Generate enhanced results
 	ro.updateProgress("Generating results", 5, time.Since(startTime))
-	enhancedResult := ro.buildEnhancedResult(reconciliationResult, txStats, stmtStats, options, startTime)
+	enhancedResult := ro.buildEnhancedResult(request, transactions, statements, reconciliationResult,
+		txStats, stmtStats, options, startTime, matchingDuration)
 	
 	return enhancedResult, nil
 }
@@ -300,7 +306,7 @@ type MatchingMetrics struct {
 	MatchingAccuracy      float64           `json:"matching_accuracy"`
 	FalsePositiveRate     float64           `json:"false_positive_rate"`
 	FalseNegativeRate     float64           `json:"false_negative_rate"`
-	MatchingStrategiesUsed []string         `json:"matching_strategies_used"`
+	MatchingStrategiesUsed []*matcher.PassStats `json:"matching_strategies_used"`
 }
 
 // PerformanceMetrics contains performance analysis
@@ -343,7 +349,7 @@ type AnomalyDetection struct {
 func DefaultReconciliationOptions() *ReconciliationOptions {
 	return &ReconciliationOptions{
 		UseAdvancedMatching:        true,
-		MatchingStrategies:        []string{"exact", "fuzzy", "amount_date"},
+		MatchingStrategies:        append([]string(nil), matcher.DefaultStrategies...),
 		EnablePreprocessing:       true,
 		ParallelProcessing:        true,
 		MaxConcurrency:           4,
@@ -359,7 +365,7 @@ func DefaultReconciliationOptions() *ReconciliationOptions {
 
 // Helper methods for orchestration
 
-func (ro *ReconciliationOrchestrator) initializeProgress() {
+func (ro *ReconciliationOrchestrator) initializeProgress(totalFiles int) {
 	ro.progressMutex.Lock()
 	defer ro.progressMutex.Unlock()
 	
@@ -368,6 +374,7 @@ func (ro *ReconciliationOrchestrator) initializeProgress() {
 		CompletedSteps:  0,
 		StartTime:       time.Now(),
 		PercentComplete: 0.0,
+		TotalFiles:      totalFiles,
 	}
 }
 
@@ -385,6 +392,8 @@ func (ro *ReconciliationOrchestrator) updateProgress(step string, completed int,
 		avgTimePerStep := elapsed / time.Duration(completed)
 		remainingSteps := ro.currentProgress.TotalSteps - completed
 		ro.currentProgress.EstimatedRemaining = avgTimePerStep * time.Duration(remainingSteps)
+	} else if completed >= ro.currentProgress.TotalSteps {
+		ro.currentProgress.EstimatedRemaining = 0
 	}
 	
 	// Notify callbacks
@@ -409,6 +418,12 @@ func (ro *ReconciliationOrchestrator) validateAdvancedRequest(
 		options.MaxConcurrency = 4
 	}
 	
+	for _, name := range options.MatchingStrategies {
+		if matcher.DefaultStrategyRegistry.Get(name) == nil {
+			return fmt.Errorf("unknown matching strategy %q", name)
+		}
+	}
+	
 	return nil
 }
 
@@ -471,6 +486,7 @@ func (ro *ReconciliationOrchestrator) applyAdvancedFiltering(
 	
 	// Apply basic date range filtering
 	filteredTx, filteredStmt := ro.service.applyDateRangeFiltering(transactions, statements, request)
+	filteredTx, filteredStmt = ro.service.addCarriedItems(filteredTx, filteredStmt, request)
 	
 	// Apply additional filters from options
 	if options.AmountThresholds != nil {
@@ -493,11 +509,26 @@ func (ro *ReconciliationOrchestrator) performAdvancedMatching(
 ) (*matcher.ReconciliationResult, error) {
 	
 	// Use custom matching configuration if provided
+	config := ro.service.matchingEngine.GetConfiguration()
 	if options.CustomMatchingConfig != nil {
-		err := ro.service.matchingEngine.UpdateConfiguration(options.CustomMatchingConfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to update matching configuration: %w", err)
-		}
+		config = options.CustomMatchingConfig.Clone()
+	}
+	
+	// The requested strategies run as separate passes, in order; without
+	// advanced matching a single scoring pass is used
+	config.Strategies = nil
+	if options.UseAdvancedMatching {
+		config.Strategies = options.MatchingStrategies
+	}
+	
+	// Candidates are scored by MaxConcurrency workers over date partitions
+	config.Parallelism = 1
+	if options.ParallelProcessing {
+		config.Parallelism = options.MaxConcurrency
+	}
+	
+	if err := ro.service.matchingEngine.UpdateConfiguration(config); err != nil {
+		return nil, fmt.Errorf("failed to update matching configuration: %w", err)
 	}
 	
 	// Perform the matching
@@ -505,19 +536,40 @@ func (ro *ReconciliationOrchestrator) performAdvancedMatching(
 }
 
 func (ro *ReconciliationOrchestrator) buildEnhancedResult(
+	request *ReconciliationRequest,
+	transactions []*models.Transaction,
+	statements []*models.BankStatement,
 	result *matcher.ReconciliationResult,
 	txStats *parsers.ParseStats,
 	stmtStats map[string]*parsers.ParseStats,
 	options *ReconciliationOptions,
 	startTime time.Time,
+	matchingDuration time.Duration,
 ) *EnhancedReconciliationResult {
 	
-	// Build base result
+	// Build base result the same way ProcessReconciliation does
 	baseResult := &ReconciliationResult{
 		Summary:              &ResultSummary{},
 		ProcessingStats:     &ProcessingStats{},
-		ProcessedAt:         time.Now(),
+		ProcessedAt:         startTime,
+		Request:             request,
+	}
+	if request.StartDate != nil && request.EndDate != nil {
+		baseResult.Summary.DateRange = &DateRange{
+			Start: *request.StartDate,
+			End:   *request.EndDate,
+		}
+	}
+	
+	var discrepancies []*Discrepancy
+	if options.PerformDiscrepancyAnalysis {
+		discrepancies = ro.service.analyzeDiscrepancies(result.Matches, transactions, statements)
+		discrepancies = append(discrepancies, ro.service.analyzeGroupDiscrepancies(result.GroupMatches)...)
 	}
+	ro.service.buildFinalResult(baseResult, result, discrepancies, txStats, stmtStats, matchingDuration)
+	
+	baseResult.Summary.ProcessingDuration = time.Since(startTime)
+	baseResult.ProcessingStats.TotalProcessingTime = baseResult.Summary.ProcessingDuration
 	
 	// Build enhanced result
 	enhancedResult := &EnhancedReconciliationResult{
@@ -531,6 +583,7 @@ func (ro *ReconciliationOrchestrator) buildEnhancedResult(
 	}
 	
 	if options.IncludeDetailedMetrics {
+		enhancedResult.MatchingMetrics = ro.calculateMatchingMetrics(result)
 		enhancedResult.PerformanceMetrics = ro.calculatePerformanceMetrics(startTime)
 	}
 	
@@ -622,6 +675,22 @@ func (ro *ReconciliationOrchestrator) filterTransactionsByType(
 	return filtered
 }
 
+func (ro *ReconciliationOrchestrator) recordFilesParsed(files, records int) {
+	ro.progressMutex.Lock()
+	defer ro.progressMutex.Unlock()
+	
+	ro.currentProgress.FilesParsed += files
+	ro.currentProgress.RecordsProcessed += records
+	ro.currentProgress.TotalRecords += records
+}
+
+func (ro *ReconciliationOrchestrator) setMatchesFound(count int) {
+	ro.progressMutex.Lock()
+	defer ro.progressMutex.Unlock()
+	
+	ro.currentProgress.MatchesFound = count
+}
+
 func (ro *ReconciliationOrchestrator) addWarning(message string) {
 	ro.progressMutex.Lock()
 	defer ro.progressMutex.Unlock()
@@ -636,6 +705,26 @@ func (ro *ReconciliationOrchestrator) calculateDataQualityMetrics(result *matche
 	}
 }
 
+// calculateMatchingMetrics summarises match confidence by match type and
+// records what each matching pass contributed
+func (ro *ReconciliationOrchestrator) calculateMatchingMetrics(result *matcher.ReconciliationResult) *MatchingMetrics {
+	metrics := &MatchingMetrics{
+		ConfidenceDistribution: make(map[string]int),
+		MatchingStrategiesUsed: result.Passes,
+	}
+	
+	total := 0.0
+	for _, match := range result.Matches {
+		metrics.ConfidenceDistribution[match.MatchType.String()]++
+		total += match.ConfidenceScore
+	}
+	if len(result.Matches) > 0 {
+		metrics.AverageConfidenceScore = total / float64(len(result.Matches))
+	}
+	
+	return metrics
+}
+
 func (ro *ReconciliationOrchestrator) calculatePerformanceMetrics(startTime time.Time) *PerformanceMetrics {
 	return &PerformanceMetrics{
 		TotalProcessingTime: time.Since(startTime),
@@ -11,6 +11,7 @@ import (
 	"golang-reconciliation-service/internal/matcher"
 	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/rules"
 
 	"github.com/shopspring/decimal"
 )
@@ -273,6 +274,72 @@ func TestReconciliationOrchestrator_TypeFiltering(t *testing.T) {
 	t.Logf("Type filtering: %d CREDIT transactions found", result.Summary.TotalTransactions)
 }
 
+func TestReconciliationOrchestrator_MatchingStrategies(t *testing.T) {
+	// Setup test data
+	systemFile, bankFiles, cleanup := createTestDataFiles(t)
+	defer cleanup()
+	
+	txConfig, bankConfigs := createTestConfigs()
+	
+	service, err := NewReconciliationService(
+		txConfig,
+		bankConfigs[filepath.Base(bankFiles[0])],
+		matcher.DefaultMatchingConfig(),
+		DefaultConfig(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	
+	orchestrator, err := NewReconciliationOrchestrator(service, nil)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+	
+	fileConfigs := make(map[string]*parsers.BankConfig)
+	for _, file := range bankFiles {
+		if config, exists := bankConfigs[filepath.Base(file)]; exists {
+			fileConfigs[file] = config
+		}
+	}
+	request := &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:        bankFiles,
+		TransactionConfig: txConfig,
+		BankConfigs:      fileConfigs,
+	}
+	
+	ctx := context.Background()
+	options := DefaultReconciliationOptions()
+	options.MatchingStrategies = []string{"exact", "fuzzy"}
+	
+	result, err := orchestrator.ProcessReconciliationWithAdvancedFeatures(ctx, request, options)
+	if err != nil {
+		t.Fatalf("Reconciliation with strategies failed: %v", err)
+	}
+	
+	if result.MatchingMetrics == nil {
+		t.Fatal("Expected matching metrics")
+	}
+	passes := result.MatchingMetrics.MatchingStrategiesUsed
+	if len(passes) != 2 || passes[0].Strategy != "exact" || passes[1].Strategy != "fuzzy" {
+		t.Fatalf("Expected exact and fuzzy passes, got %+v", passes)
+	}
+	
+	matched := 0
+	for _, pass := range passes {
+		matched += pass.Matches
+	}
+	if matched != result.Summary.MatchedTransactions {
+		t.Errorf("Expected the passes to account for all %d matches, got %d", result.Summary.MatchedTransactions, matched)
+	}
+	
+	options.MatchingStrategies = []string{"exact", "nearest"}
+	if _, err := orchestrator.ProcessReconciliationWithAdvancedFeatures(ctx, request, options); err == nil {
+		t.Error("Expected an error for an unknown matching strategy")
+	}
+}
+
 func TestReconciliationOrchestrator_CustomMatchingConfig(t *testing.T) {
 	// Setup test data
 	systemFile, bankFiles, cleanup := createTestDataFiles(t)
@@ -340,6 +407,80 @@ func TestReconciliationOrchestrator_CustomMatchingConfig(t *testing.T) {
 	}
 }
 
+func TestReconciliationOrchestrator_PreprocessingKeepsDescriptions(t *testing.T) {
+	dir := t.TempDir()
+	systemFile := filepath.Join(dir, "transactions.csv")
+	bankFile := filepath.Join(dir, "statements.csv")
+	if err := os.WriteFile(systemFile, []byte(`trxID,amount,type,transactionTime,description
+TX001,100.00,CREDIT,2024-01-15T10:00:00Z,Acme Corp invoice 1001
+TX002,100.00,CREDIT,2024-01-15T11:00:00Z,Globex payroll January
+`), 0o644); err != nil {
+		t.Fatalf("Failed to write system file: %v", err)
+	}
+	if err := os.WriteFile(bankFile, []byte(`unique_identifier,amount,date,description
+BS001,100.00,2024-01-15,GLOBEX PAYROLL JANUARY
+BS002,100.00,2024-01-15,ACME CORP INVOICE 1001
+BS003,-5.00,2024-01-15,Monthly bank fee
+`), 0o644); err != nil {
+		t.Fatalf("Failed to write bank file: %v", err)
+	}
+
+	txConfig, bankConfigs := createTestConfigs()
+	bankConfig := bankConfigs["bank1_statements.csv"]
+	service, err := NewReconciliationService(txConfig, bankConfig, matcher.DefaultMatchingConfig(), DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	orchestrator, err := NewReconciliationOrchestrator(service, DefaultPreprocessingConfig())
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	set, err := rules.ReadCSV(strings.NewReader(`action,trx_id,statement_id,id_prefix,amount,description,name
+ignore,,,,,(?i)bank fee,fees
+`))
+	if err != nil {
+		t.Fatalf("Failed to read rules: %v", err)
+	}
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.Weights = matchingConfig.Weights.WithDescriptionWeight(0.3)
+	matchingConfig.Rules = set
+
+	options := DefaultReconciliationOptions()
+	options.EnablePreprocessing = true
+	options.CustomMatchingConfig = matchingConfig
+
+	request := &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: bankConfig},
+	}
+	result, err := orchestrator.ProcessReconciliationWithAdvancedFeatures(context.Background(), request, options)
+	if err != nil {
+		t.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	// Amounts and dates tie, so only the descriptions tell the pairs apart
+	expected := map[string]string{"TX001": "BS002", "TX002": "BS001"}
+	if len(result.MatchedTransactions) != len(expected) {
+		t.Fatalf("Expected %d matches, got %d", len(expected), len(result.MatchedTransactions))
+	}
+	for _, match := range result.MatchedTransactions {
+		if match.Transaction.Description == "" || match.BankStatement.Description == "" {
+			t.Errorf("Expected descriptions to survive preprocessing for %s", match.Transaction.TrxID)
+		}
+		if want := expected[match.Transaction.TrxID]; match.BankStatement.UniqueIdentifier != want {
+			t.Errorf("Expected %s to match %s by description, got %s",
+				match.Transaction.TrxID, want, match.BankStatement.UniqueIdentifier)
+		}
+	}
+
+	if len(result.IgnoredStatements) != 1 || result.IgnoredStatements[0].Statement.UniqueIdentifier != "BS003" {
+		t.Errorf("Expected BS003 to be ignored by its description, got %d ignored", len(result.IgnoredStatements))
+	}
+}
+
 func TestDataPreprocessor_TransactionPreprocessing(t *testing.T) {
 	preprocessor := NewDataPreprocessor(DefaultPreprocessingConfig())
 	
@@ -0,0 +1,662 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/parsers"
+
+	"github.com/shopspring/decimal"
+)
+
+// memoryCheckInterval is the number of records read between two memory checks
+const memoryCheckInterval = 1000
+
+// duplicateSpreadDays is how many normalised days apart two records with the
+// same duplicate key can be: the key uses the calendar date in the record's
+// own time zone, which is at most a day either side of its UTC date
+const duplicateSpreadDays = 2
+
+// processOutOfCore reconciles inputs too large to be held in memory. The
+// result is the one the in-memory path gives, in the same order, while the
+// heap is kept under Config.MemoryLimitMB:
+//
+//  1. Both inputs are streamed, filtered and numbered in input order, and
+//     spilled to chunk files sorted by day and amount.
+//  2. The two sorted streams are merge-joined within the matching window,
+//     linking every transaction with each statement it could be paired
+//     with. Linked items form components that never compete with items of
+//     another component.
+//  3. The records are sorted again by component and matched a batch of
+//     whole components at a time, each batch in input order.
+//
+// Batches are folded into the result as soon as they are matched. With
+// DetailedBreakdown the matched and unmatched items are kept for the result,
+// so the result itself still grows with the input; without it only the
+// summary, the totals and the discrepancies are kept.
+//
+// Transaction and statement identifiers are assumed to be unique: the
+// in-memory path compares them across the whole input, this one only within
+// a batch.
+func (rs *ReconciliationService) processOutOfCore(
+	ctx context.Context,
+	request *ReconciliationRequest,
+	result *ReconciliationResult,
+) error {
+	config := rs.matchingEngine.Config
+	window, bounded := config.MatchingWindowDays()
+	if !bounded {
+		return fmt.Errorf("matching must be bounded by date: set a date tolerance, leave identifiers unweighted and use no manual match rules")
+	}
+	if config.AmbiguityReviewScore > 0 {
+		return fmt.Errorf("same-day ambiguity review needs every item of a day at once: leave the ambiguity review score unset")
+	}
+
+	dir, err := os.MkdirTemp(rs.config.SpillDir, "reconcile-spill-")
+	if err != nil {
+		return fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	run := &outOfCoreRun{
+		rs:      rs,
+		config:  config,
+		request: request,
+		monitor: parsers.NewMemoryMonitor(rs.config.MemoryLimitMB, 1, nil),
+		window:  int64(window),
+		// Without currency conversion and group passes a pair is only a
+		// candidate when the amounts are within tolerance as well
+		linkByAmount:        config.BaseCurrency == "" && !config.EnablePartialMatching && !config.EnableOneToManyMatching,
+		transactions:        newSpillSorter(dir, rs.config.SpillChunkSize, byDayAndAmount),
+		statements:          newSpillSorter(dir, rs.config.SpillChunkSize, byDayAndAmount),
+		carriedTransactions: make(map[int64]*models.Transaction),
+		carriedStatements:   make(map[int64]*models.BankStatement),
+		fold:                newOutOfCoreFold(rs, request),
+	}
+
+	monitorCtx, stopMonitor := context.WithCancel(ctx)
+	defer stopMonitor()
+	go run.monitor.Start(monitorCtx)
+
+	parseStats, bankParseStats, err := run.spillInputs(ctx)
+	if err != nil {
+		return err
+	}
+
+	matchingStartTime := time.Now()
+	if err := run.linkComponents(); err != nil {
+		return err
+	}
+	components, err := run.sortByComponent()
+	if err != nil {
+		return err
+	}
+	if err := run.matchComponents(ctx, components); err != nil {
+		return err
+	}
+	matchingDuration := time.Since(matchingStartTime)
+
+	matchingResult, discrepancies := run.fold.result()
+	rs.buildFinalResult(result, matchingResult, discrepancies, parseStats, bankParseStats, matchingDuration)
+	run.fold.finishSummary(result.Summary)
+	result.ProcessingStats.PeakMemoryUsage = int64(run.monitor.PeakMemoryMB()) * 1024 * 1024
+
+	return nil
+}
+
+// outOfCoreRun holds the state of one out-of-core reconciliation
+type outOfCoreRun struct {
+	rs      *ReconciliationService
+	config  *matcher.MatchingConfig
+	request *ReconciliationRequest
+	monitor *parsers.MemoryMonitor
+
+	window       int64 // see matcher.MatchingConfig.MatchingWindowDays
+	linkByAmount bool  // link on amount as well as date
+
+	transactions *spillSorter
+	statements   *spillSorter
+	records      int64   // records numbered so far
+	parent       []int64 // union-find over record numbers
+
+	// Carried items keep their identity, so that the summary can tell them
+	// apart from the parsed ones
+	carriedTransactions map[int64]*models.Transaction
+	carriedStatements   map[int64]*models.BankStatement
+
+	fold *outOfCoreFold
+}
+
+// spillInputs streams the system file and the bank files into the spill
+// sorters, applying the same validation, date filtering and carried items as
+// the in-memory path
+func (run *outOfCoreRun) spillInputs(ctx context.Context) (*parsers.ParseStats, map[string]*parsers.ParseStats, error) {
+	rs, request := run.rs, run.request
+	streamConfig := parsers.DefaultStreamingConfig()
+	streamConfig.BatchSize = rs.config.BatchSize
+
+	txParser, err := parsers.NewStreamingTransactionParser(request.TransactionConfig, streamConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create transaction parser: %w", err)
+	}
+
+	carriedIDs := make(map[string]bool, len(request.CarriedTransactions))
+	for _, tx := range request.CarriedTransactions {
+		carriedIDs[tx.TrxID] = true
+	}
+	seenTransactions := make(map[string]bool)
+
+	invalid := 0
+	parseStats, err := txParser.ParseTransactionsStreamAdvanced(ctx, request.SystemFile, func(batch []*models.Transaction) error {
+		for _, tx := range batch {
+			if rs.config.ValidateInputs && tx.Validate() != nil {
+				invalid++
+				continue
+			}
+			if !rs.isWithinDateRange(tx.TransactionTime, request.StartDate, request.EndDate) {
+				continue
+			}
+			if carriedIDs[tx.TrxID] {
+				seenTransactions[tx.TrxID] = true
+			}
+			if err := run.addTransaction(tx); err != nil {
+				return err
+			}
+		}
+		return run.relieveMemory()
+	}, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse transactions from %s: %w", request.SystemFile, err)
+	}
+	parseStats.ErrorCount += invalid
+
+	for _, tx := range request.CarriedTransactions {
+		if !seenTransactions[tx.TrxID] {
+			seenTransactions[tx.TrxID] = true
+			run.carriedTransactions[run.records] = tx
+			if err := run.addTransaction(tx); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	carriedKeys := make(map[string]bool, len(request.CarriedStatements))
+	for _, stmt := range request.CarriedStatements {
+		carriedKeys[StatementKey(stmt)] = true
+	}
+	seenStatements := make(map[string]bool)
+
+	bankParseStats := make(map[string]*parsers.ParseStats, len(request.BankFiles))
+	for _, filePath := range request.BankFiles {
+		bankConfig, exists := request.BankConfigs[filePath]
+		if !exists {
+			return nil, nil, fmt.Errorf("no configuration found for bank file: %s", filePath)
+		}
+		parser, err := parsers.NewStreamingBankStatementParser(bankConfig, streamConfig)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create parser for %s: %w", filePath, err)
+		}
+
+		invalid := 0
+		stats, err := parser.ParseBankStatementsStreamAdvanced(ctx, filePath, func(batch []*models.BankStatement) error {
+			for _, stmt := range batch {
+				if rs.config.ValidateInputs && stmt.Validate() != nil {
+					invalid++
+					continue
+				}
+				if !rs.isWithinDateRange(stmt.Date, request.StartDate, request.EndDate) {
+					continue
+				}
+				if key := StatementKey(stmt); carriedKeys[key] {
+					seenStatements[key] = true
+				}
+				if err := run.addStatement(stmt); err != nil {
+					return err
+				}
+			}
+			return run.relieveMemory()
+		}, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse bank statements from %s: %w", filePath, err)
+		}
+		stats.ErrorCount += invalid
+		bankParseStats[filePath] = stats
+	}
+
+	for _, stmt := range request.CarriedStatements {
+		if key := StatementKey(stmt); !seenStatements[key] {
+			seenStatements[key] = true
+			run.carriedStatements[run.records] = stmt
+			if err := run.addStatement(stmt); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return parseStats, bankParseStats, nil
+}
+
+// addTransaction numbers a transaction and spills it. Its amount is signed
+// the way the statement index looks it up: debits are negative.
+func (run *outOfCoreRun) addTransaction(tx *models.Transaction) error {
+	amount := tx.Amount
+	if tx.Type == models.TransactionTypeDebit {
+		amount = amount.Neg()
+	}
+	record := &spillRecord{
+		Seq:         run.records,
+		Day:         run.config.DayNumber(tx.TransactionTime),
+		Amount:      amount,
+		Transaction: tx,
+	}
+	run.records++
+	return run.transactions.add(record)
+}
+
+// addStatement numbers a bank statement and spills it
+func (run *outOfCoreRun) addStatement(stmt *models.BankStatement) error {
+	record := &spillRecord{
+		Seq:       run.records,
+		Day:       run.config.DayNumber(stmt.Date),
+		Amount:    stmt.Amount,
+		Statement: stmt,
+	}
+	run.records++
+	return run.statements.add(record)
+}
+
+// relieveMemory spills the buffered records early when the heap is over the
+// limit
+func (run *outOfCoreRun) relieveMemory() error {
+	if !run.monitor.Check() {
+		return nil
+	}
+	if err := run.transactions.spill(); err != nil {
+		return err
+	}
+	if err := run.statements.spill(); err != nil {
+		return err
+	}
+	runtime.GC()
+	return nil
+}
+
+// resolve returns the record with a carried item restored to the value the
+// request holds
+func (run *outOfCoreRun) resolve(record *spillRecord) *spillRecord {
+	if tx, ok := run.carriedTransactions[record.Seq]; ok {
+		record.Transaction = tx
+	}
+	if stmt, ok := run.carriedStatements[record.Seq]; ok {
+		record.Statement = stmt
+	}
+	return record
+}
+
+// find returns the component of a record
+func (run *outOfCoreRun) find(seq int64) int64 {
+	for run.parent[seq] != seq {
+		run.parent[seq] = run.parent[run.parent[seq]]
+		seq = run.parent[seq]
+	}
+	return seq
+}
+
+// union links the components of two records
+func (run *outOfCoreRun) union(a, b int64) {
+	rootA, rootB := run.find(a), run.find(b)
+	if rootA != rootB {
+		run.parent[rootB] = rootA
+	}
+}
+
+// linkComponents merge-joins the day-sorted transactions and statements and
+// links every pair the matching engine could consider. Links only have to
+// cover the candidates; linking more keeps the result the same and only
+// makes batches larger. Duplicates are looked for on the way, since records
+// sharing a duplicate key are next to each other in day order.
+func (run *outOfCoreRun) linkComponents() error {
+	run.parent = make([]int64, run.records)
+	for i := range run.parent {
+		run.parent[i] = int64(i)
+	}
+
+	transactions, err := run.transactions.merge()
+	if err != nil {
+		return err
+	}
+	defer transactions.close()
+	statements, err := run.statements.merge()
+	if err != nil {
+		return err
+	}
+	defer statements.close()
+
+	txDuplicates := newDuplicateFinder(sectionTransactionDuplicates, func(record *spillRecord) string {
+		return transactionDuplicateKey(record.Transaction)
+	}, func(first, duplicate *spillRecord) *Discrepancy {
+		return duplicateTransaction(first.Transaction, duplicate.Transaction)
+	})
+	stmtDuplicates := newDuplicateFinder(sectionStatementDuplicates, func(record *spillRecord) string {
+		return statementDuplicateKey(record.Statement)
+	}, func(first, duplicate *spillRecord) *Discrepancy {
+		return duplicateStatement(first.Statement, duplicate.Statement)
+	})
+
+	// Statements within the window of the current transaction, by day and
+	// in amount order within a day
+	var days []*windowDay
+
+	for {
+		tx, err := transactions.next()
+		if err != nil {
+			return err
+		}
+		if tx == nil {
+			break
+		}
+		tx = run.resolve(tx)
+		txDuplicates.add(tx)
+
+		for next := statements.peek(); next != nil && next.Day <= tx.Day+run.window; next = statements.peek() {
+			stmt, err := statements.next()
+			if err != nil {
+				return err
+			}
+			stmt = run.resolve(stmt)
+			stmtDuplicates.add(stmt)
+
+			if len(days) == 0 || days[len(days)-1].day != stmt.Day {
+				days = append(days, &windowDay{day: stmt.Day})
+			}
+			day := days[len(days)-1]
+			day.seqs = append(day.seqs, stmt.Seq)
+			day.amounts = append(day.amounts, stmt.Amount)
+		}
+		for len(days) > 0 && days[0].day < tx.Day-run.window {
+			days = days[1:]
+		}
+
+		if !run.linkByAmount {
+			for _, day := range days {
+				for _, seq := range day.seqs {
+					run.union(tx.Seq, seq)
+				}
+			}
+			continue
+		}
+
+		// The amount range the statement index looks candidates up in
+		tolerance := run.config.GetAmountTolerance(tx.Amount.Abs())
+		minAmount, maxAmount := tx.Amount.Sub(tolerance), tx.Amount.Add(tolerance)
+		for _, day := range days {
+			i := sort.Search(len(day.amounts), func(i int) bool {
+				return day.amounts[i].GreaterThanOrEqual(minAmount)
+			})
+			for ; i < len(day.amounts) && !day.amounts[i].GreaterThan(maxAmount); i++ {
+				run.union(tx.Seq, day.seqs[i])
+			}
+		}
+	}
+
+	for {
+		stmt, err := statements.next()
+		if err != nil {
+			return err
+		}
+		if stmt == nil {
+			break
+		}
+		stmtDuplicates.add(run.resolve(stmt))
+	}
+
+	run.fold.discrepancies = append(run.fold.discrepancies, txDuplicates.finish()...)
+	run.fold.discrepancies = append(run.fold.discrepancies, stmtDuplicates.finish()...)
+	return nil
+}
+
+// windowDay holds the statements of one day in the join window
+type windowDay struct {
+	day     int64
+	seqs    []int64
+	amounts []decimal.Decimal
+}
+
+// sortByComponent sorts the records again, by component, and removes the
+// day-sorted chunks
+func (run *outOfCoreRun) sortByComponent() (*spillSorter, error) {
+	components := newSpillSorter(run.transactions.dir, run.transactions.chunkSize, byComponent)
+
+	for _, sorter := range []*spillSorter{run.transactions, run.statements} {
+		stream, err := sorter.merge()
+		if err != nil {
+			return nil, err
+		}
+		for count := 1; ; count++ {
+			record, err := stream.next()
+			if err != nil {
+				stream.close()
+				return nil, err
+			}
+			if record == nil {
+				break
+			}
+			record.Component = run.find(record.Seq)
+			if err := components.add(record); err != nil {
+				stream.close()
+				return nil, err
+			}
+			if count%memoryCheckInterval == 0 && run.monitor.Check() {
+				if err := components.spill(); err != nil {
+					stream.close()
+					return nil, err
+				}
+				runtime.GC()
+			}
+		}
+		stream.close()
+		sorter.remove()
+	}
+
+	run.parent = nil
+	return components, nil
+}
+
+// matchComponents reads the records component by component and matches them
+// in batches of at least BatchSize records. A batch is matched early when the
+// heap goes over the limit; a single component that does not fit is an error.
+func (run *outOfCoreRun) matchComponents(ctx context.Context, components *spillSorter) error {
+	defer components.remove()
+
+	stream, err := components.merge()
+	if err != nil {
+		return err
+	}
+	defer stream.close()
+
+	var batch, component []*spillRecord
+	for count := 1; ; count++ {
+		record, err := stream.next()
+		if err != nil {
+			return err
+		}
+
+		if record == nil || (len(component) > 0 && record.Component != component[0].Component) {
+			batch = append(batch, component...)
+			component = nil
+			if record == nil || len(batch) >= run.rs.config.BatchSize {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := run.matchBatch(batch); err != nil {
+					return err
+				}
+				batch = nil
+			}
+		}
+		if record == nil {
+			break
+		}
+		component = append(component, run.resolve(record))
+
+		if count%memoryCheckInterval == 0 && run.monitor.Check() {
+			if len(batch) > 0 {
+				if err := run.matchBatch(batch); err != nil {
+					return err
+				}
+				batch = nil
+			}
+			runtime.GC()
+			if run.monitor.Check() {
+				return fmt.Errorf("%d linked records do not fit in the memory limit of %d MB", len(component), run.rs.config.MemoryLimitMB)
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchBatch matches a batch of whole components in input order and folds
+// the outcome into the result
+func (run *outOfCoreRun) matchBatch(records []*spillRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+
+	var transactions []*models.Transaction
+	var statements []*models.BankStatement
+	seqs := &batchSeqs{
+		transactions: make(map[*models.Transaction]int64),
+		statements:   make(map[*models.BankStatement]int64),
+	}
+	for _, record := range records {
+		if record.Transaction != nil {
+			transactions = append(transactions, record.Transaction)
+			seqs.transactions[record.Transaction] = record.Seq
+		} else {
+			statements = append(statements, record.Statement)
+			seqs.statements[record.Statement] = record.Seq
+		}
+	}
+
+	engine := matcher.NewMatchingEngine(run.config)
+	matchingResult, err := engine.ReconcilePartition(transactions, statements)
+	if err != nil {
+		return fmt.Errorf("failed to match %d transactions and %d statements: %w", len(transactions), len(statements), err)
+	}
+
+	run.fold.add(matchingResult, seqs)
+	return nil
+}
+
+// batchSeqs numbers the items of a batch in input order
+type batchSeqs struct {
+	transactions map[*models.Transaction]int64
+	statements   map[*models.BankStatement]int64
+}
+
+// resultOrder is the position of an item in the in-memory result. Items are
+// ordered by section, then group, then input order, then index.
+type resultOrder struct {
+	section int
+	group   int
+	seq     int64
+	index   int
+}
+
+func (o resultOrder) before(other resultOrder) bool {
+	if o.section != other.section {
+		return o.section < other.section
+	}
+	if o.group != other.group {
+		return o.group < other.group
+	}
+	if o.seq != other.seq {
+		return o.seq < other.seq
+	}
+	return o.index < other.index
+}
+
+type orderedDiscrepancy struct {
+	order       resultOrder
+	discrepancy *Discrepancy
+}
+
+// duplicateFinder looks for duplicates in a stream of records in day order.
+// Records sharing a key are at most duplicateSpreadDays apart, so the records
+// of a key are reported, first one in input order first, once the stream has
+// moved past them.
+type duplicateFinder struct {
+	section int
+	key     func(*spillRecord) string
+	report  func(first, duplicate *spillRecord) *Discrepancy
+
+	day    int64
+	groups map[string]*duplicateGroup
+	found  []*orderedDiscrepancy
+}
+
+type duplicateGroup struct {
+	lastDay int64
+	records []*spillRecord
+}
+
+func newDuplicateFinder(section int, key func(*spillRecord) string, report func(first, duplicate *spillRecord) *Discrepancy) *duplicateFinder {
+	return &duplicateFinder{
+		section: section,
+		key:     key,
+		report:  report,
+		groups:  make(map[string]*duplicateGroup),
+	}
+}
+
+func (f *duplicateFinder) add(record *spillRecord) {
+	if record.Day != f.day {
+		f.day = record.Day
+		for key, group := range f.groups {
+			if group.lastDay < record.Day-duplicateSpreadDays {
+				f.flush(group)
+				delete(f.groups, key)
+			}
+		}
+	}
+
+	key := f.key(record)
+	group, exists := f.groups[key]
+	if !exists {
+		group = &duplicateGroup{}
+		f.groups[key] = group
+	}
+	group.lastDay = record.Day
+	group.records = append(group.records, record)
+}
+
+// finish reports the groups still open and returns every duplicate found
+func (f *duplicateFinder) finish() []*orderedDiscrepancy {
+	for key, group := range f.groups {
+		f.flush(group)
+		delete(f.groups, key)
+	}
+	return f.found
+}
+
+func (f *duplicateFinder) flush(group *duplicateGroup) {
+	if len(group.records) < 2 {
+		return
+	}
+	sort.Slice(group.records, func(i, j int) bool { return group.records[i].Seq < group.records[j].Seq })
+	first := group.records[0]
+	for _, duplicate := range group.records[1:] {
+		f.found = append(f.found, &orderedDiscrepancy{
+			order:       resultOrder{section: f.section, seq: duplicate.Seq},
+			discrepancy: f.report(first, duplicate),
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package reconciler
+
+import (
+	"sort"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Sections of the discrepancy list, in the order analyzeDiscrepancies and
+// analyzeGroupDiscrepancies produce them
+const (
+	sectionMatches = iota
+	sectionTransactionDuplicates
+	sectionStatementDuplicates
+	sectionGroups
+)
+
+// outOfCoreFold accumulates the results of the matched batches. Every item is
+// kept with its position in the in-memory result, so that the combined result
+// can be put back in the same order.
+type outOfCoreFold struct {
+	rs      *ReconciliationService
+	request *ReconciliationRequest
+
+	matches               []orderedItem[*matcher.MatchResult]
+	groups                []orderedItem[*matcher.GroupMatch]
+	unmatchedTransactions []orderedItem[*models.Transaction]
+	unmatchedStatements   []orderedItem[*models.BankStatement]
+	ignoredStatements     []orderedItem[*matcher.IgnoredStatement]
+	nearMisses            []orderedItem[*matcher.NearMisses]
+	pendingReview         []orderedItem[*matcher.PendingMatch]
+	discrepancies         []*orderedDiscrepancy
+
+	summary         matcher.ReconciliationSummary
+	totalTxAmount   decimal.Decimal
+	totalStmtAmount decimal.Decimal
+	carriedForward  int
+	carriedCleared  int
+
+	// Unmatched items by calendar date and the latest item date, which is
+	// all calculateAgeing needs
+	latest              time.Time
+	openTransactionDays map[time.Time]int
+	openStatementDays   map[time.Time]int
+}
+
+type orderedItem[T any] struct {
+	order resultOrder
+	item  T
+}
+
+func newOutOfCoreFold(rs *ReconciliationService, request *ReconciliationRequest) *outOfCoreFold {
+	return &outOfCoreFold{
+		rs:                  rs,
+		request:             request,
+		summary:             matcher.ReconciliationSummary{TotalAmountMatched: decimal.Zero, TotalAmountUnmatched: decimal.Zero},
+		totalTxAmount:       decimal.Zero,
+		totalStmtAmount:     decimal.Zero,
+		openTransactionDays: make(map[time.Time]int),
+		openStatementDays:   make(map[time.Time]int),
+	}
+}
+
+// add folds the result of one batch in
+func (f *outOfCoreFold) add(batch *matcher.ReconciliationResult, seqs *batchSeqs) {
+	rs := f.rs
+	detailed := rs.config.DetailedBreakdown
+
+	// Passes add their matches and groups in turn; without strategies there
+	// is a single one
+	matchPass := passIndex(batch.Passes, func(stats *matcher.PassStats) int { return stats.Matches })
+	groupPass := passIndex(batch.Passes, func(stats *matcher.PassStats) int { return stats.GroupMatches })
+	pendingPass := passIndex(batch.Passes, func(stats *matcher.PassStats) int { return stats.PendingReview })
+
+	for i, match := range batch.Matches {
+		order := resultOrder{section: sectionMatches, group: matchPass(i), seq: seqs.transactions[match.Transaction]}
+		if detailed {
+			f.matches = append(f.matches, orderedItem[*matcher.MatchResult]{order, match})
+		}
+		for j, discrepancy := range rs.analyzeDiscrepancies([]*matcher.MatchResult{match}, nil, nil) {
+			order.index = j
+			f.discrepancies = append(f.discrepancies, &orderedDiscrepancy{order: order, discrepancy: discrepancy})
+		}
+	}
+
+	for i, group := range batch.GroupMatches {
+		// Many-to-one groups are found in transaction order, one-to-many
+		// groups after them in statement order
+		order := resultOrder{section: sectionGroups, group: groupPass(i) * 2}
+		if group.GroupType == matcher.GroupOneToMany {
+			order.group++
+			order.seq = seqs.statements[group.Statements[0]]
+		} else {
+			order.seq = seqs.transactions[group.Transactions[0]]
+		}
+		if detailed {
+			f.groups = append(f.groups, orderedItem[*matcher.GroupMatch]{order, group})
+		}
+		for _, discrepancy := range rs.analyzeGroupDiscrepancies([]*matcher.GroupMatch{group}) {
+			f.discrepancies = append(f.discrepancies, &orderedDiscrepancy{order: order, discrepancy: discrepancy})
+		}
+	}
+
+	if detailed {
+		for _, tx := range batch.UnmatchedTransactions {
+			f.unmatchedTransactions = append(f.unmatchedTransactions, orderedItem[*models.Transaction]{resultOrder{seq: seqs.transactions[tx]}, tx})
+		}
+		for _, stmt := range batch.UnmatchedStatements {
+			f.unmatchedStatements = append(f.unmatchedStatements, orderedItem[*models.BankStatement]{resultOrder{seq: seqs.statements[stmt]}, stmt})
+		}
+		for _, ignored := range batch.IgnoredStatements {
+			f.ignoredStatements = append(f.ignoredStatements, orderedItem[*matcher.IgnoredStatement]{resultOrder{seq: seqs.statements[ignored.Statement]}, ignored})
+		}
+		// Near misses of transactions come before those of statements, and
+		// are only searched for within the batch
+		for _, nearMisses := range batch.NearMisses {
+			order := resultOrder{seq: seqs.transactions[nearMisses.Transaction]}
+			if nearMisses.Statement != nil {
+				order = resultOrder{section: 1, seq: seqs.statements[nearMisses.Statement]}
+			}
+			f.nearMisses = append(f.nearMisses, orderedItem[*matcher.NearMisses]{order, nearMisses})
+		}
+		for i, pending := range batch.PendingReview {
+			order := resultOrder{group: pendingPass(i), seq: seqs.transactions[pending.Match.Transaction]}
+			f.pendingReview = append(f.pendingReview, orderedItem[*matcher.PendingMatch]{order, pending})
+		}
+	}
+
+	f.addSummary(batch.Summary)
+
+	// The batch's financial totals and carried items, computed the way
+	// buildFinalResult computes them for the whole input
+	partial := &ReconciliationResult{Request: f.request, Summary: &ResultSummary{}}
+	rs.calculateFinancialSummary(partial, batch)
+	rs.summarizeCarriedItems(partial, batch)
+	f.totalTxAmount = f.totalTxAmount.Add(partial.Summary.TotalTransactionAmount)
+	f.totalStmtAmount = f.totalStmtAmount.Add(partial.Summary.TotalStatementAmount)
+	f.carriedForward += partial.Summary.CarriedForward
+	f.carriedCleared += partial.Summary.CarriedCleared
+
+	f.addAgeing(batch)
+}
+
+// passIndex returns a function giving the pass that added the i-th item of
+// a batch, with count giving the number of items each pass added
+func passIndex(passes []*matcher.PassStats, count func(*matcher.PassStats) int) func(i int) int {
+	return func(i int) int {
+		for pass, stats := range passes {
+			if i < count(stats) {
+				return pass
+			}
+			i -= count(stats)
+		}
+		return len(passes)
+	}
+}
+
+func (f *outOfCoreFold) addSummary(batch matcher.ReconciliationSummary) {
+	s := &f.summary
+	s.TotalTransactions += batch.TotalTransactions
+	s.TotalBankStatements += batch.TotalBankStatements
+	s.MatchedTransactions += batch.MatchedTransactions
+	s.MatchedStatements += batch.MatchedStatements
+	s.UnmatchedTransactions += batch.UnmatchedTransactions
+	s.UnmatchedStatements += batch.UnmatchedStatements
+	s.ExactMatches += batch.ExactMatches
+	s.CloseMatches += batch.CloseMatches
+	s.FuzzyMatches += batch.FuzzyMatches
+	s.PossibleMatches += batch.PossibleMatches
+	s.ManualMatches += batch.ManualMatches
+	s.IgnoredStatements += batch.IgnoredStatements
+	s.PendingReview += batch.PendingReview
+	s.ManyToOneMatches += batch.ManyToOneMatches
+	s.OneToManyMatches += batch.OneToManyMatches
+	s.TotalAmountMatched = s.TotalAmountMatched.Add(batch.TotalAmountMatched)
+	s.TotalAmountUnmatched = s.TotalAmountUnmatched.Add(batch.TotalAmountUnmatched)
+}
+
+func (f *outOfCoreFold) addAgeing(batch *matcher.ReconciliationResult) {
+	latest := func(date time.Time) {
+		if date.After(f.latest) {
+			f.latest = date
+		}
+	}
+	for _, match := range batch.Matches {
+		latest(match.Transaction.TransactionTime)
+		latest(match.BankStatement.Date)
+	}
+	for _, group := range batch.GroupMatches {
+		for _, tx := range group.Transactions {
+			latest(tx.TransactionTime)
+		}
+		for _, stmt := range group.Statements {
+			latest(stmt.Date)
+		}
+	}
+	for _, pending := range batch.PendingReview {
+		latest(pending.Match.Transaction.TransactionTime)
+		latest(pending.Match.BankStatement.Date)
+	}
+	for _, tx := range batch.UnmatchedTransactions {
+		latest(tx.TransactionTime)
+		f.openTransactionDays[calendarDay(tx.TransactionTime)]++
+	}
+	for _, stmt := range batch.UnmatchedStatements {
+		latest(stmt.Date)
+		f.openStatementDays[calendarDay(stmt.Date)]++
+	}
+}
+
+// calendarDay is the calendar date of a time in its own location, the way
+// ageInDays reads it
+func calendarDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// result returns the combined matching result and the discrepancies, both in
+// the order of the in-memory result
+func (f *outOfCoreFold) result() (*matcher.ReconciliationResult, []*Discrepancy) {
+	combined := &matcher.ReconciliationResult{
+		Matches:               sortedItems(f.matches),
+		GroupMatches:          sortedItems(f.groups),
+		UnmatchedTransactions: sortedItems(f.unmatchedTransactions),
+		UnmatchedStatements:   sortedItems(f.unmatchedStatements),
+		IgnoredStatements:     sortedItems(f.ignoredStatements),
+		NearMisses:            sortedItems(f.nearMisses),
+		PendingReview:         sortedItems(f.pendingReview),
+		Summary:               f.summary,
+	}
+
+	sort.SliceStable(f.discrepancies, func(i, j int) bool {
+		return f.discrepancies[i].order.before(f.discrepancies[j].order)
+	})
+	discrepancies := make([]*Discrepancy, len(f.discrepancies))
+	for i, ordered := range f.discrepancies {
+		discrepancies[i] = ordered.discrepancy
+	}
+	return combined, discrepancies
+}
+
+func sortedItems[T any](items []orderedItem[T]) []T {
+	if len(items) == 0 {
+		return nil
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].order.before(items[j].order) })
+	sorted := make([]T, len(items))
+	for i, ordered := range items {
+		sorted[i] = ordered.item
+	}
+	return sorted
+}
+
+// finishSummary sets the totals that buildFinalResult can only compute from
+// the detailed breakdown
+func (f *outOfCoreFold) finishSummary(summary *ResultSummary) {
+	summary.TotalTransactionAmount = f.totalTxAmount
+	summary.TotalStatementAmount = f.totalStmtAmount
+	summary.NetDiscrepancy = f.totalTxAmount.Sub(f.totalStmtAmount)
+	summary.CarriedForward = f.carriedForward
+	summary.CarriedCleared = f.carriedCleared
+
+	summary.Ageing = nil
+	asOf := f.latest
+	if f.request != nil && f.request.EndDate != nil {
+		asOf = *f.request.EndDate
+	}
+	if asOf.IsZero() {
+		return
+	}
+
+	ageing := &AgeingSummary{AsOf: asOf}
+	for day, count := range f.openTransactionDays {
+		for i := 0; i < count; i++ {
+			ageing.Transactions.add(ageInDays(day, asOf))
+		}
+	}
+	for day, count := range f.openStatementDays {
+		for i := 0; i < count; i++ {
+			ageing.Statements.add(ageInDays(day, asOf))
+		}
+	}
+	summary.Ageing = ageing
+}
@@ -0,0 +1,336 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/parsers"
+
+	"github.com/shopspring/decimal"
+)
+
+// writeOutOfCoreData writes a system file and a bank file with clusters of
+// equal amounts, shifted dates, split settlements, duplicates and items
+// missing on either side
+func writeOutOfCoreData(t *testing.T, dir string) (string, string) {
+	random := rand.New(rand.NewSource(7))
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	amounts := []string{"100.00", "250.00", "75.50", "19.99", "1200.00"}
+
+	var system, bank strings.Builder
+	system.WriteString("trxID,amount,type,transactionTime\n")
+	bank.WriteString("unique_identifier,amount,date\n")
+
+	statement := 0
+	writeStatement := func(amount decimal.Decimal, date time.Time) {
+		statement++
+		fmt.Fprintf(&bank, "BS%04d,%s,%s\n", statement, amount.StringFixed(2), date.Format("2006-01-02"))
+	}
+
+	for i := 1; i <= 400; i++ {
+		// Gaps between some clusters of days keep the components apart
+		day := i / 8
+		if day%5 == 4 {
+			day += 3
+		}
+		date := start.AddDate(0, 0, day).Add(time.Duration(random.Intn(20)) * time.Hour)
+		amount := decimal.RequireFromString(amounts[random.Intn(len(amounts))])
+		txType := models.TransactionTypeCredit
+		signed := amount
+		if random.Intn(3) == 0 {
+			txType = models.TransactionTypeDebit
+			signed = amount.Neg()
+		}
+		fmt.Fprintf(&system, "TX%04d,%s,%s,%s\n", i, amount.StringFixed(2), txType, date.Format(time.RFC3339))
+
+		posted := date.AddDate(0, 0, random.Intn(3))
+		switch random.Intn(10) {
+		case 0:
+			// Missing at the bank
+		case 1:
+			// Settled in two parts
+			part := signed.Div(decimal.NewFromInt(2)).Round(2)
+			writeStatement(part, posted)
+			writeStatement(signed.Sub(part), posted)
+		case 2:
+			// Small fee taken by the bank
+			writeStatement(signed.Sub(decimal.RequireFromString("0.05")), posted)
+		default:
+			writeStatement(signed, posted)
+		}
+		if random.Intn(25) == 0 {
+			// Only at the bank
+			writeStatement(decimal.NewFromInt(int64(random.Intn(500)+1)), posted)
+		}
+	}
+
+	systemFile := filepath.Join(dir, "transactions.csv")
+	bankFile := filepath.Join(dir, "bank.csv")
+	if err := os.WriteFile(systemFile, []byte(system.String()), 0644); err != nil {
+		t.Fatalf("Failed to write system file: %v", err)
+	}
+	if err := os.WriteFile(bankFile, []byte(bank.String()), 0644); err != nil {
+		t.Fatalf("Failed to write bank file: %v", err)
+	}
+	return systemFile, bankFile
+}
+
+// describeResult renders the parts of a result both modes must agree on, in
+// order
+func describeResult(result *ReconciliationResult) []string {
+	s := result.Summary
+	lines := []string{fmt.Sprintf("summary tx=%d/%d/%d stmt=%d/%d/%d exact=%d close=%d fuzzy=%d possible=%d groups=%d/%d pending=%d amounts=%s/%s/%s carried=%d/%d",
+		s.TotalTransactions, s.MatchedTransactions, s.UnmatchedTransactions,
+		s.TotalBankStatements, s.MatchedStatements, s.UnmatchedStatements,
+		s.ExactMatches, s.CloseMatches, s.FuzzyMatches, s.PossibleMatches,
+		s.ManyToOneMatches, s.OneToManyMatches, s.PendingReview,
+		s.TotalTransactionAmount, s.TotalStatementAmount, s.NetDiscrepancy,
+		s.CarriedForward, s.CarriedCleared)}
+	if s.Ageing != nil {
+		lines = append(lines, fmt.Sprintf("ageing %s %+v %+v", s.Ageing.AsOf.Format(time.RFC3339), s.Ageing.Transactions, s.Ageing.Statements))
+	}
+	for _, match := range result.MatchedTransactions {
+		lines = append(lines, fmt.Sprintf("match %s %s %s %.6f", match.Transaction.TrxID, match.BankStatement.UniqueIdentifier, match.MatchType, match.ConfidenceScore))
+	}
+	for _, group := range result.GroupMatches {
+		var ids []string
+		for _, tx := range group.Transactions {
+			ids = append(ids, tx.TrxID)
+		}
+		for _, stmt := range group.Statements {
+			ids = append(ids, stmt.UniqueIdentifier)
+		}
+		lines = append(lines, fmt.Sprintf("group %s %s", group.GroupType, strings.Join(ids, ",")))
+	}
+	for _, pending := range result.PendingReview {
+		lines = append(lines, fmt.Sprintf("pending %s %s %s", pending.Match.Transaction.TrxID, pending.Match.BankStatement.UniqueIdentifier, pending.Reason))
+	}
+	for _, tx := range result.UnmatchedTransactions {
+		lines = append(lines, "unmatched "+tx.TrxID)
+	}
+	for _, stmt := range result.UnmatchedStatements {
+		lines = append(lines, "unmatched "+stmt.UniqueIdentifier)
+	}
+	for _, discrepancy := range result.Discrepancies {
+		lines = append(lines, fmt.Sprintf("discrepancy %s %s", discrepancy.Type, discrepancy.Description))
+	}
+	return lines
+}
+
+func TestReconciliationService_OutOfCore(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemFile, bankFile := writeOutOfCoreData(t, tmpDir)
+
+	carriedTx := models.NewTransaction("TX0000", decimal.NewFromInt(100), models.TransactionTypeCredit,
+		time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))
+	carriedStmt := models.NewBankStatement("BS0000", decimal.NewFromInt(-15), time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC))
+	carriedStmt.BankName = parsers.StandardBankConfig.Name
+	startDate := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 4, 30, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		configure func(*matcher.MatchingConfig)
+		request   func(*ReconciliationRequest)
+	}{
+		{"greedy", func(*matcher.MatchingConfig) {}, nil},
+		{"optimal", func(c *matcher.MatchingConfig) { c.AssignmentMode = matcher.AssignmentOptimal }, nil},
+		{"strategies", func(c *matcher.MatchingConfig) { c.Strategies = matcher.DefaultStrategies }, nil},
+		{"groups", func(c *matcher.MatchingConfig) {
+			c.DateToleranceDays = 2
+			c.EnablePartialMatching = true
+			c.EnableOneToManyMatching = true
+		}, nil},
+		{"review queue", func(c *matcher.MatchingConfig) {
+			c.Strategies = matcher.DefaultStrategies
+			c.AutoAcceptScore = 0.95
+		}, nil},
+		{"date range and carried items", func(c *matcher.MatchingConfig) { c.DateToleranceDays = 3 }, func(r *ReconciliationRequest) {
+			r.StartDate, r.EndDate = &startDate, &endDate
+			r.CarriedTransactions = []*models.Transaction{carriedTx}
+			r.CarriedStatements = []*models.BankStatement{carriedStmt}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := func(outOfCore bool) *ReconciliationResult {
+				matchingConfig := matcher.DefaultMatchingConfig()
+				matchingConfig.DateToleranceDays = 2
+				tt.configure(matchingConfig)
+
+				config := DefaultConfig()
+				if outOfCore {
+					config.OutOfCore = true
+					config.SpillChunkSize = 37
+					config.BatchSize = 25
+					config.SpillDir = tmpDir
+				}
+
+				txConfig := parsers.DefaultTransactionParserConfig()
+				service, err := NewReconciliationService(txConfig, parsers.StandardBankConfig, matchingConfig, config)
+				if err != nil {
+					t.Fatalf("Failed to create reconciliation service: %v", err)
+				}
+				request := &ReconciliationRequest{
+					SystemFile:        systemFile,
+					BankFiles:         []string{bankFile},
+					TransactionConfig: txConfig,
+					BankConfigs:       map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+				}
+				if tt.request != nil {
+					tt.request(request)
+				}
+
+				result, err := service.ProcessReconciliation(context.Background(), request)
+				if err != nil {
+					t.Fatalf("Reconciliation failed (out of core: %v): %v", outOfCore, err)
+				}
+				return result
+			}
+
+			expected := describeResult(run(false))
+			actual := describeResult(run(true))
+			if !reflect.DeepEqual(expected, actual) {
+				for i := 0; i < len(expected) || i < len(actual); i++ {
+					var e, a string
+					if i < len(expected) {
+						e = expected[i]
+					}
+					if i < len(actual) {
+						a = actual[i]
+					}
+					if e != a {
+						t.Fatalf("Results differ at line %d of %d/%d:\n  in memory:   %s\n  out of core: %s", i, len(expected), len(actual), e, a)
+					}
+				}
+			}
+
+			// Spill files are removed
+			entries, err := os.ReadDir(tmpDir)
+			if err != nil {
+				t.Fatalf("Failed to read spill directory: %v", err)
+			}
+			if len(entries) != 2 {
+				t.Errorf("Expected only the input files to be left, got %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestSpillSorter_BoundedFanIn(t *testing.T) {
+	dir := t.TempDir()
+	sorter := newSpillSorter(dir, 3, byDayAndAmount)
+	sorter.fanIn = 4
+	defer sorter.remove()
+
+	random := rand.New(rand.NewSource(11))
+	const records = 100
+	for seq := int64(0); seq < records; seq++ {
+		record := &spillRecord{Seq: seq, Day: random.Int63n(10), Amount: decimal.NewFromInt(random.Int63n(5))}
+		if err := sorter.add(record); err != nil {
+			t.Fatalf("Failed to add record: %v", err)
+		}
+	}
+
+	// The stream can be read more than once
+	for pass := 0; pass < 2; pass++ {
+		merger, err := sorter.merge()
+		if err != nil {
+			t.Fatalf("Failed to merge: %v", err)
+		}
+		if len(merger.readers) > sorter.fanIn {
+			t.Errorf("Pass %d: merging %d chunks at once, want at most %d", pass, len(merger.readers), sorter.fanIn)
+		}
+
+		var previous *spillRecord
+		seen := make(map[int64]bool)
+		for {
+			record, err := merger.next()
+			if err != nil {
+				t.Fatalf("Failed to read record: %v", err)
+			}
+			if record == nil {
+				break
+			}
+			if previous != nil && byDayAndAmount(record, previous) {
+				t.Fatalf("Pass %d: record %d read after record %d out of order", pass, record.Seq, previous.Seq)
+			}
+			previous = record
+			seen[record.Seq] = true
+		}
+		merger.close()
+		if len(seen) != records {
+			t.Errorf("Pass %d: read %d records, want %d", pass, len(seen), records)
+		}
+	}
+
+	// Merged chunks are deleted as they are compacted
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read spill directory: %v", err)
+	}
+	if len(entries) != len(sorter.chunks) || len(entries) > sorter.fanIn {
+		t.Errorf("Expected %d chunk files left, got %d", len(sorter.chunks), len(entries))
+	}
+}
+
+func TestReconciliationService_OutOfCoreRequiresDateWindow(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemFile, bankFile := writeOutOfCoreData(t, tmpDir)
+
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.Weights = matchingConfig.Weights.WithIdentifierWeight(0.3)
+	config := DefaultConfig()
+	config.OutOfCore = true
+
+	txConfig := parsers.DefaultTransactionParserConfig()
+	service, err := NewReconciliationService(txConfig, parsers.StandardBankConfig, matchingConfig, config)
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	_, err = service.ProcessReconciliation(context.Background(), &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+	})
+	if err == nil || !strings.Contains(err.Error(), "bounded by date") {
+		t.Errorf("Expected an error about date-bounded matching, got %v", err)
+	}
+}
+
+func TestReconciliationService_OutOfCoreRejectsAmbiguityReview(t *testing.T) {
+	tmpDir := t.TempDir()
+	systemFile, bankFile := writeOutOfCoreData(t, tmpDir)
+
+	// Same-day ambiguity is scored over a whole day, which may span batches
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.AmbiguityReviewScore = 0.5
+	config := DefaultConfig()
+	config.OutOfCore = true
+
+	txConfig := parsers.DefaultTransactionParserConfig()
+	service, err := NewReconciliationService(txConfig, parsers.StandardBankConfig, matchingConfig, config)
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	_, err = service.ProcessReconciliation(context.Background(), &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+	})
+	if err == nil || !strings.Contains(err.Error(), "every item of a day") {
+		t.Errorf("Expected an error about same-day ambiguity review, got %v", err)
+	}
+}
@@ -163,6 +163,8 @@ func (dp *DataPreprocessor) preprocessTransaction(tx *models.Transaction) (*mode
 		Amount:          tx.Amount,
 		Type:            tx.Type,
 		TransactionTime: tx.TransactionTime,
+		Currency:        tx.Currency,
+		Description:     tx.Description,
 	}
 	
 	// Normalize amount
@@ -196,6 +198,11 @@ func (dp *DataPreprocessor) preprocessBankStatement(stmt *models.BankStatement)
 		UniqueIdentifier: dp.normalizeString(stmt.UniqueIdentifier),
 		Amount:          stmt.Amount,
 		Date:            stmt.Date,
+		Currency:        stmt.Currency,
+		Description:     stmt.Description,
+		SourceFile:      stmt.SourceFile,
+		BankName:        stmt.BankName,
+		SourceLine:      stmt.SourceLine,
 	}
 	
 	// Normalize amount
@@ -337,6 +344,8 @@ func (dp *DataPreprocessor) fixTransactionErrors(tx *models.Transaction, err err
 		Amount:          tx.Amount,
 		Type:            tx.Type,
 		TransactionTime: tx.TransactionTime,
+		Currency:        tx.Currency,
+		Description:     tx.Description,
 	}
 	
 	errStr := err.Error()
@@ -370,6 +379,11 @@ func (dp *DataPreprocessor) fixStatementErrors(stmt *models.BankStatement, err e
 		UniqueIdentifier: stmt.UniqueIdentifier,
 		Amount:          stmt.Amount,
 		Date:            stmt.Date,
+		Currency:        stmt.Currency,
+		Description:     stmt.Description,
+		SourceFile:      stmt.SourceFile,
+		BankName:        stmt.BankName,
+		SourceLine:      stmt.SourceLine,
 	}
 	
 	errStr := err.Error()
@@ -38,6 +38,15 @@ type Config struct {
 	// Output options
 	IncludeStatistics   bool
 	DetailedBreakdown   bool
+	
+	// Out-of-core options. With OutOfCore set the inputs are streamed through
+	// sorted spill files in SpillDir (the system temp directory when empty),
+	// SpillChunkSize records per file, and matched piece by piece while the
+	// heap stays under MemoryLimitMB; see processOutOfCore.
+	OutOfCore           bool
+	MemoryLimitMB       int
+	SpillChunkSize      int
+	SpillDir            string
 }
 
 // DefaultConfig returns a default configuration for the reconciliation service
@@ -50,6 +59,8 @@ func DefaultConfig() *Config {
 		StrictDateMatching: false,
 		IncludeStatistics:  true,
 		DetailedBreakdown:  true,
+		MemoryLimitMB:      512,
+		SpillChunkSize:     100000,
 	}
 }
 
@@ -67,6 +78,15 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("start date must be before end date")
 	}
 	
+	if c.OutOfCore {
+		if c.MemoryLimitMB <= 0 {
+			return fmt.Errorf("memory limit must be positive in out-of-core mode, got %d MB", c.MemoryLimitMB)
+		}
+		if c.SpillChunkSize <= 0 {
+			return fmt.Errorf("spill chunk size must be positive in out-of-core mode, got %d", c.SpillChunkSize)
+		}
+	}
+	
 	return nil
 }
 
@@ -78,6 +98,11 @@ type ReconciliationRequest struct {
 	EndDate           *time.Time
 	TransactionConfig *parsers.TransactionParserConfig
 	BankConfigs       map[string]*parsers.BankConfig // File path -> config mapping
+	
+	// Open items carried forward from a previous run. They join the candidate
+	// pools regardless of StartDate and EndDate.
+	CarriedTransactions []*models.Transaction   `json:"-"`
+	CarriedStatements   []*models.BankStatement `json:"-"`
 }
 
 // Validate validates the reconciliation request
@@ -115,8 +140,12 @@ type ReconciliationResult struct {
 	
 	// Detailed results
 	MatchedTransactions   []*matcher.MatchResult           `json:"matched_transactions,omitempty"`
+	GroupMatches          []*matcher.GroupMatch            `json:"group_matches,omitempty"`
 	UnmatchedTransactions []*models.Transaction            `json:"unmatched_transactions,omitempty"`
 	UnmatchedStatements   []*models.BankStatement          `json:"unmatched_statements,omitempty"`
+	IgnoredStatements     []*matcher.IgnoredStatement      `json:"ignored_statements,omitempty"`
+	NearMisses            []*matcher.NearMisses            `json:"near_misses,omitempty"`
+	PendingReview         []*matcher.PendingMatch          `json:"pending_review,omitempty"`
 	
 	// Processing information
 	ProcessingStats       *ProcessingStats                 `json:"processing_stats,omitempty"`
@@ -146,12 +175,31 @@ type ResultSummary struct {
 	CloseMatches    int `json:"close_matches"`
 	FuzzyMatches    int `json:"fuzzy_matches"`
 	PossibleMatches int `json:"possible_matches"`
+	ManualMatches   int `json:"manual_matches"`
+	
+	// Bank statements left out of matching by ignore rules
+	IgnoredStatements int `json:"ignored_statements,omitempty"`
 	
-	// Financial summary
+	// Matches held for review, counted neither as matched nor as unmatched
+	PendingReview int `json:"pending_review,omitempty"`
+	
+	// Grouped matches
+	ManyToOneMatches int `json:"many_to_one_matches"`
+	OneToManyMatches int `json:"one_to_many_matches"`
+	
+	// Financial summary, in AmountCurrency when a base currency is set
+	AmountCurrency         string          `json:"amount_currency,omitempty"`
 	TotalTransactionAmount decimal.Decimal `json:"total_transaction_amount"`
 	TotalStatementAmount   decimal.Decimal `json:"total_statement_amount"`
 	NetDiscrepancy         decimal.Decimal `json:"net_discrepancy"`
 	
+	// Open items carried forward from a previous run and how many were cleared
+	CarriedForward int `json:"carried_forward,omitempty"`
+	CarriedCleared int `json:"carried_cleared,omitempty"`
+	
+	// Ageing of the items left unmatched
+	Ageing *AgeingSummary `json:"ageing,omitempty"`
+	
 	// Processing metadata
 	ProcessingDuration time.Duration `json:"processing_duration"`
 	DateRange          *DateRange    `json:"date_range,omitempty"`
@@ -195,6 +243,7 @@ const (
 	DiscrepancyDuplicateStatement   DiscrepancyType = "duplicate_statement"
 	DiscrepancyMissingTransaction   DiscrepancyType = "missing_transaction"
 	DiscrepancyMissingStatement     DiscrepancyType = "missing_statement"
+	DiscrepancyFXDifference         DiscrepancyType = "fx_difference"
 )
 
 // Severity represents the severity level of a discrepancy
@@ -279,6 +328,15 @@ func (rs *ReconciliationService) ProcessReconciliation(
 		}
 	}
 	
+	if rs.config.OutOfCore {
+		if err := rs.processOutOfCore(ctx, request, result); err != nil {
+			return nil, fmt.Errorf("out-of-core reconciliation failed: %w", err)
+		}
+		result.Summary.ProcessingDuration = time.Since(startTime)
+		result.ProcessingStats.TotalProcessingTime = result.Summary.ProcessingDuration
+		return result, nil
+	}
+	
 	// Step 1: Parse system transactions
 	transactions, parseStats, err := rs.parseSystemTransactions(ctx, request)
 	if err != nil {
@@ -291,8 +349,9 @@ func (rs *ReconciliationService) ProcessReconciliation(
 		return nil, fmt.Errorf("failed to parse bank statements: %w", err)
 	}
 	
-	// Step 3: Apply date range filtering
+	// Step 3: Apply date range filtering, then add open items carried forward
 	transactions, statements = rs.applyDateRangeFiltering(transactions, statements, request)
+	transactions, statements = rs.addCarriedItems(transactions, statements, request)
 	
 	// Step 4: Perform reconciliation matching
 	matchingStartTime := time.Now()
@@ -304,6 +363,7 @@ func (rs *ReconciliationService) ProcessReconciliation(
 	
 	// Step 5: Analyze discrepancies
 	discrepancies := rs.analyzeDiscrepancies(reconciliationResult.Matches, transactions, statements)
+	discrepancies = append(discrepancies, rs.analyzeGroupDiscrepancies(reconciliationResult.GroupMatches)...)
 	
 	// Step 6: Build final result
 	rs.buildFinalResult(result, reconciliationResult, discrepancies, parseStats, bankParseStats, matchingDuration)
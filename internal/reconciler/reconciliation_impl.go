@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"golang-reconciliation-service/internal/fx"
 	"golang-reconciliation-service/internal/matcher"
 	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/parsers"
@@ -286,8 +287,21 @@ func (rs *ReconciliationService) analyzeDiscrepancies(
 	
 	// Analyze matches for discrepancies
 	for _, match := range matches {
-		// Check for amount differences in fuzzy matches
-		if match.MatchType == matcher.MatchFuzzy || match.MatchType == matcher.MatchPossible {
+		// Converted matches are compared in the base currency, so any remainder
+		// is reported as an FX difference rather than an amount difference
+		if match.FX != nil {
+			if !match.FX.Difference.IsZero() {
+				discrepancies = append(discrepancies, &Discrepancy{
+					Type:        DiscrepancyFXDifference,
+					Transaction: match.Transaction,
+					Statement:   match.BankStatement,
+					Description: rs.describeFXDifference(match),
+					Amount:      match.FX.Difference,
+					Severity:    rs.determineSeverity(match.ConfidenceScore),
+				})
+			}
+		} else if match.MatchType == matcher.MatchFuzzy || match.MatchType == matcher.MatchPossible {
+			// Check for amount differences in fuzzy matches
 			if !match.Transaction.Amount.Equal(match.BankStatement.NormalizeAmount()) {
 				discrepancy := &Discrepancy{
 					Type:        DiscrepancyAmountDifference,
@@ -343,26 +357,63 @@ func (rs *ReconciliationService) analyzeDiscrepancies(
 	return discrepancies
 }
 
+// describeFXDifference describes an FX difference with the original and
+// converted amounts of both sides
+func (rs *ReconciliationService) describeFXDifference(match *matcher.MatchResult) string {
+	describe := func(amount decimal.Decimal, conversion *fx.Conversion) string {
+		if conversion == nil {
+			return fmt.Sprintf("%s %s", match.FX.BaseCurrency, amount.Abs().StringFixed(2))
+		}
+		return fmt.Sprintf("%s %s (%s %s at %s)",
+			conversion.Currency, conversion.Amount.Abs().StringFixed(2),
+			conversion.TargetCurrency, conversion.ConvertedAmount.Abs().StringFixed(2),
+			conversion.Rate.String())
+	}
+	
+	return fmt.Sprintf("FX difference after conversion: transaction %s vs statement %s, difference %s %s",
+		describe(match.Transaction.Amount, match.FX.Transaction),
+		describe(match.BankStatement.Amount, match.FX.Statement),
+		match.FX.BaseCurrency, match.FX.Difference.StringFixed(2))
+}
+
+// analyzeGroupDiscrepancies reports grouped matches whose totals do not add up exactly
+func (rs *ReconciliationService) analyzeGroupDiscrepancies(groups []*matcher.GroupMatch) []*Discrepancy {
+	var discrepancies []*Discrepancy
+	
+	for _, group := range groups {
+		if group.AmountDifference.IsZero() {
+			continue
+		}
+		
+		discrepancy := &Discrepancy{
+			Type:        DiscrepancyAmountDifference,
+			Description: fmt.Sprintf("Grouped match (%s) totals differ: transactions %s vs statements %s",
+				group.GroupType, group.TransactionTotal.String(), group.StatementTotal.String()),
+			Amount:      group.AmountDifference,
+			Severity:    rs.determineSeverity(group.ConfidenceScore),
+		}
+		if len(group.Transactions) > 0 {
+			discrepancy.Transaction = group.Transactions[0]
+		}
+		if len(group.Statements) > 0 {
+			discrepancy.Statement = group.Statements[0]
+		}
+		discrepancies = append(discrepancies, discrepancy)
+	}
+	
+	return discrepancies
+}
+
 // findDuplicateTransactions identifies duplicate transactions
 func (rs *ReconciliationService) findDuplicateTransactions(transactions []*models.Transaction) []*Discrepancy {
 	var discrepancies []*Discrepancy
 	seen := make(map[string]*models.Transaction)
 	
 	for _, tx := range transactions {
-		// Create a key based on amount, type, and date (normalized to day)
-		key := fmt.Sprintf("%s_%s_%s", 
-			tx.Amount.String(), 
-			tx.Type, 
-			tx.TransactionTime.Format("2006-01-02"))
+		key := transactionDuplicateKey(tx)
 		
 		if existingTx, exists := seen[key]; exists {
-			discrepancy := &Discrepancy{
-				Type:        DiscrepancyDuplicateTransaction,
-				Transaction: tx,
-				Description: fmt.Sprintf("Duplicate transaction detected: %s and %s", existingTx.TrxID, tx.TrxID),
-				Severity:    SeverityHigh,
-			}
-			discrepancies = append(discrepancies, discrepancy)
+			discrepancies = append(discrepancies, duplicateTransaction(existingTx, tx))
 		} else {
 			seen[key] = tx
 		}
@@ -371,24 +422,35 @@ func (rs *ReconciliationService) findDuplicateTransactions(transactions []*model
 	return discrepancies
 }
 
+// transactionDuplicateKey is the key of a transaction based on amount, type,
+// and date (normalized to day)
+func transactionDuplicateKey(tx *models.Transaction) string {
+	return fmt.Sprintf("%s_%s_%s", 
+		tx.Amount.String(), 
+		tx.Type, 
+		tx.TransactionTime.Format("2006-01-02"))
+}
+
+// duplicateTransaction reports a transaction duplicating an earlier one
+func duplicateTransaction(existingTx, tx *models.Transaction) *Discrepancy {
+	return &Discrepancy{
+		Type:        DiscrepancyDuplicateTransaction,
+		Transaction: tx,
+		Description: fmt.Sprintf("Duplicate transaction detected: %s and %s", existingTx.TrxID, tx.TrxID),
+		Severity:    SeverityHigh,
+	}
+}
+
 // findDuplicateStatements identifies duplicate bank statements
 func (rs *ReconciliationService) findDuplicateStatements(statements []*models.BankStatement) []*Discrepancy {
 	var discrepancies []*Discrepancy
 	seen := make(map[string]*models.BankStatement)
 	
 	for _, stmt := range statements {
-		// Create a key based on amount and date
-		key := fmt.Sprintf("%s_%s", stmt.Amount.String(), stmt.Date.Format("2006-01-02"))
+		key := statementDuplicateKey(stmt)
 		
 		if existingStmt, exists := seen[key]; exists {
-			discrepancy := &Discrepancy{
-				Type:        DiscrepancyDuplicateStatement,
-				Statement:   stmt,
-				Description: fmt.Sprintf("Duplicate statement detected: %s and %s", 
-					existingStmt.UniqueIdentifier, stmt.UniqueIdentifier),
-				Severity:    SeverityHigh,
-			}
-			discrepancies = append(discrepancies, discrepancy)
+			discrepancies = append(discrepancies, duplicateStatement(existingStmt, stmt))
 		} else {
 			seen[key] = stmt
 		}
@@ -397,6 +459,22 @@ func (rs *ReconciliationService) findDuplicateStatements(statements []*models.Ba
 	return discrepancies
 }
 
+// statementDuplicateKey is the key of a bank statement based on amount and date
+func statementDuplicateKey(stmt *models.BankStatement) string {
+	return fmt.Sprintf("%s_%s", stmt.Amount.String(), stmt.Date.Format("2006-01-02"))
+}
+
+// duplicateStatement reports a bank statement duplicating an earlier one
+func duplicateStatement(existingStmt, stmt *models.BankStatement) *Discrepancy {
+	return &Discrepancy{
+		Type:        DiscrepancyDuplicateStatement,
+		Statement:   stmt,
+		Description: fmt.Sprintf("Duplicate statement detected: %s and %s", 
+			existingStmt.UniqueIdentifier, stmt.UniqueIdentifier),
+		Severity:    SeverityHigh,
+	}
+}
+
 // determineSeverity determines the severity of a discrepancy based on confidence score
 func (rs *ReconciliationService) determineSeverity(confidenceScore float64) Severity {
 	switch {
@@ -424,12 +502,16 @@ func (rs *ReconciliationService) buildFinalResult(
 	// Populate matched transactions
 	if rs.config.DetailedBreakdown {
 		result.MatchedTransactions = matchingResult.Matches
+		result.GroupMatches = matchingResult.GroupMatches
 	}
 	
 	// Populate unmatched transactions and statements
 	if rs.config.DetailedBreakdown {
 		result.UnmatchedTransactions = matchingResult.UnmatchedTransactions
 		result.UnmatchedStatements = matchingResult.UnmatchedStatements
+		result.IgnoredStatements = matchingResult.IgnoredStatements
+		result.NearMisses = matchingResult.NearMisses
+		result.PendingReview = matchingResult.PendingReview
 	}
 	
 	// Set discrepancies
@@ -447,22 +529,33 @@ func (rs *ReconciliationService) buildFinalResult(
 	result.Summary.CloseMatches = summary.CloseMatches
 	result.Summary.FuzzyMatches = summary.FuzzyMatches
 	result.Summary.PossibleMatches = summary.PossibleMatches
+	result.Summary.ManualMatches = summary.ManualMatches
+	result.Summary.IgnoredStatements = summary.IgnoredStatements
+	result.Summary.PendingReview = summary.PendingReview
+	result.Summary.ManyToOneMatches = summary.ManyToOneMatches
+	result.Summary.OneToManyMatches = summary.OneToManyMatches
 	
 	// Calculate financial summaries
 	rs.calculateFinancialSummary(result, matchingResult)
 	
+	// Track carried-forward items and age the open ones
+	rs.summarizeCarriedItems(result, matchingResult)
+	rs.calculateAgeing(result, matchingResult)
+	
 	// Build processing statistics
 	if rs.config.IncludeStatistics {
 		rs.buildProcessingStats(result, transactionStats, bankStats, matchingDuration)
 	}
 }
 
-// calculateFinancialSummary calculates financial summary information
+// calculateFinancialSummary calculates financial summary information. With a
+// base currency the totals are summed in it, as matching compares amounts.
 func (rs *ReconciliationService) calculateFinancialSummary(
 	result *ReconciliationResult,
 	matchingResult *matcher.ReconciliationResult,
 ) {
 	
+	config := rs.matchingEngine.Config
 	totalTxAmount := decimal.Zero
 	totalStmtAmount := decimal.Zero
 	
@@ -471,8 +564,14 @@ func (rs *ReconciliationService) calculateFinancialSummary(
 	for _, match := range matchingResult.Matches {
 		allTransactions = append(allTransactions, match.Transaction)
 	}
+	for _, group := range matchingResult.GroupMatches {
+		allTransactions = append(allTransactions, group.Transactions...)
+	}
+	for _, pending := range matchingResult.PendingReview {
+		allTransactions = append(allTransactions, pending.Match.Transaction)
+	}
 	for _, tx := range allTransactions {
-		totalTxAmount = totalTxAmount.Add(tx.GetAbsoluteAmount())
+		totalTxAmount = totalTxAmount.Add(config.BaseAmount(tx.Amount, tx.Currency, tx.TransactionTime).Abs())
 	}
 	
 	// Calculate total statement amount from all statements
@@ -480,10 +579,17 @@ func (rs *ReconciliationService) calculateFinancialSummary(
 	for _, match := range matchingResult.Matches {
 		allStatements = append(allStatements, match.BankStatement)
 	}
+	for _, group := range matchingResult.GroupMatches {
+		allStatements = append(allStatements, group.Statements...)
+	}
+	for _, pending := range matchingResult.PendingReview {
+		allStatements = append(allStatements, pending.Match.BankStatement)
+	}
 	for _, stmt := range allStatements {
-		totalStmtAmount = totalStmtAmount.Add(stmt.NormalizeAmount())
+		totalStmtAmount = totalStmtAmount.Add(config.BaseAmount(stmt.Amount, stmt.Currency, stmt.Date).Abs())
 	}
 	
+	result.Summary.AmountCurrency = fx.NormalizeCurrency(config.BaseCurrency)
 	result.Summary.TotalTransactionAmount = totalTxAmount
 	result.Summary.TotalStatementAmount = totalStmtAmount
 	result.Summary.NetDiscrepancy = totalTxAmount.Sub(totalStmtAmount)
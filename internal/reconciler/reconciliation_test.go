@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"golang-reconciliation-service/internal/fx"
 	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/parsers"
 
 	"github.com/shopspring/decimal"
@@ -632,6 +635,345 @@ BS003,-250.10,2024-01-16`
 	}
 }
 
+func TestReconciliationService_PartialMatching(t *testing.T) {
+	tmpDir := t.TempDir()
+	
+	systemFile := filepath.Join(tmpDir, "transactions.csv")
+	systemCSV := `trxID,amount,type,transactionTime
+TX001,1000.00,CREDIT,2024-01-15T10:00:00Z
+TX002,250.00,DEBIT,2024-01-16T14:20:00Z`
+	if err := os.WriteFile(systemFile, []byte(systemCSV), 0644); err != nil {
+		t.Fatalf("Failed to write system file: %v", err)
+	}
+	
+	bankFile := filepath.Join(tmpDir, "bank_statements.csv")
+	bankCSV := `unique_identifier,amount,date
+BS001,600.00,2024-01-15
+BS002,400.00,2024-01-15
+BS003,-250.00,2024-01-16`
+	if err := os.WriteFile(bankFile, []byte(bankCSV), 0644); err != nil {
+		t.Fatalf("Failed to write bank file: %v", err)
+	}
+	
+	txConfig, _ := createTestConfigs()
+	bankConfig := &parsers.BankConfig{
+		Name:             "TestBank",
+		HasHeader:        true,
+		Delimiter:        ',',
+		IdentifierColumn: "unique_identifier",
+		AmountColumn:     "amount",
+		DateColumn:       "date",
+	}
+	
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.EnablePartialMatching = true
+	
+	service, err := NewReconciliationService(txConfig, bankConfig, matchingConfig, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	
+	request := &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: bankConfig},
+	}
+	
+	result, err := service.ProcessReconciliation(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Reconciliation failed: %v", err)
+	}
+	
+	if len(result.GroupMatches) != 1 {
+		t.Fatalf("Expected 1 group match, got %d", len(result.GroupMatches))
+	}
+	
+	if result.Summary.ManyToOneMatches != 1 {
+		t.Errorf("Expected 1 many-to-one match in summary, got %d", result.Summary.ManyToOneMatches)
+	}
+	
+	if result.Summary.UnmatchedTransactions != 0 || result.Summary.UnmatchedStatements != 0 {
+		t.Errorf("Expected everything to be matched, got %d unmatched transactions and %d unmatched statements",
+			result.Summary.UnmatchedTransactions, result.Summary.UnmatchedStatements)
+	}
+	
+	if !result.Summary.NetDiscrepancy.IsZero() {
+		t.Errorf("Expected zero net discrepancy, got %s", result.Summary.NetDiscrepancy.String())
+	}
+}
+
+func TestReconciliationService_StatementSources(t *testing.T) {
+	systemFile, bankFiles, cleanup := createTestDataFiles(t)
+	defer cleanup()
+	
+	txConfig, bankConfigs := createTestConfigs()
+	
+	service, err := NewReconciliationService(
+		txConfig,
+		bankConfigs[filepath.Base(bankFiles[0])],
+		matcher.DefaultMatchingConfig(),
+		DefaultConfig(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	
+	fileConfigs := make(map[string]*parsers.BankConfig)
+	for _, file := range bankFiles {
+		fileConfigs[file] = bankConfigs[filepath.Base(file)]
+	}
+	
+	result, err := service.ProcessReconciliation(context.Background(), &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         bankFiles,
+		TransactionConfig: txConfig,
+		BankConfigs:       fileConfigs,
+	})
+	if err != nil {
+		t.Fatalf("Reconciliation failed: %v", err)
+	}
+	
+	statements := append([]*models.BankStatement{}, result.UnmatchedStatements...)
+	for _, match := range result.MatchedTransactions {
+		statements = append(statements, match.BankStatement)
+	}
+	if len(statements) != result.Summary.TotalBankStatements {
+		t.Fatalf("Expected %d statements in the result, got %d", result.Summary.TotalBankStatements, len(statements))
+	}
+	
+	for _, stmt := range statements {
+		config, ok := fileConfigs[stmt.SourceFile]
+		if !ok {
+			t.Errorf("Statement %s has unknown source file %q", stmt.UniqueIdentifier, stmt.SourceFile)
+			continue
+		}
+		if stmt.BankName != config.Name {
+			t.Errorf("Statement %s: expected bank %s, got %s", stmt.UniqueIdentifier, config.Name, stmt.BankName)
+		}
+		if stmt.SourceLine < 2 {
+			t.Errorf("Statement %s: expected a data line number, got %d", stmt.UniqueIdentifier, stmt.SourceLine)
+		}
+	}
+}
+
+func TestReconciliationService_FXDifference(t *testing.T) {
+	tmpDir := t.TempDir()
+	
+	systemFile := filepath.Join(tmpDir, "transactions.csv")
+	systemCSV := `trxID,amount,type,transactionTime
+TX001,110.00,CREDIT,2024-01-15T10:30:00Z
+TX002,55.00,CREDIT,2024-01-15T11:30:00Z`
+	if err := os.WriteFile(systemFile, []byte(systemCSV), 0644); err != nil {
+		t.Fatalf("Failed to write system file: %v", err)
+	}
+	
+	bankFile := filepath.Join(tmpDir, "bank_eur.csv")
+	bankCSV := `unique_identifier,amount,date,currency
+BS001,100.00,2024-01-15,EUR
+BS002,50.05,2024-01-15,EUR`
+	if err := os.WriteFile(bankFile, []byte(bankCSV), 0644); err != nil {
+		t.Fatalf("Failed to write bank file: %v", err)
+	}
+	
+	rates := fx.NewRateTable()
+	if err := rates.AddRate(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "EUR", "USD", decimal.NewFromFloat(1.10)); err != nil {
+		t.Fatalf("Failed to add rate: %v", err)
+	}
+	
+	txConfig := parsers.DefaultTransactionParserConfig()
+	txConfig.Currency = "USD"
+	bankConfig := &parsers.BankConfig{
+		Name:             "EUR Bank",
+		HasHeader:        true,
+		Delimiter:        ',',
+		IdentifierColumn: "unique_identifier",
+		AmountColumn:     "amount",
+		DateColumn:       "date",
+		CurrencyColumn:   "currency",
+	}
+	
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.AmountTolerancePercent = 1.0
+	matchingConfig.BaseCurrency = "USD"
+	matchingConfig.FXRates = rates
+	
+	service, err := NewReconciliationService(txConfig, bankConfig, matchingConfig, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	
+	result, err := service.ProcessReconciliation(context.Background(), &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: bankConfig},
+	})
+	if err != nil {
+		t.Fatalf("Reconciliation failed: %v", err)
+	}
+	
+	if result.Summary.MatchedTransactions != 2 {
+		t.Fatalf("Expected 2 matched transactions, got %d", result.Summary.MatchedTransactions)
+	}
+	
+	// Totals are summed in the base currency, not across raw amounts
+	summary := result.Summary
+	if summary.AmountCurrency != "USD" {
+		t.Errorf("Expected totals in USD, got %q", summary.AmountCurrency)
+	}
+	if !summary.TotalTransactionAmount.Equal(decimal.RequireFromString("165.00")) ||
+		!summary.TotalStatementAmount.Equal(decimal.RequireFromString("165.06")) ||
+		!summary.NetDiscrepancy.Equal(decimal.RequireFromString("-0.06")) {
+		t.Errorf("Expected totals 165.00/165.06/-0.06 USD, got %s/%s/%s",
+			summary.TotalTransactionAmount, summary.TotalStatementAmount, summary.NetDiscrepancy)
+	}
+	
+	var fxDiscrepancies []*Discrepancy
+	for _, discrepancy := range result.Discrepancies {
+		switch discrepancy.Type {
+		case DiscrepancyFXDifference:
+			fxDiscrepancies = append(fxDiscrepancies, discrepancy)
+		case DiscrepancyAmountDifference:
+			t.Errorf("Converted match reported as a plain amount difference: %s", discrepancy.Description)
+		}
+	}
+	
+	if len(fxDiscrepancies) != 1 {
+		t.Fatalf("Expected 1 FX difference, got %d", len(fxDiscrepancies))
+	}
+	
+	discrepancy := fxDiscrepancies[0]
+	if discrepancy.Statement.UniqueIdentifier != "BS002" {
+		t.Errorf("Expected FX difference on BS002, got %s", discrepancy.Statement.UniqueIdentifier)
+	}
+	if !discrepancy.Amount.Equal(decimal.RequireFromString("0.06")) {
+		t.Errorf("Expected FX difference of 0.06, got %s", discrepancy.Amount.String())
+	}
+	for _, part := range []string{"EUR 50.05", "USD 55.06", "USD 55.00"} {
+		if !strings.Contains(discrepancy.Description, part) {
+			t.Errorf("Expected description to contain %q, got %q", part, discrepancy.Description)
+		}
+	}
+}
+
+func TestReconciliationService_CarryForward(t *testing.T) {
+	tmpDir := t.TempDir()
+	
+	// TX001 was left open by January's run and is posted by the bank on Feb 1
+	systemFile := filepath.Join(tmpDir, "transactions.csv")
+	systemCSV := `trxID,amount,type,transactionTime
+TX001,100.00,CREDIT,2024-01-31T22:00:00Z
+TX002,40.00,CREDIT,2024-02-10T10:00:00Z
+TX003,25.00,CREDIT,2024-02-20T10:00:00Z`
+	if err := os.WriteFile(systemFile, []byte(systemCSV), 0644); err != nil {
+		t.Fatalf("Failed to write system file: %v", err)
+	}
+	
+	bankFile := filepath.Join(tmpDir, "bank.csv")
+	bankCSV := `unique_identifier,amount,date
+BS001,100.00,2024-02-01
+BS002,40.00,2024-02-10`
+	if err := os.WriteFile(bankFile, []byte(bankCSV), 0644); err != nil {
+		t.Fatalf("Failed to write bank file: %v", err)
+	}
+	
+	txConfig := parsers.DefaultTransactionParserConfig()
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.DateToleranceDays = 3
+	service, err := NewReconciliationService(txConfig, parsers.StandardBankConfig, matchingConfig, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+	
+	carriedTx := models.NewTransaction("TX001", decimal.NewFromInt(100), models.TransactionTypeCredit,
+		time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC))
+	carriedStmt := models.NewBankStatement("BS000", decimal.NewFromInt(-15), time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC))
+	carriedStmt.BankName = parsers.StandardBankConfig.Name
+	
+	startDate := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC)
+	request := &ReconciliationRequest{
+		SystemFile:          systemFile,
+		BankFiles:           []string{bankFile},
+		StartDate:           &startDate,
+		EndDate:             &endDate,
+		TransactionConfig:   txConfig,
+		BankConfigs:         map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+		CarriedTransactions: []*models.Transaction{carriedTx},
+		CarriedStatements:   []*models.BankStatement{carriedStmt},
+	}
+	
+	result, err := service.ProcessReconciliation(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Reconciliation failed: %v", err)
+	}
+	
+	// TX001 is outside the date range but joins the pool as a carried item,
+	// once, even though the system file also contains it
+	if result.Summary.TotalTransactions != 3 || result.Summary.MatchedTransactions != 2 {
+		t.Fatalf("Expected 2 of 3 transactions matched, got %d of %d",
+			result.Summary.MatchedTransactions, result.Summary.TotalTransactions)
+	}
+	cleared := false
+	for _, match := range result.MatchedTransactions {
+		if match.Transaction == carriedTx && match.BankStatement.UniqueIdentifier == "BS001" {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Errorf("Expected carried TX001 to match BS001")
+	}
+	if result.Summary.CarriedForward != 2 || result.Summary.CarriedCleared != 1 {
+		t.Errorf("Expected 1 of 2 carried items cleared, got %d of %d",
+			result.Summary.CarriedCleared, result.Summary.CarriedForward)
+	}
+	
+	ageing := result.Summary.Ageing
+	if ageing == nil {
+		t.Fatalf("Expected an ageing summary")
+	}
+	if !ageing.AsOf.Equal(endDate) {
+		t.Errorf("Expected ageing as of the end date, got %s", ageing.AsOf)
+	}
+	// TX003 is 9 days old at the end of the period, BS000 55 days
+	if (ageing.Transactions != AgeingBuckets{Days8To30: 1}) {
+		t.Errorf("Unexpected transaction ageing: %+v", ageing.Transactions)
+	}
+	if (ageing.Statements != AgeingBuckets{Over30: 1}) {
+		t.Errorf("Unexpected statement ageing: %+v", ageing.Statements)
+	}
+}
+
+func TestAgeInDays(t *testing.T) {
+	asOf := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	
+	tests := []struct {
+		date     time.Time
+		expected int
+		bucket   AgeingBuckets
+	}{
+		{time.Date(2024, 1, 31, 23, 0, 0, 0, time.UTC), 0, AgeingBuckets{Days0To7: 1}},
+		{time.Date(2024, 1, 24, 0, 0, 0, 0, time.UTC), 7, AgeingBuckets{Days0To7: 1}},
+		{time.Date(2024, 1, 23, 0, 0, 0, 0, time.UTC), 8, AgeingBuckets{Days8To30: 1}},
+		{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 30, AgeingBuckets{Days8To30: 1}},
+		{time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), 31, AgeingBuckets{Over30: 1}},
+		{time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC), 0, AgeingBuckets{Days0To7: 1}},
+	}
+	
+	for _, tt := range tests {
+		age := ageInDays(tt.date, asOf)
+		if age != tt.expected {
+			t.Errorf("%s: expected age %d, got %d", tt.date.Format("2006-01-02"), tt.expected, age)
+		}
+		var buckets AgeingBuckets
+		buckets.add(age)
+		if buckets != tt.bucket {
+			t.Errorf("%s: expected bucket %+v, got %+v", tt.date.Format("2006-01-02"), tt.bucket, buckets)
+		}
+	}
+}
+
 // Benchmark tests for performance validation
 func BenchmarkReconciliationService_SmallDataset(b *testing.B) {
 	// Setup
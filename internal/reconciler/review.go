@@ -0,0 +1,114 @@
+package reconciler
+
+import (
+	"fmt"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/review"
+)
+
+// ReviewOutcome reports what applying review decisions to a run did
+type ReviewOutcome struct {
+	Accepted []*matcher.PendingMatch `json:"accepted,omitempty"`
+	Rejected []*matcher.PendingMatch `json:"rejected,omitempty"`
+
+	// Remaining is the number of matches still pending review
+	Remaining int `json:"remaining"`
+
+	// Unknown lists the decisions on pairs that are not pending review, such
+	// as pairs decided in an earlier pass
+	Unknown []*review.Decision `json:"unknown,omitempty"`
+}
+
+// ApplyReviewDecisions finalises the matches held for review. An accepted
+// match joins the matched transactions and is analysed for discrepancies
+// like any other match; a rejected match leaves its transaction and statement
+// unmatched. Matches without a decision stay pending. The summary is updated
+// in place; its financial totals already include the pending items.
+func (rs *ReconciliationService) ApplyReviewDecisions(result *ReconciliationResult, decisions *review.Decisions) (*ReviewOutcome, error) {
+	if result.Summary == nil {
+		return nil, fmt.Errorf("result has no summary")
+	}
+	if result.Summary.PendingReview != len(result.PendingReview) {
+		return nil, fmt.Errorf("result lists %d of its %d pending matches; it needs the detailed breakdown",
+			len(result.PendingReview), result.Summary.PendingReview)
+	}
+
+	outcome := &ReviewOutcome{}
+	decided := make(map[*review.Decision]bool, decisions.Len())
+	var remaining []*matcher.PendingMatch
+	for _, pending := range result.PendingReview {
+		match := pending.Match
+		decision := decisions.For(match.Transaction.TrxID, match.BankStatement.UniqueIdentifier)
+		if decision == nil {
+			remaining = append(remaining, pending)
+			continue
+		}
+		decided[decision] = true
+
+		switch decision.Action {
+		case review.ActionAccept:
+			rs.acceptPending(result, pending, decision)
+			outcome.Accepted = append(outcome.Accepted, pending)
+		case review.ActionReject:
+			rs.rejectPending(result, pending)
+			outcome.Rejected = append(outcome.Rejected, pending)
+		}
+	}
+
+	for _, decision := range decisions.Decisions {
+		if !decided[decision] {
+			outcome.Unknown = append(outcome.Unknown, decision)
+		}
+	}
+
+	result.PendingReview = remaining
+	result.Summary.PendingReview = len(remaining)
+	outcome.Remaining = len(remaining)
+	return outcome, nil
+}
+
+func (rs *ReconciliationService) acceptPending(result *ReconciliationResult, pending *matcher.PendingMatch, decision *review.Decision) {
+	match := pending.Match
+	reason := "Accepted on review"
+	if decision.Reviewer != "" {
+		reason += " by " + decision.Reviewer
+	}
+	if decision.Note != "" {
+		reason += ": " + decision.Note
+	}
+	match.Reasons = append(match.Reasons, reason)
+
+	result.MatchedTransactions = append(result.MatchedTransactions, match)
+	result.Discrepancies = append(result.Discrepancies, rs.analyzeDiscrepancies([]*matcher.MatchResult{match}, nil, nil)...)
+
+	summary := result.Summary
+	summary.MatchedTransactions++
+	summary.MatchedStatements++
+	switch match.MatchType {
+	case matcher.MatchExact:
+		summary.ExactMatches++
+	case matcher.MatchClose:
+		summary.CloseMatches++
+	case matcher.MatchFuzzy:
+		summary.FuzzyMatches++
+	case matcher.MatchPossible:
+		summary.PossibleMatches++
+	case matcher.MatchManual:
+		summary.ManualMatches++
+	}
+}
+
+func (rs *ReconciliationService) rejectPending(result *ReconciliationResult, pending *matcher.PendingMatch) {
+	match := pending.Match
+	result.UnmatchedTransactions = append(result.UnmatchedTransactions, match.Transaction)
+	result.UnmatchedStatements = append(result.UnmatchedStatements, match.BankStatement)
+
+	summary := result.Summary
+	summary.UnmatchedTransactions++
+	summary.UnmatchedStatements++
+	if summary.Ageing != nil {
+		summary.Ageing.Transactions.add(ageInDays(match.Transaction.TransactionTime, summary.Ageing.AsOf))
+		summary.Ageing.Statements.add(ageInDays(match.BankStatement.Date, summary.Ageing.AsOf))
+	}
+}
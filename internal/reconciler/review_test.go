@@ -0,0 +1,108 @@
+package reconciler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/review"
+)
+
+func TestReconciliationService_ApplyReviewDecisions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// TX001 matches exactly, TX002 and TX003 settle a day late and score
+	// below the auto-accept score
+	systemFile := filepath.Join(tmpDir, "transactions.csv")
+	systemCSV := `trxID,amount,type,transactionTime
+TX001,100.00,CREDIT,2024-02-10T10:00:00Z
+TX002,40.00,CREDIT,2024-02-12T10:00:00Z
+TX003,25.00,CREDIT,2024-02-20T10:00:00Z`
+	if err := os.WriteFile(systemFile, []byte(systemCSV), 0644); err != nil {
+		t.Fatalf("Failed to write system file: %v", err)
+	}
+
+	bankFile := filepath.Join(tmpDir, "bank.csv")
+	bankCSV := `unique_identifier,amount,date
+BS001,100.00,2024-02-10
+BS002,40.00,2024-02-13
+BS003,25.00,2024-02-21`
+	if err := os.WriteFile(bankFile, []byte(bankCSV), 0644); err != nil {
+		t.Fatalf("Failed to write bank file: %v", err)
+	}
+
+	txConfig := parsers.DefaultTransactionParserConfig()
+	matchingConfig := matcher.DefaultMatchingConfig()
+	matchingConfig.DateToleranceDays = 3
+	matchingConfig.AutoAcceptScore = 0.95
+	service, err := NewReconciliationService(txConfig, parsers.StandardBankConfig, matchingConfig, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create reconciliation service: %v", err)
+	}
+
+	result, err := service.ProcessReconciliation(context.Background(), &ReconciliationRequest{
+		SystemFile:        systemFile,
+		BankFiles:         []string{bankFile},
+		TransactionConfig: txConfig,
+		BankConfigs:       map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+	})
+	if err != nil {
+		t.Fatalf("Reconciliation failed: %v", err)
+	}
+
+	// Pending items are neither matched nor unmatched, but count in the totals
+	summary := result.Summary
+	if summary.PendingReview != 2 || len(result.PendingReview) != 2 {
+		t.Fatalf("Expected 2 matches pending review, got %d (%d listed)", summary.PendingReview, len(result.PendingReview))
+	}
+	if summary.MatchedTransactions != 1 || summary.UnmatchedTransactions != 0 || summary.UnmatchedStatements != 0 {
+		t.Errorf("Expected 1 matched and nothing unmatched, got %d matched, %d and %d unmatched",
+			summary.MatchedTransactions, summary.UnmatchedTransactions, summary.UnmatchedStatements)
+	}
+	if !summary.TotalTransactionAmount.Equal(summary.TotalStatementAmount) {
+		t.Errorf("Expected totals to include pending items, got %s and %s", summary.TotalTransactionAmount, summary.TotalStatementAmount)
+	}
+
+	decisions, err := review.ReadCSV(strings.NewReader(`action,trx_id,statement_id,reviewer,note
+accept,TX002,BS002,alice,
+reject,TX003,BS003,alice,different customer
+accept,TX009,BS009,alice,
+`))
+	if err != nil {
+		t.Fatalf("Failed to read decisions: %v", err)
+	}
+
+	outcome, err := service.ApplyReviewDecisions(result, decisions)
+	if err != nil {
+		t.Fatalf("ApplyReviewDecisions failed: %v", err)
+	}
+	if len(outcome.Accepted) != 1 || len(outcome.Rejected) != 1 || outcome.Remaining != 0 {
+		t.Errorf("Expected 1 accepted, 1 rejected and none remaining, got %d, %d and %d",
+			len(outcome.Accepted), len(outcome.Rejected), outcome.Remaining)
+	}
+	if len(outcome.Unknown) != 1 || outcome.Unknown[0].TrxID != "TX009" {
+		t.Errorf("Expected the TX009 decision to be unknown, got %d unknown", len(outcome.Unknown))
+	}
+
+	if summary.PendingReview != 0 || len(result.PendingReview) != 0 {
+		t.Errorf("Expected nothing pending, got %d", summary.PendingReview)
+	}
+	if summary.MatchedTransactions != 2 || summary.MatchedStatements != 2 || len(result.MatchedTransactions) != 2 {
+		t.Errorf("Expected 2 matches, got %d/%d (%d listed)", summary.MatchedTransactions, summary.MatchedStatements, len(result.MatchedTransactions))
+	}
+	accepted := result.MatchedTransactions[1]
+	if accepted.Transaction.TrxID != "TX002" || accepted.Reasons[len(accepted.Reasons)-1] != "Accepted on review by alice" {
+		t.Errorf("Expected TX002 accepted by alice, got %s with %v", accepted.Transaction.TrxID, accepted.Reasons)
+	}
+	if summary.UnmatchedTransactions != 1 || summary.UnmatchedStatements != 1 ||
+		result.UnmatchedTransactions[0].TrxID != "TX003" || result.UnmatchedStatements[0].UniqueIdentifier != "BS003" {
+		t.Errorf("Expected TX003 and BS003 unmatched, got %d and %d", summary.UnmatchedTransactions, summary.UnmatchedStatements)
+	}
+	if ageing := summary.Ageing; ageing == nil || (ageing.Transactions != AgeingBuckets{Days0To7: 1}) || (ageing.Statements != AgeingBuckets{Days0To7: 1}) {
+		t.Errorf("Expected the rejected items to be aged, got %+v", ageing)
+	}
+}
@@ -0,0 +1,275 @@
+package reconciler
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// spillRecord is a transaction or a bank statement on its way through the
+// external sort, together with the keys it is sorted by
+type spillRecord struct {
+	Seq         int64           // position in input order; transactions come first
+	Day         int64           // normalised day, see matcher.MatchingConfig.DayNumber
+	Amount      decimal.Decimal // signed like a bank statement: debits are negative
+	Component   int64           // linked component, set before the second sort
+	Transaction *models.Transaction
+	Statement   *models.BankStatement
+}
+
+// spillLess orders spill records
+type spillLess func(a, b *spillRecord) bool
+
+// byDayAndAmount orders records by day, then amount, then input order
+func byDayAndAmount(a, b *spillRecord) bool {
+	if a.Day != b.Day {
+		return a.Day < b.Day
+	}
+	if cmp := a.Amount.Cmp(b.Amount); cmp != 0 {
+		return cmp < 0
+	}
+	return a.Seq < b.Seq
+}
+
+// byComponent orders records by linked component, then input order
+func byComponent(a, b *spillRecord) bool {
+	if a.Component != b.Component {
+		return a.Component < b.Component
+	}
+	return a.Seq < b.Seq
+}
+
+// maxMergeFanIn is the number of chunk files merged at once. Beyond it the
+// chunks are first merged into fewer, larger ones, so a run spilling many
+// small chunks under memory pressure never runs out of file descriptors.
+const maxMergeFanIn = 64
+
+// spillSorter is an external sort: records are buffered, and every full
+// buffer is sorted and written to a chunk file. The chunks are read back as
+// one sorted stream by merging them.
+type spillSorter struct {
+	dir       string
+	chunkSize int
+	fanIn     int
+	less      spillLess
+	buffer    []*spillRecord
+	chunks    []string
+}
+
+func newSpillSorter(dir string, chunkSize int, less spillLess) *spillSorter {
+	return &spillSorter{dir: dir, chunkSize: chunkSize, fanIn: maxMergeFanIn, less: less}
+}
+
+// add buffers a record, spilling the buffer when it is full
+func (s *spillSorter) add(record *spillRecord) error {
+	s.buffer = append(s.buffer, record)
+	if len(s.buffer) >= s.chunkSize {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill sorts the buffered records and writes them to a new chunk file
+func (s *spillSorter) spill() error {
+	if len(s.buffer) == 0 {
+		return nil
+	}
+	sort.Slice(s.buffer, func(i, j int) bool { return s.less(s.buffer[i], s.buffer[j]) })
+
+	i := 0
+	err := s.writeChunk(func() (*spillRecord, error) {
+		if i == len(s.buffer) {
+			return nil, nil
+		}
+		i++
+		return s.buffer[i-1], nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.buffer = nil
+	return nil
+}
+
+// writeChunk writes the records returned by next, already in order, to a new
+// chunk file until next returns nil
+func (s *spillSorter) writeChunk(next func() (*spillRecord, error)) error {
+	file, err := os.CreateTemp(s.dir, "reconcile-*.chunk")
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %w", err)
+	}
+	s.chunks = append(s.chunks, file.Name())
+
+	writer := bufio.NewWriter(file)
+	encoder := gob.NewEncoder(writer)
+	for {
+		record, err := next()
+		if err != nil {
+			file.Close()
+			return err
+		}
+		if record == nil {
+			break
+		}
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write spill file %s: %w", file.Name(), err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write spill file %s: %w", file.Name(), err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close spill file %s: %w", file.Name(), err)
+	}
+	return nil
+}
+
+// merge spills what is left in the buffer and opens a sorted stream over all
+// chunks. It can be called again to read the records once more.
+func (s *spillSorter) merge() (*spillMerger, error) {
+	if err := s.spill(); err != nil {
+		return nil, err
+	}
+	for len(s.chunks) > s.fanIn {
+		if err := s.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return openMerger(s.chunks, s.less)
+}
+
+// compact merges the oldest chunks, up to the fan-in, into one new chunk and
+// deletes them
+func (s *spillSorter) compact() error {
+	merged := s.chunks[:s.fanIn]
+	merger, err := openMerger(merged, s.less)
+	if err != nil {
+		return err
+	}
+	s.chunks = append([]string(nil), s.chunks[s.fanIn:]...)
+	err = s.writeChunk(merger.next)
+	merger.close()
+	for _, name := range merged {
+		os.Remove(name)
+	}
+	return err
+}
+
+// openMerger opens a sorted stream over the given chunk files
+func openMerger(chunks []string, less spillLess) (*spillMerger, error) {
+	merger := &spillMerger{less: less}
+	for _, name := range chunks {
+		file, err := os.Open(name)
+		if err != nil {
+			merger.close()
+			return nil, fmt.Errorf("failed to open spill file: %w", err)
+		}
+		reader := &chunkReader{file: file, decoder: gob.NewDecoder(bufio.NewReader(file))}
+		merger.readers = append(merger.readers, reader)
+
+		if err := reader.advance(); err != nil {
+			merger.close()
+			return nil, err
+		}
+		if reader.head != nil {
+			merger.heads = append(merger.heads, reader)
+		}
+	}
+	heap.Init(merger)
+	return merger, nil
+}
+
+// remove deletes the chunk files
+func (s *spillSorter) remove() {
+	for _, name := range s.chunks {
+		os.Remove(name)
+	}
+	s.chunks = nil
+	s.buffer = nil
+}
+
+// chunkReader reads one chunk file, one record ahead
+type chunkReader struct {
+	file    *os.File
+	decoder *gob.Decoder
+	head    *spillRecord
+}
+
+func (r *chunkReader) advance() error {
+	record := &spillRecord{}
+	if err := r.decoder.Decode(record); err != nil {
+		r.head = nil
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("failed to read spill file %s: %w", r.file.Name(), err)
+	}
+	r.head = record
+	return nil
+}
+
+// spillMerger merges sorted chunks into one sorted stream. It is a heap of
+// the chunk readers ordered by their next record.
+type spillMerger struct {
+	less    spillLess
+	readers []*chunkReader
+	heads   []*chunkReader
+}
+
+func (m *spillMerger) Len() int           { return len(m.heads) }
+func (m *spillMerger) Less(i, j int) bool { return m.less(m.heads[i].head, m.heads[j].head) }
+func (m *spillMerger) Swap(i, j int)      { m.heads[i], m.heads[j] = m.heads[j], m.heads[i] }
+func (m *spillMerger) Push(x any)         { m.heads = append(m.heads, x.(*chunkReader)) }
+func (m *spillMerger) Pop() any {
+	last := m.heads[len(m.heads)-1]
+	m.heads = m.heads[:len(m.heads)-1]
+	return last
+}
+
+// peek returns the next record without consuming it, or nil at the end
+func (m *spillMerger) peek() *spillRecord {
+	if len(m.heads) == 0 {
+		return nil
+	}
+	return m.heads[0].head
+}
+
+// next returns the next record in order, or nil at the end
+func (m *spillMerger) next() (*spillRecord, error) {
+	if len(m.heads) == 0 {
+		return nil, nil
+	}
+
+	reader := m.heads[0]
+	record := reader.head
+	if err := reader.advance(); err != nil {
+		return nil, err
+	}
+	if reader.head == nil {
+		heap.Pop(m)
+	} else {
+		heap.Fix(m, 0)
+	}
+	return record, nil
+}
+
+// close closes the chunk files
+func (m *spillMerger) close() {
+	for _, reader := range m.readers {
+		reader.file.Close()
+	}
+	m.readers = nil
+	m.heads = nil
+}
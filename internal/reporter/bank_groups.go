@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"path/filepath"
+	"sort"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// unknownBankName labels statements that carry no bank name or source file
+const unknownBankName = "Unknown bank"
+
+// BankGroup collects the unmatched bank statements of a single bank together
+// with per-bank subtotals
+type BankGroup struct {
+	BankName    string                  `json:"bank_name"`
+	SourceFiles []string                `json:"source_files,omitempty"`
+	Count       int                     `json:"count"`
+	DebitTotal  decimal.Decimal         `json:"debit_total"`
+	CreditTotal decimal.Decimal         `json:"credit_total"`
+	NetTotal    decimal.Decimal         `json:"net_total"`
+	Statements  []*models.BankStatement `json:"statements"`
+}
+
+// GroupStatementsByBank groups statements by the bank they were parsed from.
+// Statements without a bank name fall back to their source file. Groups are
+// sorted by bank name; statements keep their input order within a group.
+func GroupStatementsByBank(statements []*models.BankStatement) []*BankGroup {
+	groups := make(map[string]*BankGroup)
+	seenFiles := make(map[string]map[string]bool)
+
+	for _, stmt := range statements {
+		name := bankLabel(stmt)
+
+		group, ok := groups[name]
+		if !ok {
+			group = &BankGroup{
+				BankName:    name,
+				DebitTotal:  decimal.Zero,
+				CreditTotal: decimal.Zero,
+				NetTotal:    decimal.Zero,
+			}
+			groups[name] = group
+			seenFiles[name] = make(map[string]bool)
+		}
+
+		if stmt.SourceFile != "" && !seenFiles[name][stmt.SourceFile] {
+			seenFiles[name][stmt.SourceFile] = true
+			group.SourceFiles = append(group.SourceFiles, stmt.SourceFile)
+		}
+
+		group.Count++
+		if stmt.IsDebit() {
+			group.DebitTotal = group.DebitTotal.Add(stmt.Amount.Abs())
+		} else {
+			group.CreditTotal = group.CreditTotal.Add(stmt.Amount.Abs())
+		}
+		group.NetTotal = group.NetTotal.Add(stmt.Amount)
+		group.Statements = append(group.Statements, stmt)
+	}
+
+	result := make([]*BankGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group.SourceFiles)
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].BankName < result[j].BankName
+	})
+
+	return result
+}
+
+// bankLabel returns the name used to group a statement by bank
+func bankLabel(stmt *models.BankStatement) string {
+	switch {
+	case stmt.BankName != "":
+		return stmt.BankName
+	case stmt.SourceFile != "":
+		return filepath.Base(stmt.SourceFile)
+	default:
+		return unknownBankName
+	}
+}
@@ -0,0 +1,108 @@
+package reporter
+
+import (
+	"encoding/csv"
+
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/reconciler"
+
+	"github.com/shopspring/decimal"
+)
+
+// fxHeaders are the CSV columns added after Notes when a match was compared
+// through an FX conversion. Original amounts are in their own currency,
+// converted amounts and the difference in the base currency.
+var fxHeaders = []string{
+	"FX_Base_Currency",
+	"FX_Transaction_Currency",
+	"FX_Transaction_Converted",
+	"FX_Statement_Currency",
+	"FX_Statement_Amount",
+	"FX_Statement_Converted",
+	"FX_Difference",
+}
+
+// FXConversionRecord is the JSON report entry for a match compared through
+// an FX conversion. A side without a conversion was already in the base
+// currency.
+type FXConversionRecord struct {
+	TransactionID         string          `json:"transaction_id"`
+	StatementID           string          `json:"statement_id"`
+	BaseCurrency          string          `json:"base_currency"`
+	TransactionConversion *fx.Conversion  `json:"transaction_conversion,omitempty"`
+	StatementConversion   *fx.Conversion  `json:"statement_conversion,omitempty"`
+	FXDifference          decimal.Decimal `json:"fx_difference"`
+}
+
+func fxConversionRecords(matches []*matcher.MatchResult) []*FXConversionRecord {
+	records := make([]*FXConversionRecord, 0, len(matches))
+	for _, match := range matches {
+		records = append(records, &FXConversionRecord{
+			TransactionID:         match.Transaction.TrxID,
+			StatementID:           match.BankStatement.UniqueIdentifier,
+			BaseCurrency:          match.FX.BaseCurrency,
+			TransactionConversion: match.FX.Transaction,
+			StatementConversion:   match.FX.Statement,
+			FXDifference:          match.FX.Difference,
+		})
+	}
+	return records
+}
+
+// hasConversions reports whether any match of the result, held for review or
+// not, was compared through an FX conversion
+func hasConversions(result *reconciler.ReconciliationResult) bool {
+	if len(convertedMatches(result.MatchedTransactions)) > 0 {
+		return true
+	}
+	for _, item := range result.PendingReview {
+		if item.Match.FX != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fxColumns returns the FX columns of a match row, empty when the match was
+// not converted
+func fxColumns(match *matcher.MatchResult) []string {
+	details := match.FX
+	if details == nil {
+		return nil
+	}
+
+	txCurrency, txConverted := details.BaseCurrency, match.Transaction.Amount
+	if details.Transaction != nil {
+		txCurrency, txConverted = details.Transaction.Currency, details.Transaction.ConvertedAmount
+	}
+	stmtCurrency, stmtConverted := details.BaseCurrency, match.BankStatement.Amount
+	if details.Statement != nil {
+		stmtCurrency, stmtConverted = details.Statement.Currency, details.Statement.ConvertedAmount
+	}
+
+	return []string{
+		details.BaseCurrency,
+		txCurrency,
+		txConverted.String(),
+		stmtCurrency,
+		match.BankStatement.Amount.String(),
+		stmtConverted.String(),
+		details.Difference.String(),
+	}
+}
+
+// reportCSVWriter writes CSV report rows padded to the width of the header,
+// so rows without FX details line up with those that have them
+type reportCSVWriter struct {
+	*csv.Writer
+	width int
+}
+
+// Write writes a row, padding it with empty columns up to the header width
+func (w *reportCSVWriter) Write(record []string) error {
+	for len(record) < w.width {
+		record = append(record, "")
+	}
+	return w.Writer.Write(record)
+}
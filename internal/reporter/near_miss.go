@@ -0,0 +1,150 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// nearMissLookup finds the near misses of unmatched items. Items are looked up
+// by ID rather than by pointer, so results read back from JSON work as well.
+type nearMissLookup struct {
+	transactions map[string][]*matcher.NearMiss
+	statements   map[string][]*matcher.NearMiss
+}
+
+func newNearMissLookup(nearMisses []*matcher.NearMisses) *nearMissLookup {
+	lookup := &nearMissLookup{
+		transactions: make(map[string][]*matcher.NearMiss),
+		statements:   make(map[string][]*matcher.NearMiss),
+	}
+	for _, item := range nearMisses {
+		switch {
+		case item.Transaction != nil:
+			lookup.transactions[item.Transaction.TrxID] = item.Candidates
+		case item.Statement != nil:
+			lookup.statements[statementKey(item.Statement)] = item.Candidates
+		}
+	}
+	return lookup
+}
+
+// statementKey tells apart statements sharing an identifier across files
+func statementKey(stmt *models.BankStatement) string {
+	return stmt.SourceFile + "\x00" + stmt.UniqueIdentifier + "\x00" + strconv.Itoa(stmt.SourceLine)
+}
+
+func (l *nearMissLookup) forTransaction(tx *models.Transaction) []*matcher.NearMiss {
+	if l == nil {
+		return nil
+	}
+	return l.transactions[tx.TrxID]
+}
+
+func (l *nearMissLookup) forStatement(stmt *models.BankStatement) []*matcher.NearMiss {
+	if l == nil {
+		return nil
+	}
+	return l.statements[statementKey(stmt)]
+}
+
+// filterNearMisses keeps the near misses of the unmatched items the report
+// includes
+func (rg *ReportGenerator) filterNearMisses(nearMisses []*matcher.NearMisses) []*matcher.NearMisses {
+	var filtered []*matcher.NearMisses
+	for _, item := range nearMisses {
+		if (item.Transaction != nil && rg.config.IncludeUnmatchedTransactions) ||
+			(item.Statement != nil && rg.config.IncludeUnmatchedStatements) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// describeNearMissReason renders what blocked a near miss, with the rule or
+// the item that stands in the way
+func describeNearMissReason(nearMiss *matcher.NearMiss) string {
+	switch {
+	case nearMiss.Rule != "":
+		return fmt.Sprintf("%s (rule %q)", nearMiss.Reason, nearMiss.Rule)
+	case nearMiss.MatchedTo != "":
+		return fmt.Sprintf("%s (to %s)", nearMiss.Reason, nearMiss.MatchedTo)
+	default:
+		return string(nearMiss.Reason)
+	}
+}
+
+// nearMissCounterpart describes the counterpart of a near miss, either a
+// statement or a transaction
+type nearMissCounterpart struct {
+	id     string
+	amount decimal.Decimal
+	txType models.TransactionType
+	date   string
+	source string
+}
+
+func counterpartOf(nearMiss *matcher.NearMiss) nearMissCounterpart {
+	if stmt := nearMiss.Statement; stmt != nil {
+		return nearMissCounterpart{stmt.UniqueIdentifier, stmt.Amount, stmt.GetTransactionType(), stmt.Date.Format("2006-01-02"), stmt.SourceFile}
+	}
+	tx := nearMiss.Transaction
+	return nearMissCounterpart{tx.TrxID, tx.Amount, tx.Type, tx.TransactionTime.Format("2006-01-02"), ""}
+}
+
+// printNearMisses prints the near misses of an unmatched item below it
+func (rg *ReportGenerator) printNearMisses(nearMisses []*matcher.NearMiss, writer io.Writer, indent string) {
+	for _, nearMiss := range nearMisses {
+		counterpart := counterpartOf(nearMiss)
+		fmt.Fprintf(writer, "%s     near miss: %s, Amount: %s (%s), Date: %s (%+dd), Score: %.2f, Blocked: %s\n",
+			indent,
+			counterpart.id,
+			counterpart.amount.StringFixed(2),
+			formatAmountDifference(nearMiss),
+			counterpart.date,
+			nearMiss.DateDifferenceDays,
+			nearMiss.ConfidenceScore,
+			describeNearMissReason(nearMiss))
+	}
+}
+
+// formatAmountDifference signs the amount difference of a near miss
+func formatAmountDifference(nearMiss *matcher.NearMiss) string {
+	if nearMiss.AmountDifference.IsNegative() {
+		return nearMiss.AmountDifference.StringFixed(2)
+	}
+	return "+" + nearMiss.AmountDifference.StringFixed(2)
+}
+
+// writeNearMissRecords writes one CSV row per near miss of an unmatched item,
+// following the row of the item
+func (rg *ReportGenerator) writeNearMissRecords(csvWriter *reportCSVWriter, itemID string, nearMisses []*matcher.NearMiss) error {
+	for _, nearMiss := range nearMisses {
+		counterpart := counterpartOf(nearMiss)
+		record := []string{
+			"Near Miss",
+			counterpart.id,
+			counterpart.amount.String(),
+			string(counterpart.txType),
+			counterpart.date,
+			string(nearMiss.Reason),
+			counterpart.source,
+			"",
+			fmt.Sprintf("%.2f", nearMiss.ConfidenceScore),
+			nearMiss.AmountDifference.String(),
+			(time.Duration(nearMiss.DateDifferenceDays) * 24 * time.Hour).String(),
+			fmt.Sprintf("Near miss for %s: %s", itemID, describeNearMissReason(nearMiss)),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write near miss record: %w", err)
+		}
+	}
+
+	return nil
+}
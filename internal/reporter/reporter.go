@@ -34,10 +34,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/matcher"
 	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/reconciler"
 
@@ -71,6 +74,7 @@ type ReportConfig struct {
 	
 	// Detail level options
 	IncludeMatchedTransactions   bool `json:"include_matched_transactions"`
+	IncludeGroupMatches          bool `json:"include_group_matches"`
 	IncludeUnmatchedTransactions bool `json:"include_unmatched_transactions"`
 	IncludeUnmatchedStatements   bool `json:"include_unmatched_statements"`
 	IncludeDiscrepancies         bool `json:"include_discrepancies"`
@@ -95,6 +99,7 @@ func DefaultReportConfig() *ReportConfig {
 	return &ReportConfig{
 		Format:                       FormatConsole,
 		IncludeMatchedTransactions:   false,
+		IncludeGroupMatches:          true,
 		IncludeUnmatchedTransactions: true,
 		IncludeUnmatchedStatements:   true,
 		IncludeDiscrepancies:         true,
@@ -182,17 +187,60 @@ func (rg *ReportGenerator) generateConsoleReport(result *reconciler.Reconciliati
 	rg.printMatchQualityTable(result.Summary, writer)
 	fmt.Fprintf(writer, "\n")
 	
-	// Unmatched transactions
+	// Open items: carry-forward and ageing
+	if result.Summary.CarriedForward > 0 || hasOpenItems(result.Summary.Ageing) {
+		fmt.Fprintf(writer, "=== OPEN ITEMS ===\n")
+		rg.printOpenItems(result.Summary, writer)
+		fmt.Fprintf(writer, "\n")
+	}
+	
+	// Pairs forced by match rules
+	if manual := manualMatches(result.MatchedTransactions); len(manual) > 0 {
+		fmt.Fprintf(writer, "=== MANUAL MATCHES ===\n")
+		rg.printManualMatches(manual, writer)
+		fmt.Fprintf(writer, "\n")
+	}
+	
+	// Grouped matches
+	if rg.config.IncludeGroupMatches && len(result.GroupMatches) > 0 {
+		fmt.Fprintf(writer, "=== GROUPED MATCHES ===\n")
+		rg.printGroupMatches(result.GroupMatches, writer)
+		fmt.Fprintf(writer, "\n")
+	}
+	
+	// Matches compared through an FX conversion
+	if converted := convertedMatches(result.MatchedTransactions); len(converted) > 0 {
+		fmt.Fprintf(writer, "=== CURRENCY CONVERSIONS ===\n")
+		rg.printCurrencyConversions(converted, writer)
+		fmt.Fprintf(writer, "\n")
+	}
+	
+	// Matches held for a reviewer to accept or reject
+	if len(result.PendingReview) > 0 {
+		fmt.Fprintf(writer, "=== PENDING REVIEW ===\n")
+		rg.printPendingReview(result.PendingReview, writer)
+		fmt.Fprintf(writer, "\n")
+	}
+	
+	// Unmatched transactions and statements, each with its near misses
+	nearMisses := newNearMissLookup(result.NearMisses)
 	if rg.config.IncludeUnmatchedTransactions && len(result.UnmatchedTransactions) > 0 {
 		fmt.Fprintf(writer, "=== UNMATCHED TRANSACTIONS ===\n")
-		rg.printUnmatchedTransactions(result.UnmatchedTransactions, writer)
+		rg.printUnmatchedTransactions(result.UnmatchedTransactions, nearMisses, writer)
 		fmt.Fprintf(writer, "\n")
 	}
 	
 	// Unmatched bank statements
 	if rg.config.IncludeUnmatchedStatements && len(result.UnmatchedStatements) > 0 {
 		fmt.Fprintf(writer, "=== UNMATCHED BANK STATEMENTS ===\n")
-		rg.printUnmatchedStatements(result.UnmatchedStatements, writer)
+		rg.printUnmatchedStatements(result.UnmatchedStatements, nearMisses, writer)
+		fmt.Fprintf(writer, "\n")
+	}
+	
+	// Bank statements left out by ignore rules
+	if len(result.IgnoredStatements) > 0 {
+		fmt.Fprintf(writer, "=== IGNORED STATEMENTS ===\n")
+		rg.printIgnoredStatements(result.IgnoredStatements, writer)
 		fmt.Fprintf(writer, "\n")
 	}
 	
@@ -225,26 +273,32 @@ func (rg *ReportGenerator) generateJSONReport(result *reconciler.ReconciliationR
 
 // generateCSVReport generates a CSV report with transaction details
 func (rg *ReportGenerator) generateCSVReport(result *reconciler.ReconciliationResult, writer io.Writer) error {
-	csvWriter := csv.NewWriter(writer)
+	csvWriter := &reportCSVWriter{Writer: csv.NewWriter(writer)}
 	csvWriter.Comma = rg.config.CSVDelimiter
 	defer csvWriter.Flush()
 	
+	headers := []string{
+		"Type",
+		"ID",
+		"Amount",
+		"Transaction_Type",
+		"Date",
+		"Status",
+		"Bank_File",
+		"Match_Type",
+		"Confidence_Score",
+		"Amount_Difference",
+		"Date_Difference",
+		"Notes",
+	}
+	// FX columns are only added when a match was converted
+	if hasConversions(result) {
+		headers = append(headers, fxHeaders...)
+	}
+	csvWriter.width = len(headers)
+	
 	// Write headers if enabled
 	if rg.config.CSVHeaders {
-		headers := []string{
-			"Type",
-			"ID",
-			"Amount",
-			"Transaction_Type",
-			"Date",
-			"Status",
-			"Bank_File",
-			"Match_Type",
-			"Confidence_Score",
-			"Amount_Difference",
-			"Date_Difference",
-			"Notes",
-		}
 		if err := csvWriter.Write(headers); err != nil {
 			return fmt.Errorf("failed to write CSV headers: %w", err)
 		}
@@ -260,20 +314,34 @@ func (rg *ReportGenerator) generateCSVReport(result *reconciler.ReconciliationRe
 				string(match.Transaction.Type),
 				match.Transaction.TransactionTime.Format("2006-01-02 15:04:05"),
 				"Matched",
-				"", // Bank file would need to be tracked separately
+				match.BankStatement.SourceFile,
 				match.MatchType.String(),
 				fmt.Sprintf("%.2f", match.ConfidenceScore),
 				match.AmountDifference.String(),
 				match.DateDifference.String(),
 				strings.Join(match.Reasons, "; "),
 			}
+			record = append(record, fxColumns(match)...)
 			if err := csvWriter.Write(record); err != nil {
 				return fmt.Errorf("failed to write matched transaction record: %w", err)
 			}
 		}
 	}
 	
-	// Write unmatched transactions
+	// Write grouped matches, one row per member
+	if rg.config.IncludeGroupMatches {
+		if err := rg.writeGroupMatchRecords(csvWriter, result.GroupMatches); err != nil {
+			return err
+		}
+	}
+	
+	// Write matches held for review
+	if err := rg.writePendingReviewRecords(csvWriter, result.PendingReview); err != nil {
+		return err
+	}
+	
+	// Write unmatched transactions, each followed by its near misses
+	nearMisses := newNearMissLookup(result.NearMisses)
 	if rg.config.IncludeUnmatchedTransactions {
 		for _, tx := range result.UnmatchedTransactions {
 			record := []string{
@@ -293,35 +361,119 @@ func (rg *ReportGenerator) generateCSVReport(result *reconciler.ReconciliationRe
 			if err := csvWriter.Write(record); err != nil {
 				return fmt.Errorf("failed to write unmatched transaction record: %w", err)
 			}
+			if err := rg.writeNearMissRecords(csvWriter, tx.TrxID, nearMisses.forTransaction(tx)); err != nil {
+				return err
+			}
 		}
 	}
 	
 	// Write unmatched bank statements
 	if rg.config.IncludeUnmatchedStatements {
-		for _, stmt := range result.UnmatchedStatements {
-			record := []string{
-				"Unmatched Bank Statement",
-				stmt.UniqueIdentifier,
-				stmt.Amount.String(),
-				string(stmt.GetTransactionType()),
-				stmt.Date.Format("2006-01-02"),
-				"Unmatched",
-				"", // Bank file would need to be tracked separately
-				"",
-				"",
-				"",
-				"",
-				"No matching system transaction found",
-			}
-			if err := csvWriter.Write(record); err != nil {
-				return fmt.Errorf("failed to write unmatched statement record: %w", err)
+		if rg.config.GroupUnmatchedByBank {
+			for _, group := range GroupStatementsByBank(result.UnmatchedStatements) {
+				if err := rg.writeUnmatchedStatementRecords(csvWriter, group.Statements, nearMisses); err != nil {
+					return err
+				}
+				if err := rg.writeBankSubtotalRecord(csvWriter, group); err != nil {
+					return err
+				}
 			}
+		} else if err := rg.writeUnmatchedStatementRecords(csvWriter, result.UnmatchedStatements, nearMisses); err != nil {
+			return err
+		}
+	}
+	
+	// Write statements left out by ignore rules
+	if rg.config.IncludeUnmatchedStatements {
+		if err := rg.writeIgnoredStatementRecords(csvWriter, result.IgnoredStatements); err != nil {
+			return err
 		}
 	}
 	
 	return nil
 }
 
+// writeUnmatchedStatementRecords writes one CSV row per unmatched statement,
+// followed by rows for its near misses
+func (rg *ReportGenerator) writeUnmatchedStatementRecords(csvWriter *reportCSVWriter, statements []*models.BankStatement, nearMisses *nearMissLookup) error {
+	for _, stmt := range statements {
+		record := []string{
+			"Unmatched Bank Statement",
+			stmt.UniqueIdentifier,
+			stmt.Amount.String(),
+			string(stmt.GetTransactionType()),
+			stmt.Date.Format("2006-01-02"),
+			"Unmatched",
+			stmt.SourceFile,
+			"",
+			"",
+			"",
+			"",
+			"No matching system transaction found",
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write unmatched statement record: %w", err)
+		}
+		if err := rg.writeNearMissRecords(csvWriter, stmt.UniqueIdentifier, nearMisses.forStatement(stmt)); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// writeIgnoredStatementRecords writes one CSV row per statement left out of
+// matching by an ignore rule
+func (rg *ReportGenerator) writeIgnoredStatementRecords(csvWriter *reportCSVWriter, ignored []*matcher.IgnoredStatement) error {
+	for _, item := range ignored {
+		stmt := item.Statement
+		record := []string{
+			"Ignored Bank Statement",
+			stmt.UniqueIdentifier,
+			stmt.Amount.String(),
+			string(stmt.GetTransactionType()),
+			stmt.Date.Format("2006-01-02"),
+			"Ignored",
+			stmt.SourceFile,
+			"",
+			"",
+			"",
+			"",
+			fmt.Sprintf("Ignored by rule %q", item.Rule),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write ignored statement record: %w", err)
+		}
+	}
+	
+	return nil
+}
+
+// writeBankSubtotalRecord writes the subtotal row that follows the unmatched
+// statements of a bank. The Amount column holds the net total.
+func (rg *ReportGenerator) writeBankSubtotalRecord(csvWriter *reportCSVWriter, group *BankGroup) error {
+	record := []string{
+		"Unmatched Bank Subtotal",
+		group.BankName,
+		group.NetTotal.String(),
+		"",
+		"",
+		"Unmatched",
+		strings.Join(group.SourceFiles, " "),
+		"",
+		"",
+		"",
+		"",
+		fmt.Sprintf("%d unmatched statements; debits %s; credits %s",
+			group.Count, group.DebitTotal.StringFixed(2), group.CreditTotal.StringFixed(2)),
+	}
+	if err := csvWriter.Write(record); err != nil {
+		return fmt.Errorf("failed to write bank subtotal record: %w", err)
+	}
+	
+	return nil
+}
+
 // Helper methods for console output formatting
 
 func (rg *ReportGenerator) printSummaryTable(summary *reconciler.ResultSummary, writer io.Writer) {
@@ -333,6 +485,11 @@ func (rg *ReportGenerator) printSummaryTable(summary *reconciler.ResultSummary,
 	fmt.Fprintf(writer, "  Unmatched: %d (%.1f%%)\n", 
 		summary.UnmatchedTransactions,
 		rg.calculatePercentage(summary.UnmatchedTransactions, summary.TotalTransactions))
+	if summary.PendingReview > 0 {
+		fmt.Fprintf(writer, "  Pending:   %d (%.1f%%)\n", 
+			summary.PendingReview,
+			rg.calculatePercentage(summary.PendingReview, summary.TotalTransactions))
+	}
 	
 	fmt.Fprintf(writer, "\nBank Statements:\n")
 	fmt.Fprintf(writer, "  Total:     %d\n", summary.TotalBankStatements)
@@ -342,9 +499,17 @@ func (rg *ReportGenerator) printSummaryTable(summary *reconciler.ResultSummary,
 	fmt.Fprintf(writer, "  Unmatched: %d (%.1f%%)\n", 
 		summary.UnmatchedStatements,
 		rg.calculatePercentage(summary.UnmatchedStatements, summary.TotalBankStatements))
+	if summary.PendingReview > 0 {
+		fmt.Fprintf(writer, "  Pending:   %d (%.1f%%)\n", 
+			summary.PendingReview,
+			rg.calculatePercentage(summary.PendingReview, summary.TotalBankStatements))
+	}
 }
 
 func (rg *ReportGenerator) printFinancialSummary(summary *reconciler.ResultSummary, writer io.Writer) {
+	if summary.AmountCurrency != "" {
+		fmt.Fprintf(writer, "Amounts in:               %s\n", summary.AmountCurrency)
+	}
 	fmt.Fprintf(writer, "Total Transaction Amount: %s\n", summary.TotalTransactionAmount.StringFixed(2))
 	fmt.Fprintf(writer, "Total Statement Amount:   %s\n", summary.TotalStatementAmount.StringFixed(2))
 	fmt.Fprintf(writer, "Net Discrepancy:          %s\n", summary.NetDiscrepancy.StringFixed(2))
@@ -356,7 +521,7 @@ func (rg *ReportGenerator) printFinancialSummary(summary *reconciler.ResultSumma
 }
 
 func (rg *ReportGenerator) printMatchQualityTable(summary *reconciler.ResultSummary, writer io.Writer) {
-	total := summary.ExactMatches + summary.CloseMatches + summary.FuzzyMatches + summary.PossibleMatches
+	total := summary.ExactMatches + summary.CloseMatches + summary.FuzzyMatches + summary.PossibleMatches + summary.ManualMatches
 	
 	fmt.Fprintf(writer, "Exact Matches:    %d (%.1f%%)\n", 
 		summary.ExactMatches, rg.calculatePercentage(summary.ExactMatches, total))
@@ -366,9 +531,214 @@ func (rg *ReportGenerator) printMatchQualityTable(summary *reconciler.ResultSumm
 		summary.FuzzyMatches, rg.calculatePercentage(summary.FuzzyMatches, total))
 	fmt.Fprintf(writer, "Possible Matches: %d (%.1f%%)\n", 
 		summary.PossibleMatches, rg.calculatePercentage(summary.PossibleMatches, total))
+	if summary.ManualMatches > 0 {
+		fmt.Fprintf(writer, "Manual Matches:   %d (%.1f%%)\n", 
+			summary.ManualMatches, rg.calculatePercentage(summary.ManualMatches, total))
+	}
+	
+	if summary.ManyToOneMatches > 0 {
+		fmt.Fprintf(writer, "Many-to-One Groups: %d\n", summary.ManyToOneMatches)
+	}
+	if summary.OneToManyMatches > 0 {
+		fmt.Fprintf(writer, "One-to-Many Groups: %d\n", summary.OneToManyMatches)
+	}
+}
+
+func hasOpenItems(ageing *reconciler.AgeingSummary) bool {
+	return ageing != nil && ageing.Transactions.Total()+ageing.Statements.Total() > 0
+}
+
+func (rg *ReportGenerator) printOpenItems(summary *reconciler.ResultSummary, writer io.Writer) {
+	if summary.CarriedForward > 0 {
+		fmt.Fprintf(writer, "Carried Forward: %d (%d cleared, %d still open)\n",
+			summary.CarriedForward, summary.CarriedCleared, summary.CarriedForward-summary.CarriedCleared)
+	}
+	
+	if !hasOpenItems(summary.Ageing) {
+		return
+	}
+	ageing := summary.Ageing
+	fmt.Fprintf(writer, "Ageing as of %s (days):\n", ageing.AsOf.Format("2006-01-02"))
+	fmt.Fprintf(writer, "  %-16s %6s %6s %6s\n", "", "0-7", "8-30", "30+")
+	fmt.Fprintf(writer, "  %-16s %6d %6d %6d\n", "Transactions:",
+		ageing.Transactions.Days0To7, ageing.Transactions.Days8To30, ageing.Transactions.Over30)
+	fmt.Fprintf(writer, "  %-16s %6d %6d %6d\n", "Bank Statements:",
+		ageing.Statements.Days0To7, ageing.Statements.Days8To30, ageing.Statements.Over30)
+}
+
+func (rg *ReportGenerator) printGroupMatches(groups []*matcher.GroupMatch, writer io.Writer) {
+	fmt.Fprintf(writer, "Total Grouped Matches: %d\n\n", len(groups))
+	
+	for i, group := range groups {
+		fmt.Fprintf(writer, "  %d. %s (Confidence: %.2f)\n", i+1, group.GroupType, group.ConfidenceScore)
+		for _, tx := range group.Transactions {
+			fmt.Fprintf(writer, "     Transaction: %s, Amount: %s, Time: %s\n",
+				tx.TrxID, tx.Amount.StringFixed(2), tx.TransactionTime.Format("2006-01-02 15:04:05"))
+		}
+		for _, stmt := range group.Statements {
+			fmt.Fprintf(writer, "     Statement:   %s, Amount: %s, Date: %s\n",
+				stmt.UniqueIdentifier, stmt.Amount.StringFixed(2), stmt.Date.Format("2006-01-02"))
+		}
+		fmt.Fprintf(writer, "     Totals: transactions %s, statements %s, difference %s\n",
+			group.TransactionTotal.StringFixed(2), group.StatementTotal.StringFixed(2), group.AmountDifference.StringFixed(2))
+		
+		// Limit output for very long lists
+		if i >= 9 && len(groups) > 10 {
+			fmt.Fprintf(writer, "  ... and %d more\n", len(groups)-10)
+			break
+		}
+	}
+}
+
+// manualMatches returns the matches forced by a match rule
+func manualMatches(matches []*matcher.MatchResult) []*matcher.MatchResult {
+	var manual []*matcher.MatchResult
+	for _, match := range matches {
+		if match.MatchType == matcher.MatchManual {
+			manual = append(manual, match)
+		}
+	}
+	return manual
+}
+
+func (rg *ReportGenerator) printManualMatches(matches []*matcher.MatchResult, writer io.Writer) {
+	fmt.Fprintf(writer, "Total Manual Matches: %d\n\n", len(matches))
+	
+	for i, match := range matches {
+		fmt.Fprintf(writer, "  %d. Transaction %s <-> Statement %s (rule: %s)\n",
+			i+1, match.Transaction.TrxID, match.BankStatement.UniqueIdentifier, match.Rule)
+		fmt.Fprintf(writer, "     Amounts: %s vs %s, Difference: %s\n",
+			match.Transaction.Amount.StringFixed(2), match.BankStatement.Amount.StringFixed(2), match.AmountDifference.StringFixed(2))
+		
+		// Limit output for very long lists
+		if i >= 9 && len(matches) > 10 {
+			fmt.Fprintf(writer, "  ... and %d more\n", len(matches)-10)
+			break
+		}
+	}
+}
+
+func (rg *ReportGenerator) printIgnoredStatements(ignored []*matcher.IgnoredStatement, writer io.Writer) {
+	fmt.Fprintf(writer, "Total Ignored Bank Statements: %d\n\n", len(ignored))
+	
+	for i, item := range ignored {
+		stmt := item.Statement
+		fmt.Fprintf(writer, "  %d. ID: %s, Amount: %s, Date: %s, Rule: %s%s\n",
+			i+1,
+			stmt.UniqueIdentifier,
+			stmt.Amount.StringFixed(2),
+			stmt.Date.Format("2006-01-02"),
+			item.Rule,
+			formatStatementSource(stmt))
+		
+		// Limit output for very long lists
+		if i >= 9 && len(ignored) > 10 {
+			fmt.Fprintf(writer, "  ... and %d more\n", len(ignored)-10)
+			break
+		}
+	}
+}
+
+// ManualMatchRecord is the JSON report entry for a pair forced by a match rule
+type ManualMatchRecord struct {
+	TransactionID    string          `json:"transaction_id"`
+	StatementID      string          `json:"statement_id"`
+	Rule             string          `json:"rule"`
+	AmountDifference decimal.Decimal `json:"amount_difference"`
+}
+
+// convertedMatches returns the matches whose amounts were converted to the base currency
+func convertedMatches(matches []*matcher.MatchResult) []*matcher.MatchResult {
+	var converted []*matcher.MatchResult
+	for _, match := range matches {
+		if match.FX != nil {
+			converted = append(converted, match)
+		}
+	}
+	return converted
+}
+
+func (rg *ReportGenerator) printCurrencyConversions(matches []*matcher.MatchResult, writer io.Writer) {
+	fmt.Fprintf(writer, "Total Converted Matches: %d\n\n", len(matches))
+	
+	for i, match := range matches {
+		fmt.Fprintf(writer, "  %d. Transaction %s <-> Statement %s\n",
+			i+1, match.Transaction.TrxID, match.BankStatement.UniqueIdentifier)
+		fmt.Fprintf(writer, "     Transaction: %s\n", formatConvertedAmount(match.Transaction.Amount, match.FX.Transaction, match.FX.BaseCurrency))
+		fmt.Fprintf(writer, "     Statement:   %s\n", formatConvertedAmount(match.BankStatement.Amount, match.FX.Statement, match.FX.BaseCurrency))
+		fmt.Fprintf(writer, "     FX Difference: %s %s\n", match.FX.BaseCurrency, match.FX.Difference.StringFixed(2))
+		
+		// Limit output for very long lists
+		if i >= 9 && len(matches) > 10 {
+			fmt.Fprintf(writer, "  ... and %d more\n", len(matches)-10)
+			break
+		}
+	}
+}
+
+// formatConvertedAmount shows an amount in its original currency and, when it
+// was converted, in the base currency together with the rate used
+func formatConvertedAmount(amount decimal.Decimal, conversion *fx.Conversion, baseCurrency string) string {
+	if conversion == nil {
+		return fmt.Sprintf("%s %s", baseCurrency, amount.StringFixed(2))
+	}
+	return fmt.Sprintf("%s %s -> %s %s (rate %s, %s)",
+		conversion.Currency, conversion.Amount.StringFixed(2),
+		conversion.TargetCurrency, conversion.ConvertedAmount.StringFixed(2),
+		conversion.Rate.String(), conversion.RateDate.Format("2006-01-02"))
 }
 
-func (rg *ReportGenerator) printUnmatchedTransactions(transactions []*models.Transaction, writer io.Writer) {
+// writeGroupMatchRecords writes one CSV row per member of each grouped match.
+// Rows belonging to the same group share a group label in the Notes column.
+func (rg *ReportGenerator) writeGroupMatchRecords(csvWriter *reportCSVWriter, groups []*matcher.GroupMatch) error {
+	for i, group := range groups {
+		label := fmt.Sprintf("Group %d", i+1)
+		
+		for _, tx := range group.Transactions {
+			record := []string{
+				"Grouped Transaction",
+				tx.TrxID,
+				tx.Amount.String(),
+				string(tx.Type),
+				tx.TransactionTime.Format("2006-01-02 15:04:05"),
+				"Matched",
+				"",
+				string(group.GroupType),
+				fmt.Sprintf("%.2f", group.ConfidenceScore),
+				group.AmountDifference.String(),
+				"",
+				fmt.Sprintf("%s: %s", label, strings.Join(group.Reasons, "; ")),
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return fmt.Errorf("failed to write grouped transaction record: %w", err)
+			}
+		}
+		
+		for _, stmt := range group.Statements {
+			record := []string{
+				"Grouped Bank Statement",
+				stmt.UniqueIdentifier,
+				stmt.Amount.String(),
+				string(stmt.GetTransactionType()),
+				stmt.Date.Format("2006-01-02"),
+				"Matched",
+				stmt.SourceFile,
+				string(group.GroupType),
+				fmt.Sprintf("%.2f", group.ConfidenceScore),
+				group.AmountDifference.String(),
+				"",
+				label,
+			}
+			if err := csvWriter.Write(record); err != nil {
+				return fmt.Errorf("failed to write grouped statement record: %w", err)
+			}
+		}
+	}
+	
+	return nil
+}
+
+func (rg *ReportGenerator) printUnmatchedTransactions(transactions []*models.Transaction, nearMisses *nearMissLookup, writer io.Writer) {
 	// Sort transactions if requested
 	if rg.config.SortByAmount {
 		sort.Slice(transactions, func(i, j int) bool {
@@ -392,17 +762,17 @@ func (rg *ReportGenerator) printUnmatchedTransactions(transactions []*models.Tra
 	
 	if len(debitTxns) > 0 {
 		fmt.Fprintf(writer, "Debit Transactions (%d):\n", len(debitTxns))
-		rg.printTransactionList(debitTxns, writer)
+		rg.printTransactionList(debitTxns, nearMisses, writer)
 		fmt.Fprintf(writer, "\n")
 	}
 	
 	if len(creditTxns) > 0 {
 		fmt.Fprintf(writer, "Credit Transactions (%d):\n", len(creditTxns))
-		rg.printTransactionList(creditTxns, writer)
+		rg.printTransactionList(creditTxns, nearMisses, writer)
 	}
 }
 
-func (rg *ReportGenerator) printUnmatchedStatements(statements []*models.BankStatement, writer io.Writer) {
+func (rg *ReportGenerator) printUnmatchedStatements(statements []*models.BankStatement, nearMisses *nearMissLookup, writer io.Writer) {
 	// Sort statements if requested
 	if rg.config.SortByAmount {
 		sort.Slice(statements, func(i, j int) bool {
@@ -412,6 +782,33 @@ func (rg *ReportGenerator) printUnmatchedStatements(statements []*models.BankSta
 	
 	fmt.Fprintf(writer, "Total Unmatched Bank Statements: %d\n\n", len(statements))
 	
+	if rg.config.GroupUnmatchedByBank {
+		rg.printStatementsByBank(statements, nearMisses, writer)
+		return
+	}
+	
+	rg.printStatementsByDirection(statements, nearMisses, writer, "")
+}
+
+// printStatementsByBank prints unmatched statements grouped by bank, with
+// subtotals for each bank
+func (rg *ReportGenerator) printStatementsByBank(statements []*models.BankStatement, nearMisses *nearMissLookup, writer io.Writer) {
+	for i, group := range GroupStatementsByBank(statements) {
+		if i > 0 {
+			fmt.Fprintf(writer, "\n")
+		}
+		fmt.Fprintf(writer, "Bank: %s (%d)\n", group.BankName, group.Count)
+		if len(group.SourceFiles) > 0 {
+			fmt.Fprintf(writer, "  Files: %s\n", strings.Join(group.SourceFiles, ", "))
+		}
+		fmt.Fprintf(writer, "  Subtotal: debits %s, credits %s, net %s\n\n",
+			group.DebitTotal.StringFixed(2), group.CreditTotal.StringFixed(2), group.NetTotal.StringFixed(2))
+		rg.printStatementsByDirection(group.Statements, nearMisses, writer, "  ")
+	}
+}
+
+// printStatementsByDirection prints statements split into debits and credits
+func (rg *ReportGenerator) printStatementsByDirection(statements []*models.BankStatement, nearMisses *nearMissLookup, writer io.Writer, indent string) {
 	// Group by debit/credit
 	debitStmts := make([]*models.BankStatement, 0)
 	creditStmts := make([]*models.BankStatement, 0)
@@ -425,14 +822,14 @@ func (rg *ReportGenerator) printUnmatchedStatements(statements []*models.BankSta
 	}
 	
 	if len(debitStmts) > 0 {
-		fmt.Fprintf(writer, "Debit Statements (%d):\n", len(debitStmts))
-		rg.printStatementList(debitStmts, writer)
+		fmt.Fprintf(writer, "%sDebit Statements (%d):\n", indent, len(debitStmts))
+		rg.printStatementList(debitStmts, nearMisses, writer, indent)
 		fmt.Fprintf(writer, "\n")
 	}
 	
 	if len(creditStmts) > 0 {
-		fmt.Fprintf(writer, "Credit Statements (%d):\n", len(creditStmts))
-		rg.printStatementList(creditStmts, writer)
+		fmt.Fprintf(writer, "%sCredit Statements (%d):\n", indent, len(creditStmts))
+		rg.printStatementList(creditStmts, nearMisses, writer, indent)
 	}
 }
 
@@ -486,7 +883,7 @@ func (rg *ReportGenerator) printProcessingStats(stats *reconciler.ProcessingStat
 	}
 }
 
-func (rg *ReportGenerator) printTransactionList(transactions []*models.Transaction, writer io.Writer) {
+func (rg *ReportGenerator) printTransactionList(transactions []*models.Transaction, nearMisses *nearMissLookup, writer io.Writer) {
 	for i, tx := range transactions {
 		fmt.Fprintf(writer, "  %d. ID: %s, Amount: %s, Type: %s, Time: %s\n",
 			i+1,
@@ -494,6 +891,7 @@ func (rg *ReportGenerator) printTransactionList(transactions []*models.Transacti
 			tx.Amount.StringFixed(2),
 			tx.Type,
 			tx.TransactionTime.Format("2006-01-02 15:04:05"))
+		rg.printNearMisses(nearMisses.forTransaction(tx), writer, "")
 		
 		// Limit output for very long lists
 		if i >= 9 && len(transactions) > 10 {
@@ -503,22 +901,37 @@ func (rg *ReportGenerator) printTransactionList(transactions []*models.Transacti
 	}
 }
 
-func (rg *ReportGenerator) printStatementList(statements []*models.BankStatement, writer io.Writer) {
+func (rg *ReportGenerator) printStatementList(statements []*models.BankStatement, nearMisses *nearMissLookup, writer io.Writer, indent string) {
 	for i, stmt := range statements {
-		fmt.Fprintf(writer, "  %d. ID: %s, Amount: %s, Date: %s\n",
+		fmt.Fprintf(writer, "%s  %d. ID: %s, Amount: %s, Date: %s%s\n",
+			indent,
 			i+1,
 			stmt.UniqueIdentifier,
 			stmt.Amount.StringFixed(2),
-			stmt.Date.Format("2006-01-02"))
+			stmt.Date.Format("2006-01-02"),
+			formatStatementSource(stmt))
+		rg.printNearMisses(nearMisses.forStatement(stmt), writer, indent)
 		
 		// Limit output for very long lists
 		if i >= 9 && len(statements) > 10 {
-			fmt.Fprintf(writer, "  ... and %d more\n", len(statements)-10)
+			fmt.Fprintf(writer, "%s  ... and %d more\n", indent, len(statements)-10)
 			break
 		}
 	}
 }
 
+// formatStatementSource returns a ", Source: file:line" suffix for statements
+// that know where they were read from
+func formatStatementSource(stmt *models.BankStatement) string {
+	if stmt.SourceFile == "" {
+		return ""
+	}
+	if stmt.SourceLine > 0 {
+		return fmt.Sprintf(", Source: %s:%d", filepath.Base(stmt.SourceFile), stmt.SourceLine)
+	}
+	return fmt.Sprintf(", Source: %s", filepath.Base(stmt.SourceFile))
+}
+
 // Helper methods
 
 func (rg *ReportGenerator) calculatePercentage(part, total int) float64 {
@@ -538,18 +951,56 @@ func (rg *ReportGenerator) filterResultForOutput(result *reconciler.Reconciliati
 		output["matched_transactions"] = result.MatchedTransactions
 	}
 	
+	// Match types are numeric in matched_transactions, so manual pairs are
+	// listed separately with the rule that forced them
+	if manual := manualMatches(result.MatchedTransactions); len(manual) > 0 {
+		records := make([]*ManualMatchRecord, 0, len(manual))
+		for _, match := range manual {
+			records = append(records, &ManualMatchRecord{
+				TransactionID:    match.Transaction.TrxID,
+				StatementID:      match.BankStatement.UniqueIdentifier,
+				Rule:             match.Rule,
+				AmountDifference: match.AmountDifference,
+			})
+		}
+		output["manual_matches"] = records
+	}
+	
+	if rg.config.IncludeGroupMatches && result.GroupMatches != nil {
+		output["group_matches"] = result.GroupMatches
+	}
+	
 	if rg.config.IncludeUnmatchedTransactions && result.UnmatchedTransactions != nil {
 		output["unmatched_transactions"] = result.UnmatchedTransactions
 	}
 	
 	if rg.config.IncludeUnmatchedStatements && result.UnmatchedStatements != nil {
 		output["unmatched_statements"] = result.UnmatchedStatements
+		if rg.config.GroupUnmatchedByBank {
+			output["unmatched_statements_by_bank"] = GroupStatementsByBank(result.UnmatchedStatements)
+		}
+	}
+	
+	if len(result.IgnoredStatements) > 0 {
+		output["ignored_statements"] = result.IgnoredStatements
+	}
+	
+	if len(result.PendingReview) > 0 {
+		output["pending_review"] = pendingReviewRecords(result.PendingReview)
+	}
+	
+	if nearMisses := rg.filterNearMisses(result.NearMisses); len(nearMisses) > 0 {
+		output["near_misses"] = nearMisses
 	}
 	
 	if rg.config.IncludeDiscrepancies && result.Discrepancies != nil {
 		output["discrepancies"] = result.Discrepancies
 	}
 	
+	if converted := convertedMatches(result.MatchedTransactions); len(converted) > 0 {
+		output["fx_conversions"] = fxConversionRecords(converted)
+	}
+	
 	if rg.config.IncludeProcessingStats && result.ProcessingStats != nil {
 		output["processing_stats"] = result.ProcessingStats
 	}
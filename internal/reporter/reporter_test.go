@@ -2,12 +2,14 @@ package reporter
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"golang-reconciliation-service/internal/fx"
 	"golang-reconciliation-service/internal/matcher"
 	"golang-reconciliation-service/internal/models"
 	"golang-reconciliation-service/internal/reconciler"
@@ -613,6 +615,371 @@ func createSampleReconciliationResult() *reconciler.ReconciliationResult {
 	}
 }
 
+func TestGroupMatchReporting(t *testing.T) {
+	result := createSampleReconciliationResult()
+	tx := &models.Transaction{
+		TrxID:           "TXN003",
+		Amount:          decimal.NewFromFloat(1000.00),
+		Type:            models.TransactionTypeCredit,
+		TransactionTime: time.Now(),
+	}
+	result.GroupMatches = []*matcher.GroupMatch{
+		{
+			GroupType:    matcher.GroupManyToOne,
+			Transactions: []*models.Transaction{tx},
+			Statements: []*models.BankStatement{
+				{UniqueIdentifier: "STMT010", Amount: decimal.NewFromFloat(600.00), Date: time.Now()},
+				{UniqueIdentifier: "STMT011", Amount: decimal.NewFromFloat(400.00), Date: time.Now()},
+			},
+			TransactionTotal: decimal.NewFromFloat(1000.00),
+			StatementTotal:   decimal.NewFromFloat(1000.00),
+			AmountDifference: decimal.Zero,
+			ConfidenceScore:  0.9,
+			Reasons:          []string{"2 bank statements sum to the transaction amount"},
+		},
+	}
+	result.Summary.ManyToOneMatches = 1
+
+	tests := []struct {
+		format        OutputFormat
+		shouldContain []string
+	}{
+		{FormatConsole, []string{"=== GROUPED MATCHES ===", "many_to_one", "STMT010", "STMT011", "Many-to-One Groups: 1"}},
+		{FormatJSON, []string{"\"group_matches\"", "STMT010", "\"many_to_one_matches\": 1"}},
+		{FormatCSV, []string{"Grouped Transaction,TXN003", "Grouped Bank Statement,STMT010", "Grouped Bank Statement,STMT011", "Group 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultReportConfig()
+			config.Format = tt.format
+
+			generator, err := NewReportGenerator(config)
+			if err != nil {
+				t.Fatalf("failed to create report generator: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			if err := generator.GenerateReport(result, &buffer); err != nil {
+				t.Fatalf("failed to generate report: %v", err)
+			}
+
+			output := buffer.String()
+			for _, expected := range tt.shouldContain {
+				if !strings.Contains(output, expected) {
+					t.Errorf("output should contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleOverrideReporting(t *testing.T) {
+	result := createSampleReconciliationResult()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	result.MatchedTransactions = append(result.MatchedTransactions, &matcher.MatchResult{
+		Transaction:      &models.Transaction{TrxID: "TXN004", Amount: decimal.NewFromFloat(90.00), Type: models.TransactionTypeCredit, TransactionTime: date},
+		BankStatement:    &models.BankStatement{UniqueIdentifier: "STMT020", Amount: decimal.NewFromFloat(85.00), Date: date},
+		MatchType:        matcher.MatchManual,
+		AmountDifference: decimal.NewFromFloat(5.00),
+		Reasons:          []string{"Manual match by rule \"short payment\""},
+		Rule:             "short payment",
+	})
+	result.IgnoredStatements = []*matcher.IgnoredStatement{
+		{Statement: &models.BankStatement{UniqueIdentifier: "FEE-01", Amount: decimal.NewFromFloat(-2.50), Date: date}, Rule: "bank fees"},
+	}
+	result.Summary.ManualMatches = 1
+	result.Summary.IgnoredStatements = 1
+
+	tests := []struct {
+		format        OutputFormat
+		shouldContain []string
+	}{
+		{FormatConsole, []string{"Manual Matches:   1", "=== MANUAL MATCHES ===", "TXN004 <-> Statement STMT020 (rule: short payment)", "=== IGNORED STATEMENTS ===", "FEE-01", "Rule: bank fees"}},
+		{FormatJSON, []string{"\"manual_matches\"", "\"rule\": \"short payment\"", "\"ignored_statements\"", "\"rule\": \"bank fees\""}},
+		{FormatCSV, []string{"Matched Transaction,TXN004", ",Manual,", "Ignored Bank Statement,FEE-01", "Ignored by rule \"\"bank fees\"\""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultReportConfig()
+			config.Format = tt.format
+			config.IncludeMatchedTransactions = true
+
+			generator, err := NewReportGenerator(config)
+			if err != nil {
+				t.Fatalf("failed to create report generator: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			if err := generator.GenerateReport(result, &buffer); err != nil {
+				t.Fatalf("failed to generate report: %v", err)
+			}
+
+			output := buffer.String()
+			for _, expected := range tt.shouldContain {
+				if !strings.Contains(output, expected) {
+					t.Errorf("output should contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCurrencyConversionReporting(t *testing.T) {
+	result := createSampleReconciliationResult()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	result.MatchedTransactions = append(result.MatchedTransactions, &matcher.MatchResult{
+		Transaction:      &models.Transaction{TrxID: "TXN-EUR", Amount: decimal.NewFromFloat(100.00), Type: models.TransactionTypeCredit, TransactionTime: date, Currency: "EUR"},
+		BankStatement:    &models.BankStatement{UniqueIdentifier: "STMT-USD", Amount: decimal.NewFromFloat(108.50), Date: date},
+		MatchType:        matcher.MatchClose,
+		ConfidenceScore:  0.9,
+		AmountDifference: decimal.NewFromFloat(0.50),
+		FX: &matcher.FXDetails{
+			BaseCurrency: "USD",
+			Transaction: &fx.Conversion{
+				Currency:        "EUR",
+				Amount:          decimal.NewFromFloat(100.00),
+				TargetCurrency:  "USD",
+				ConvertedAmount: decimal.NewFromFloat(108.00),
+				Rate:            decimal.NewFromFloat(1.08),
+				RateDate:        date,
+			},
+			Difference: decimal.NewFromFloat(0.50),
+		},
+	})
+
+	tests := []struct {
+		format        OutputFormat
+		shouldContain []string
+	}{
+		{FormatConsole, []string{"=== CURRENCY CONVERSIONS ===", "EUR 100.00 -> USD 108.00", "FX Difference: USD 0.50"}},
+		{FormatJSON, []string{"\"fx_conversions\"", "\"transaction_id\": \"TXN-EUR\"", "\"base_currency\": \"USD\"", "\"converted_amount\": \"108\"", "\"fx_difference\": \"0.5\""}},
+		{FormatCSV, []string{"FX_Base_Currency", "FX_Difference", "Matched Transaction,TXN-EUR,100,", ",USD,EUR,108,USD,108.5,108.5,0.5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultReportConfig()
+			config.Format = tt.format
+			config.IncludeMatchedTransactions = true
+
+			generator, err := NewReportGenerator(config)
+			if err != nil {
+				t.Fatalf("failed to create report generator: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			if err := generator.GenerateReport(result, &buffer); err != nil {
+				t.Fatalf("failed to generate report: %v", err)
+			}
+
+			output := buffer.String()
+			for _, expected := range tt.shouldContain {
+				if !strings.Contains(output, expected) {
+					t.Errorf("output should contain %q", expected)
+				}
+			}
+
+			// Rows without FX details are padded to the header width
+			if tt.format == FormatCSV {
+				if _, err := csv.NewReader(strings.NewReader(output)).ReadAll(); err != nil {
+					t.Errorf("CSV rows should all have the header width: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestNearMissReporting(t *testing.T) {
+	result := createSampleReconciliationResult()
+	tx := result.UnmatchedTransactions[0]
+	stmt := result.UnmatchedStatements[0]
+	result.NearMisses = []*matcher.NearMisses{
+		{Transaction: tx, Candidates: []*matcher.NearMiss{
+			{Statement: stmt, ConfidenceScore: 0.42, AmountDifference: decimal.NewFromInt(-100), DateDifferenceDays: 1, Reason: matcher.RejectionAmountOutsideTolerance},
+			{Statement: result.MatchedTransactions[0].BankStatement, ConfidenceScore: 0.3, AmountDifference: decimal.NewFromFloat(-174.75),
+				Reason: matcher.RejectionExcludedByRule, Rule: "wrong customer"},
+		}},
+		{Statement: stmt, Candidates: []*matcher.NearMiss{
+			{Transaction: tx, ConfidenceScore: 0.42, AmountDifference: decimal.NewFromInt(100), DateDifferenceDays: -1, Reason: matcher.RejectionAmountOutsideTolerance},
+		}},
+	}
+
+	tests := []struct {
+		format        OutputFormat
+		shouldContain []string
+	}{
+		{FormatConsole, []string{
+			"near miss: STMT002, Amount: 150.00 (-100.00), Date:",
+			"(+1d), Score: 0.42, Blocked: amount_outside_tolerance",
+			`Blocked: excluded_by_rule (rule "wrong customer")`,
+			"near miss: TXN002, Amount: 250.00 (+100.00)",
+		}},
+		{FormatJSON, []string{"\"near_misses\"", "\"reason\": \"amount_outside_tolerance\"", "\"rule\": \"wrong customer\"", "\"date_difference_days\": -1"}},
+		{FormatCSV, []string{"Near Miss,STMT002,150,CREDIT,", ",amount_outside_tolerance,,,0.42,-100,24h0m0s,Near miss for TXN002: amount_outside_tolerance",
+			"Near Miss,TXN002,250,CREDIT,", "Near miss for STMT002: amount_outside_tolerance"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultReportConfig()
+			config.Format = tt.format
+
+			generator, err := NewReportGenerator(config)
+			if err != nil {
+				t.Fatalf("failed to create report generator: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			if err := generator.GenerateReport(result, &buffer); err != nil {
+				t.Fatalf("failed to generate report: %v", err)
+			}
+
+			output := buffer.String()
+			for _, expected := range tt.shouldContain {
+				if !strings.Contains(output, expected) {
+					t.Errorf("output should contain %q, got:\n%s", expected, output)
+				}
+			}
+		})
+	}
+
+	// Near misses of items left out of the report are left out as well
+	config := DefaultReportConfig()
+	config.IncludeUnmatchedStatements = false
+	generator, err := NewReportGenerator(config)
+	if err != nil {
+		t.Fatalf("failed to create report generator: %v", err)
+	}
+	filtered := generator.filterResultForOutput(result)["near_misses"].([]*matcher.NearMisses)
+	if len(filtered) != 1 || filtered[0].Transaction != tx {
+		t.Errorf("Expected only the near misses of TXN002, got %d items", len(filtered))
+	}
+}
+
+func TestPendingReviewReporting(t *testing.T) {
+	result := createSampleReconciliationResult()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	result.PendingReview = []*matcher.PendingMatch{
+		{
+			Match: &matcher.MatchResult{
+				Transaction:      &models.Transaction{TrxID: "TXN010", Amount: decimal.NewFromFloat(60.00), Type: models.TransactionTypeCredit, TransactionTime: date},
+				BankStatement:    &models.BankStatement{UniqueIdentifier: "STMT010", Amount: decimal.NewFromFloat(60.00), Date: date.AddDate(0, 0, 2)},
+				MatchType:        matcher.MatchPossible,
+				ConfidenceScore:  0.82,
+				AmountDifference: decimal.Zero,
+				DateDifference:   48 * time.Hour,
+			},
+			Reason: matcher.ReviewLowConfidence,
+		},
+		{
+			Match: &matcher.MatchResult{
+				Transaction:      &models.Transaction{TrxID: "TXN011", Amount: decimal.NewFromFloat(20.00), Type: models.TransactionTypeCredit, TransactionTime: date},
+				BankStatement:    &models.BankStatement{UniqueIdentifier: "STMT011", Amount: decimal.NewFromFloat(20.00), Date: date},
+				MatchType:        matcher.MatchExact,
+				ConfidenceScore:  1.0,
+				AmountDifference: decimal.Zero,
+			},
+			Reason:         matcher.ReviewAmbiguousSameDay,
+			AmbiguityScore: 0.75,
+		},
+	}
+	result.Summary.PendingReview = 2
+
+	tests := []struct {
+		format        OutputFormat
+		shouldContain []string
+	}{
+		{FormatConsole, []string{
+			"  Pending:   2 (",
+			"=== PENDING REVIEW ===",
+			"Transaction TXN010 <-> Statement STMT010 (Possible, Confidence: 0.82)",
+			"Held: low_confidence",
+			"Held: ambiguous_same_day (ambiguity 0.75)",
+		}},
+		{FormatJSON, []string{"\"pending_review\"", "\"match_type\": \"Possible\"", "\"reason\": \"ambiguous_same_day\"", "\"ambiguity_score\": 0.75"}},
+		{FormatCSV, []string{"Pending Review,TXN010,60,CREDIT,", ",Pending Review,,Possible,0.82,0,48h0m0s,Pending review with STMT010: low_confidence",
+			"Pending review with STMT011: ambiguous_same_day (ambiguity 0.75)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultReportConfig()
+			config.Format = tt.format
+
+			generator, err := NewReportGenerator(config)
+			if err != nil {
+				t.Fatalf("failed to create report generator: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			if err := generator.GenerateReport(result, &buffer); err != nil {
+				t.Fatalf("failed to generate report: %v", err)
+			}
+
+			output := buffer.String()
+			for _, expected := range tt.shouldContain {
+				if !strings.Contains(output, expected) {
+					t.Errorf("output should contain %q, got:\n%s", expected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmatchedStatementsByBank(t *testing.T) {
+	result := createSampleReconciliationResult()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	result.UnmatchedStatements = []*models.BankStatement{
+		{UniqueIdentifier: "BCA001", Amount: decimal.NewFromFloat(-40.00), Date: date, BankName: "BCA", SourceFile: "/data/bca.csv", SourceLine: 2},
+		{UniqueIdentifier: "MDR001", Amount: decimal.NewFromFloat(75.00), Date: date, BankName: "Mandiri", SourceFile: "/data/mandiri.csv", SourceLine: 4},
+		{UniqueIdentifier: "BCA002", Amount: decimal.NewFromFloat(100.00), Date: date, BankName: "BCA", SourceFile: "/data/bca.csv", SourceLine: 3},
+	}
+
+	groups := GroupStatementsByBank(result.UnmatchedStatements)
+	if len(groups) != 2 || groups[0].BankName != "BCA" || groups[1].BankName != "Mandiri" {
+		t.Fatalf("expected BCA and Mandiri groups, got %+v", groups)
+	}
+	if groups[0].Count != 2 || !groups[0].NetTotal.Equal(decimal.NewFromFloat(60.00)) ||
+		!groups[0].DebitTotal.Equal(decimal.NewFromFloat(40.00)) || !groups[0].CreditTotal.Equal(decimal.NewFromFloat(100.00)) {
+		t.Errorf("unexpected BCA subtotals: %+v", groups[0])
+	}
+
+	tests := []struct {
+		format        OutputFormat
+		shouldContain []string
+	}{
+		{FormatConsole, []string{"Bank: BCA (2)", "Subtotal: debits 40.00, credits 100.00, net 60.00", "Bank: Mandiri (1)", "Source: bca.csv:3"}},
+		{FormatJSON, []string{"\"unmatched_statements_by_bank\"", "\"bank_name\": \"Mandiri\"", "\"net_total\": \"60\""}},
+		{FormatCSV, []string{"Unmatched Bank Statement,BCA001,-40,DEBIT,2024-01-15,Unmatched,/data/bca.csv", "Unmatched Bank Subtotal,BCA,60", "2 unmatched statements; debits 40.00; credits 100.00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			config := DefaultReportConfig()
+			config.Format = tt.format
+
+			generator, err := NewReportGenerator(config)
+			if err != nil {
+				t.Fatalf("failed to create report generator: %v", err)
+			}
+
+			var buffer bytes.Buffer
+			if err := generator.GenerateReport(result, &buffer); err != nil {
+				t.Fatalf("failed to generate report: %v", err)
+			}
+
+			output := buffer.String()
+			for _, expected := range tt.shouldContain {
+				if !strings.Contains(output, expected) {
+					t.Errorf("output should contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
 func TestEmptyResultHandling(t *testing.T) {
 	// Test with empty reconciliation result
 	emptyResult := &reconciler.ReconciliationResult{
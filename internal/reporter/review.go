@@ -0,0 +1,90 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang-reconciliation-service/internal/matcher"
+
+	"github.com/shopspring/decimal"
+)
+
+// PendingReviewRecord is the JSON report entry for a match held for review.
+// The match type is spelled out, as it is numeric in the match itself.
+type PendingReviewRecord struct {
+	TransactionID    string               `json:"transaction_id"`
+	StatementID      string               `json:"statement_id"`
+	MatchType        string               `json:"match_type"`
+	ConfidenceScore  float64              `json:"confidence_score"`
+	AmountDifference decimal.Decimal      `json:"amount_difference"`
+	Reason           matcher.ReviewReason `json:"reason"`
+	AmbiguityScore   float64              `json:"ambiguity_score,omitempty"`
+}
+
+func pendingReviewRecords(pending []*matcher.PendingMatch) []*PendingReviewRecord {
+	records := make([]*PendingReviewRecord, 0, len(pending))
+	for _, item := range pending {
+		records = append(records, &PendingReviewRecord{
+			TransactionID:    item.Match.Transaction.TrxID,
+			StatementID:      item.Match.BankStatement.UniqueIdentifier,
+			MatchType:        item.Match.MatchType.String(),
+			ConfidenceScore:  item.Match.ConfidenceScore,
+			AmountDifference: item.Match.AmountDifference,
+			Reason:           item.Reason,
+			AmbiguityScore:   item.AmbiguityScore,
+		})
+	}
+	return records
+}
+
+func (rg *ReportGenerator) printPendingReview(pending []*matcher.PendingMatch, writer io.Writer) {
+	fmt.Fprintf(writer, "Total Pending Review: %d\n\n", len(pending))
+
+	for i, item := range pending {
+		match := item.Match
+		fmt.Fprintf(writer, "  %d. Transaction %s <-> Statement %s (%s, Confidence: %.2f)\n",
+			i+1, match.Transaction.TrxID, match.BankStatement.UniqueIdentifier, match.MatchType, match.ConfidenceScore)
+		fmt.Fprintf(writer, "     Amounts: %s vs %s, Dates: %s vs %s, Held: %s%s\n",
+			match.Transaction.Amount.StringFixed(2),
+			match.BankStatement.Amount.StringFixed(2),
+			match.Transaction.TransactionTime.Format("2006-01-02"),
+			match.BankStatement.Date.Format("2006-01-02"),
+			item.DescribeReason(),
+			formatStatementSource(match.BankStatement))
+
+		// Limit output for very long lists
+		if i >= 9 && len(pending) > 10 {
+			fmt.Fprintf(writer, "  ... and %d more\n", len(pending)-10)
+			break
+		}
+	}
+}
+
+// writePendingReviewRecords writes one CSV row per match held for review
+func (rg *ReportGenerator) writePendingReviewRecords(csvWriter *reportCSVWriter, pending []*matcher.PendingMatch) error {
+	for _, item := range pending {
+		match := item.Match
+		notes := append([]string{fmt.Sprintf("Pending review with %s: %s", match.BankStatement.UniqueIdentifier, item.DescribeReason())}, match.Reasons...)
+		record := []string{
+			"Pending Review",
+			match.Transaction.TrxID,
+			match.Transaction.Amount.String(),
+			string(match.Transaction.Type),
+			match.Transaction.TransactionTime.Format("2006-01-02 15:04:05"),
+			"Pending Review",
+			match.BankStatement.SourceFile,
+			match.MatchType.String(),
+			fmt.Sprintf("%.2f", match.ConfidenceScore),
+			match.AmountDifference.String(),
+			match.DateDifference.String(),
+			strings.Join(notes, "; "),
+		}
+		record = append(record, fxColumns(match)...)
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write pending review record: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+// Package review reads the decisions reviewers make on matches held for
+// review. A match scoring below the auto-accept score, or made on a day with
+// ambiguous same-day matches, is neither matched nor unmatched until a
+// decision accepts or rejects it.
+//
+// Decisions are loaded from a CSV file with one decision per row:
+//
+//	action,trx_id,statement_id,reviewer,note
+//	accept,TX1001,BS-7731,alice,
+//	reject,TX1002,BS-7732,alice,different customer
+//
+// or from a YAML (or JSON) file with the same fields:
+//
+//	decisions:
+//	  - action: accept
+//	    trx_id: TX1001
+//	    statement_id: BS-7731
+//	    reviewer: alice
+//
+// An accepted match is counted as matched; a rejected match leaves both of its
+// items unmatched.
+//
+// Example usage:
+//
+//	decisions, err := review.Load("decisions.csv")
+//	if decision := decisions.For(trxID, statementID); decision != nil { ... }
+package review
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Actions of a decision as written in the CSV action column
+const (
+	ActionAccept = "accept"
+	ActionReject = "reject"
+)
+
+// Decisions is the collection of decisions applied to a run
+type Decisions struct {
+	Decisions []*Decision `yaml:"decisions" json:"decisions"`
+}
+
+// Decision accepts or rejects the match of a transaction with a bank
+// statement, named by their identifiers
+type Decision struct {
+	Action      string `yaml:"action" json:"action"`
+	TrxID       string `yaml:"trx_id" json:"trx_id"`
+	StatementID string `yaml:"statement_id" json:"statement_id"`
+	Reviewer    string `yaml:"reviewer" json:"reviewer,omitempty"`
+	Note        string `yaml:"note" json:"note,omitempty"`
+}
+
+// Load reads decisions from a file. The format is chosen by extension: .csv,
+// or .yaml, .yml and .json.
+func Load(path string) (*Decisions, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open decisions file: %w", err)
+	}
+	defer file.Close()
+
+	var decisions *Decisions
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		decisions, err = ReadCSV(file)
+	case ".yaml", ".yml", ".json":
+		decisions, err = ReadYAML(file)
+	default:
+		return nil, fmt.Errorf("unsupported decisions file %s: expected .csv, .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read decisions file %s: %w", path, err)
+	}
+	return decisions, nil
+}
+
+// ReadCSV reads decisions in CSV form. The header must name the action,
+// trx_id and statement_id columns; the other columns are optional and may be
+// in any order.
+func ReadCSV(r io.Reader) (*Decisions, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"action", "trx_id", "statement_id"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing required column '%s'", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	decisions := &Decisions{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		decision := &Decision{
+			Action:      strings.ToLower(field(record, "action")),
+			TrxID:       field(record, "trx_id"),
+			StatementID: field(record, "statement_id"),
+			Reviewer:    field(record, "reviewer"),
+			Note:        field(record, "note"),
+		}
+		if err := decision.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		decisions.Decisions = append(decisions.Decisions, decision)
+	}
+
+	if err := decisions.Validate(); err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// ReadYAML reads decisions in YAML form. JSON is accepted as well, being a
+// subset of YAML.
+func ReadYAML(r io.Reader) (*Decisions, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := &Decisions{}
+	if err := yaml.Unmarshal(data, decisions); err != nil {
+		return nil, err
+	}
+
+	for i, decision := range decisions.Decisions {
+		decision.Action = strings.ToLower(decision.Action)
+		if err := decision.validate(); err != nil {
+			return nil, fmt.Errorf("decision #%d: %w", i+1, err)
+		}
+	}
+
+	if err := decisions.Validate(); err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
+
+// Validate checks the decisions and rejects a pair decided more than once.
+// Load, ReadCSV and ReadYAML call it already.
+func (d *Decisions) Validate() error {
+	seen := make(map[string]bool, len(d.Decisions))
+	for _, decision := range d.Decisions {
+		if err := decision.validate(); err != nil {
+			return err
+		}
+		key := decision.TrxID + "\x00" + decision.StatementID
+		if seen[key] {
+			return fmt.Errorf("pair %s/%s is decided more than once", decision.TrxID, decision.StatementID)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func (d *Decision) validate() error {
+	if d.TrxID == "" || d.StatementID == "" {
+		return fmt.Errorf("trx_id and statement_id are required")
+	}
+	switch d.Action {
+	case ActionAccept, ActionReject:
+		return nil
+	case "":
+		return fmt.Errorf("missing action for pair %s/%s", d.TrxID, d.StatementID)
+	default:
+		return fmt.Errorf("unknown action '%s' for pair %s/%s (must be accept or reject)", d.Action, d.TrxID, d.StatementID)
+	}
+}
+
+// For returns the decision on a pair, or nil if there is none
+func (d *Decisions) For(trxID, statementID string) *Decision {
+	for _, decision := range d.Decisions {
+		if decision.TrxID == trxID && decision.StatementID == statementID {
+			return decision
+		}
+	}
+	return nil
+}
+
+// Len returns the number of decisions
+func (d *Decisions) Len() int {
+	return len(d.Decisions)
+}
@@ -0,0 +1,101 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleCSV = `action,trx_id,statement_id,reviewer,note
+accept,TX001,BS001,alice,
+Reject,TX002,BS002,alice,different customer
+`
+
+const sampleYAML = `decisions:
+  - action: accept
+    trx_id: TX001
+    statement_id: BS001
+    reviewer: alice
+  - action: reject
+    trx_id: TX002
+    statement_id: BS002
+    reviewer: alice
+    note: different customer
+`
+
+func TestReadDecisions(t *testing.T) {
+	readers := map[string]func(string) (*Decisions, error){
+		"csv":  func(input string) (*Decisions, error) { return ReadCSV(strings.NewReader(input)) },
+		"yaml": func(input string) (*Decisions, error) { return ReadYAML(strings.NewReader(input)) },
+	}
+	inputs := map[string]string{"csv": sampleCSV, "yaml": sampleYAML}
+
+	for format, read := range readers {
+		t.Run(format, func(t *testing.T) {
+			decisions, err := read(inputs[format])
+			if err != nil {
+				t.Fatalf("Failed to read decisions: %v", err)
+			}
+			if decisions.Len() != 2 {
+				t.Fatalf("Expected 2 decisions, got %d", decisions.Len())
+			}
+
+			if decision := decisions.For("TX001", "BS001"); decision == nil || decision.Action != ActionAccept || decision.Reviewer != "alice" {
+				t.Errorf("Expected TX001/BS001 accepted by alice, got %+v", decision)
+			}
+			if decision := decisions.For("TX002", "BS002"); decision == nil || decision.Action != ActionReject || decision.Note != "different customer" {
+				t.Errorf("Expected TX002/BS002 rejected with a note, got %+v", decision)
+			}
+			if decisions.For("TX001", "BS002") != nil {
+				t.Error("Expected no decision for TX001/BS002")
+			}
+		})
+	}
+}
+
+func TestReadCSV_Errors(t *testing.T) {
+	header := "action,trx_id,statement_id,reviewer,note\n"
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"missing statement column", "action,trx_id\naccept,TX1\n", "statement_id"},
+		{"unknown action", header + "approve,TX1,BS1,,\n", "line 2: unknown action"},
+		{"missing action", header + ",TX1,BS1,,\n", "line 2: missing action"},
+		{"incomplete pair", header + "accept,TX1,,,\n", "trx_id and statement_id are required"},
+		{"decided twice", header + "accept,TX1,BS1,,\nreject,TX1,BS1,,\n", "decided more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"decisions.csv": sampleCSV, "decisions.yml": sampleYAML, "decisions.txt": sampleCSV} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"decisions.csv", "decisions.yml"} {
+		decisions, err := Load(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Load(%s) failed: %v", name, err)
+		} else if decisions.Len() != 2 {
+			t.Errorf("Load(%s): expected 2 decisions, got %d", name, decisions.Len())
+		}
+	}
+
+	if _, err := Load(filepath.Join(dir, "decisions.txt")); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected unsupported file error, got %v", err)
+	}
+}
@@ -0,0 +1,336 @@
+// Package rules provides manual overrides for the matching engine: pairs an
+// operator has confirmed by hand, pairs that must never be matched, and bank
+// statements that should be left out of matching altogether (bank fees,
+// internal transfers and similar noise).
+//
+// Rules are loaded from a CSV file with one rule per row:
+//
+//	action,trx_id,statement_id,id_prefix,amount,description,name
+//	match,TX1001,BS-7731,,,,refund booked under wrong reference
+//	exclude,TX1002,BS-7732,,,,
+//	ignore,,,FEE-,,,bank fees
+//	ignore,,,,2.50,(?i)monthly charge,account fee
+//
+// or from a YAML (or JSON) file with the same fields grouped by action:
+//
+//	match:
+//	  - trx_id: TX1001
+//	    statement_id: BS-7731
+//	    name: refund booked under wrong reference
+//	ignore:
+//	  - id_prefix: FEE-
+//
+// A match rule pairs a transaction with a statement before any scoring takes
+// place. An exclude rule forbids a pair. An ignore rule removes every bank
+// statement it matches from the candidate pools; the criteria of one ignore
+// rule must all match. Rules without a name are named after their position in
+// the file.
+//
+// Example usage:
+//
+//	set, err := rules.Load("rules.csv")
+//	if rule := set.IgnoreRuleFor(stmt); rule != nil { ... }
+package rules
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// Actions of a rule as written in the CSV action column
+const (
+	ActionMatch   = "match"
+	ActionExclude = "exclude"
+	ActionIgnore  = "ignore"
+)
+
+// Set is the collection of rules applied to a reconciliation
+type Set struct {
+	Matches    []*PairRule   `yaml:"match" json:"match,omitempty"`
+	Exclusions []*PairRule   `yaml:"exclude" json:"exclude,omitempty"`
+	Ignores    []*IgnoreRule `yaml:"ignore" json:"ignore,omitempty"`
+}
+
+// PairRule names a transaction and a bank statement by their identifiers. It
+// is used both for forced matches and for exclusions.
+type PairRule struct {
+	Name        string `yaml:"name" json:"name,omitempty"`
+	TrxID       string `yaml:"trx_id" json:"trx_id"`
+	StatementID string `yaml:"statement_id" json:"statement_id"`
+}
+
+// IgnoreRule selects bank statements to leave out of matching. Amount is
+// compared ignoring sign and Description is a regular expression matched
+// against the statement description.
+type IgnoreRule struct {
+	Name        string `yaml:"name" json:"name,omitempty"`
+	IDPrefix    string `yaml:"id_prefix" json:"id_prefix,omitempty"`
+	Amount      string `yaml:"amount" json:"amount,omitempty"`
+	Description string `yaml:"description" json:"description,omitempty"`
+
+	amount      *decimal.Decimal
+	description *regexp.Regexp
+}
+
+// Load reads a rule set from a file. The format is chosen by extension:
+// .csv, or .yaml, .yml and .json.
+func Load(path string) (*Set, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rules file: %w", err)
+	}
+	defer file.Close()
+
+	var set *Set
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		set, err = ReadCSV(file)
+	case ".yaml", ".yml", ".json":
+		set, err = ReadYAML(file)
+	default:
+		return nil, fmt.Errorf("unsupported rules file %s: expected .csv, .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file %s: %w", path, err)
+	}
+	return set, nil
+}
+
+// ReadCSV reads a rule set in CSV form. The header must name the action
+// column; the other columns are optional and may be in any order.
+func ReadCSV(r io.Reader) (*Set, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["action"]; !ok {
+		return nil, fmt.Errorf("missing required column 'action'")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	set := &Set{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		name := field(record, "name")
+		if name == "" {
+			name = fmt.Sprintf("line %d", line)
+		}
+
+		switch action := strings.ToLower(field(record, "action")); action {
+		case ActionMatch, ActionExclude:
+			rule := &PairRule{
+				Name:        name,
+				TrxID:       field(record, "trx_id"),
+				StatementID: field(record, "statement_id"),
+			}
+			if action == ActionMatch {
+				set.Matches = append(set.Matches, rule)
+			} else {
+				set.Exclusions = append(set.Exclusions, rule)
+			}
+		case ActionIgnore:
+			set.Ignores = append(set.Ignores, &IgnoreRule{
+				Name:        name,
+				IDPrefix:    field(record, "id_prefix"),
+				Amount:      field(record, "amount"),
+				Description: field(record, "description"),
+			})
+		case "":
+			return nil, fmt.Errorf("line %d: missing action", line)
+		default:
+			return nil, fmt.Errorf("line %d: unknown action '%s' (must be match, exclude or ignore)", line, action)
+		}
+	}
+
+	if err := set.Compile(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// ReadYAML reads a rule set in YAML form. JSON is accepted as well, being a
+// subset of YAML.
+func ReadYAML(r io.Reader) (*Set, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{}
+	if err := yaml.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+
+	for i, rule := range set.Matches {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("match #%d", i+1)
+		}
+	}
+	for i, rule := range set.Exclusions {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("exclude #%d", i+1)
+		}
+	}
+	for i, rule := range set.Ignores {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("ignore #%d", i+1)
+		}
+	}
+
+	if err := set.Compile(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Compile validates the rules and prepares the ignore criteria. It must be
+// called before the set is used when rules are built in code; Load, ReadCSV
+// and ReadYAML call it already.
+func (s *Set) Compile() error {
+	matchedTx := make(map[string]string)
+	matchedStmt := make(map[string]string)
+	for _, rule := range s.Matches {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+		if other, ok := matchedTx[rule.TrxID]; ok {
+			return fmt.Errorf("rule %q: transaction %s is already matched by rule %q", rule.Name, rule.TrxID, other)
+		}
+		if other, ok := matchedStmt[rule.StatementID]; ok {
+			return fmt.Errorf("rule %q: statement %s is already matched by rule %q", rule.Name, rule.StatementID, other)
+		}
+		matchedTx[rule.TrxID] = rule.Name
+		matchedStmt[rule.StatementID] = rule.Name
+	}
+
+	for _, rule := range s.Exclusions {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+		if s.MatchRuleFor(rule.TrxID, rule.StatementID) != nil {
+			return fmt.Errorf("rule %q: pair %s/%s is both matched and excluded", rule.Name, rule.TrxID, rule.StatementID)
+		}
+	}
+
+	for _, rule := range s.Ignores {
+		if err := rule.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PairRule) validate() error {
+	if r.TrxID == "" || r.StatementID == "" {
+		return fmt.Errorf("rule %q: trx_id and statement_id are required", r.Name)
+	}
+	return nil
+}
+
+func (r *IgnoreRule) compile() error {
+	if r.IDPrefix == "" && r.Amount == "" && r.Description == "" {
+		return fmt.Errorf("rule %q: an ignore rule needs an id_prefix, amount or description", r.Name)
+	}
+
+	r.amount = nil
+	if r.Amount != "" {
+		amount, err := decimal.NewFromString(r.Amount)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid amount '%s': %w", r.Name, r.Amount, err)
+		}
+		amount = amount.Abs()
+		r.amount = &amount
+	}
+
+	r.description = nil
+	if r.Description != "" {
+		description, err := regexp.Compile(r.Description)
+		if err != nil {
+			return fmt.Errorf("rule %q: invalid description pattern: %w", r.Name, err)
+		}
+		r.description = description
+	}
+	return nil
+}
+
+// Matches reports whether the rule selects the statement
+func (r *IgnoreRule) Matches(stmt *models.BankStatement) bool {
+	if r.IDPrefix != "" && !strings.HasPrefix(stmt.UniqueIdentifier, r.IDPrefix) {
+		return false
+	}
+	if r.amount != nil && !stmt.Amount.Abs().Equal(*r.amount) {
+		return false
+	}
+	if r.description != nil && !r.description.MatchString(stmt.Description) {
+		return false
+	}
+	return true
+}
+
+// MatchRuleFor returns the match rule for a pair, or nil if there is none
+func (s *Set) MatchRuleFor(trxID, statementID string) *PairRule {
+	return findPair(s.Matches, trxID, statementID)
+}
+
+// ExclusionFor returns the exclude rule for a pair, or nil if there is none
+func (s *Set) ExclusionFor(trxID, statementID string) *PairRule {
+	return findPair(s.Exclusions, trxID, statementID)
+}
+
+// IgnoreRuleFor returns the first ignore rule that selects the statement, or
+// nil if it is not ignored
+func (s *Set) IgnoreRuleFor(stmt *models.BankStatement) *IgnoreRule {
+	for _, rule := range s.Ignores {
+		if rule.Matches(stmt) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Len returns the total number of rules in the set
+func (s *Set) Len() int {
+	return len(s.Matches) + len(s.Exclusions) + len(s.Ignores)
+}
+
+func findPair(pairs []*PairRule, trxID, statementID string) *PairRule {
+	for _, rule := range pairs {
+		if rule.TrxID == trxID && rule.StatementID == statementID {
+			return rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+const sampleCSV = `action,trx_id,statement_id,id_prefix,amount,description,name
+match,TX001,BS001,,,,late refund
+exclude,TX002,BS002,,,,
+ignore,,,FEE-,,,bank fees
+ignore,,,,2.50,(?i)monthly charge,
+`
+
+const sampleYAML = `match:
+  - trx_id: TX001
+    statement_id: BS001
+    name: late refund
+exclude:
+  - trx_id: TX002
+    statement_id: BS002
+ignore:
+  - id_prefix: FEE-
+    name: bank fees
+  - amount: 2.50
+    description: (?i)monthly charge
+`
+
+func TestReadRules(t *testing.T) {
+	readers := map[string]func(string) (*Set, error){
+		"csv":  func(input string) (*Set, error) { return ReadCSV(strings.NewReader(input)) },
+		"yaml": func(input string) (*Set, error) { return ReadYAML(strings.NewReader(input)) },
+	}
+	inputs := map[string]string{"csv": sampleCSV, "yaml": sampleYAML}
+
+	for format, read := range readers {
+		t.Run(format, func(t *testing.T) {
+			set, err := read(inputs[format])
+			if err != nil {
+				t.Fatalf("Failed to read rules: %v", err)
+			}
+			if len(set.Matches) != 1 || len(set.Exclusions) != 1 || len(set.Ignores) != 2 {
+				t.Fatalf("Expected 1 match, 1 exclusion and 2 ignores, got %d, %d, %d",
+					len(set.Matches), len(set.Exclusions), len(set.Ignores))
+			}
+
+			if rule := set.MatchRuleFor("TX001", "BS001"); rule == nil || rule.Name != "late refund" {
+				t.Errorf("Expected match rule 'late refund', got %+v", rule)
+			}
+			if set.ExclusionFor("TX002", "BS002") == nil {
+				t.Error("Expected TX002/BS002 to be excluded")
+			}
+			if set.ExclusionFor("TX001", "BS002") != nil {
+				t.Error("Expected TX001/BS002 not to be excluded")
+			}
+			if set.Exclusions[0].Name == "" || set.Ignores[1].Name == "" {
+				t.Error("Expected unnamed rules to get a default name")
+			}
+		})
+	}
+}
+
+func TestReadCSV_Errors(t *testing.T) {
+	header := "action,trx_id,statement_id,id_prefix,amount,description,name\n"
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"missing action column", "trx_id,statement_id\nTX1,BS1\n", "action"},
+		{"unknown action", header + "pair,TX1,BS1,,,,\n", "line 2: unknown action"},
+		{"incomplete pair", header + "match,TX1,,,,,\n", "trx_id and statement_id are required"},
+		{"empty ignore", header + "ignore,,,,,,\n", "needs an id_prefix, amount or description"},
+		{"invalid amount", header + "ignore,,,,abc,,\n", "invalid amount"},
+		{"invalid pattern", header + "ignore,,,,,([,\n", "invalid description pattern"},
+		{"transaction matched twice", header + "match,TX1,BS1,,,,\nmatch,TX1,BS2,,,,\n", "already matched"},
+		{"matched and excluded", header + "match,TX1,BS1,,,,\nexclude,TX1,BS1,,,,\n", "both matched and excluded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestIgnoreRuleFor(t *testing.T) {
+	set, err := ReadCSV(strings.NewReader(sampleCSV))
+	if err != nil {
+		t.Fatalf("Failed to read rules: %v", err)
+	}
+
+	date := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		stmt     *models.BankStatement
+		wantRule string
+	}{
+		{"id prefix", &models.BankStatement{UniqueIdentifier: "FEE-001", Amount: decimal.NewFromInt(-10), Date: date}, "bank fees"},
+		{"amount and description", &models.BankStatement{UniqueIdentifier: "BS900", Amount: decimal.NewFromFloat(-2.5), Date: date, Description: "Monthly Charge Jan"}, "line 5"},
+		{"amount without description", &models.BankStatement{UniqueIdentifier: "BS901", Amount: decimal.NewFromFloat(-2.5), Date: date}, ""},
+		{"description with other amount", &models.BankStatement{UniqueIdentifier: "BS902", Amount: decimal.NewFromInt(5), Date: date, Description: "monthly charge"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := set.IgnoreRuleFor(tt.stmt)
+			got := ""
+			if rule != nil {
+				got = rule.Name
+			}
+			if got != tt.wantRule {
+				t.Errorf("Expected rule %q, got %q", tt.wantRule, got)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"rules.csv": sampleCSV, "rules.yml": sampleYAML, "rules.txt": sampleCSV} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	for _, name := range []string{"rules.csv", "rules.yml"} {
+		set, err := Load(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("Load(%s) failed: %v", name, err)
+		} else if set.Len() != 4 {
+			t.Errorf("Load(%s): expected 4 rules, got %d", name, set.Len())
+		}
+	}
+
+	if _, err := Load(filepath.Join(dir, "rules.txt")); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Errorf("Expected unsupported file error, got %v", err)
+	}
+}
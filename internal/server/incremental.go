@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// IncrementalTransactionsRequest is the body of POST /api/v1/incremental/transactions
+type IncrementalTransactionsRequest struct {
+	Transactions []*models.Transaction `json:"transactions"`
+}
+
+// IncrementalStatementsRequest is the body of POST /api/v1/incremental/statements
+type IncrementalStatementsRequest struct {
+	Statements []*models.BankStatement `json:"statements"`
+}
+
+// IncrementalMatch describes a match made by the incremental reconciler
+type IncrementalMatch struct {
+	TransactionID    string   `json:"transaction_id"`
+	StatementID      string   `json:"statement_id"`
+	BankName         string   `json:"bank_name,omitempty"`
+	MatchType        string   `json:"match_type"`
+	ConfidenceScore  float64  `json:"confidence_score"`
+	AmountDifference string   `json:"amount_difference"`
+	Reasons          []string `json:"reasons,omitempty"`
+	Rule             string   `json:"rule,omitempty"`
+}
+
+// IncrementalBatchResponse is returned for every batch of items added
+type IncrementalBatchResponse struct {
+	Matches  []*IncrementalMatch      `json:"matches"`
+	Open     int                      `json:"open"`
+	Rejected []string                 `json:"rejected,omitempty"`
+	Stats    matcher.IncrementalStats `json:"stats"`
+}
+
+// IncrementalStateResponse lists the open items and counters
+type IncrementalStateResponse struct {
+	Stats        matcher.IncrementalStats `json:"stats"`
+	Transactions []*models.Transaction    `json:"open_transactions"`
+	Statements   []*models.BankStatement  `json:"open_statements"`
+}
+
+func (s *Server) handleAddIncrementalTransactions(w http.ResponseWriter, r *http.Request) {
+	var request IncrementalTransactionsRequest
+	if err := decodeStrict(http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes), &request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	batch, err := s.config.Incremental.AddTransactions(request.Transactions)
+	s.writeIncrementalBatch(w, batch, err)
+}
+
+func (s *Server) handleAddIncrementalStatements(w http.ResponseWriter, r *http.Request) {
+	var request IncrementalStatementsRequest
+	if err := decodeStrict(http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes), &request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	batch, err := s.config.Incremental.AddStatements(request.Statements)
+	s.writeIncrementalBatch(w, batch, err)
+}
+
+func (s *Server) handleGetIncremental(w http.ResponseWriter, r *http.Request) {
+	incremental := s.config.Incremental.Reconciler()
+	writeJSON(w, http.StatusOK, &IncrementalStateResponse{
+		Stats:        incremental.Stats(),
+		Transactions: incremental.OpenTransactions(),
+		Statements:   incremental.OpenStatements(),
+	})
+}
+
+// writeIncrementalBatch reports the outcome of a batch. The items were
+// matched even when the snapshot could not be saved, so the batch is
+// returned along with the error.
+func (s *Server) writeIncrementalBatch(w http.ResponseWriter, batch *reconciler.IncrementalBatch, err error) {
+	response := &IncrementalBatchResponse{
+		Matches:  make([]*IncrementalMatch, 0, len(batch.Matches)),
+		Open:     batch.Open,
+		Rejected: batch.Rejected,
+		Stats:    s.config.Incremental.Reconciler().Stats(),
+	}
+	for _, match := range batch.Matches {
+		response.Matches = append(response.Matches, &IncrementalMatch{
+			TransactionID:    match.Transaction.TrxID,
+			StatementID:      match.BankStatement.UniqueIdentifier,
+			BankName:         match.BankStatement.BankName,
+			MatchType:        match.MatchType.String(),
+			ConfidenceScore:  match.ConfidenceScore,
+			AmountDifference: match.AmountDifference.String(),
+			Reasons:          match.Reasons,
+			Rule:             match.Rule,
+		})
+	}
+
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to save incremental snapshot")
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
+			"error":  fmt.Sprintf("items were matched but the snapshot could not be saved: %v", err),
+			"result": response,
+		})
+		return
+	}
+
+	s.logger.WithFields(logger.Fields{
+		"matches":  len(response.Matches),
+		"open":     response.Open,
+		"rejected": len(response.Rejected),
+	}).Debug("Incremental batch added")
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,622 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-reconciliation-service/internal/fx"
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/internal/store"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// JobStatus is the lifecycle state of a reconciliation job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobRequest is the JSON form of a job submission. Files are server-side
+// paths relative to the data directory; with a multipart submission they may
+// also be uploaded as the system_file, bank_files and fx_rates parts, and the
+// request itself is sent as the "request" field.
+type JobRequest struct {
+	SystemFile string   `json:"system_file,omitempty"`
+	BankFiles  []string `json:"bank_files,omitempty"`
+
+	// BankProfiles binds a bank file, named as in BankFiles or by its upload
+	// file name, to a bank profile
+	BankProfiles map[string]string `json:"bank_profiles,omitempty"`
+
+	FXRatesFile string `json:"fx_rates,omitempty"`
+	StartDate   string `json:"start_date,omitempty"` // YYYY-MM-DD
+	EndDate     string `json:"end_date,omitempty"`   // YYYY-MM-DD, inclusive
+
+	// Matching holds matcher.MatchingConfig fields that override the server defaults
+	Matching json.RawMessage `json:"matching,omitempty"`
+
+	// Options holds reconciler.ReconciliationOptions fields that override the defaults
+	Options json.RawMessage `json:"options,omitempty"`
+
+	// CarryForward matches the open items left by the previous run of Ledger
+	// along with the job's input. It requires the server to keep run history.
+	CarryForward bool   `json:"carry_forward,omitempty"`
+	Ledger       string `json:"ledger,omitempty"`
+}
+
+// JobStatusResponse describes a job as returned by the API
+type JobStatusResponse struct {
+	ID          string                             `json:"id"`
+	Status      JobStatus                          `json:"status"`
+	CreatedAt   time.Time                          `json:"created_at"`
+	StartedAt   *time.Time                         `json:"started_at,omitempty"`
+	CompletedAt *time.Time                         `json:"completed_at,omitempty"`
+	Progress    *reconciler.ReconciliationProgress `json:"progress,omitempty"`
+	Summary     *reconciler.ResultSummary          `json:"summary,omitempty"`
+	Error       string                             `json:"error,omitempty"`
+	ResultURL   string                             `json:"result_url,omitempty"`
+	RunID       string                             `json:"run_id,omitempty"`
+}
+
+// Job is a reconciliation submitted to the server
+type Job struct {
+	ID string
+
+	mu          sync.RWMutex
+	status      JobStatus
+	createdAt   time.Time
+	startedAt   time.Time
+	completedAt time.Time
+	progress    *reconciler.ReconciliationProgress
+	result      *reconciler.ReconciliationResult
+	runID       string
+	err         string
+}
+
+// jobInput is a parsed submission: the request plus any uploaded file paths
+type jobInput struct {
+	request     JobRequest
+	systemFile  string
+	bankFiles   map[string]string // upload file name -> stored path
+	bankOrder   []string
+	fxRatesFile string
+}
+
+// jobPlan is everything a job needs to run
+type jobPlan struct {
+	request      *reconciler.ReconciliationRequest
+	matching     *matcher.MatchingConfig
+	options      *reconciler.ReconciliationOptions
+	ledger       string
+	carryForward bool
+}
+
+func (s *Server) newJob() (*Job, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return &Job{
+		ID:        hex.EncodeToString(id),
+		status:    JobQueued,
+		createdAt: time.Now(),
+	}, nil
+}
+
+func (s *Server) readJSONJob(w http.ResponseWriter, r *http.Request) (*jobInput, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes)
+	input := &jobInput{}
+	if err := decodeStrict(r.Body, &input.request); err != nil {
+		return nil, fmt.Errorf("invalid job request: %w", err)
+	}
+	return input, nil
+}
+
+func (s *Server) readMultipartJob(w http.ResponseWriter, r *http.Request, jobID string) (*jobInput, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadBytes)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, fmt.Errorf("invalid multipart request: %w", err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	input := &jobInput{bankFiles: make(map[string]string)}
+	if raw := r.FormValue("request"); raw != "" {
+		if err := decodeStrict(strings.NewReader(raw), &input.request); err != nil {
+			return nil, fmt.Errorf("invalid job request: %w", err)
+		}
+	}
+
+	jobDir := filepath.Join(s.config.UploadDir, jobID)
+	files := r.MultipartForm.File
+
+	if len(files["system_file"]) > 1 || len(files["fx_rates"]) > 1 {
+		return nil, fmt.Errorf("upload at most one system_file and one fx_rates file")
+	}
+	for _, header := range files["system_file"] {
+		path, err := saveUpload(header, filepath.Join(jobDir, "system"))
+		if err != nil {
+			return nil, err
+		}
+		input.systemFile = path
+	}
+	for _, header := range files["fx_rates"] {
+		path, err := saveUpload(header, filepath.Join(jobDir, "fx"))
+		if err != nil {
+			return nil, err
+		}
+		input.fxRatesFile = path
+	}
+	for _, header := range files["bank_files"] {
+		name := filepath.Base(header.Filename)
+		if _, exists := input.bankFiles[name]; exists {
+			return nil, fmt.Errorf("bank file uploaded more than once: %s", name)
+		}
+		path, err := saveUpload(header, filepath.Join(jobDir, "banks"))
+		if err != nil {
+			return nil, err
+		}
+		input.bankFiles[name] = path
+		input.bankOrder = append(input.bankOrder, name)
+	}
+
+	return input, nil
+}
+
+// planJob resolves the files, bank configs and matching overrides of a submission
+func (s *Server) planJob(input *jobInput) (*jobPlan, error) {
+	req := input.request
+
+	systemFile := input.systemFile
+	switch {
+	case systemFile != "" && req.SystemFile != "":
+		return nil, fmt.Errorf("system file given both as an upload and as a path")
+	case systemFile == "" && req.SystemFile == "":
+		return nil, fmt.Errorf("system file is required")
+	case systemFile == "":
+		path, err := s.resolveServerPath(req.SystemFile)
+		if err != nil {
+			return nil, err
+		}
+		systemFile = path
+	}
+
+	// Bank files keep the name the client used so profiles can refer to them
+	var bankPaths []string
+	pathsByName := make(map[string]string)
+	for _, name := range req.BankFiles {
+		if _, exists := pathsByName[name]; exists {
+			return nil, fmt.Errorf("bank file listed more than once: %s", name)
+		}
+		path, err := s.resolveServerPath(name)
+		if err != nil {
+			return nil, err
+		}
+		pathsByName[name] = path
+		bankPaths = append(bankPaths, path)
+	}
+	for _, name := range input.bankOrder {
+		if _, exists := pathsByName[name]; exists {
+			return nil, fmt.Errorf("bank file %s given both as an upload and as a path", name)
+		}
+		pathsByName[name] = input.bankFiles[name]
+		bankPaths = append(bankPaths, input.bankFiles[name])
+	}
+	if len(bankPaths) == 0 {
+		return nil, fmt.Errorf("at least one bank file is required")
+	}
+
+	profiles := make(map[string]string)
+	for name, profile := range req.BankProfiles {
+		path, ok := pathsByName[name]
+		if !ok {
+			return nil, fmt.Errorf("bank_profiles refers to unknown bank file: %s", name)
+		}
+		profiles[path] = profile
+	}
+	bankConfigs, err := s.config.ResolveBankConfigs(bankPaths, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CarryForward && s.config.Store == nil {
+		return nil, fmt.Errorf("carry_forward requires the server to keep run history")
+	}
+
+	matching, err := s.matchingConfig(req, input.fxRatesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	options := reconciler.DefaultReconciliationOptions()
+	if len(req.Options) > 0 {
+		if err := decodeStrict(bytes.NewReader(req.Options), options); err != nil {
+			return nil, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+	options.CustomMatchingConfig = matching
+
+	return &jobPlan{
+		request: &reconciler.ReconciliationRequest{
+			SystemFile:        systemFile,
+			BankFiles:         bankPaths,
+			StartDate:         startDate,
+			EndDate:           endDate,
+			TransactionConfig: s.config.TransactionConfig,
+			BankConfigs:       bankConfigs,
+		},
+		matching:     matching,
+		options:      options,
+		ledger:       req.Ledger,
+		carryForward: req.CarryForward,
+	}, nil
+}
+
+// matchingConfig applies the request's overrides to a copy of the server's
+// matching config and loads the FX rate table, if any. The copy is deep, so
+// overrides decoded into its windows and strategies never reach the server's
+// config or other jobs.
+func (s *Server) matchingConfig(req JobRequest, uploadedRates string) (*matcher.MatchingConfig, error) {
+	matching := s.config.MatchingConfig.Clone()
+	if len(req.Matching) > 0 {
+		if err := decodeStrict(bytes.NewReader(req.Matching), matching); err != nil {
+			return nil, fmt.Errorf("invalid matching overrides: %w", err)
+		}
+	}
+
+	ratesFile := uploadedRates
+	if ratesFile != "" && req.FXRatesFile != "" {
+		return nil, fmt.Errorf("fx rates given both as an upload and as a path")
+	}
+	if ratesFile == "" && req.FXRatesFile != "" {
+		path, err := s.resolveServerPath(req.FXRatesFile)
+		if err != nil {
+			return nil, err
+		}
+		ratesFile = path
+	}
+	if ratesFile != "" {
+		if matching.BaseCurrency == "" {
+			return nil, fmt.Errorf("fx rates require matching.base_currency to be set")
+		}
+		rates, err := fx.LoadRateTable(ratesFile)
+		if err != nil {
+			return nil, err
+		}
+		matching.FXRates = rates
+	}
+
+	if err := matching.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid matching config: %w", err)
+	}
+	return matching, nil
+}
+
+// resolveServerPath maps a file reference onto the data directory and
+// rejects anything outside it. Symlinks are followed for the check, so a
+// link inside the data directory cannot point out of it.
+func (s *Server) resolveServerPath(name string) (string, error) {
+	if s.config.DataDir == "" {
+		return "", fmt.Errorf("server-side file references are disabled; upload %s instead", name)
+	}
+
+	path := filepath.Clean(name)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.config.DataDir, path)
+	}
+	if !withinDir(s.config.DataDir, path) {
+		return "", fmt.Errorf("file %s is outside the data directory", name)
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("file %s does not exist", name)
+	}
+	dataDir, err := filepath.EvalSymlinks(s.config.DataDir)
+	if err != nil || !withinDir(dataDir, resolved) {
+		return "", fmt.Errorf("file %s is outside the data directory", name)
+	}
+	path = resolved
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("file %s does not exist", name)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("file %s is a directory", name)
+	}
+	return path, nil
+}
+
+// withinDir reports whether path is dir or lies beneath it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// runJob processes a job on the orchestrator once a slot is free
+func (s *Server) runJob(job *Job, plan *jobPlan) {
+	defer s.wg.Done()
+	defer s.removeUploads(job.ID)
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-s.ctx.Done():
+		job.finish(nil, "", s.ctx.Err())
+		return
+	}
+
+	job.start()
+	log := s.logger.WithField("job_id", job.ID)
+	log.Info("Reconciliation job started")
+
+	result, runID, err := s.reconcile(job, plan)
+	job.finish(result, runID, err)
+	s.evictJobs()
+
+	if err != nil {
+		log.WithError(err).Error("Reconciliation job failed")
+		return
+	}
+	log.WithFields(logger.Fields{
+		"matched":   result.Summary.MatchedTransactions,
+		"unmatched": result.Summary.UnmatchedTransactions,
+	}).Info("Reconciliation job completed")
+}
+
+// reconcile runs the job and saves it to the run history, returning the
+// result and its run ID
+func (s *Server) reconcile(job *Job, plan *jobPlan) (*reconciler.ReconciliationResult, string, error) {
+	var previous *store.Run
+	if plan.carryForward {
+		s.ledgerMu.Lock()
+		defer s.ledgerMu.Unlock()
+
+		var err error
+		previous, err = store.PreviousRun(s.config.Store, plan.ledger)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load open items: %w", err)
+		}
+		if previous != nil {
+			plan.request.CarriedTransactions = previous.OpenTransactions()
+			plan.request.CarriedStatements = previous.OpenStatements()
+		}
+	}
+
+	reconcilerConfig := *s.config.ReconcilerConfig
+
+	// The service default is the first file's config; each bank file is
+	// parsed with its own config from the request
+	service, err := reconciler.NewReconciliationService(
+		plan.request.TransactionConfig,
+		plan.request.BankConfigs[plan.request.BankFiles[0]],
+		plan.matching,
+		&reconcilerConfig,
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create reconciliation service: %w", err)
+	}
+
+	orchestrator, err := reconciler.NewReconciliationOrchestrator(service, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create orchestrator: %w", err)
+	}
+	orchestrator.AddProgressCallback(job.setProgress)
+
+	result, err := orchestrator.ProcessReconciliationWithAdvancedFeatures(s.ctx, plan.request, plan.options)
+	if err != nil {
+		return nil, "", err
+	}
+	return result.ReconciliationResult, s.saveRun(result.ReconciliationResult, plan, previous), nil
+}
+
+// saveRun stores a completed job in the run history, if the server has a
+// store, while its input files still exist to be checksummed. A failure is
+// logged but does not fail the job.
+func (s *Server) saveRun(result *reconciler.ReconciliationResult, plan *jobPlan, previous *store.Run) string {
+	if s.config.Store == nil {
+		return ""
+	}
+
+	run, err := store.NewRun(result, plan.matching)
+	if err == nil {
+		run.Ledger = plan.ledger
+		run.UpdateLedger(previous)
+		err = s.config.Store.Save(run)
+	}
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to save reconciliation run")
+		return ""
+	}
+	return run.ID
+}
+
+func (s *Server) removeUploads(jobID string) {
+	if err := os.RemoveAll(filepath.Join(s.config.UploadDir, jobID)); err != nil {
+		s.logger.WithError(err).WithField("job_id", jobID).Warn("Failed to remove uploaded files")
+	}
+}
+
+// evictJobs forgets the oldest finished jobs beyond MaxRetainedJobs
+func (s *Server) evictJobs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var finished []*Job
+	for _, job := range s.jobs {
+		if _, status, _ := job.outcome(); status == JobCompleted || status == JobFailed {
+			finished = append(finished, job)
+		}
+	}
+	excess := len(finished) - s.config.MaxRetainedJobs
+	if excess <= 0 {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].finishedAt().Before(finished[j].finishedAt())
+	})
+	for _, job := range finished[:excess] {
+		delete(s.jobs, job.ID)
+	}
+}
+
+func (j *Job) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.status = JobRunning
+	j.startedAt = time.Now()
+}
+
+// setProgress is the orchestrator progress callback. The orchestrator keeps
+// updating its progress value, so a copy is stored.
+func (j *Job) setProgress(progress *reconciler.ReconciliationProgress) {
+	snapshot := *progress
+	snapshot.Errors = append([]string(nil), progress.Errors...)
+	snapshot.Warnings = append([]string(nil), progress.Warnings...)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.progress = &snapshot
+}
+
+func (j *Job) finish(result *reconciler.ReconciliationResult, runID string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.completedAt = time.Now()
+	if err != nil {
+		j.status = JobFailed
+		j.err = err.Error()
+		return
+	}
+	j.status = JobCompleted
+	j.result = result
+	j.runID = runID
+}
+
+func (j *Job) finishedAt() time.Time {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.completedAt
+}
+
+func (j *Job) outcome() (*reconciler.ReconciliationResult, JobStatus, string) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.result, j.status, j.err
+}
+
+func (j *Job) snapshot() *JobStatusResponse {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	response := &JobStatusResponse{
+		ID:        j.ID,
+		Status:    j.status,
+		CreatedAt: j.createdAt,
+		Progress:  j.progress,
+		Error:     j.err,
+	}
+	if !j.startedAt.IsZero() {
+		startedAt := j.startedAt
+		response.StartedAt = &startedAt
+	}
+	if !j.completedAt.IsZero() {
+		completedAt := j.completedAt
+		response.CompletedAt = &completedAt
+	}
+	if j.status == JobCompleted {
+		response.Summary = j.result.Summary
+		response.ResultURL = "/api/v1/jobs/" + j.ID + "/result"
+		response.RunID = j.runID
+	}
+	return response
+}
+
+// saveUpload stores an uploaded file under dir using its base name
+func saveUpload(header *multipart.FileHeader, dir string) (string, error) {
+	name := filepath.Base(header.Filename)
+	if name == "." || name == string(filepath.Separator) {
+		return "", fmt.Errorf("uploaded file has no name")
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read upload %s: %w", name, err)
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to store upload %s: %w", name, err)
+	}
+	path := filepath.Join(dir, name)
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to store upload %s: %w", name, err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", fmt.Errorf("failed to store upload %s: %w", name, err)
+	}
+	return path, nil
+}
+
+// parseDateRange parses an optional YYYY-MM-DD range; the end date covers the whole day
+func parseDateRange(start, end string) (*time.Time, *time.Time, error) {
+	var startDate, endDate *time.Time
+	if start != "" {
+		t, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid start date format. Use YYYY-MM-DD: %w", err)
+		}
+		startDate = &t
+	}
+	if end != "" {
+		t, err := time.Parse("2006-01-02", end)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid end date format. Use YYYY-MM-DD: %w", err)
+		}
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		endDate = &t
+	}
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		return nil, nil, fmt.Errorf("start date cannot be after end date")
+	}
+	return startDate, endDate, nil
+}
+
+// decodeStrict decodes JSON and rejects unknown fields, so typos in
+// overrides are reported instead of silently ignored
+func decodeStrict(r io.Reader, value interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(value)
+}
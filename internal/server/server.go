@@ -0,0 +1,377 @@
+// Package server exposes reconciliation as an HTTP/JSON API.
+//
+// Clients submit a job by uploading a system file and bank files, or by
+// referencing files under the server's data directory, together with optional
+// matching overrides. Jobs run in the background on a
+// reconciler.ReconciliationOrchestrator; their progress is reported from the
+// orchestrator's progress callbacks and the finished result can be fetched in
+// any reporter.OutputFormat.
+//
+// Routes:
+//
+//	POST /api/v1/jobs               submit a job (JSON body or multipart upload)
+//	GET  /api/v1/jobs               list jobs
+//	GET  /api/v1/jobs/{id}          job status and progress
+//	GET  /api/v1/jobs/{id}/result   report, ?format=json|csv|console
+//	GET  /healthz                   liveness check
+//
+// When the server runs an incremental reconciler, items can also be added one
+// batch at a time and are matched against the open items immediately:
+//
+//	POST /api/v1/incremental/transactions   add transactions
+//	POST /api/v1/incremental/statements     add bank statements
+//	GET  /api/v1/incremental                counters and open items
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/internal/reporter"
+	"golang-reconciliation-service/internal/store"
+	"golang-reconciliation-service/pkg/logger"
+)
+
+// BankConfigResolver returns a bank configuration for every bank file, keyed by
+// path. profiles maps a path to the profile the client asked for, if any.
+type BankConfigResolver func(paths []string, profiles map[string]string) (map[string]*parsers.BankConfig, error)
+
+// Config holds configuration options for the API server
+type Config struct {
+	// DataDir is the directory server-side file references are resolved
+	// against. Leave empty to accept uploaded files only.
+	DataDir string
+
+	// UploadDir is where uploaded files are stored, one directory per job
+	UploadDir string
+
+	// MaxUploadBytes limits the size of a job submission or incremental
+	// request body
+	MaxUploadBytes int64
+
+	// MaxConcurrentJobs limits how many jobs run at the same time
+	MaxConcurrentJobs int
+
+	// MaxRetainedJobs limits how many finished jobs are kept in memory for
+	// their status and result; the oldest are forgotten first. Queued and
+	// running jobs are always kept, and jobs saved to Store stay in history.
+	MaxRetainedJobs int
+
+	// TransactionConfig is used to parse system files
+	TransactionConfig *parsers.TransactionParserConfig
+
+	// MatchingConfig is the base configuration job overrides are applied to
+	MatchingConfig *matcher.MatchingConfig
+
+	// ReconcilerConfig configures the reconciliation service of each job
+	ReconcilerConfig *reconciler.Config
+
+	// ReportConfig returns the report configuration for an output format
+	ReportConfig func(format reporter.OutputFormat) *reporter.ReportConfig
+
+	// ResolveBankConfigs chooses the bank configuration of each bank file
+	ResolveBankConfigs BankConfigResolver
+
+	// Store, when set, keeps a history of completed jobs
+	Store store.ResultStore
+
+	// Incremental, when set, serves the incremental matching routes
+	Incremental *reconciler.IncrementalService
+}
+
+// DefaultConfig returns a server configuration that accepts uploads only
+func DefaultConfig() *Config {
+	return &Config{
+		UploadDir:          filepath.Join(os.TempDir(), "reconciler-uploads"),
+		MaxUploadBytes:     64 << 20,
+		MaxConcurrentJobs:  2,
+		MaxRetainedJobs:    100,
+		TransactionConfig:  parsers.DefaultTransactionParserConfig(),
+		MatchingConfig:     matcher.DefaultMatchingConfig(),
+		ReconcilerConfig:   reconciler.DefaultConfig(),
+		ReportConfig:       defaultReportConfig,
+		ResolveBankConfigs: resolveRegisteredBankConfigs,
+	}
+}
+
+// Validate checks if the server configuration is valid
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.UploadDir) == "" {
+		return fmt.Errorf("upload directory cannot be empty")
+	}
+	if c.MaxUploadBytes <= 0 {
+		return fmt.Errorf("max upload size must be positive, got %d", c.MaxUploadBytes)
+	}
+	if c.MaxConcurrentJobs <= 0 {
+		return fmt.Errorf("max concurrent jobs must be positive, got %d", c.MaxConcurrentJobs)
+	}
+	if c.MaxRetainedJobs <= 0 {
+		return fmt.Errorf("max retained jobs must be positive, got %d", c.MaxRetainedJobs)
+	}
+	if c.TransactionConfig == nil || c.MatchingConfig == nil || c.ReconcilerConfig == nil {
+		return fmt.Errorf("transaction, matching and reconciler configs are required")
+	}
+	if c.ReportConfig == nil || c.ResolveBankConfigs == nil {
+		return fmt.Errorf("report config and bank config resolver are required")
+	}
+	if err := c.TransactionConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid transaction config: %w", err)
+	}
+	if err := c.MatchingConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid matching config: %w", err)
+	}
+	if err := c.ReconcilerConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid reconciler config: %w", err)
+	}
+	return nil
+}
+
+// Server runs reconciliation jobs submitted over HTTP
+type Server struct {
+	config *Config
+	logger logger.Logger
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+
+	// ledgerMu serializes carry-forward jobs, so each one sees the open
+	// items saved by the job before it
+	ledgerMu sync.Mutex
+
+	slots  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates an API server. Call Close to cancel running jobs.
+func New(config *Config) (*Server, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid server config: %w", err)
+	}
+	if config.DataDir != "" {
+		dataDir, err := filepath.Abs(config.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data directory: %w", err)
+		}
+		config.DataDir = dataDir
+	}
+	if err := os.MkdirAll(config.UploadDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		config: config,
+		logger: logger.GetGlobalLogger().WithComponent("server"),
+		jobs:   make(map[string]*Job),
+		slots:  make(chan struct{}, config.MaxConcurrentJobs),
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Handler returns the HTTP handler serving the API routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /api/v1/jobs", s.handleListJobs)
+	mux.HandleFunc("GET /api/v1/jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", s.handleGetResult)
+	if s.config.Incremental != nil {
+		mux.HandleFunc("POST /api/v1/incremental/transactions", s.handleAddIncrementalTransactions)
+		mux.HandleFunc("POST /api/v1/incremental/statements", s.handleAddIncrementalStatements)
+		mux.HandleFunc("GET /api/v1/incremental", s.handleGetIncremental)
+	}
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+// Close cancels running jobs and waits for them to stop
+func (s *Server) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.newJob()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var input *jobInput
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		input, err = s.readMultipartJob(w, r, job.ID)
+	} else {
+		input, err = s.readJSONJob(w, r)
+	}
+	if err != nil {
+		s.removeUploads(job.ID)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	plan, err := s.planJob(input)
+	if err != nil {
+		s.removeUploads(job.ID)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.runJob(job, plan)
+
+	s.logger.WithFields(logger.Fields{
+		"job_id":     job.ID,
+		"bank_files": len(plan.request.BankFiles),
+	}).Info("Reconciliation job submitted")
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job.snapshot())
+}
+
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	jobs := make([]*JobStatusResponse, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.snapshot())
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": jobs})
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job := s.lookupJob(r.PathValue("id"))
+	if job == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job not found: %s", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, job.snapshot())
+}
+
+func (s *Server) handleGetResult(w http.ResponseWriter, r *http.Request) {
+	job := s.lookupJob(r.PathValue("id"))
+	if job == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job not found: %s", r.PathValue("id")))
+		return
+	}
+
+	format := reporter.OutputFormat(strings.ToLower(r.URL.Query().Get("format")))
+	if format == "" {
+		format = reporter.FormatJSON
+	}
+	if !format.IsValid() {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid output format '%s'. Valid formats: console, json, csv", format))
+		return
+	}
+
+	result, status, jobErr := job.outcome()
+	switch status {
+	case JobCompleted:
+	case JobFailed:
+		writeError(w, http.StatusConflict, fmt.Errorf("job failed: %s", jobErr))
+		return
+	default:
+		writeError(w, http.StatusConflict, fmt.Errorf("job is %s; poll /api/v1/jobs/%s until it completes", status, job.ID))
+		return
+	}
+
+	generator, err := reporter.NewReportGenerator(s.config.ReportConfig(format))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType(format))
+	if err := generator.GenerateReport(result, w); err != nil {
+		s.logger.WithError(err).WithField("job_id", job.ID).Error("Failed to write job result")
+	}
+}
+
+func (s *Server) lookupJob(id string) *Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jobs[id]
+}
+
+// defaultReportConfig includes every section and disables ANSI colours, which
+// are of no use to HTTP clients
+func defaultReportConfig(format reporter.OutputFormat) *reporter.ReportConfig {
+	config := reporter.DefaultReportConfig()
+	config.Format = format
+	config.UseColors = false
+	config.IncludeMatchedTransactions = true
+	return config
+}
+
+// resolveRegisteredBankConfigs uses the requested profile from the parser's
+// profile registry, or detects the layout from the file headers
+func resolveRegisteredBankConfigs(paths []string, profiles map[string]string) (map[string]*parsers.BankConfig, error) {
+	configs := make(map[string]*parsers.BankConfig, len(paths))
+	for _, path := range paths {
+		var bankConfig *parsers.BankConfig
+		if name := profiles[path]; name != "" {
+			bankConfig = parsers.GetBankConfig(name)
+			if bankConfig == nil {
+				return nil, fmt.Errorf("unknown bank profile: %s", name)
+			}
+		} else {
+			parser, err := parsers.NewBankStatementParser(parsers.StandardBankConfig)
+			if err != nil {
+				return nil, err
+			}
+			if bankConfig, err = parser.DetectBankFormat(path); err != nil {
+				return nil, fmt.Errorf("failed to detect format of %s: %w", filepath.Base(path), err)
+			}
+		}
+
+		copied := *bankConfig
+		configs[path] = &copied
+	}
+	return configs, nil
+}
+
+func contentType(format reporter.OutputFormat) string {
+	switch format {
+	case reporter.FormatJSON:
+		return "application/json"
+	case reporter.FormatCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
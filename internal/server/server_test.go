@@ -0,0 +1,502 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/reconciler"
+	"golang-reconciliation-service/internal/store"
+)
+
+const (
+	testTransactions = `trxID,amount,type,transactionTime
+TX001,100.50,CREDIT,2024-01-15T10:30:00Z
+TX002,250.00,DEBIT,2024-01-16T14:20:00Z
+TX003,75.25,CREDIT,2024-01-17T09:15:00Z
+`
+	testStatements = `unique_identifier,amount,date
+BS001,100.50,2024-01-15
+BS002,-250.00,2024-01-16
+`
+)
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server, string) {
+	t.Helper()
+	return newTestServerWithStore(t, nil)
+}
+
+func newTestServerWithStore(t *testing.T, resultStore store.ResultStore) (*Server, *httptest.Server, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	for name, content := range map[string]string{
+		"transactions.csv": testTransactions,
+		"statements.csv":   testStatements,
+	} {
+		if err := os.WriteFile(filepath.Join(dataDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.DataDir = dataDir
+	config.UploadDir = t.TempDir()
+	config.Store = resultStore
+
+	srv, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		srv.Close()
+	})
+
+	return srv, httpServer, dataDir
+}
+
+func decodeResponse(t *testing.T, resp *http.Response, value interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
+
+// waitForJob polls the job status until it is no longer queued or running
+func waitForJob(t *testing.T, baseURL, id string) *JobStatusResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(baseURL + "/api/v1/jobs/" + id)
+		if err != nil {
+			t.Fatalf("failed to get job: %v", err)
+		}
+		var status JobStatusResponse
+		decodeResponse(t, resp, &status)
+		if status.Status == JobCompleted || status.Status == JobFailed {
+			return &status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish in time", id)
+	return nil
+}
+
+func TestServer_JSONJob(t *testing.T) {
+	_, httpServer, _ := newTestServer(t)
+
+	body := `{
+		"system_file": "transactions.csv",
+		"bank_files": ["statements.csv"],
+		"bank_profiles": {"statements.csv": "standard"},
+		"matching": {"date_tolerance_days": 2, "assignment_mode": "optimal"}
+	}`
+	resp, err := http.Post(httpServer.URL+"/api/v1/jobs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to submit job: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", resp.StatusCode)
+	}
+	var submitted JobStatusResponse
+	decodeResponse(t, resp, &submitted)
+
+	status := waitForJob(t, httpServer.URL, submitted.ID)
+	if status.Status != JobCompleted {
+		t.Fatalf("expected job to complete, got %s: %s", status.Status, status.Error)
+	}
+	if status.Summary == nil || status.Summary.TotalTransactions != 3 || status.Summary.MatchedTransactions != 2 {
+		t.Errorf("unexpected summary: %+v", status.Summary)
+	}
+	if status.Progress == nil || status.Progress.FilesParsed != 2 || status.Progress.PercentComplete != 100 {
+		t.Errorf("expected progress from the orchestrator, got %+v", status.Progress)
+	}
+
+	tests := []struct {
+		format      string
+		contentType string
+		contains    string
+	}{
+		{"json", "application/json", `"unmatched_transactions"`},
+		{"csv", "text/csv", "TX003"},
+		{"console", "text/plain", "RECONCILIATION"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			resp, err := http.Get(httpServer.URL + status.ResultURL + "?format=" + tt.format)
+			if err != nil {
+				t.Fatalf("failed to get result: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var buf bytes.Buffer
+			buf.ReadFrom(resp.Body)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, buf.String())
+			}
+			if !strings.HasPrefix(resp.Header.Get("Content-Type"), tt.contentType) {
+				t.Errorf("expected content type %s, got %s", tt.contentType, resp.Header.Get("Content-Type"))
+			}
+			if !strings.Contains(buf.String(), tt.contains) {
+				t.Errorf("expected %s report to contain %q", tt.format, tt.contains)
+			}
+		})
+	}
+}
+
+func TestServer_MultipartJob(t *testing.T) {
+	srv, httpServer, _ := newTestServer(t)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("request", `{"bank_profiles": {"upload.csv": "Standard"}}`)
+	for field, file := range map[string]struct{ name, content string }{
+		"system_file": {"tx.csv", testTransactions},
+		"bank_files":  {"upload.csv", testStatements},
+	} {
+		part, err := writer.CreateFormFile(field, file.name)
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write([]byte(file.content))
+	}
+	writer.Close()
+
+	resp, err := http.Post(httpServer.URL+"/api/v1/jobs", writer.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("failed to submit job: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d", resp.StatusCode)
+	}
+	var submitted JobStatusResponse
+	decodeResponse(t, resp, &submitted)
+
+	status := waitForJob(t, httpServer.URL, submitted.ID)
+	if status.Status != JobCompleted || status.Summary.MatchedTransactions != 2 {
+		t.Fatalf("expected uploaded job to complete with 2 matches, got %s %+v %s", status.Status, status.Summary, status.Error)
+	}
+
+	// Uploads are removed once the job has finished
+	srv.Close()
+	if _, err := os.Stat(filepath.Join(srv.config.UploadDir, submitted.ID)); !os.IsNotExist(err) {
+		t.Errorf("expected uploads of job %s to be removed", submitted.ID)
+	}
+}
+
+func TestServer_SavesRunHistory(t *testing.T) {
+	resultStore, err := store.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	_, httpServer, _ := newTestServerWithStore(t, resultStore)
+
+	body := `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "matching": {"date_tolerance_days": 3}}`
+	resp, err := http.Post(httpServer.URL+"/api/v1/jobs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to submit job: %v", err)
+	}
+	var submitted JobStatusResponse
+	decodeResponse(t, resp, &submitted)
+
+	status := waitForJob(t, httpServer.URL, submitted.ID)
+	if status.Status != JobCompleted || status.RunID == "" {
+		t.Fatalf("expected completed job with a run id, got %s %q %s", status.Status, status.RunID, status.Error)
+	}
+
+	run, err := resultStore.Get(status.RunID)
+	if err != nil {
+		t.Fatalf("failed to get stored run: %v", err)
+	}
+	if run.Result.Summary.MatchedTransactions != 2 || len(run.Result.UnmatchedTransactions) != 1 {
+		t.Errorf("unexpected stored result: %+v", run.Result.Summary)
+	}
+	if run.MatchingConfig == nil || run.MatchingConfig.DateToleranceDays != 3 {
+		t.Errorf("expected the job's matching config to be stored, got %+v", run.MatchingConfig)
+	}
+	if run.SystemFile.SHA256 == "" || len(run.BankFiles) != 1 || run.BankFiles[0].SHA256 == "" {
+		t.Errorf("expected input file checksums, got %+v %+v", run.SystemFile, run.BankFiles)
+	}
+}
+
+func TestServer_RejectsInvalidJobs(t *testing.T) {
+	_, httpServer, dataDir := newTestServer(t)
+	outside := filepath.Join(filepath.Dir(dataDir), "outside.csv")
+	if err := os.WriteFile(outside, []byte(testTransactions), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", outside, err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dataDir, "link.csv")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		contains string
+	}{
+		{"missing system file", `{"bank_files": ["statements.csv"]}`, "system file is required"},
+		{"missing bank files", `{"system_file": "transactions.csv"}`, "at least one bank file"},
+		{"path outside data dir", `{"system_file": "../outside.csv", "bank_files": ["statements.csv"]}`, "outside the data directory"},
+		{"absolute path outside data dir", `{"system_file": "` + outside + `", "bank_files": ["statements.csv"]}`, "outside the data directory"},
+		{"symlink out of data dir", `{"system_file": "link.csv", "bank_files": ["statements.csv"]}`, "outside the data directory"},
+		{"unknown profile", `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "bank_profiles": {"statements.csv": "nope"}}`, "unknown bank profile"},
+		{"profile for unknown file", `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "bank_profiles": {"other.csv": "standard"}}`, "unknown bank file"},
+		{"unknown matching field", `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "matching": {"date_tolerance": 2}}`, "invalid matching overrides"},
+		{"invalid matching value", `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "matching": {"date_tolerance_days": -1}}`, "invalid matching config"},
+		{"invalid date", `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "start_date": "15/01/2024"}`, "invalid start date"},
+		{"carry forward without history", `{"system_file": "transactions.csv", "bank_files": ["statements.csv"], "carry_forward": true}`, "run history"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(httpServer.URL+"/api/v1/jobs", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("failed to submit job: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", resp.StatusCode)
+			}
+			var errResp map[string]string
+			decodeResponse(t, resp, &errResp)
+			if !strings.Contains(errResp["error"], tt.contains) {
+				t.Errorf("expected error containing %q, got %q", tt.contains, errResp["error"])
+			}
+		})
+	}
+}
+
+func TestServer_MatchingOverridesAreIsolated(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+	base := srv.config.MatchingConfig
+	base.DateWindow = &matcher.DateWindow{LagDays: 3}
+	base.DateWindows = make([]matcher.DateWindowRule, 1, 4)
+	base.DateWindows[0] = matcher.DateWindowRule{Bank: "BCA", DateWindow: matcher.DateWindow{LagDays: 2}}
+
+	overrides := `{"date_window": {"lag_days": 1, "lead_days": 1}, "date_windows": [{"bank": "Chase", "lag_days": 5, "lead_days": 0}, {"type": "DEBIT", "lag_days": 1, "lead_days": 0}]}`
+	matching, err := srv.matchingConfig(JobRequest{Matching: json.RawMessage(overrides)}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matching.DateWindow.LagDays != 1 || len(matching.DateWindows) != 2 {
+		t.Errorf("expected the overrides to apply, got %+v and %+v", matching.DateWindow, matching.DateWindows)
+	}
+
+	if *base.DateWindow != (matcher.DateWindow{LagDays: 3}) {
+		t.Errorf("the server's date window changed to %+v", base.DateWindow)
+	}
+	if len(base.DateWindows) != 1 || base.DateWindows[:2][1].Bank != "" || base.DateWindows[0].Bank != "BCA" {
+		t.Errorf("the server's date window rules changed to %+v", base.DateWindows[:2])
+	}
+}
+
+func TestServer_EvictsFinishedJobs(t *testing.T) {
+	srv, httpServer, _ := newTestServer(t)
+	srv.config.MaxRetainedJobs = 1
+
+	body := `{"system_file": "transactions.csv", "bank_files": ["statements.csv"]}`
+	var ids []string
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(httpServer.URL+"/api/v1/jobs", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to submit job: %v", err)
+		}
+		var submitted JobStatusResponse
+		decodeResponse(t, resp, &submitted)
+		waitForJob(t, httpServer.URL, submitted.ID)
+		ids = append(ids, submitted.ID)
+	}
+
+	// Jobs are evicted just after they finish; wait for the job goroutines
+	srv.wg.Wait()
+	if srv.lookupJob(ids[0]) != nil || srv.lookupJob(ids[1]) == nil {
+		t.Errorf("expected only the latest job to be retained")
+	}
+}
+
+func TestServer_ServerPathsDisabled(t *testing.T) {
+	config := DefaultConfig()
+	config.UploadDir = t.TempDir()
+	srv, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer srv.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs",
+		strings.NewReader(`{"system_file": "transactions.csv", "bank_files": ["statements.csv"]}`))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "upload") {
+		t.Errorf("expected server-side paths to be rejected, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServer_UnknownJob(t *testing.T) {
+	_, httpServer, _ := newTestServer(t)
+
+	for _, path := range []string{"/api/v1/jobs/missing", "/api/v1/jobs/missing/result"} {
+		resp, err := http.Get(httpServer.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected status 404, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_Incremental(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	incrementalConfig := reconciler.DefaultIncrementalConfig()
+	incrementalConfig.SnapshotPath = statePath
+	incremental, err := reconciler.NewIncrementalService(nil, incrementalConfig)
+	if err != nil {
+		t.Fatalf("failed to create incremental service: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.UploadDir = t.TempDir()
+	config.Incremental = incremental
+	srv, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		srv.Close()
+	})
+
+	post := func(path, body string) (*http.Response, IncrementalBatchResponse) {
+		t.Helper()
+		resp, err := http.Post(httpServer.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var batch IncrementalBatchResponse
+		if resp.StatusCode == http.StatusOK {
+			decodeResponse(t, resp, &batch)
+		} else {
+			resp.Body.Close()
+		}
+		return resp, batch
+	}
+
+	resp, batch := post("/api/v1/incremental/statements",
+		`{"statements": [{"unique_identifier": "BS001", "amount": "100.50", "date": "2024-01-15T00:00:00Z"}]}`)
+	if resp.StatusCode != http.StatusOK || len(batch.Matches) != 0 || batch.Open != 1 {
+		t.Fatalf("expected the statement to stay open, got %d %+v", resp.StatusCode, batch)
+	}
+
+	resp, batch = post("/api/v1/incremental/transactions", `{"transactions": [
+		{"trxID": "TX001", "amount": "100.50", "type": "CREDIT", "transactionTime": "2024-01-15T10:30:00Z"},
+		{"trxID": "TX002", "amount": "40.00", "type": "CREDIT", "transactionTime": "2024-01-15T11:00:00Z"},
+		{"trxID": "", "amount": "1.00", "type": "CREDIT", "transactionTime": "2024-01-15T11:00:00Z"}
+	]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	if len(batch.Matches) != 1 || batch.Matches[0].TransactionID != "TX001" || batch.Matches[0].StatementID != "BS001" ||
+		batch.Matches[0].MatchType != "Exact" {
+		t.Errorf("expected TX001 to match BS001 exactly, got %+v", batch.Matches)
+	}
+	if batch.Open != 1 || len(batch.Rejected) != 1 {
+		t.Errorf("expected one open and one rejected transaction, got %+v", batch)
+	}
+	if batch.Stats.Matches != 1 || batch.Stats.OpenTransactions != 1 || batch.Stats.OpenStatements != 0 {
+		t.Errorf("unexpected stats: %+v", batch.Stats)
+	}
+
+	if resp, _ := post("/api/v1/incremental/transactions", `{"items": []}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected unknown fields to be rejected, got %d", resp.StatusCode)
+	}
+
+	stateResp, err := http.Get(httpServer.URL + "/api/v1/incremental")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var state IncrementalStateResponse
+	decodeResponse(t, stateResp, &state)
+	if len(state.Transactions) != 1 || state.Transactions[0].TrxID != "TX002" || len(state.Statements) != 0 {
+		t.Errorf("expected TX002 to be the only open item, got %+v", state)
+	}
+
+	if _, err := os.Stat(statePath); err != nil {
+		t.Errorf("expected the state to be saved: %v", err)
+	}
+}
+
+func TestServer_RequestBodyLimit(t *testing.T) {
+	incremental, err := reconciler.NewIncrementalService(nil, reconciler.DefaultIncrementalConfig())
+	if err != nil {
+		t.Fatalf("failed to create incremental service: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.UploadDir = t.TempDir()
+	config.Incremental = incremental
+	config.MaxUploadBytes = 128
+	srv, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	httpServer := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		srv.Close()
+	})
+
+	padding := strings.Repeat(" ", 256)
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/api/v1/jobs", `{"system_file": "transactions.csv",` + padding + `"bank_files": ["statements.csv"]}`},
+		{"/api/v1/incremental/transactions", `{"transactions":` + padding + `[]}`},
+		{"/api/v1/incremental/statements", `{"statements":` + padding + `[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Post(httpServer.URL+tt.path, "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", resp.StatusCode)
+			}
+			var errResp map[string]string
+			decodeResponse(t, resp, &errResp)
+			if !strings.Contains(errResp["error"], "too large") {
+				t.Errorf("expected a body size error, got %q", errResp["error"])
+			}
+		})
+	}
+}
+
+func TestServer_IncrementalDisabled(t *testing.T) {
+	_, httpServer, _ := newTestServer(t)
+
+	resp, err := http.Get(httpServer.URL + "/api/v1/incremental")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 without an incremental reconciler, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,243 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	runsDirName   = "runs"
+	indexFileName = "index.jsonl"
+)
+
+// FileStore is a ResultStore kept in a local directory. Each run is written
+// to runs/<id>.json and a one-line summary is appended to index.jsonl, so
+// listing runs does not need to read every result.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore opens the store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("store directory cannot be empty")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, runsDirName), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Dir returns the directory the store is kept in
+func (fs *FileStore) Dir() string {
+	return fs.dir
+}
+
+// Save writes the run and adds it to the index
+func (fs *FileStore) Save(run *Run) error {
+	if run == nil || !validRunID(run.ID) {
+		return fmt.Errorf("run must have a valid id")
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run %s: %w", run.ID, err)
+	}
+	summary, err := json.Marshal(run.Summary())
+	if err != nil {
+		return fmt.Errorf("failed to encode run %s: %w", run.ID, err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	// Write to a temporary file first so a crash never leaves a partial run
+	path := fs.runPath(run.ID)
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write run %s: %w", run.ID, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write run %s: %w", run.ID, err)
+	}
+
+	index, err := os.OpenFile(filepath.Join(fs.dir, indexFileName), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to update run index: %w", err)
+	}
+	defer index.Close()
+
+	if err := appendIndexLine(index, summary); err != nil {
+		return fmt.Errorf("failed to update run index: %w", err)
+	}
+	return nil
+}
+
+// writeFileSync writes data to a new file at path and syncs it to disk
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// appendIndexLine appends a line to the index and syncs it to disk. The index
+// is opened with O_APPEND and locked for the append, so runs saved by other
+// processes sharing the store are never overwritten. A final line without its
+// newline was cut short by a crash during an earlier append, so it is
+// truncated away first rather than being joined to the new line.
+func appendIndexLine(index *os.File, line []byte) error {
+	if err := lockFile(index); err != nil {
+		return err
+	}
+	end, err := completeIndexEnd(index)
+	if err != nil {
+		return err
+	}
+	if err := index.Truncate(end); err != nil {
+		return err
+	}
+	if _, err := index.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return index.Sync()
+}
+
+// completeIndexEnd returns the offset just past the last newline of the
+// index, or 0 when it has none
+func completeIndexEnd(index *os.File) (int64, error) {
+	info, err := index.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 4096)
+	for end := info.Size(); end > 0; {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := index.ReadAt(chunk, start); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// Get reads a stored run
+func (fs *FileStore) Get(id string) (*Run, error) {
+	if !validRunID(id) {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+
+	data, err := os.ReadFile(fs.runPath(id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run %s: %w", id, err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to decode run %s: %w", id, err)
+	}
+	return &run, nil
+}
+
+// List returns run summaries from the index, newest first. When a run was
+// saved more than once, its latest summary is used.
+func (fs *FileStore) List(options ListOptions) ([]*RunSummary, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	file, err := os.Open(filepath.Join(fs.dir, indexFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run index: %w", err)
+	}
+	defer file.Close()
+
+	// An undecodable final line is an append cut short by a crash, and is
+	// skipped; one followed by other lines means the index is corrupt
+	byID := make(map[string]*RunSummary)
+	var lineErr error
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		if lineErr != nil {
+			return nil, lineErr
+		}
+		var summary RunSummary
+		if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+			lineErr = fmt.Errorf("run index line %d: %w", line, err)
+			continue
+		}
+		byID[summary.ID] = &summary
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read run index: %w", err)
+	}
+
+	summaries := make([]*RunSummary, 0, len(byID))
+	for _, summary := range byID {
+		if !options.Since.IsZero() && summary.CreatedAt.Before(options.Since) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].CreatedAt.Equal(summaries[j].CreatedAt) {
+			return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+		}
+		return summaries[i].ID > summaries[j].ID
+	})
+
+	if options.Limit > 0 && len(summaries) > options.Limit {
+		summaries = summaries[:options.Limit]
+	}
+	return summaries, nil
+}
+
+// Close implements ResultStore; a FileStore holds no open resources
+func (fs *FileStore) Close() error {
+	return nil
+}
+
+func (fs *FileStore) runPath(id string) string {
+	return filepath.Join(fs.dir, runsDirName, id+".json")
+}
+
+// validRunID rejects IDs that could escape the runs directory
+func validRunID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\.`) && id == filepath.Base(id)
+}
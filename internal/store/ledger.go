@@ -0,0 +1,143 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/reconciler"
+)
+
+// OpenItem is an unmatched transaction or bank statement in the open-items
+// ledger. Each run carries the open items of the previous run of its ledger
+// into its candidate pools; items it matches are resolved by that run and
+// the rest stay open.
+type OpenItem struct {
+	Transaction *models.Transaction   `json:"transaction,omitempty"`
+	Statement   *models.BankStatement `json:"statement,omitempty"`
+	OpenedIn    string                `json:"opened_in"`             // run that first left the item unmatched
+	ResolvedIn  string                `json:"resolved_in,omitempty"` // run that matched the item
+}
+
+func (item *OpenItem) key() string {
+	if item.Transaction != nil {
+		return transactionKey(item.Transaction)
+	}
+	return statementKey(item.Statement)
+}
+
+func transactionKey(tx *models.Transaction) string {
+	return "transaction:" + tx.TrxID
+}
+
+func statementKey(stmt *models.BankStatement) string {
+	return "statement:" + reconciler.StatementKey(stmt)
+}
+
+// LatestRun returns the most recent run of a ledger, or ErrRunNotFound if the
+// ledger has no runs yet
+func LatestRun(store ResultStore, ledger string) (*Run, error) {
+	summaries, err := store.List(ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		if summary.Ledger == ledger {
+			return store.Get(summary.ID)
+		}
+	}
+	return nil, fmt.Errorf("%w: no runs in ledger %q", ErrRunNotFound, ledger)
+}
+
+// PreviousRun is LatestRun for carry-forward: a ledger without runs is not an
+// error and yields nil
+func PreviousRun(store ResultStore, ledger string) (*Run, error) {
+	run, err := LatestRun(store, ledger)
+	if errors.Is(err, ErrRunNotFound) {
+		return nil, nil
+	}
+	return run, err
+}
+
+// OpenTransactions returns the transactions still open after the run
+func (r *Run) OpenTransactions() []*models.Transaction {
+	var transactions []*models.Transaction
+	for _, item := range r.OpenItems {
+		if item.Transaction != nil {
+			transactions = append(transactions, item.Transaction)
+		}
+	}
+	return transactions
+}
+
+// OpenStatements returns the bank statements still open after the run
+func (r *Run) OpenStatements() []*models.BankStatement {
+	var statements []*models.BankStatement
+	for _, item := range r.OpenItems {
+		if item.Statement != nil {
+			statements = append(statements, item.Statement)
+		}
+	}
+	return statements
+}
+
+// UpdateLedger records the run's place in the open-items ledger. previous is
+// the run whose open items were carried into this one and may be nil. Items
+// the run matched that were open after previous are marked resolved by this
+// run; items it left unmatched or pending review become its open items,
+// keeping the run that first opened them.
+func (r *Run) UpdateLedger(previous *Run) {
+	r.OpenItems = nil
+	r.ResolvedItems = nil
+	if r.Result == nil {
+		return
+	}
+
+	carried := make(map[string]*OpenItem)
+	if previous != nil {
+		r.CarriedFrom = previous.ID
+		for _, item := range previous.OpenItems {
+			carried[item.key()] = item
+		}
+	}
+
+	resolve := func(key string) {
+		if item, ok := carried[key]; ok {
+			resolved := *item
+			resolved.ResolvedIn = r.ID
+			r.ResolvedItems = append(r.ResolvedItems, &resolved)
+			delete(carried, key)
+		}
+	}
+	for _, match := range r.Result.MatchedTransactions {
+		resolve(transactionKey(match.Transaction))
+		resolve(statementKey(match.BankStatement))
+	}
+	for _, group := range r.Result.GroupMatches {
+		for _, tx := range group.Transactions {
+			resolve(transactionKey(tx))
+		}
+		for _, stmt := range group.Statements {
+			resolve(statementKey(stmt))
+		}
+	}
+
+	open := func(item *OpenItem) {
+		if previousItem, ok := carried[item.key()]; ok {
+			item.OpenedIn = previousItem.OpenedIn
+		} else {
+			item.OpenedIn = r.ID
+		}
+		r.OpenItems = append(r.OpenItems, item)
+	}
+	for _, tx := range r.Result.UnmatchedTransactions {
+		open(&OpenItem{Transaction: tx})
+	}
+	for _, stmt := range r.Result.UnmatchedStatements {
+		open(&OpenItem{Statement: stmt})
+	}
+	for _, pending := range r.Result.PendingReview {
+		open(&OpenItem{Transaction: pending.Match.Transaction})
+		open(&OpenItem{Statement: pending.Match.BankStatement})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package store
+
+import "os"
+
+// lockFile does nothing where advisory locks are not available; appends are
+// then only serialised within the process
+func lockFile(file *os.File) error {
+	return nil
+}
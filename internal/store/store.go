@@ -0,0 +1,222 @@
+// Package store persists reconciliation runs so their results can be browsed
+// after the report has been written.
+//
+// ResultStore is the storage abstraction; FileStore is the default embedded
+// implementation, which keeps one JSON document per run in a local directory
+// and needs no external database.
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/reconciler"
+)
+
+// ErrRunNotFound is returned when a run ID is not in the store
+var ErrRunNotFound = errors.New("run not found")
+
+// ResultStore persists reconciliation runs
+type ResultStore interface {
+	// Save stores a run. A run with the same ID is replaced.
+	Save(run *Run) error
+
+	// Get returns the run with the given ID, or ErrRunNotFound
+	Get(id string) (*Run, error)
+
+	// List returns run summaries, newest first
+	List(options ListOptions) ([]*RunSummary, error)
+
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// ListOptions filters the runs returned by ResultStore.List
+type ListOptions struct {
+	Limit int       // maximum number of runs; 0 means no limit
+	Since time.Time // only runs created at or after this time, when set
+}
+
+// InputFile identifies an input file of a run by path and content checksum
+type InputFile struct {
+	Path    string `json:"path"`
+	Profile string `json:"profile,omitempty"` // bank profile used to parse the file
+	SHA256  string `json:"sha256,omitempty"`
+	Size    int64  `json:"size"`
+}
+
+// Run is a stored reconciliation: the full result together with the inputs
+// and configuration that produced it
+type Run struct {
+	ID             string                           `json:"id"`
+	CreatedAt      time.Time                        `json:"created_at"`
+	SystemFile     InputFile                        `json:"system_file"`
+	BankFiles      []InputFile                      `json:"bank_files"`
+	MatchingConfig *matcher.MatchingConfig          `json:"matching_config,omitempty"`
+	Result         *reconciler.ReconciliationResult `json:"result"`
+
+	// Open-items ledger; see UpdateLedger
+	Ledger        string      `json:"ledger,omitempty"`
+	CarriedFrom   string      `json:"carried_from,omitempty"` // run whose open items were carried in
+	OpenItems     []*OpenItem `json:"open_items,omitempty"`
+	ResolvedItems []*OpenItem `json:"resolved_items,omitempty"`
+}
+
+// RunSummary is the listing form of a run
+type RunSummary struct {
+	ID                    string    `json:"id"`
+	CreatedAt             time.Time `json:"created_at"`
+	SystemFile            string    `json:"system_file"`
+	BankFiles             []string  `json:"bank_files"`
+	TotalTransactions     int       `json:"total_transactions"`
+	MatchedTransactions   int       `json:"matched_transactions"`
+	UnmatchedTransactions int       `json:"unmatched_transactions"`
+	UnmatchedStatements   int       `json:"unmatched_statements"`
+	PendingReview         int       `json:"pending_review,omitempty"`
+	Discrepancies         int       `json:"discrepancies"`
+	Ledger                string    `json:"ledger,omitempty"`
+	OpenItems             int       `json:"open_items"`
+	ResolvedItems         int       `json:"resolved_items"`
+}
+
+// NewRun builds a run for a result with a new ID, checksumming the input files
+// named in the result's request. matchingConfig is the configuration the run
+// was matched with and may be nil.
+func NewRun(result *reconciler.ReconciliationResult, matchingConfig *matcher.MatchingConfig) (*Run, error) {
+	if result == nil || result.Request == nil {
+		return nil, fmt.Errorf("result and its request are required")
+	}
+
+	id, err := NewRunID(result.ProcessedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &Run{
+		ID:             id,
+		CreatedAt:      result.ProcessedAt,
+		MatchingConfig: matchingConfig,
+		Result:         result,
+	}
+
+	if run.SystemFile, err = describeInputFile(result.Request.SystemFile); err != nil {
+		return nil, err
+	}
+	for _, path := range result.Request.BankFiles {
+		file, err := describeInputFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if bankConfig := result.Request.BankConfigs[path]; bankConfig != nil {
+			file.Profile = bankConfig.Name
+		}
+		run.BankFiles = append(run.BankFiles, file)
+	}
+
+	return run, nil
+}
+
+// Summary returns the listing form of the run
+func (r *Run) Summary() *RunSummary {
+	summary := &RunSummary{
+		ID:            r.ID,
+		CreatedAt:     r.CreatedAt,
+		SystemFile:    r.SystemFile.Path,
+		Ledger:        r.Ledger,
+		OpenItems:     len(r.OpenItems),
+		ResolvedItems: len(r.ResolvedItems),
+	}
+	for _, file := range r.BankFiles {
+		summary.BankFiles = append(summary.BankFiles, file.Path)
+	}
+	if r.Result != nil {
+		if r.Result.Summary != nil {
+			summary.TotalTransactions = r.Result.Summary.TotalTransactions
+			summary.MatchedTransactions = r.Result.Summary.MatchedTransactions
+			summary.UnmatchedTransactions = r.Result.Summary.UnmatchedTransactions
+			summary.UnmatchedStatements = r.Result.Summary.UnmatchedStatements
+			summary.PendingReview = r.Result.Summary.PendingReview
+		}
+		summary.Discrepancies = len(r.Result.Discrepancies)
+	}
+	return summary
+}
+
+// FindRun returns the run with the given ID or the single run whose ID starts
+// with it, so users can type a short prefix
+func FindRun(store ResultStore, idOrPrefix string) (*Run, error) {
+	run, err := store.Get(idOrPrefix)
+	if err == nil || !errors.Is(err, ErrRunNotFound) || idOrPrefix == "" {
+		return run, err
+	}
+
+	summaries, err := store.List(ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, summary := range summaries {
+		if strings.HasPrefix(summary.ID, idOrPrefix) {
+			matches = append(matches, summary.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, idOrPrefix)
+	case 1:
+		return store.Get(matches[0])
+	default:
+		return nil, fmt.Errorf("run id %s is ambiguous: matches %s", idOrPrefix, strings.Join(matches, ", "))
+	}
+}
+
+// NewRunID returns a run ID that sorts by creation time, e.g. "20240115-103000-1a2b3c"
+func NewRunID(createdAt time.Time) (string, error) {
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate run id: %w", err)
+	}
+	return createdAt.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
+
+// ChecksumFile returns the hex SHA-256 of a file's content and its size
+func ChecksumFile(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+func describeInputFile(path string) (InputFile, error) {
+	checksum, size, err := ChecksumFile(path)
+	if err != nil {
+		return InputFile{}, fmt.Errorf("failed to checksum %s: %w", path, err)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return InputFile{Path: path, SHA256: checksum, Size: size}, nil
+}
@@ -0,0 +1,469 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-reconciliation-service/internal/matcher"
+	"golang-reconciliation-service/internal/models"
+	"golang-reconciliation-service/internal/parsers"
+	"golang-reconciliation-service/internal/reconciler"
+
+	"github.com/shopspring/decimal"
+)
+
+// newTestResult builds a result whose request names two files in a temp dir
+func newTestResult(t *testing.T, processedAt time.Time) *reconciler.ReconciliationResult {
+	t.Helper()
+
+	dir := t.TempDir()
+	systemFile := filepath.Join(dir, "transactions.csv")
+	bankFile := filepath.Join(dir, "statements.csv")
+	for path, content := range map[string]string{
+		systemFile: "trxID,amount,type,transactionTime\nTX001,100.50,CREDIT,2024-01-15T10:30:00Z\n",
+		bankFile:   "unique_identifier,amount,date\nBS001,100.50,2024-01-15\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	txTime := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	matchedTx := models.NewTransaction("TX001", decimal.NewFromFloat(100.50), models.TransactionTypeCredit, txTime)
+	matchedStmt := models.NewBankStatement("BS001", decimal.NewFromFloat(100.50), txTime)
+	unmatchedTx := models.NewTransaction("TX002", decimal.NewFromFloat(250), models.TransactionTypeDebit, txTime)
+	unmatchedStmt := models.NewBankStatement("BS002", decimal.NewFromFloat(-75.25), txTime)
+
+	return &reconciler.ReconciliationResult{
+		Summary: &reconciler.ResultSummary{
+			TotalTransactions:     2,
+			MatchedTransactions:   1,
+			UnmatchedTransactions: 1,
+			UnmatchedStatements:   1,
+		},
+		MatchedTransactions: []*matcher.MatchResult{{
+			Transaction:     matchedTx,
+			BankStatement:   matchedStmt,
+			MatchType:       matcher.MatchExact,
+			ConfidenceScore: 1,
+		}},
+		UnmatchedTransactions: []*models.Transaction{unmatchedTx},
+		UnmatchedStatements:   []*models.BankStatement{unmatchedStmt},
+		Discrepancies: []*reconciler.Discrepancy{{
+			Type:        reconciler.DiscrepancyMissingStatement,
+			Transaction: unmatchedTx,
+			Description: "no bank statement found",
+			Severity:    reconciler.SeverityHigh,
+		}},
+		ProcessedAt: processedAt,
+		Request: &reconciler.ReconciliationRequest{
+			SystemFile:  systemFile,
+			BankFiles:   []string{bankFile},
+			BankConfigs: map[string]*parsers.BankConfig{bankFile: parsers.StandardBankConfig},
+		},
+	}
+}
+
+func newTestRun(t *testing.T, processedAt time.Time) *Run {
+	t.Helper()
+
+	run, err := NewRun(newTestResult(t, processedAt), matcher.DefaultMatchingConfig())
+	if err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	return run
+}
+
+func TestFileStore_SaveAndGet(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer fileStore.Close()
+
+	run := newTestRun(t, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC))
+	if !strings.HasPrefix(run.ID, "20240116-090000-") {
+		t.Errorf("expected run id to start with the creation time, got %s", run.ID)
+	}
+	if err := fileStore.Save(run); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	stored, err := fileStore.Get(run.ID)
+	if err != nil {
+		t.Fatalf("failed to get run: %v", err)
+	}
+
+	if stored.SystemFile.SHA256 == "" || stored.SystemFile.SHA256 != run.SystemFile.SHA256 {
+		t.Errorf("expected system file checksum %q, got %q", run.SystemFile.SHA256, stored.SystemFile.SHA256)
+	}
+	if len(stored.BankFiles) != 1 || stored.BankFiles[0].Profile != parsers.StandardBankConfig.Name {
+		t.Errorf("expected bank file with its profile, got %+v", stored.BankFiles)
+	}
+	if stored.MatchingConfig == nil || stored.MatchingConfig.DateToleranceDays != run.MatchingConfig.DateToleranceDays {
+		t.Errorf("expected matching config to be stored, got %+v", stored.MatchingConfig)
+	}
+
+	result := stored.Result
+	if result == nil || result.Summary == nil || result.Summary.MatchedTransactions != 1 {
+		t.Fatalf("expected stored summary, got %+v", result)
+	}
+	if len(result.MatchedTransactions) != 1 || result.MatchedTransactions[0].Transaction.TrxID != "TX001" ||
+		result.MatchedTransactions[0].BankStatement.UniqueIdentifier != "BS001" {
+		t.Errorf("expected stored match TX001/BS001, got %+v", result.MatchedTransactions)
+	}
+	if len(result.UnmatchedTransactions) != 1 || result.UnmatchedTransactions[0].TrxID != "TX002" {
+		t.Errorf("expected unmatched transaction TX002, got %+v", result.UnmatchedTransactions)
+	}
+	if len(result.UnmatchedStatements) != 1 || !result.UnmatchedStatements[0].Amount.Equal(decimal.NewFromFloat(-75.25)) {
+		t.Errorf("expected unmatched statement BS002, got %+v", result.UnmatchedStatements)
+	}
+	if len(result.Discrepancies) != 1 || result.Discrepancies[0].Type != reconciler.DiscrepancyMissingStatement {
+		t.Errorf("expected stored discrepancy, got %+v", result.Discrepancies)
+	}
+}
+
+func TestFileStore_List(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	var runs []*Run
+	for day := 0; day < 3; day++ {
+		run := newTestRun(t, base.AddDate(0, 0, day))
+		if err := fileStore.Save(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+		runs = append(runs, run)
+	}
+
+	// Saving a run again replaces its summary instead of listing it twice
+	runs[0].Result.Summary.MatchedTransactions = 0
+	if err := fileStore.Save(runs[0]); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		options  ListOptions
+		expected []*Run
+	}{
+		{"all newest first", ListOptions{}, []*Run{runs[2], runs[1], runs[0]}},
+		{"limit", ListOptions{Limit: 2}, []*Run{runs[2], runs[1]}},
+		{"since", ListOptions{Since: base.AddDate(0, 0, 1)}, []*Run{runs[2], runs[1]}},
+		{"since after all runs", ListOptions{Since: base.AddDate(0, 0, 5)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries, err := fileStore.List(tt.options)
+			if err != nil {
+				t.Fatalf("failed to list runs: %v", err)
+			}
+			if len(summaries) != len(tt.expected) {
+				t.Fatalf("expected %d runs, got %d", len(tt.expected), len(summaries))
+			}
+			for i, summary := range summaries {
+				if summary.ID != tt.expected[i].ID {
+					t.Errorf("run %d: expected %s, got %s", i, tt.expected[i].ID, summary.ID)
+				}
+			}
+		})
+	}
+
+	summaries, _ := fileStore.List(ListOptions{})
+	if last := summaries[len(summaries)-1]; last.MatchedTransactions != 0 {
+		t.Errorf("expected the latest summary of a re-saved run, got %d matched", last.MatchedTransactions)
+	}
+}
+
+func TestFileStore_TornIndexLine(t *testing.T) {
+	dir := t.TempDir()
+	fileStore, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	for day := 0; day < 2; day++ {
+		if err := fileStore.Save(newTestRun(t, base.AddDate(0, 0, day))); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	// A crash during an append leaves the final line cut short
+	indexPath := filepath.Join(dir, indexFileName)
+	index, err := os.OpenFile(indexPath, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("failed to open index: %v", err)
+	}
+	if _, err := index.WriteString(`{"id":"run-torn","created_`); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+	index.Close()
+
+	summaries, err := fileStore.List(ListOptions{})
+	if err != nil || len(summaries) != 2 {
+		t.Fatalf("expected the torn line to be skipped, got %d runs, %v", len(summaries), err)
+	}
+
+	// The next save drops the torn line instead of appending to it
+	if err := fileStore.Save(newTestRun(t, base.AddDate(0, 0, 2))); err != nil {
+		t.Fatalf("failed to save run: %v", err)
+	}
+	summaries, err = fileStore.List(ListOptions{})
+	if err != nil || len(summaries) != 3 {
+		t.Fatalf("expected 3 runs after the next save, got %d, %v", len(summaries), err)
+	}
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	if strings.Contains(string(data), "run-torn") {
+		t.Errorf("expected the torn line to be truncated, got %q", data)
+	}
+
+	// An undecodable line followed by others is still an error
+	if err := os.WriteFile(indexPath, append([]byte("not json\n"), data...), 0o644); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+	if _, err := fileStore.List(ListOptions{}); err == nil || !strings.Contains(err.Error(), "run index line 1") {
+		t.Errorf("expected a corrupt index error, got %v", err)
+	}
+}
+
+func TestFileStore_ConcurrentStores(t *testing.T) {
+	// Two stores on one directory stand in for two processes sharing it
+	dir := t.TempDir()
+	stores := make([]*FileStore, 2)
+	for i := range stores {
+		fileStore, err := NewFileStore(dir)
+		if err != nil {
+			t.Fatalf("failed to open store: %v", err)
+		}
+		stores[i] = fileStore
+	}
+
+	const perStore = 20
+	base := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	errs := make(chan error, len(stores)*perStore)
+	for i, fileStore := range stores {
+		wg.Add(1)
+		go func(i int, fileStore *FileStore) {
+			defer wg.Done()
+			for n := 0; n < perStore; n++ {
+				errs <- fileStore.Save(newTestRun(t, base.Add(time.Duration(i*perStore+n)*time.Minute)))
+			}
+		}(i, fileStore)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	summaries, err := stores[0].List(ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if len(summaries) != len(stores)*perStore {
+		t.Errorf("expected %d runs in the index, got %d", len(stores)*perStore, len(summaries))
+	}
+}
+
+func TestFileStore_EmptyList(t *testing.T) {
+	fileStore, err := NewFileStore(filepath.Join(t.TempDir(), "history"))
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	summaries, err := fileStore.List(ListOptions{})
+	if err != nil || len(summaries) != 0 {
+		t.Errorf("expected no runs, got %v, %v", summaries, err)
+	}
+}
+
+func TestFindRun(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+
+	first := newTestRun(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC))
+	second := newTestRun(t, time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC))
+	for _, run := range []*Run{first, second} {
+		if err := fileStore.Save(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		idOrPrefix string
+		expectedID string
+		notFound   bool
+		contains   string
+	}{
+		{"exact id", first.ID, first.ID, false, ""},
+		{"unique prefix", "20240115-11", second.ID, false, ""},
+		{"ambiguous prefix", "20240115", "", false, "ambiguous"},
+		{"unknown id", "20230101", "", true, ""},
+		{"path traversal", "../runs/" + first.ID, "", true, ""},
+		{"empty id", "", "", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := FindRun(fileStore, tt.idOrPrefix)
+			if tt.expectedID != "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if run.ID != tt.expectedID {
+					t.Errorf("expected run %s, got %s", tt.expectedID, run.ID)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error, got run %s", run.ID)
+			}
+			if tt.notFound && !errors.Is(err, ErrRunNotFound) {
+				t.Errorf("expected ErrRunNotFound, got %v", err)
+			}
+			if tt.contains != "" && !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("expected error containing %q, got %v", tt.contains, err)
+			}
+		})
+	}
+}
+
+func TestNewRun_MissingInput(t *testing.T) {
+	result := newTestResult(t, time.Now())
+	result.Request.BankFiles = append(result.Request.BankFiles, filepath.Join(t.TempDir(), "missing.csv"))
+
+	if _, err := NewRun(result, nil); err == nil || !strings.Contains(err.Error(), "missing.csv") {
+		t.Errorf("expected an error naming the missing input file, got %v", err)
+	}
+}
+
+func TestRun_UpdateLedger(t *testing.T) {
+	base := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	// January leaves TX002 and BS002 open
+	january := newTestRun(t, base)
+	january.UpdateLedger(nil)
+	if len(january.OpenItems) != 2 || len(january.ResolvedItems) != 0 {
+		t.Fatalf("expected 2 open items, got %d open and %d resolved", len(january.OpenItems), len(january.ResolvedItems))
+	}
+	for _, item := range january.OpenItems {
+		if item.OpenedIn != january.ID {
+			t.Errorf("expected item opened in %s, got %s", january.ID, item.OpenedIn)
+		}
+	}
+
+	// February clears TX001 and TX002 but leaves BS002 and a new TX003 open
+	february := newTestRun(t, base.AddDate(0, 0, 1))
+	result := february.Result
+	txTime := base.AddDate(0, 0, 1)
+	cleared := january.OpenTransactions()[0]
+	result.MatchedTransactions = append(result.MatchedTransactions, &matcher.MatchResult{
+		Transaction:   cleared,
+		BankStatement: models.NewBankStatement("BS010", cleared.Amount, txTime),
+	})
+	result.UnmatchedTransactions = []*models.Transaction{
+		models.NewTransaction("TX003", decimal.NewFromInt(10), models.TransactionTypeCredit, txTime),
+	}
+	result.UnmatchedStatements = january.OpenStatements()
+	february.UpdateLedger(january)
+
+	if february.CarriedFrom != january.ID {
+		t.Errorf("expected run to be carried from %s, got %s", january.ID, february.CarriedFrom)
+	}
+	if len(february.ResolvedItems) != 1 {
+		t.Fatalf("expected 1 resolved item, got %d", len(february.ResolvedItems))
+	}
+	resolved := february.ResolvedItems[0]
+	if resolved.Transaction.TrxID != "TX002" || resolved.OpenedIn != january.ID || resolved.ResolvedIn != february.ID {
+		t.Errorf("expected TX002 opened in %s and resolved in %s, got %+v", january.ID, february.ID, resolved)
+	}
+
+	openedIn := make(map[string]string)
+	for _, item := range february.OpenItems {
+		openedIn[item.key()] = item.OpenedIn
+	}
+	expected := map[string]string{
+		"transaction:TX003": february.ID,
+		"statement:/BS002":  january.ID,
+	}
+	if len(openedIn) != len(expected) {
+		t.Fatalf("expected open items %v, got %v", expected, openedIn)
+	}
+	for key, run := range expected {
+		if openedIn[key] != run {
+			t.Errorf("%s: expected opened in %s, got %q", key, run, openedIn[key])
+		}
+	}
+
+	// The ledger survives a round trip through the store
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	february.Ledger = "operating"
+	for _, run := range []*Run{january, february} {
+		if err := fileStore.Save(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+
+	latest, err := PreviousRun(fileStore, "operating")
+	if err != nil || latest == nil || latest.ID != february.ID {
+		t.Fatalf("expected latest operating run %s, got %v, %v", february.ID, latest, err)
+	}
+	if len(latest.OpenTransactions()) != 1 || len(latest.OpenStatements()) != 1 || len(latest.ResolvedItems) != 1 {
+		t.Errorf("expected stored open and resolved items, got %d open and %d resolved", len(latest.OpenItems), len(latest.ResolvedItems))
+	}
+	if latest, err := PreviousRun(fileStore, ""); err != nil || latest == nil || latest.ID != january.ID {
+		t.Errorf("expected latest default-ledger run %s, got %v, %v", january.ID, latest, err)
+	}
+	if latest, err := PreviousRun(fileStore, "savings"); err != nil || latest != nil {
+		t.Errorf("expected no previous run for an empty ledger, got %v, %v", latest, err)
+	}
+}
+
+func TestRun_UpdateLedger_PendingReview(t *testing.T) {
+	run := newTestRun(t, time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC))
+	txTime := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	run.Result.PendingReview = []*matcher.PendingMatch{{
+		Match: &matcher.MatchResult{
+			Transaction:   models.NewTransaction("TX004", decimal.NewFromInt(40), models.TransactionTypeCredit, txTime),
+			BankStatement: models.NewBankStatement("BS004", decimal.NewFromInt(40), txTime.AddDate(0, 0, 2)),
+		},
+		Reason: matcher.ReviewLowConfidence,
+	}}
+	run.UpdateLedger(nil)
+
+	// Both sides of a pending match stay open until it is accepted
+	open := make(map[string]bool)
+	for _, item := range run.OpenItems {
+		open[item.key()] = true
+	}
+	for _, key := range []string{"transaction:TX002", "statement:/BS002", "transaction:TX004", "statement:/BS004"} {
+		if !open[key] {
+			t.Errorf("expected %s to be open, got %v", key, open)
+		}
+	}
+	if len(run.OpenItems) != 4 {
+		t.Errorf("expected 4 open items, got %d", len(run.OpenItems))
+	}
+}
@@ -153,6 +153,19 @@ func TestSpecificErrorConstructors(t *testing.T) {
 			t.Errorf("expected value context, got %v", err.Context["value"])
 		}
 	})
+	t.Run("InvalidAmountError", func(t *testing.T) {
+		err := InvalidAmountError("bank.csv", 4, "amount", "1.234,56")
+
+		if err.Code != CodeInvalidAmount {
+			t.Errorf("expected invalid amount code, got %s", err.Code)
+		}
+		if err.Context.Value != "1.234,56" {
+			t.Errorf("expected value context, got %v", err.Context.Value)
+		}
+		if err.Suggestion == "" {
+			t.Error("expected suggestion to be set")
+		}
+	})
 }
 
 func TestErrorSummary(t *testing.T) {
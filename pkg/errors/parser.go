@@ -95,6 +95,10 @@ func (e *EnhancedParseError) GetDetailedError() string {
 // NewEnhancedParseError creates a new enhanced parse error
 func NewEnhancedParseError(code ErrorCode, context *ParseContext, message string, cause error) *EnhancedParseError {
 	baseError := Wrap(cause, CategoryParse, code, message)
+	if baseError == nil {
+		// Wrap returns nil without a cause; most parse errors have none
+		baseError = New(CategoryParse, code, message)
+	}
 	
 	// Add context to base error
 	if context != nil {